@@ -0,0 +1,41 @@
+//go:build !test
+
+package main
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/config"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+)
+
+// configUsage - справка по подкоманде config
+const configUsage = `Usage: gophermart config print [flags]
+
+Prints the effective configuration (defaults < config file < env < flags) with secrets redacted.
+Accepts the same flags as the server, including -c <file>.
+`
+
+// runConfigCommand - выполняет подкоманду config и возвращает код завершения процесса
+func runConfigCommand(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprint(os.Stderr, configUsage)
+		return 2
+	}
+
+	cfg, err := config.Load(args[1:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to print configuration: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -3,14 +3,23 @@
 package main
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/certs"
 	"beliaev-aa/yp-gofermart/internal/gofermart/config"
 	"beliaev-aa/yp-gofermart/internal/gofermart/http-server"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/health"
+	"beliaev-aa/yp-gofermart/internal/gofermart/lifecycle"
+	"beliaev-aa/yp-gofermart/internal/gofermart/metrics"
+	"beliaev-aa/yp-gofermart/internal/gofermart/outbox"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/internal/gofermart/storage"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
 	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"beliaev-aa/yp-gofermart/internal/gofermart/workers"
 	"context"
+	"crypto/tls"
 	"errors"
+	"flag"
 	"github.com/go-chi/chi/v5"
 	"go.uber.org/zap"
 	"net/http"
@@ -22,19 +31,70 @@ import (
 )
 
 func main() {
-	// Инициализация сервиса логирования
-	logger := utils.NewLogger()
+	// Подкоманда config print выводит действующую конфигурацию и завершает работу
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:]))
+	}
+	// Подкоманда users grant назначает роль пользователю в хранилище, например первому администратору
+	if len(os.Args) > 1 && os.Args[1] == "users" {
+		os.Exit(runUsersCommand(os.Args[2:]))
+	}
+
+	// Инициализация сервиса логирования; уровень журнала задаётся конфигурацией и меняется по SIGHUP
+	logLevel := zap.NewAtomicLevel()
+	logger := utils.NewLogger(logLevel)
 
 	// Загрузка конфигурации приложения
 	cfg, err := config.LoadConfig()
+	if errors.Is(err, flag.ErrHelp) {
+		// Справка по флагам уже выведена
+		os.Exit(0)
+	}
 	if err != nil {
 		// Завершение работы приложения с ошибкой, если конфигурация не загружена
 		logger.Fatal("Server failed to load configuration.", zap.Error(err))
 	}
+	if err := logLevel.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		logger.Fatal("Invalid log level.", zap.Error(err))
+	}
 
-	// Создание подключения к базе данных
-	store, err := storage.NewStorage(cfg.DatabaseURI, logger)
+	// Сборка цепочки проверок номера заказа
+	orderValidator, err := validation.New(cfg.OrderValidatorOptions())
 	if err != nil {
+		logger.Fatal("Invalid order number validation settings.", zap.Error(err))
+	}
+
+	// Сертификат TLS перечитывается с диска при изменении; служебный сервер при заданном CA клиентов требует mTLS
+	var (
+		certReloader        *certs.Reloader
+		serverTLS, adminTLS *tls.Config
+	)
+	if cfg.TLSEnabled() {
+		if certReloader, err = certs.NewReloader(cfg.TLSCertFile, cfg.TLSKeyFile, logger); err != nil {
+			logger.Fatal("Failed to load TLS certificate.", zap.Error(err))
+		}
+		if serverTLS, err = certs.ServerConfig(certReloader, ""); err != nil {
+			logger.Fatal("Invalid TLS settings.", zap.Error(err))
+		}
+		if adminTLS, err = certs.ServerConfig(certReloader, cfg.AdminClientCAFile); err != nil {
+			logger.Fatal("Invalid admin client CA.", zap.Error(err))
+		}
+	}
+
+	// Получатель событий outbox; при значении none события накапливаются в таблице без доставки
+	outboxSink, err := outbox.New(cfg.OutboxSinkOptions())
+	if err != nil {
+		logger.Fatal("Invalid outbox sink settings.", zap.Error(err))
+	}
+
+	// Создание подключения к базе данных; оно открывается последним, чтобы ошибки настроек выше не оставляли его незакрытым
+	store, err := storage.NewStorage(cfg.DatabaseURI, storageOptions(cfg), logger)
+	if err != nil {
+		if outboxSink != nil {
+			if err := outboxSink.Close(); err != nil {
+				logger.Error("Failed to close outbox sink", zap.Error(err))
+			}
+		}
 		// Завершение работы приложения с ошибкой при подключении к базе данных
 		logger.Fatal("Failed to connect to database.", zap.Error(err))
 	}
@@ -43,60 +103,169 @@ func main() {
 	accrualService := services.NewAccrualService(cfg.AccrualSystemAddress, logger)
 
 	// Инициализация сервиса для работы с заказами
-	orderService := services.NewOrderService(accrualService, store.OrderRepo, store.UserRepo, logger)
+	orderService := services.NewOrderService(accrualService, store.TxManager, store.OrderRepo, store.UserRepo, store.AuditRepo, store.OutboxRepo, store.WebhookRepo, store.NotifyRepo, orderValidator, logger)
+
+	// Сервис webhook пользователей; доставки ставятся в очередь при обработке заказов
+	webhookService := services.NewWebhookService(store.WebhookRepo, services.WebhookOptions{
+		MaxAttempts:          cfg.WebhookMaxAttempts,
+		AllowPrivateNetworks: cfg.WebhookAllowPrivate,
+	}, logger)
+
+	// Брокер изменений заказов для потоков событий; изменения поступают через хранилище от всех экземпляров приложения
+	orderUpdates := services.NewOrderUpdateBroker(services.OrderUpdateBrokerOptions{MaxStreamsPerUser: cfg.OrderStreamMaxPerUser}, logger)
+
+	// Ограничитель частоты запросов; корзины в PostgreSQL общие для всех экземпляров приложения
+	var rateLimitRepo repository.RateLimitRepository = repository.NewRateLimitRepositoryMemory()
+	if cfg.RateLimitBackend == config.RateLimitBackendPostgres {
+		rateLimitRepo = store.RateLimitRepo
+	}
+	rateLimiter := services.NewRateLimiter(rateLimitRepo, cfg.RateLimitPolicies(), logger)
 
 	// Создание сервисов приложения
 	appServices := &services.AppServices{
-		AuthService:  services.NewAuthService([]byte(cfg.JWTSecret), store.UserRepo, logger),
-		OrderService: orderService,
-		UserService:  services.NewUserService(store.UserRepo, store.WithdrawalRepo, logger),
+		AuditService:     services.NewAuditService(store.AuditRepo, logger),
+		AuthService:      services.NewAuthService([]byte(cfg.JWTSecret), cfg.JWTTTL, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+		OrderService:     orderService,
+		OrderUpdates:     orderUpdates,
+		OrderValidator:   orderValidator,
+		RateLimiter:      rateLimiter,
+		StatementService: services.NewStatementService(store.UserRepo, store.OrderRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.StatementRepo, logger),
+		UserService:      services.NewUserService(store.TxManager, store.UserRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.AuditRepo, store.OutboxRepo, logger),
+		WebhookService:   webhookService,
 	}
 
+	// Перезагрузка конфигурации по SIGHUP: файл конфигурации и файлы секретов перечитываются, уровень журнала
+	// ключи подписи JWT и ограничения частоты запросов применяются сразу, об изменении остальных параметров сообщается в журнале
+	reloader := config.NewReloader(cfg, config.LoadConfig, func(next *config.Config) error {
+		if err := logLevel.UnmarshalText([]byte(next.LogLevel)); err != nil {
+			return err
+		}
+		appServices.AuthService.UpdateKeys([]byte(next.JWTSecret), next.JWTTTL)
+		rateLimiter.SetPolicies(next.RateLimitPolicies())
+		return nil
+	}, logger)
+	reloadSignals := make(chan os.Signal, 1)
+	signal.Notify(reloadSignals, syscall.SIGHUP)
+
+	// Метрики запросов основного сервера отдаются служебным сервером
+	metricsRegistry := metrics.NewRegistry()
+
 	// Инициализация роутера Chi
 	r := chi.NewRouter()
-	httpserver.RegisterRoutes(r, appServices, logger)
+	httpserver.RegisterRoutes(r, appServices, httpserver.Options{
+		GzipLevel:              cfg.GzipLevel,
+		MaxBodySize:            int64(cfg.MaxBodySize),
+		OrdersBatchLimit:       cfg.OrdersBatchLimit,
+		OrdersBatchMaxBodySize: int64(cfg.OrdersBatchMaxBodySize),
+		OrderStreamHeartbeat:   cfg.OrderStreamHeartbeat,
+		RequestTimeout:         cfg.RequestTimeout,
+		SwaggerUI:              cfg.SwaggerUI,
+		Metrics:                metricsRegistry,
+	}, logger)
 
-	// Логирование запуска сервера
-	logger.Info("Starting server on " + cfg.RunAddress)
+	// Остановка по SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Создаем канал для захвата системных сигналов
-	stopChan := make(chan os.Signal, 1)
-	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
+	// Компоненты останавливаются в порядке, обратном добавлению: сначала HTTP-сервер перестаёт принимать запросы
+	// и дожидается текущих, затем останавливаются служебный сервер, фоновые процессы, получатель outbox и подключения к базе данных
+	app := lifecycle.New(lifecycle.Options{StopTimeout: cfg.ShutdownTimeout}, logger)
+	app.Append(lifecycle.Closer("database", store.Close))
+	if outboxSink != nil {
+		app.Append(lifecycle.Closer("outbox sink", outboxSink.Close))
+	}
+	app.Append(lifecycle.Workers("workers", func(ctx context.Context, wg *sync.WaitGroup) {
+		// Обновление статусов заказов
+		wg.Add(1)
+		go workers.StartOrderStatusUpdater(ctx, orderService, cfg.OrderUpdateInterval, cfg.OrderUpdateTimeout, logger, wg)
 
-	// Создаем контекст для управления goroutine обновления статусов заказов
-	ctx, cancel := context.WithCancel(context.Background())
-	var wg sync.WaitGroup
+		// Формирование ежемесячных выписок
+		wg.Add(1)
+		go workers.StartMonthlyStatementGenerator(ctx, appServices.StatementService, logger, wg)
 
-	// Запуск фонового процесса для обновления статусов заказов
-	wg.Add(1)
-	go workers.StartOrderStatusUpdater(ctx, orderService, logger, &wg)
+		// Доставка событий outbox
+		if outboxSink != nil {
+			relay := services.NewOutboxRelay(store.OutboxRepo, outboxSink, services.OutboxRelayOptions{BatchSize: cfg.OutboxBatchSize, MaxAttempts: cfg.OutboxMaxAttempts}, logger)
+			wg.Add(1)
+			go workers.StartOutboxRelay(ctx, relay, cfg.OutboxPollInterval, logger, wg)
+		}
 
-	// Запуск HTTP-сервера в отдельной goroutine
-	server := &http.Server{
-		Addr:    cfg.RunAddress,
-		Handler: r,
-	}
+		// Доставка событий на webhook пользователей
+		wg.Add(1)
+		go workers.StartWebhookDispatcher(ctx, webhookService, cfg.WebhookPollInterval, logger, wg)
 
-	go func() {
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
-			logger.Fatal("Server failed to start", zap.Error(err))
+		// Перезагрузка конфигурации
+		wg.Add(1)
+		go workers.StartConfigReloader(ctx, reloadSignals, reloader, logger, wg)
+
+		// Передача изменений заказов в потоки событий
+		wg.Add(1)
+		go workers.StartOrderUpdateListener(ctx, store.NotifyRepo, orderUpdates, 5*time.Second, logger, wg)
+
+		// Перечитывание сертификата TLS
+		if certReloader != nil && cfg.TLSReloadInterval > 0 {
+			wg.Add(1)
+			go workers.StartCertificateReloader(ctx, certReloader, cfg.TLSReloadInterval, logger, wg)
 		}
-	}()
 
-	<-stopChan
-	logger.Info("Shutting down server...")
+		// Удаление неиспользуемых корзин ограничения частоты запросов
+		if cfg.RateLimitPruneInterval > 0 {
+			wg.Add(1)
+			go workers.StartRateLimitPruner(ctx, rateLimiter, cfg.RateLimitPruneInterval, logger, wg)
+		}
+	}))
 
-	cancel()
+	// Служебный сервер останавливается после основного, чтобы /readyz сообщал об остановке, пока текущие запросы завершаются
+	readiness := health.NewReadinessHandler(store, logger)
+	if cfg.AdminAddress != "" {
+		adminRouter := chi.NewRouter()
+		httpserver.RegisterInternalRoutes(adminRouter, metricsRegistry, readiness, logger)
+		app.Append(app.HTTPServer("admin", &http.Server{
+			Addr:              cfg.AdminAddress,
+			Handler:           adminRouter,
+			TLSConfig:         adminTLS,
+			ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+			WriteTimeout:      cfg.HTTPWriteTimeout,
+			IdleTimeout:       cfg.HTTPIdleTimeout,
+		}))
+	}
 
-	wg.Wait()
+	server := &http.Server{
+		Addr:              cfg.RunAddress,
+		Handler:           r,
+		TLSConfig:         serverTLS,
+		ReadHeaderTimeout: cfg.HTTPReadHeaderTimeout,
+		WriteTimeout:      cfg.HTTPWriteTimeout,
+		IdleTimeout:       cfg.HTTPIdleTimeout,
+	}
+	// Открытые потоки событий закрываются при остановке, иначе Shutdown ждал бы их до истечения таймаута
+	server.RegisterOnShutdown(orderUpdates.Close)
+	server.RegisterOnShutdown(readiness.ShutDown)
+	httpServer := app.HTTPServer("http", server)
+	httpServer.StopTimeout = cfg.HTTPShutdownTimeout
+	app.Append(httpServer)
+
+	if err := app.Run(ctx); err != nil {
+		logger.Error("Server stopped with errors", zap.Error(err))
+		os.Exit(1)
+	}
+	logger.Info("Server gracefully stopped")
+}
 
-	// Завершаем работу HTTP-сервера с таймаутом для завершения текущих запросов
-	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancelShutdown()
+// storageOptions - параметры подключения к базе данных из конфигурации
+func storageOptions(cfg *config.Config) storage.Options {
+	return storage.Options{
+		MaxOpenConns:     cfg.DBMaxOpenConns,
+		MaxIdleConns:     cfg.DBMaxIdleConns,
+		ConnMaxLifetime:  cfg.DBConnMaxLifetime,
+		ConnMaxIdleTime:  cfg.DBConnMaxIdleTime,
+		StatementTimeout: cfg.DBStatementTimeout,
+		LockTimeout:      cfg.DBLockTimeout,
+		PrepareStmt:      cfg.DBPrepareStmt,
+		LogLevel:         cfg.DBLogLevel,
+		ConnectTimeout:   cfg.DBConnectTimeout,
 
-	if err := server.Shutdown(ctxShutdown); err != nil {
-		logger.Fatal("Server shutdown failed", zap.Error(err))
-	} else {
-		logger.Info("Server gracefully stopped")
+		ReplicaDSN:          cfg.DatabaseReplicaURI,
+		ReplicaStickyWindow: cfg.DBReplicaSticky,
 	}
 }
@@ -0,0 +1,82 @@
+//go:build !test
+
+package main
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/config"
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"strings"
+)
+
+// usersUsage - справка по подкоманде users
+const usersUsage = `Usage: gophermart users grant <login> <role> [flags]
+
+Adds a role (user, partner, support, admin) to a registered user in the storage, e.g. to appoint
+the first administrator. /api/admin picks up the role on the next request, other routes on the next login.
+Accepts the same flags as the server, including -c <file>.
+`
+
+// usersCommandActor - автор изменения ролей в журнале аудита
+const usersCommandActor = "cli"
+
+// runUsersCommand - выполняет подкоманду users и возвращает код завершения процесса
+func runUsersCommand(args []string) int {
+	if len(args) < 3 || args[0] != "grant" || strings.HasPrefix(args[1], "-") || strings.HasPrefix(args[2], "-") {
+		fmt.Fprint(os.Stderr, usersUsage)
+		return 2
+	}
+	login, role := args[1], args[2]
+
+	cfg, err := config.Load(args[3:])
+	if err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		fmt.Fprintf(os.Stderr, "invalid configuration:\n%v\n", err)
+		return 1
+	}
+
+	logLevel := zap.NewAtomicLevel()
+	if err := logLevel.UnmarshalText([]byte(cfg.LogLevel)); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid log level: %v\n", err)
+		return 1
+	}
+	logger := utils.NewLogger(logLevel)
+
+	store, err := storage.NewStorage(cfg.DatabaseURI, storageOptions(cfg), logger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to connect to database: %v\n", err)
+		return 1
+	}
+	defer func() {
+		if err := store.Close(); err != nil {
+			logger.Error("Failed to close storage", zap.Error(err))
+		}
+	}()
+
+	roles, err := grantRole(context.Background(), services.NewUserService(store.TxManager, store.UserRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.AuditRepo, store.OutboxRepo, logger), login, role)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to grant role %q to %q: %v\n", role, login, err)
+		return 1
+	}
+	fmt.Fprintf(os.Stdout, "%s: %s\n", login, strings.Join(roles.Strings(), ","))
+	return 0
+}
+
+// grantRole - добавляет роль к сохраненным ролям пользователя и возвращает новый набор ролей
+func grantRole(ctx context.Context, userService *services.UserService, login, role string) (domain.Roles, error) {
+	user, err := userService.GetUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	return userService.SetRoles(ctx, login, append(user.Roles.Strings(), role), usersCommandActor)
+}
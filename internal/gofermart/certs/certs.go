@@ -0,0 +1,111 @@
+package certs
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"time"
+)
+
+var ErrClientCA = errors.New("client CA file contains no certificates")
+
+// Reloader - хранит сертификат TLS и перечитывает его с диска при изменении файлов, не прерывая
+// установленные соединения: новые рукопожатия получают сертификат через GetCertificate
+type Reloader struct {
+	certFile string
+	keyFile  string
+	logger   *zap.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	modified time.Time
+}
+
+// NewReloader - загружает сертификат и ключ; ошибка загрузки прерывает запуск приложения
+func NewReloader(certFile, keyFile string, logger *zap.Logger) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile, logger: logger}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetCertificate - возвращает текущий сертификат; используется как tls.Config.GetCertificate
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Reload - перечитывает сертификат и ключ, если файлы изменились с прошлой загрузки, и сообщает, был ли
+// сертификат заменён. При ошибке (например, новый сертификат записан, а ключ ещё нет) остаётся прежний.
+func (r *Reloader) Reload() (bool, error) {
+	modified, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := r.cert != nil && modified.Equal(r.modified)
+	r.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modified = modified
+	r.mu.Unlock()
+
+	r.logger.Info("TLS certificate loaded", zap.String("cert", r.certFile), zap.Time("modified", modified))
+	return true, nil
+}
+
+// ServerConfig - возвращает конфигурацию TLS сервера с сертификатом из reloader. Если задан clientCAFile,
+// сервер требует клиентский сертификат, подписанный этим CA (mTLS). HTTP/2 согласуется через ALPN.
+func ServerConfig(reloader *Reloader, clientCAFile string) (*tls.Config, error) {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: reloader.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+	if clientCAFile == "" {
+		return config, nil
+	}
+
+	pem, err := os.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("%s: %w", clientCAFile, ErrClientCA)
+	}
+	config.ClientCAs = pool
+	config.ClientAuth = tls.RequireAndVerifyClientCert
+	return config, nil
+}
+
+// latestModTime - возвращает время последнего изменения из нескольких файлов
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
@@ -0,0 +1,212 @@
+package certs
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"go.uber.org/zap"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeCertificate - создаёт самоподписанный сертификат с ключом и записывает их в PEM-файлы
+func writeCertificate(t *testing.T, dir, name, commonName string) (certFile, keyFile string) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	if err := os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600); err != nil {
+		t.Fatalf("failed to write certificate: %v", err)
+	}
+	if err := os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600); err != nil {
+		t.Fatalf("failed to write key: %v", err)
+	}
+	return certFile, keyFile
+}
+
+// touch - сдвигает время изменения файлов, чтобы Reload заметил изменение независимо от точности часов ФС
+func touch(t *testing.T, modified time.Time, paths ...string) {
+	t.Helper()
+	for _, path := range paths {
+		if err := os.Chtimes(path, modified, modified); err != nil {
+			t.Fatalf("failed to change file times: %v", err)
+		}
+	}
+}
+
+// commonName - имя субъекта текущего сертификата reloader
+func commonName(t *testing.T, r *Reloader) string {
+	t.Helper()
+	cert, err := r.GetCertificate(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return leaf.Subject.CommonName
+}
+
+func TestNewReloader_MissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := NewReloader(filepath.Join(dir, "missing.crt"), filepath.Join(dir, "missing.key"), zap.NewNop()); err == nil {
+		t.Fatal("expected error for missing certificate files")
+	}
+}
+
+func TestReloader_Reload(t *testing.T) {
+	testCases := []struct {
+		name             string
+		update           func(t *testing.T, dir, certFile, keyFile string)
+		expectedReloaded bool
+		expectErr        bool
+		expectedName     string
+	}{
+		{
+			name:         "Unchanged_Files_Are_Not_Reloaded",
+			update:       func(*testing.T, string, string, string) {},
+			expectedName: "first",
+		},
+		{
+			name: "Changed_Files_Are_Reloaded",
+			update: func(t *testing.T, dir, certFile, keyFile string) {
+				writeCertificate(t, dir, "server", "second")
+				touch(t, time.Now().Add(time.Minute), certFile, keyFile)
+			},
+			expectedReloaded: true,
+			expectedName:     "second",
+		},
+		{
+			name: "Mismatched_Key_Keeps_Previous_Certificate",
+			update: func(t *testing.T, dir, certFile, keyFile string) {
+				_, otherKey := writeCertificate(t, dir, "other", "other")
+				data, err := os.ReadFile(otherKey)
+				if err != nil {
+					t.Fatalf("failed to read key: %v", err)
+				}
+				if err := os.WriteFile(keyFile, data, 0o600); err != nil {
+					t.Fatalf("failed to write key: %v", err)
+				}
+				touch(t, time.Now().Add(time.Minute), keyFile)
+			},
+			expectErr:    true,
+			expectedName: "first",
+		},
+		{
+			name: "Removed_Files_Keep_Previous_Certificate",
+			update: func(t *testing.T, _, certFile, _ string) {
+				if err := os.Remove(certFile); err != nil {
+					t.Fatalf("failed to remove certificate: %v", err)
+				}
+			},
+			expectErr:    true,
+			expectedName: "first",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			certFile, keyFile := writeCertificate(t, dir, "server", "first")
+			reloader, err := NewReloader(certFile, keyFile, zap.NewNop())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			tc.update(t, dir, certFile, keyFile)
+			reloaded, err := reloader.Reload()
+
+			if (err != nil) != tc.expectErr {
+				t.Errorf("expected error: %v, got: %v", tc.expectErr, err)
+			}
+			if reloaded != tc.expectedReloaded {
+				t.Errorf("expected reloaded: %v, got: %v", tc.expectedReloaded, reloaded)
+			}
+			if name := commonName(t, reloader); name != tc.expectedName {
+				t.Errorf("expected certificate %q, got %q", tc.expectedName, name)
+			}
+		})
+	}
+}
+
+func TestServerConfig(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := writeCertificate(t, dir, "server", "server")
+	caFile, _ := writeCertificate(t, dir, "ca", "ca")
+	invalidCAFile := filepath.Join(dir, "invalid.crt")
+	if err := os.WriteFile(invalidCAFile, []byte("not a certificate"), 0o600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	reloader, err := NewReloader(certFile, keyFile, zap.NewNop())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testCases := []struct {
+		name               string
+		clientCAFile       string
+		expectedErr        error
+		expectErr          bool
+		expectedClientAuth tls.ClientAuthType
+	}{
+		{name: "Without_Client_CA", expectedClientAuth: tls.NoClientCert},
+		{name: "With_Client_CA_Requires_Client_Certificate", clientCAFile: caFile, expectedClientAuth: tls.RequireAndVerifyClientCert},
+		{name: "Invalid_Client_CA", clientCAFile: invalidCAFile, expectedErr: ErrClientCA, expectErr: true},
+		{name: "Missing_Client_CA", clientCAFile: filepath.Join(dir, "missing.crt"), expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := ServerConfig(reloader, tc.clientCAFile)
+
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("expected error: %v, got: %v", tc.expectErr, err)
+			}
+			if tc.expectedErr != nil && !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error: %v, got: %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if config.ClientAuth != tc.expectedClientAuth {
+				t.Errorf("expected client auth %v, got %v", tc.expectedClientAuth, config.ClientAuth)
+			}
+			if config.MinVersion != tls.VersionTLS12 {
+				t.Errorf("expected TLS 1.2 minimum, got %x", config.MinVersion)
+			}
+		})
+	}
+}
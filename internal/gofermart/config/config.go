@@ -1,9 +1,15 @@
 package config
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/outbox"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"errors"
 	"flag"
+	"fmt"
 	"os"
+	"strings"
+	"time"
 )
 
 const (
@@ -11,12 +17,94 @@ const (
 	defaultDatabaseURI          = ""
 	defaultJWTSecret            = "your-256-bit-secret-key"
 	defaultRunAddress           = "localhost:8080"
+	defaultJWTTTL               = 24 * time.Hour
+	defaultLogLevel             = "info"
+
+	defaultDBMaxOpenConns     = 25
+	defaultDBMaxIdleConns     = 5
+	defaultDBConnMaxLifetime  = 30 * time.Minute
+	defaultDBConnMaxIdleTime  = 5 * time.Minute
+	defaultDBStatementTimeout = 5 * time.Second
+	defaultDBLockTimeout      = 2 * time.Second
+	defaultDBPrepareStmt      = true
+	defaultDBLogLevel         = "warn"
+	defaultDBConnectTimeout   = 30 * time.Second
+	defaultDBReplicaURI       = ""
+	defaultDBReplicaSticky    = 5 * time.Second
+
+	defaultRequestTimeout = 10 * time.Second
+	defaultGzipLevel      = 5
+	defaultMaxBodySize    = 64 << 10
+
+	defaultShutdownTimeout     = 10 * time.Second
+	defaultHTTPShutdownTimeout = 15 * time.Second
+
+	defaultHTTPReadHeaderTimeout = 5 * time.Second
+	defaultHTTPWriteTimeout      = 30 * time.Second
+	defaultHTTPIdleTimeout       = 2 * time.Minute
+	defaultTLSReloadInterval     = 30 * time.Second
+	defaultAdminAddress          = ""
+
+	defaultOrderUpdateInterval = time.Second
+	defaultOrderUpdateTimeout  = 4 * time.Second
+
+	defaultOrdersBatchLimit       = 500
+	defaultOrdersBatchMaxBodySize = 1 << 20
+
+	defaultOrderValidators = validation.RuleLength + "," + validation.RuleLuhn
+	defaultOrderMinLength  = validation.DefaultMinLength
+	defaultOrderMaxLength  = validation.DefaultMaxLength
+
+	defaultSwaggerUI = false
+
+	defaultOutboxSink         = outbox.SinkNone
+	defaultOutboxPollInterval = time.Second
+	defaultOutboxBatchSize    = 100
+	defaultOutboxMaxAttempts  = 20
+
+	defaultWebhookPollInterval = time.Second
+	defaultWebhookMaxAttempts  = 8
+	defaultWebhookAllowPrivate = false
+
+	defaultOrderStreamHeartbeat  = 15 * time.Second
+	defaultOrderStreamMaxPerUser = 5
+
+	defaultRateLimitBackend       = RateLimitBackendMemory
+	defaultRateLimitAuth          = "20/1m"
+	defaultRateLimitAPI           = "600/1m"
+	defaultRateLimitOrders        = "60/1m"
+	defaultRateLimitPruneInterval = 5 * time.Minute
+)
+
+// Хранилища корзин ограничения частоты запросов
+const (
+	RateLimitBackendMemory   = "memory"   // в памяти процесса, ограничение действует на каждый экземпляр отдельно
+	RateLimitBackendPostgres = "postgres" // в базе данных, ограничение общее для всех экземпляров
 )
 
 var (
-	ErrAccrualConfig    = errors.New("AccrualSystemAddress is not configured")
-	ErrDatabaseConfig   = errors.New("DatabaseURI is not configured")
-	ErrRunAddressConfig = errors.New("RunAddress is not configured")
+	ErrAccrualConfig        = errors.New("AccrualSystemAddress is not configured")
+	ErrBodySizeConfig       = errors.New("request body size limits must not be negative")
+	ErrConfigFileFormat     = errors.New("config file must have a .yaml, .yml or .toml extension")
+	ErrConfigValue          = errors.New("invalid configuration value")
+	ErrDatabaseConfig       = errors.New("DatabaseURI is not configured")
+	ErrDBLogLevelConfig     = errors.New("DBLogLevel must be one of: silent, error, warn, info")
+	ErrDBPoolConfig         = errors.New("database pool settings are invalid")
+	ErrGzipLevelConfig      = errors.New("GzipLevel must be between 1 and 9")
+	ErrHTTPTimeoutConfig    = errors.New("HTTP server timeouts are invalid")
+	ErrJWTConfig            = errors.New("JWTTTL must be positive")
+	ErrLogLevelConfig       = errors.New("LogLevel must be one of: debug, info, warn, error")
+	ErrOrdersBatchConfig    = errors.New("OrdersBatchLimit must be positive")
+	ErrOrderStreamConfig    = errors.New("order stream settings are invalid")
+	ErrOrderUpdateConfig    = errors.New("order status polling interval and timeout must be positive")
+	ErrOrderValidatorConfig = errors.New("order number validation settings are invalid")
+	ErrOutboxConfig         = errors.New("outbox settings are invalid")
+	ErrRateLimitConfig      = errors.New("rate limit settings are invalid")
+	ErrRequestTimeoutConfig = errors.New("RequestTimeout must not be negative")
+	ErrRunAddressConfig     = errors.New("RunAddress is not configured")
+	ErrShutdownConfig       = errors.New("shutdown timeouts must not be negative")
+	ErrTLSConfig            = errors.New("TLS settings are invalid")
+	ErrWebhookConfig        = errors.New("webhook delivery settings are invalid")
 )
 
 // Config - описывает конфигурацию приложения
@@ -25,51 +113,387 @@ type Config struct {
 	DatabaseURI          string
 	AccrualSystemAddress string
 	JWTSecret            string
+
+	// Время жизни выпускаемых JWT-токенов
+	JWTTTL time.Duration
+
+	// Уровень журнала приложения: debug, info, warn, error
+	LogLevel string
+
+	// Настройки пула соединений и сессии базы данных
+	DBMaxOpenConns     int
+	DBMaxIdleConns     int
+	DBConnMaxLifetime  time.Duration
+	DBConnMaxIdleTime  time.Duration
+	DBStatementTimeout time.Duration
+	DBLockTimeout      time.Duration
+	DBPrepareStmt      bool
+	DBLogLevel         string
+	DBConnectTimeout   time.Duration
+
+	// Реплика для чтений и окно read-your-writes после записи пользователя
+	DatabaseReplicaURI string
+	DBReplicaSticky    time.Duration
+
+	// Предельное время обработки HTTP-запроса, по истечении которого запросы к хранилищу прерываются
+	RequestTimeout time.Duration
+
+	// Уровень сжатия ответов gzip и deflate (1–9)
+	GzipLevel int
+
+	// Максимальный размер тела запроса в байтах
+	MaxBodySize int
+
+	// Предельное время остановки: ожидания завершения текущих HTTP-запросов и остановки каждого из остальных компонентов
+	HTTPShutdownTimeout time.Duration
+	ShutdownTimeout     time.Duration
+
+	// Таймауты HTTP-сервера: чтение заголовков запроса (защита от slowloris), запись ответа и простой keep-alive соединения
+	HTTPReadHeaderTimeout time.Duration
+	HTTPWriteTimeout      time.Duration
+	HTTPIdleTimeout       time.Duration
+
+	// Сертификат и ключ TLS (пустые — HTTP без шифрования) и период проверки их изменения на диске
+	TLSCertFile       string
+	TLSKeyFile        string
+	TLSReloadInterval time.Duration
+
+	// Адрес служебного сервера с /metrics, /healthz и /readyz (пустой — сервер не запускается)
+	// и сертификат CA клиентов, при заданном которым служебный сервер требует mTLS
+	AdminAddress      string
+	AdminClientCAFile string
+
+	// Опрос статусов заказов в системе начислений: период и предельное время одного прохода
+	OrderUpdateInterval time.Duration
+	OrderUpdateTimeout  time.Duration
+
+	// Максимальное количество номеров заказов и размер тела в байтах одного пакетного запроса
+	OrdersBatchLimit       int
+	OrdersBatchMaxBodySize int
+
+	// Цепочка проверок номера заказа (через запятую: luhn, length, prefix, regex) и параметры правил
+	OrderValidators string
+	OrderMinLength  int
+	OrderMaxLength  int
+	OrderPrefixes   string
+	OrderPattern    string
+	// Префиксы номеров, выделенные партнёрам: login:префикс|префикс через запятую
+	OrderPartnerPrefixes string
+
+	// Публикация Swagger UI по адресу /api/docs
+	SwaggerUI bool
+
+	// Доставка событий outbox: получатель (none, stdout, file, webhook, nats, kafka), его адрес, тема или файл,
+	// период опроса таблицы outbox и размер порции
+	OutboxSink         string
+	OutboxURL          string
+	OutboxTopic        string
+	OutboxFile         string
+	OutboxPollInterval time.Duration
+	OutboxBatchSize    int
+	OutboxMaxAttempts  int
+
+	// Доставка событий на webhook пользователей: период опроса очереди, число попыток до отказа
+	// и разрешение доставки на адреса внутренних сетей (только для разработки и тестов)
+	WebhookPollInterval time.Duration
+	WebhookMaxAttempts  int
+	WebhookAllowPrivate bool
+
+	// Поток изменений заказов (SSE): период heartbeat-комментариев и максимальное количество одновременных потоков пользователя
+	OrderStreamHeartbeat  time.Duration
+	OrderStreamMaxPerUser int
+
+	// Ограничение частоты запросов: хранилище корзин (memory, postgres), ограничения вида "60/1m" для входа
+	// и регистрации, остальных запросов пользователя и загрузки заказов, период удаления неиспользуемых корзин
+	RateLimitBackend       string
+	RateLimitAuth          string
+	RateLimitAPI           string
+	RateLimitOrders        string
+	RateLimitPruneInterval time.Duration
 }
 
-// LoadConfig - загружает конфигурацию, отдает приоритет переменным окружения
+// LoadConfig - загружает конфигурацию из аргументов командной строки процесса
 func LoadConfig() (*Config, error) {
-	cfg := &Config{}
+	return Load(os.Args[1:])
+}
 
-	flag.StringVar(&cfg.RunAddress, "a", defaultRunAddress, "Address and port to run the HTTP server")
-	flag.StringVar(&cfg.DatabaseURI, "d", defaultDatabaseURI, "PostgreSQL DSN")
-	flag.StringVar(&cfg.AccrualSystemAddress, "r", defaultAccrualSystemAddress, "Address of the accrual settlement system")
-	flag.StringVar(&cfg.JWTSecret, "s", defaultJWTSecret, "Your JWT-secret key")
-	flag.Parse()
+// Load - загружает конфигурацию. Источники применяются по возрастанию приоритета: значения по умолчанию,
+// файл конфигурации (-c или CONFIG_FILE), переменные окружения, флаги. Значение можно прочитать из файла,
+// указав путь к нему в переменной окружения с суффиксом _FILE или в ключе с суффиксом _file, чтобы секреты
+// не попадали в список процессов. Ошибки значений и проверки возвращаются все сразу.
+func Load(args []string) (*Config, error) {
+	cfg := defaultConfig()
 
-	if envRunAddress := os.Getenv("RUN_ADDRESS"); envRunAddress != "" {
-		cfg.RunAddress = envRunAddress
+	// Флаги разбираются в отдельную копию, чтобы применить их последними и только явно заданные
+	flagged := defaultConfig()
+	flags := flag.NewFlagSet("gophermart", flag.ContinueOnError)
+	var configFile string
+	flags.StringVar(&configFile, "c", "", "Path to a YAML or TOML config file")
+	for _, s := range settings {
+		s.defineFlag(flags, flagged)
 	}
-
-	if envDatabaseURI := os.Getenv("DATABASE_URI"); envDatabaseURI != "" {
-		cfg.DatabaseURI = envDatabaseURI
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if configFile == "" {
+		configFile = os.Getenv("CONFIG_FILE")
 	}
 
-	if envAccrualAddress := os.Getenv("ACCRUAL_SYSTEM_ADDRESS"); envAccrualAddress != "" {
-		cfg.AccrualSystemAddress = envAccrualAddress
+	var errs []error
+	if configFile != "" {
+		values, err := readFile(configFile)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range sortedKeys(values) {
+			fileValue := values[key]
+			s, ok := lookupSetting(key)
+			if !ok {
+				// Ключ с суффиксом _file задаёт путь к файлу со значением параметра
+				if s, ok = lookupSetting(strings.TrimSuffix(key, fileSuffixKey)); !ok || !strings.HasSuffix(key, fileSuffixKey) {
+					errs = append(errs, fmt.Errorf("%w: %s: unknown key %q", ErrConfigValue, configFile, key))
+					continue
+				}
+				if _, duplicate := values[s.key]; duplicate {
+					errs = append(errs, fmt.Errorf("%w: %s: both %s and %s are set", ErrConfigValue, configFile, s.key, key))
+					continue
+				}
+				var err error
+				if fileValue, err = readSecretFile(fileValue); err != nil {
+					errs = append(errs, fmt.Errorf("%w: %s: %s: %v", ErrConfigValue, configFile, key, err))
+					continue
+				}
+			}
+			if err := s.bind(cfg).Set(fileValue); err != nil {
+				errs = append(errs, fmt.Errorf("%w: %s: %s: %v", ErrConfigValue, configFile, s.key, err))
+			}
+		}
 	}
 
-	if envJWTSecret := os.Getenv("JWT_SECRET"); envJWTSecret != "" {
-		cfg.JWTSecret = envJWTSecret
+	for _, s := range settings {
+		envValue, err := s.lookupEnv()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %v", ErrConfigValue, s.envName(), err))
+			continue
+		}
+		if envValue != "" {
+			if err := s.bind(cfg).Set(envValue); err != nil {
+				errs = append(errs, fmt.Errorf("%w: invalid %s: %v", ErrConfigValue, s.envName(), err))
+			}
+		}
 	}
 
-	if err := validateConfig(cfg); err != nil {
+	flags.Visit(func(f *flag.Flag) {
+		if s, ok := lookupFlag(f.Name); ok {
+			_ = s.bind(cfg).Set(f.Value.String())
+		}
+	})
+
+	if err := errors.Join(append(errs, validateConfig(cfg))...); err != nil {
 		return nil, err
 	}
 
 	return cfg, nil
 }
 
-// validateConfig - проверяет обязательные параметры конфигурации
+// defaultConfig - возвращает конфигурацию со значениями по умолчанию
+func defaultConfig() *Config {
+	return &Config{
+		RunAddress:           defaultRunAddress,
+		DatabaseURI:          defaultDatabaseURI,
+		AccrualSystemAddress: defaultAccrualSystemAddress,
+		JWTSecret:            defaultJWTSecret,
+		JWTTTL:               defaultJWTTTL,
+		LogLevel:             defaultLogLevel,
+
+		DBMaxOpenConns:     defaultDBMaxOpenConns,
+		DBMaxIdleConns:     defaultDBMaxIdleConns,
+		DBConnMaxLifetime:  defaultDBConnMaxLifetime,
+		DBConnMaxIdleTime:  defaultDBConnMaxIdleTime,
+		DBStatementTimeout: defaultDBStatementTimeout,
+		DBLockTimeout:      defaultDBLockTimeout,
+		DBPrepareStmt:      defaultDBPrepareStmt,
+		DBLogLevel:         defaultDBLogLevel,
+		DBConnectTimeout:   defaultDBConnectTimeout,
+		DatabaseReplicaURI: defaultDBReplicaURI,
+		DBReplicaSticky:    defaultDBReplicaSticky,
+
+		RequestTimeout: defaultRequestTimeout,
+		GzipLevel:      defaultGzipLevel,
+		MaxBodySize:    defaultMaxBodySize,
+
+		HTTPShutdownTimeout: defaultHTTPShutdownTimeout,
+		ShutdownTimeout:     defaultShutdownTimeout,
+
+		HTTPReadHeaderTimeout: defaultHTTPReadHeaderTimeout,
+		HTTPWriteTimeout:      defaultHTTPWriteTimeout,
+		HTTPIdleTimeout:       defaultHTTPIdleTimeout,
+		TLSReloadInterval:     defaultTLSReloadInterval,
+		AdminAddress:          defaultAdminAddress,
+
+		OrderUpdateInterval: defaultOrderUpdateInterval,
+		OrderUpdateTimeout:  defaultOrderUpdateTimeout,
+
+		OrdersBatchLimit:       defaultOrdersBatchLimit,
+		OrdersBatchMaxBodySize: defaultOrdersBatchMaxBodySize,
+
+		OrderValidators: defaultOrderValidators,
+		OrderMinLength:  defaultOrderMinLength,
+		OrderMaxLength:  defaultOrderMaxLength,
+
+		SwaggerUI: defaultSwaggerUI,
+
+		OutboxSink:         defaultOutboxSink,
+		OutboxPollInterval: defaultOutboxPollInterval,
+		OutboxBatchSize:    defaultOutboxBatchSize,
+		OutboxMaxAttempts:  defaultOutboxMaxAttempts,
+
+		WebhookPollInterval: defaultWebhookPollInterval,
+		WebhookMaxAttempts:  defaultWebhookMaxAttempts,
+		WebhookAllowPrivate: defaultWebhookAllowPrivate,
+
+		OrderStreamHeartbeat:  defaultOrderStreamHeartbeat,
+		OrderStreamMaxPerUser: defaultOrderStreamMaxPerUser,
+
+		RateLimitBackend:       defaultRateLimitBackend,
+		RateLimitAuth:          defaultRateLimitAuth,
+		RateLimitAPI:           defaultRateLimitAPI,
+		RateLimitOrders:        defaultRateLimitOrders,
+		RateLimitPruneInterval: defaultRateLimitPruneInterval,
+	}
+}
+
+// validateConfig - проверяет параметры конфигурации и возвращает все найденные ошибки
 func validateConfig(cfg *Config) error {
+	var errs []error
 	if cfg.AccrualSystemAddress == "" {
-		return ErrAccrualConfig
+		errs = append(errs, ErrAccrualConfig)
 	}
 	if cfg.DatabaseURI == "" {
-		return ErrDatabaseConfig
+		errs = append(errs, ErrDatabaseConfig)
 	}
 	if cfg.RunAddress == "" {
-		return ErrRunAddressConfig
+		errs = append(errs, ErrRunAddressConfig)
+	}
+	if cfg.JWTTTL <= 0 {
+		errs = append(errs, ErrJWTConfig)
+	}
+	switch cfg.LogLevel {
+	case "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, ErrLogLevelConfig)
+	}
+	if cfg.DBMaxOpenConns < 0 || cfg.DBMaxIdleConns < 0 ||
+		(cfg.DBMaxOpenConns > 0 && cfg.DBMaxIdleConns > cfg.DBMaxOpenConns) ||
+		cfg.DBConnMaxLifetime < 0 || cfg.DBConnMaxIdleTime < 0 ||
+		cfg.DBStatementTimeout < 0 || cfg.DBLockTimeout < 0 || cfg.DBConnectTimeout < 0 || cfg.DBReplicaSticky < 0 {
+		errs = append(errs, ErrDBPoolConfig)
+	}
+	switch cfg.DBLogLevel {
+	case "", "silent", "error", "warn", "info":
+	default:
+		errs = append(errs, ErrDBLogLevelConfig)
+	}
+	if cfg.RequestTimeout < 0 {
+		errs = append(errs, ErrRequestTimeoutConfig)
+	}
+	if cfg.GzipLevel < 1 || cfg.GzipLevel > 9 {
+		errs = append(errs, ErrGzipLevelConfig)
+	}
+	if cfg.MaxBodySize < 0 || cfg.OrdersBatchMaxBodySize < 0 {
+		errs = append(errs, ErrBodySizeConfig)
+	}
+	if cfg.HTTPShutdownTimeout < 0 || cfg.ShutdownTimeout < 0 {
+		errs = append(errs, ErrShutdownConfig)
+	}
+	if cfg.HTTPReadHeaderTimeout < 0 || cfg.HTTPWriteTimeout < 0 || cfg.HTTPIdleTimeout < 0 ||
+		(cfg.HTTPWriteTimeout > 0 && cfg.RequestTimeout > 0 && cfg.HTTPWriteTimeout <= cfg.RequestTimeout) {
+		errs = append(errs, ErrHTTPTimeoutConfig)
+	}
+	if (cfg.TLSCertFile == "") != (cfg.TLSKeyFile == "") || cfg.TLSReloadInterval < 0 ||
+		(cfg.AdminClientCAFile != "" && (cfg.TLSCertFile == "" || cfg.AdminAddress == "")) {
+		errs = append(errs, ErrTLSConfig)
+	}
+	if cfg.OrderUpdateInterval <= 0 || cfg.OrderUpdateTimeout <= 0 {
+		errs = append(errs, ErrOrderUpdateConfig)
+	}
+	if cfg.OrdersBatchLimit <= 0 {
+		errs = append(errs, ErrOrdersBatchConfig)
+	}
+	if _, err := validation.ParsePartnerPrefixes(cfg.OrderPartnerPrefixes); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %v", ErrOrderValidatorConfig, err))
+	} else if _, err := validation.New(cfg.OrderValidatorOptions()); err != nil {
+		errs = append(errs, fmt.Errorf("%w: %v", ErrOrderValidatorConfig, err))
+	}
+	if !outbox.ValidKind(cfg.OutboxSink) || cfg.OutboxPollInterval < 0 || cfg.OutboxBatchSize < 0 || cfg.OutboxMaxAttempts < 0 {
+		errs = append(errs, ErrOutboxConfig)
+	}
+	if cfg.WebhookPollInterval < 0 || cfg.WebhookMaxAttempts < 0 {
+		errs = append(errs, ErrWebhookConfig)
+	}
+	if cfg.OrderStreamHeartbeat < 0 || cfg.OrderStreamMaxPerUser < 0 {
+		errs = append(errs, ErrOrderStreamConfig)
+	}
+	switch cfg.RateLimitBackend {
+	case "", RateLimitBackendMemory, RateLimitBackendPostgres:
+	default:
+		errs = append(errs, fmt.Errorf("%w: unknown backend %q", ErrRateLimitConfig, cfg.RateLimitBackend))
+	}
+	for group, policy := range map[string]string{domain.RateLimitAuth: cfg.RateLimitAuth, domain.RateLimitAPI: cfg.RateLimitAPI, domain.RateLimitOrders: cfg.RateLimitOrders} {
+		if _, err := domain.ParseRateLimitPolicy(policy); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %v", ErrRateLimitConfig, group, err))
+		}
+	}
+	if cfg.RateLimitPruneInterval < 0 {
+		errs = append(errs, ErrRateLimitConfig)
+	}
+	return errors.Join(errs...)
+}
+
+// OrderValidatorOptions - возвращает параметры цепочки проверок номера заказа
+func (cfg *Config) OrderValidatorOptions() validation.Options {
+	partnerPrefixes, _ := validation.ParsePartnerPrefixes(cfg.OrderPartnerPrefixes)
+	return validation.Options{
+		Rules:           splitList(cfg.OrderValidators),
+		MinLength:       cfg.OrderMinLength,
+		MaxLength:       cfg.OrderMaxLength,
+		Prefixes:        splitList(cfg.OrderPrefixes),
+		PartnerPrefixes: partnerPrefixes,
+		Pattern:         cfg.OrderPattern,
+	}
+}
+
+// OutboxSinkOptions - возвращает параметры получателя событий outbox
+func (cfg *Config) OutboxSinkOptions() outbox.Options {
+	return outbox.Options{
+		Kind:  cfg.OutboxSink,
+		URL:   cfg.OutboxURL,
+		Topic: cfg.OutboxTopic,
+		File:  cfg.OutboxFile,
+	}
+}
+
+// TLSEnabled - сертификат и ключ TLS заданы, серверы принимают только HTTPS
+func (cfg *Config) TLSEnabled() bool {
+	return cfg.TLSCertFile != "" && cfg.TLSKeyFile != ""
+}
+
+// RateLimitPolicies - возвращает ограничения частоты запросов по группам маршрутов; вызывается после проверки конфигурации
+func (cfg *Config) RateLimitPolicies() map[string]domain.RateLimitPolicy {
+	policies := make(map[string]domain.RateLimitPolicy, 3)
+	policies[domain.RateLimitAuth], _ = domain.ParseRateLimitPolicy(cfg.RateLimitAuth)
+	policies[domain.RateLimitAPI], _ = domain.ParseRateLimitPolicy(cfg.RateLimitAPI)
+	policies[domain.RateLimitOrders], _ = domain.ParseRateLimitPolicy(cfg.RateLimitOrders)
+	return policies
+}
+
+// splitList - разбивает строку со значениями через запятую, отбрасывая пустые элементы
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
-	return nil
+	return items
 }
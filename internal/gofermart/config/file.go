@@ -0,0 +1,245 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// readFile - читает файл конфигурации YAML или TOML и возвращает значения параметров по ключам.
+// Вложенные таблицы разворачиваются в ключи через "_", поэтому db: {max_open_conns: 10} равнозначно
+// db_max_open_conns: 10; списки объединяются через запятую.
+func readFile(path string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var document map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	case ".toml":
+		if document, err = parseTOML(data); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: %w", path, ErrConfigFileFormat)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", document, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+// flatten - разворачивает вложенные таблицы в плоские ключи
+func flatten(prefix string, node map[string]any, values map[string]string) error {
+	keys := make([]string, 0, len(node))
+	for key := range node {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, name := range keys {
+		key := name
+		if prefix != "" {
+			key = prefix + "_" + name
+		}
+		switch item := node[name].(type) {
+		case map[string]any:
+			if err := flatten(key, item, values); err != nil {
+				return err
+			}
+		case []any:
+			parts := make([]string, len(item))
+			for i, element := range item {
+				if _, nested := element.(map[string]any); nested {
+					return fmt.Errorf("key %q: lists of tables are not supported", key)
+				}
+				parts[i] = fmt.Sprint(element)
+			}
+			values[key] = strings.Join(parts, ",")
+		case nil:
+			values[key] = ""
+		default:
+			values[key] = fmt.Sprint(item)
+		}
+	}
+	return nil
+}
+
+// parseTOML - разбирает подмножество TOML, достаточное для файла конфигурации: таблицы [section],
+// ключи с точками, строки в двойных и одинарных кавычках, целые числа, логические значения
+// и однострочные массивы из них
+func parseTOML(data []byte) (map[string]any, error) {
+	document := make(map[string]any)
+	table := document
+
+	for number, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			header, rest, ok := strings.Cut(line[1:], "]")
+			if !ok || strings.HasPrefix(header, "[") || !isTOMLComment(rest) {
+				return nil, fmt.Errorf("line %d: invalid table header", number+1)
+			}
+			var err error
+			if table, err = tomlTable(document, header); err != nil {
+				return nil, fmt.Errorf("line %d: %w", number+1, err)
+			}
+			continue
+		}
+
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value", number+1)
+		}
+		path, err := tomlKey(key)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		parsed, rest, err := tomlValue(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		if !isTOMLComment(rest) {
+			return nil, fmt.Errorf("line %d: unexpected %q after value", number+1, strings.TrimSpace(rest))
+		}
+
+		parent, err := tomlTable(table, strings.Join(path[:len(path)-1], "."))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number+1, err)
+		}
+		name := path[len(path)-1]
+		if _, exists := parent[name]; exists {
+			return nil, fmt.Errorf("line %d: duplicate key %q", number+1, name)
+		}
+		parent[name] = parsed
+	}
+	return document, nil
+}
+
+// tomlTable - возвращает таблицу по пути с точками, создавая недостающие
+func tomlTable(root map[string]any, header string) (map[string]any, error) {
+	if strings.TrimSpace(header) == "" {
+		return root, nil
+	}
+	path, err := tomlKey(header)
+	if err != nil {
+		return nil, err
+	}
+	table := root
+	for _, name := range path {
+		switch existing := table[name].(type) {
+		case nil:
+			next := make(map[string]any)
+			table[name] = next
+			table = next
+		case map[string]any:
+			table = existing
+		default:
+			return nil, fmt.Errorf("key %q is not a table", name)
+		}
+	}
+	return table, nil
+}
+
+// tomlKey - разбирает ключ, в том числе с точками
+func tomlKey(key string) ([]string, error) {
+	path := strings.Split(strings.TrimSpace(key), ".")
+	for i, part := range path {
+		part = strings.TrimSpace(part)
+		if unquoted, err := strconv.Unquote(part); err == nil {
+			part = unquoted
+		}
+		if part == "" || strings.ContainsAny(part, " \t\"'") {
+			return nil, fmt.Errorf("invalid key %q", key)
+		}
+		path[i] = part
+	}
+	return path, nil
+}
+
+// tomlValue - разбирает значение в начале raw и возвращает остаток строки
+func tomlValue(raw string) (any, string, error) {
+	switch {
+	case strings.HasPrefix(raw, `"""`), strings.HasPrefix(raw, "'''"):
+		return nil, "", errors.New("multi-line strings are not supported")
+	case strings.HasPrefix(raw, `"`):
+		for end := 1; end < len(raw); end++ {
+			switch raw[end] {
+			case '\\':
+				end++
+			case '"':
+				unquoted, err := strconv.Unquote(raw[:end+1])
+				if err != nil {
+					return nil, "", fmt.Errorf("invalid string %s", raw[:end+1])
+				}
+				return unquoted, raw[end+1:], nil
+			}
+		}
+		return nil, "", errors.New("unterminated string")
+	case strings.HasPrefix(raw, "'"):
+		literal, rest, ok := strings.Cut(raw[1:], "'")
+		if !ok {
+			return nil, "", errors.New("unterminated string")
+		}
+		return literal, rest, nil
+	case strings.HasPrefix(raw, "["):
+		var items []any
+		rest := strings.TrimSpace(raw[1:])
+		for !strings.HasPrefix(rest, "]") {
+			item, next, err := tomlValue(rest)
+			if err != nil {
+				return nil, "", err
+			}
+			if _, nested := item.([]any); nested {
+				return nil, "", errors.New("nested arrays are not supported")
+			}
+			items = append(items, item)
+			rest = strings.TrimSpace(next)
+			if strings.HasPrefix(rest, ",") {
+				rest = strings.TrimSpace(rest[1:])
+			} else if !strings.HasPrefix(rest, "]") {
+				return nil, "", errors.New("unterminated array")
+			}
+		}
+		return items, rest[1:], nil
+	}
+
+	token, rest := raw, ""
+	if end := strings.IndexAny(raw, " \t,]#"); end >= 0 {
+		token, rest = raw[:end], raw[end:]
+	}
+	switch token {
+	case "true":
+		return true, rest, nil
+	case "false":
+		return false, rest, nil
+	}
+	if number, err := strconv.ParseInt(strings.ReplaceAll(token, "_", ""), 0, 64); err == nil {
+		return number, rest, nil
+	}
+	if token == "" {
+		return nil, "", errors.New("missing value")
+	}
+	return nil, "", fmt.Errorf("unsupported value %q (strings must be quoted)", token)
+}
+
+// isTOMLComment - остаток строки пуст или является комментарием
+func isTOMLComment(rest string) bool {
+	rest = strings.TrimSpace(rest)
+	return rest == "" || strings.HasPrefix(rest, "#")
+}
@@ -0,0 +1,124 @@
+package config
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseTOML(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected map[string]any
+		wantErr  bool
+	}{
+		{
+			name: "Scalars_Tables_And_Comments",
+			input: `# Gophermart
+run_address = "localhost:9090" # inline comment
+jwt_secret = 'C:\no\escapes'
+swagger_ui = true
+orders_batch_limit = 1_000
+
+[db]
+max_open_conns = 10
+log.level = "info"
+`,
+			expected: map[string]any{
+				"run_address":        "localhost:9090",
+				"jwt_secret":         `C:\no\escapes`,
+				"swagger_ui":         true,
+				"orders_batch_limit": int64(1000),
+				"db": map[string]any{
+					"max_open_conns": int64(10),
+					"log":            map[string]any{"level": "info"},
+				},
+			},
+		},
+		{
+			name:     "Arrays_And_Escapes",
+			input:    `admin_logins = ["alice", "b\"ob", ] # support`,
+			expected: map[string]any{"admin_logins": []any{"alice", `b"ob`}},
+		},
+		{name: "Unquoted_String", input: `jwt_ttl = 1h`, wantErr: true},
+		{name: "Duplicate_Key", input: "gzip_level = 1\ngzip_level = 2", wantErr: true},
+		{name: "Missing_Value", input: `gzip_level =`, wantErr: true},
+		{name: "Unterminated_String", input: `run_address = "localhost`, wantErr: true},
+		{name: "Trailing_Garbage", input: `gzip_level = 1 2`, wantErr: true},
+		{name: "Multiline_String", input: `order_pattern = """`, wantErr: true},
+		{name: "Key_Redefined_As_Table", input: "db = 1\n[db]", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			document, err := parseTOML([]byte(tc.input))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", document)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, document); diff != "" {
+				t.Errorf("unexpected document (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		fileName string
+		content  string
+		expected map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "YAML_Nested_Keys_And_Lists",
+			fileName: "config.yaml",
+			content:  "db:\n  max_open_conns: 10\n  prepare_stmt: false\norder_prefixes: [799, 1234]\norder_pattern:\n",
+			expected: map[string]string{
+				"db_max_open_conns": "10",
+				"db_prepare_stmt":   "false",
+				"order_prefixes":    "799,1234",
+				"order_pattern":     "",
+			},
+		},
+		{
+			name:     "TOML_Tables",
+			fileName: "config.TOML",
+			content:  "[order.stream]\nheartbeat = \"30s\"\n",
+			expected: map[string]string{"order_stream_heartbeat": "30s"},
+		},
+		{name: "Invalid_YAML", fileName: "config.yaml", content: "db: [", wantErr: true},
+		{name: "List_Of_Tables", fileName: "config.yaml", content: "admin_logins:\n  - login: alice\n", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), tc.fileName)
+			if err := os.WriteFile(path, []byte(tc.content), 0o600); err != nil {
+				t.Fatalf("failed to write config file: %v", err)
+			}
+
+			values, err := readFile(path)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", values)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, values); diff != "" {
+				t.Errorf("unexpected values (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
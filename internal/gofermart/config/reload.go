@@ -0,0 +1,67 @@
+package config
+
+import (
+	"go.uber.org/zap"
+	"sync"
+)
+
+// Reloader - перечитывает конфигурацию по запросу (SIGHUP) и применяет изменения параметров, которые можно
+// обновить без перезапуска. Об изменениях остальных параметров сообщается в журнале: они вступят в силу
+// только после перезапуска.
+type Reloader struct {
+	mu      sync.Mutex
+	current *Config
+	load    func() (*Config, error)
+	apply   func(cfg *Config) error
+	logger  *zap.Logger
+}
+
+// NewReloader - создает Reloader для действующей конфигурации current; load загружает новую конфигурацию,
+// apply применяет конфигурацию, в которой изменены только параметры, обновляемые без перезапуска
+func NewReloader(current *Config, load func() (*Config, error), apply func(cfg *Config) error, logger *zap.Logger) *Reloader {
+	return &Reloader{
+		current: current,
+		load:    load,
+		apply:   apply,
+		logger:  logger,
+	}
+}
+
+// Reload - загружает конфигурацию и применяет изменения. При ошибке загрузки или применения
+// продолжает действовать прежняя конфигурация.
+func (r *Reloader) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	applied := *r.current
+	var reloaded []string
+	for _, s := range settings {
+		nextValue := s.bind(next).String()
+		if s.bind(r.current).String() == nextValue {
+			continue
+		}
+		if !s.reloadable {
+			r.logger.Warn("Configuration setting changed, restart required to apply it", zap.String("setting", s.key))
+			continue
+		}
+		// Значение уже прошло разбор при загрузке, поэтому повторная установка не завершится ошибкой
+		_ = s.bind(&applied).Set(nextValue)
+		reloaded = append(reloaded, s.key)
+	}
+
+	if len(reloaded) == 0 {
+		r.logger.Info("Configuration reloaded, nothing to apply")
+		return nil
+	}
+	if err := r.apply(&applied); err != nil {
+		return err
+	}
+	r.current = &applied
+	r.logger.Info("Configuration reloaded", zap.Strings("settings", reloaded))
+	return nil
+}
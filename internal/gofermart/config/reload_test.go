@@ -0,0 +1,144 @@
+package config
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+func TestReloader_Reload(t *testing.T) {
+	errLoad := errors.New("invalid config")
+	errApply := errors.New("apply failed")
+
+	testCases := []struct {
+		name            string
+		change          func(cfg *Config)
+		loadErr         error
+		applyErr        error
+		expectedErr     error
+		expectedApplied func(t *testing.T, cfg *Config)
+		expectedRestart []string
+	}{
+		{
+			name: "Applies_Reloadable_Settings",
+			change: func(cfg *Config) {
+				cfg.LogLevel = "debug"
+				cfg.JWTSecret = "rotated"
+				cfg.JWTTTL = time.Hour
+			},
+			expectedApplied: func(t *testing.T, cfg *Config) {
+				if cfg.LogLevel != "debug" || cfg.JWTSecret != "rotated" || cfg.JWTTTL != time.Hour {
+					t.Errorf("unexpected applied config: %s/%s/%v", cfg.LogLevel, cfg.JWTSecret, cfg.JWTTTL)
+				}
+			},
+		},
+		{
+			name: "Reports_Settings_Requiring_Restart",
+			change: func(cfg *Config) {
+				cfg.LogLevel = "warn"
+				cfg.RunAddress = "localhost:9999"
+				cfg.DBMaxOpenConns = 50
+			},
+			expectedApplied: func(t *testing.T, cfg *Config) {
+				if cfg.LogLevel != "warn" {
+					t.Errorf("unexpected log level: %s", cfg.LogLevel)
+				}
+				if cfg.RunAddress != defaultRunAddress || cfg.DBMaxOpenConns != defaultDBMaxOpenConns {
+					t.Errorf("settings requiring restart were applied: %s/%d", cfg.RunAddress, cfg.DBMaxOpenConns)
+				}
+			},
+			expectedRestart: []string{"run_address", "db_max_open_conns"},
+		},
+		{
+			name:            "Nothing_Changed",
+			change:          func(cfg *Config) {},
+			expectedApplied: nil,
+		},
+		{
+			name:        "Load_Error_Keeps_Config",
+			change:      func(cfg *Config) {},
+			loadErr:     errLoad,
+			expectedErr: errLoad,
+		},
+		{
+			name:        "Apply_Error",
+			change:      func(cfg *Config) { cfg.LogLevel = "error" },
+			applyErr:    errApply,
+			expectedErr: errApply,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			core, logs := observer.New(zap.InfoLevel)
+			next := defaultConfig()
+			tc.change(next)
+
+			var applied *Config
+			reloader := NewReloader(defaultConfig(), func() (*Config, error) {
+				return next, tc.loadErr
+			}, func(cfg *Config) error {
+				applied = cfg
+				return tc.applyErr
+			}, zap.New(core))
+
+			err := reloader.Reload()
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedApplied == nil {
+				if applied != nil && tc.applyErr == nil {
+					t.Errorf("expected nothing to be applied, got %+v", applied)
+				}
+			} else {
+				if applied == nil {
+					t.Fatal("expected config to be applied")
+				}
+				tc.expectedApplied(t, applied)
+			}
+
+			var restart []string
+			for _, entry := range logs.FilterMessage("Configuration setting changed, restart required to apply it").All() {
+				restart = append(restart, entry.ContextMap()["setting"].(string))
+			}
+			if len(restart) != len(tc.expectedRestart) {
+				t.Fatalf("expected restart warnings for %v, got %v", tc.expectedRestart, restart)
+			}
+			for i := range restart {
+				if restart[i] != tc.expectedRestart[i] {
+					t.Errorf("expected restart warnings for %v, got %v", tc.expectedRestart, restart)
+				}
+			}
+		})
+	}
+}
+
+func TestReloader_ReloadKeepsFailedChangesPending(t *testing.T) {
+	next := defaultConfig()
+	next.LogLevel = "debug"
+
+	applyErr := errors.New("apply failed")
+	var applied []string
+	reloader := NewReloader(defaultConfig(), func() (*Config, error) {
+		return next, nil
+	}, func(cfg *Config) error {
+		applied = append(applied, cfg.LogLevel)
+		return applyErr
+	}, zap.NewNop())
+
+	if err := reloader.Reload(); !errors.Is(err, applyErr) {
+		t.Fatalf("expected apply error, got %v", err)
+	}
+	applyErr = nil
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(applied) != 2 {
+		t.Errorf("expected the change to be applied again after a failure and only once after success, got %v", applied)
+	}
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// redactedValue - значение, которым заменяются секреты при выводе конфигурации
+	redactedValue = "REDACTED"
+
+	// fileSuffixEnv и fileSuffixKey - суффиксы переменной окружения и ключа файла конфигурации,
+	// значение которых — путь к файлу со значением параметра
+	fileSuffixEnv = "_FILE"
+	fileSuffixKey = "_file"
+)
+
+// setting - параметр конфигурации. Ключ используется в файле конфигурации, имя переменной окружения —
+// тот же ключ в верхнем регистре.
+type setting struct {
+	key   string
+	flag  string
+	usage string
+	// bind - возвращает значение параметра, связанное с полем конфигурации
+	bind func(cfg *Config) value
+	// redact - скрывает секрет при выводе конфигурации; nil, если параметр не секретный
+	redact func(string) string
+	// reloadable - изменение параметра применяется по SIGHUP без перезапуска
+	reloadable bool
+}
+
+// value - значение параметра, которое задаётся строкой из флага, переменной окружения или файла конфигурации
+type value interface {
+	String() string
+	Set(string) error
+}
+
+// settings - все параметры конфигурации в порядке вывода
+var settings = []setting{
+	{key: "run_address", flag: "a", usage: "Address and port to run the HTTP server", bind: func(cfg *Config) value { return (*stringValue)(&cfg.RunAddress) }},
+	{key: "database_uri", flag: "d", usage: "PostgreSQL DSN", bind: func(cfg *Config) value { return (*stringValue)(&cfg.DatabaseURI) }, redact: redactDSN},
+	{key: "accrual_system_address", flag: "r", usage: "Address of the accrual settlement system", bind: func(cfg *Config) value { return (*stringValue)(&cfg.AccrualSystemAddress) }},
+	{key: "jwt_secret", flag: "s", usage: "Your JWT-secret key", bind: func(cfg *Config) value { return (*stringValue)(&cfg.JWTSecret) }, redact: redactSecret, reloadable: true},
+	{key: "jwt_ttl", flag: "jwt-ttl", usage: "Lifetime of issued JWT tokens", bind: func(cfg *Config) value { return (*durationValue)(&cfg.JWTTTL) }, reloadable: true},
+	{key: "log_level", flag: "log-level", usage: "Log level: debug, info, warn, error", bind: func(cfg *Config) value { return (*stringValue)(&cfg.LogLevel) }, reloadable: true},
+
+	{key: "db_max_open_conns", flag: "db-max-open-conns", usage: "Maximum number of open database connections", bind: func(cfg *Config) value { return (*intValue)(&cfg.DBMaxOpenConns) }},
+	{key: "db_max_idle_conns", flag: "db-max-idle-conns", usage: "Maximum number of idle database connections", bind: func(cfg *Config) value { return (*intValue)(&cfg.DBMaxIdleConns) }},
+	{key: "db_conn_max_lifetime", flag: "db-conn-max-lifetime", usage: "Maximum lifetime of a database connection", bind: func(cfg *Config) value { return (*durationValue)(&cfg.DBConnMaxLifetime) }},
+	{key: "db_conn_max_idle_time", flag: "db-conn-max-idle-time", usage: "Maximum idle time of a database connection", bind: func(cfg *Config) value { return (*durationValue)(&cfg.DBConnMaxIdleTime) }},
+	{key: "db_statement_timeout", flag: "db-statement-timeout", usage: "Per-query statement timeout (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.DBStatementTimeout) }},
+	{key: "db_lock_timeout", flag: "db-lock-timeout", usage: "Lock wait timeout (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.DBLockTimeout) }},
+	{key: "db_prepare_stmt", flag: "db-prepare-stmt", usage: "Cache prepared statements", bind: func(cfg *Config) value { return (*boolValue)(&cfg.DBPrepareStmt) }},
+	{key: "db_log_level", flag: "db-log-level", usage: "GORM log level: silent, error, warn, info", bind: func(cfg *Config) value { return (*stringValue)(&cfg.DBLogLevel) }},
+	{key: "db_connect_timeout", flag: "db-connect-timeout", usage: "How long to retry the initial database connection", bind: func(cfg *Config) value { return (*durationValue)(&cfg.DBConnectTimeout) }},
+	{key: "database_replica_uri", flag: "db-replica", usage: "Optional PostgreSQL read replica DSN", bind: func(cfg *Config) value { return (*stringValue)(&cfg.DatabaseReplicaURI) }, redact: redactDSN},
+	{key: "db_replica_sticky", flag: "db-replica-sticky", usage: "How long a user's reads stay on the primary after their own write", bind: func(cfg *Config) value { return (*durationValue)(&cfg.DBReplicaSticky) }},
+
+	{key: "request_timeout", flag: "request-timeout", usage: "Per-request processing deadline (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.RequestTimeout) }},
+	{key: "gzip_level", flag: "gzip-level", usage: "Compression level of gzip and deflate responses (1-9)", bind: func(cfg *Config) value { return (*intValue)(&cfg.GzipLevel) }},
+	{key: "http_max_body_size", flag: "http-max-body-size", usage: "Maximum request body size in bytes (0 disables)", bind: func(cfg *Config) value { return (*intValue)(&cfg.MaxBodySize) }},
+	{key: "http_shutdown_timeout", flag: "http-shutdown-timeout", usage: "How long shutdown waits for in-flight HTTP requests (0 waits indefinitely)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.HTTPShutdownTimeout) }},
+	{key: "http_read_header_timeout", flag: "http-read-header-timeout", usage: "How long the HTTP server waits for request headers (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.HTTPReadHeaderTimeout) }},
+	{key: "http_write_timeout", flag: "http-write-timeout", usage: "How long the HTTP server may spend writing a response, must exceed request_timeout (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.HTTPWriteTimeout) }},
+	{key: "http_idle_timeout", flag: "http-idle-timeout", usage: "How long an idle keep-alive connection stays open (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.HTTPIdleTimeout) }},
+	{key: "tls_cert_file", flag: "tls-cert", usage: "TLS certificate file; serves HTTPS and HTTP/2 when set together with the key", bind: func(cfg *Config) value { return (*stringValue)(&cfg.TLSCertFile) }},
+	{key: "tls_key_file", flag: "tls-key", usage: "TLS private key file", bind: func(cfg *Config) value { return (*stringValue)(&cfg.TLSKeyFile) }},
+	{key: "tls_reload_interval", flag: "tls-reload-interval", usage: "How often the TLS certificate and key are checked for changes on disk (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.TLSReloadInterval) }},
+	{key: "admin_address", flag: "admin-address", usage: "Address of the internal server with /metrics, /healthz and /readyz (empty disables it)", bind: func(cfg *Config) value { return (*stringValue)(&cfg.AdminAddress) }},
+	{key: "admin_client_ca_file", flag: "admin-client-ca", usage: "CA certificate file; the internal server requires client certificates signed by it", bind: func(cfg *Config) value { return (*stringValue)(&cfg.AdminClientCAFile) }},
+	{key: "shutdown_timeout", flag: "shutdown-timeout", usage: "How long shutdown waits for each background component to stop (0 waits indefinitely)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.ShutdownTimeout) }},
+
+	{key: "order_update_interval", flag: "order-update-interval", usage: "How often order statuses are polled from the accrual system", bind: func(cfg *Config) value { return (*durationValue)(&cfg.OrderUpdateInterval) }},
+	{key: "order_update_timeout", flag: "order-update-timeout", usage: "Deadline of a single order status polling pass", bind: func(cfg *Config) value { return (*durationValue)(&cfg.OrderUpdateTimeout) }},
+	{key: "orders_batch_limit", flag: "orders-batch-limit", usage: "Maximum number of order numbers in a batch upload", bind: func(cfg *Config) value { return (*intValue)(&cfg.OrdersBatchLimit) }},
+	{key: "orders_batch_max_body_size", flag: "orders-batch-max-body-size", usage: "Maximum batch order upload body size in bytes (0 disables)", bind: func(cfg *Config) value { return (*intValue)(&cfg.OrdersBatchMaxBodySize) }},
+	{key: "order_validators", flag: "order-validators", usage: "Comma-separated order number validation rules: luhn, length, prefix, regex", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OrderValidators) }},
+	{key: "order_min_length", flag: "order-min-length", usage: "Minimum order number length for the length rule", bind: func(cfg *Config) value { return (*intValue)(&cfg.OrderMinLength) }},
+	{key: "order_max_length", flag: "order-max-length", usage: "Maximum order number length for the length rule (0 disables)", bind: func(cfg *Config) value { return (*intValue)(&cfg.OrderMaxLength) }},
+	{key: "order_prefixes", flag: "order-prefixes", usage: "Comma-separated allowed order number prefixes for the prefix rule", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OrderPrefixes) }},
+	{key: "order_partner_prefixes", flag: "order-partner-prefixes", usage: "Order number prefixes of partners for the prefix rule, e.g. acme:799|800,shop:1234", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OrderPartnerPrefixes) }},
+	{key: "order_pattern", flag: "order-pattern", usage: "Regular expression an order number must match for the regex rule", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OrderPattern) }},
+
+	{key: "swagger_ui", flag: "swagger-ui", usage: "Serve Swagger UI at /api/docs", bind: func(cfg *Config) value { return (*boolValue)(&cfg.SwaggerUI) }},
+
+	{key: "outbox_sink", flag: "outbox-sink", usage: "Outbox event sink: none, stdout, file, webhook, nats, kafka", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OutboxSink) }},
+	{key: "outbox_url", flag: "outbox-url", usage: "Webhook URL, NATS server URL or Kafka REST Proxy URL for the outbox sink", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OutboxURL) }, redact: redactDSN},
+	{key: "outbox_topic", flag: "outbox-topic", usage: "NATS subject prefix or Kafka topic for outbox events", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OutboxTopic) }},
+	{key: "outbox_file", flag: "outbox-file", usage: "File the file outbox sink appends events to", bind: func(cfg *Config) value { return (*stringValue)(&cfg.OutboxFile) }},
+	{key: "outbox_poll_interval", flag: "outbox-poll-interval", usage: "How often the outbox relay looks for undelivered events", bind: func(cfg *Config) value { return (*durationValue)(&cfg.OutboxPollInterval) }},
+	{key: "outbox_batch_size", flag: "outbox-batch-size", usage: "Maximum number of outbox events delivered per relay pass", bind: func(cfg *Config) value { return (*intValue)(&cfg.OutboxBatchSize) }},
+	{key: "outbox_max_attempts", flag: "outbox-max-attempts", usage: "Delivery attempts before an outbox event is marked as failed and stops holding back its key", bind: func(cfg *Config) value { return (*intValue)(&cfg.OutboxMaxAttempts) }},
+
+	{key: "webhook_poll_interval", flag: "webhook-poll-interval", usage: "How often pending user webhook deliveries are sent", bind: func(cfg *Config) value { return (*durationValue)(&cfg.WebhookPollInterval) }},
+	{key: "webhook_max_attempts", flag: "webhook-max-attempts", usage: "Delivery attempts before a user webhook delivery is marked as failed", bind: func(cfg *Config) value { return (*intValue)(&cfg.WebhookMaxAttempts) }},
+	{key: "webhook_allow_private", flag: "webhook-allow-private", usage: "Allow user webhooks to target loopback and private network addresses", bind: func(cfg *Config) value { return (*boolValue)(&cfg.WebhookAllowPrivate) }},
+
+	{key: "order_stream_heartbeat", flag: "order-stream-heartbeat", usage: "How often keep-alive comments are sent on order update streams (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.OrderStreamHeartbeat) }},
+	{key: "order_stream_max_per_user", flag: "order-stream-max-per-user", usage: "Maximum number of concurrent order update streams per user (0 disables the limit)", bind: func(cfg *Config) value { return (*intValue)(&cfg.OrderStreamMaxPerUser) }},
+
+	{key: "rate_limit_backend", flag: "rate-limit-backend", usage: "Where rate limit buckets are kept: memory (per instance) or postgres (shared by all instances)", bind: func(cfg *Config) value { return (*stringValue)(&cfg.RateLimitBackend) }},
+	{key: "rate_limit_auth", flag: "rate-limit-auth", usage: "Rate limit of register and login requests per client IP, e.g. 20/1m (0 disables)", bind: func(cfg *Config) value { return (*stringValue)(&cfg.RateLimitAuth) }, reloadable: true},
+	{key: "rate_limit_api", flag: "rate-limit-api", usage: "Rate limit of authenticated API requests per user, e.g. 600/1m (0 disables)", bind: func(cfg *Config) value { return (*stringValue)(&cfg.RateLimitAPI) }, reloadable: true},
+	{key: "rate_limit_orders", flag: "rate-limit-orders", usage: "Additional rate limit of order uploads per user, e.g. 60/1m (0 disables)", bind: func(cfg *Config) value { return (*stringValue)(&cfg.RateLimitOrders) }, reloadable: true},
+	{key: "rate_limit_prune_interval", flag: "rate-limit-prune-interval", usage: "How often idle rate limit buckets are removed (0 disables)", bind: func(cfg *Config) value { return (*durationValue)(&cfg.RateLimitPruneInterval) }},
+}
+
+// envName - имя переменной окружения параметра
+func (s setting) envName() string {
+	return strings.ToUpper(s.key)
+}
+
+// lookupEnv - возвращает значение параметра из переменной окружения или из файла, путь к которому задан
+// переменной с суффиксом _FILE (секреты Docker и Kubernetes); пустая строка — параметр не задан
+func (s setting) lookupEnv() (string, error) {
+	name := s.envName()
+	envValue := os.Getenv(name)
+	path := os.Getenv(name + fileSuffixEnv)
+	if path == "" {
+		return envValue, nil
+	}
+	if envValue != "" {
+		return "", fmt.Errorf("both %s and %s%s are set", name, name, fileSuffixEnv)
+	}
+	return readSecretFile(path)
+}
+
+// readSecretFile - читает значение параметра из файла, отбрасывая завершающий перевод строки
+func readSecretFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// defineFlag - объявляет флаг параметра, связанный с полем cfg; стандартные типы флагов
+// дают понятную справку (-a string, -jwt-ttl duration)
+func (s setting) defineFlag(flags *flag.FlagSet, cfg *Config) {
+	switch v := s.bind(cfg).(type) {
+	case *stringValue:
+		flags.StringVar((*string)(v), s.flag, string(*v), s.usage)
+	case *intValue:
+		flags.IntVar((*int)(v), s.flag, int(*v), s.usage)
+	case *boolValue:
+		flags.BoolVar((*bool)(v), s.flag, bool(*v), s.usage)
+	case *durationValue:
+		flags.DurationVar((*time.Duration)(v), s.flag, time.Duration(*v), s.usage)
+	default:
+		flags.Var(v, s.flag, s.usage)
+	}
+}
+
+// lookupSetting - находит параметр по ключу файла конфигурации
+func lookupSetting(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// lookupFlag - находит параметр по имени флага
+func lookupFlag(name string) (setting, bool) {
+	for _, s := range settings {
+		if s.flag == name {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// sortedKeys - возвращает ключи значений в алфавитном порядке, чтобы ошибки выводились в одном порядке
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Print - выводит действующую конфигурацию в формате файла конфигурации, скрывая секреты
+func (cfg *Config) Print(w io.Writer) error {
+	for _, s := range settings {
+		current := s.bind(cfg).String()
+		if s.redact != nil && current != "" {
+			current = s.redact(current)
+		}
+		if _, err := fmt.Fprintf(w, "%s: %s\n", s.key, strconv.Quote(current)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactSecret - полностью скрывает значение
+func redactSecret(string) string {
+	return redactedValue
+}
+
+// dsnPasswordPattern - пароль в DSN формата "ключ=значение"
+var dsnPasswordPattern = regexp.MustCompile(`(?i)(password\s*=\s*)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// redactDSN - скрывает пароль в DSN формата URL или "ключ=значение", оставляя адрес и имя пользователя
+func redactDSN(dsn string) string {
+	if parsed, err := url.Parse(dsn); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), redactedValue)
+		}
+		query := parsed.Query()
+		if query.Has("password") {
+			query.Set("password", redactedValue)
+			parsed.RawQuery = query.Encode()
+		}
+		return parsed.String()
+	}
+	return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+redactedValue)
+}
+
+// stringValue - строковый параметр
+type stringValue string
+
+func (v *stringValue) String() string { return string(*v) }
+
+func (v *stringValue) Set(s string) error {
+	*v = stringValue(s)
+	return nil
+}
+
+// intValue - целочисленный параметр
+type intValue int
+
+func (v *intValue) String() string { return strconv.Itoa(int(*v)) }
+
+func (v *intValue) Set(s string) error {
+	parsed, err := strconv.Atoi(s)
+	if err != nil {
+		return fmt.Errorf("%q is not an integer", s)
+	}
+	*v = intValue(parsed)
+	return nil
+}
+
+// boolValue - логический параметр
+type boolValue bool
+
+func (v *boolValue) String() string { return strconv.FormatBool(bool(*v)) }
+
+func (v *boolValue) Set(s string) error {
+	parsed, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a boolean", s)
+	}
+	*v = boolValue(parsed)
+	return nil
+}
+
+// durationValue - параметр длительности в формате time.ParseDuration, например 500ms, 30s или 1h30m
+type durationValue time.Duration
+
+func (v *durationValue) String() string { return time.Duration(*v).String() }
+
+func (v *durationValue) Set(s string) error {
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("%q is not a duration (expected a value like 500ms, 30s or 1h)", s)
+	}
+	*v = durationValue(parsed)
+	return nil
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// AuditEventType - вид события журнала аудита
+type AuditEventType string
+
+// Виды событий журнала аудита
+const (
+	AuditUserRegistered     AuditEventType = "user.registered"
+	AuditLoginSucceeded     AuditEventType = "user.login_succeeded"
+	AuditLoginFailed        AuditEventType = "user.login_failed"
+	AuditTokenIssued        AuditEventType = "token.issued"
+	AuditRolesChanged       AuditEventType = "user.roles_changed"
+	AuditUserBlocked        AuditEventType = "user.blocked"
+	AuditUserUnblocked      AuditEventType = "user.unblocked"
+	AuditBalanceWithdrawn   AuditEventType = "balance.withdrawn"
+	AuditBalanceAdjusted    AuditEventType = "balance.adjusted"
+	AuditOrderUploaded      AuditEventType = "order.uploaded"
+	AuditOrderBatchUploaded AuditEventType = "order.batch_uploaded"
+)
+
+// knownAuditEventTypes - все допустимые виды событий
+var knownAuditEventTypes = map[AuditEventType]bool{
+	AuditUserRegistered:     true,
+	AuditLoginSucceeded:     true,
+	AuditLoginFailed:        true,
+	AuditTokenIssued:        true,
+	AuditRolesChanged:       true,
+	AuditUserBlocked:        true,
+	AuditUserUnblocked:      true,
+	AuditBalanceWithdrawn:   true,
+	AuditBalanceAdjusted:    true,
+	AuditOrderUploaded:      true,
+	AuditOrderBatchUploaded: true,
+}
+
+// Valid - проверяет, что вид события известен
+func (t AuditEventType) Valid() bool {
+	return knownAuditEventTypes[t]
+}
+
+// AuditPayload - подробности события аудита; в базе данных хранится как JSON
+type AuditPayload map[string]any
+
+// Value - реализует driver.Valuer
+func (p AuditPayload) Value() (driver.Value, error) {
+	if p == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan - реализует sql.Scanner
+func (p *AuditPayload) Scan(src any) error {
+	var data []byte
+	switch typed := src.(type) {
+	case nil:
+		*p = AuditPayload{}
+		return nil
+	case string:
+		data = []byte(typed)
+	case []byte:
+		data = typed
+	default:
+		return fmt.Errorf("cannot scan %T into AuditPayload", src)
+	}
+	payload := AuditPayload{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return err
+	}
+	*p = payload
+	return nil
+}
+
+// AuditEvent - запись журнала аудита. Записи только добавляются: журнал не изменяется и не очищается приложением.
+type AuditEvent struct {
+	EventID   int            `gorm:"column:event_id;primaryKey;autoIncrement"`
+	Type      AuditEventType `gorm:"column:type;not null;index:idx_audit_events_type_created,priority:1"`
+	Actor     string         `gorm:"column:actor;not null;index"`   // логин пользователя, выполнившего действие
+	Subject   string         `gorm:"column:subject;not null;index"` // логин пользователя, которого касается событие
+	IP        string         `gorm:"column:ip;not null"`
+	UserAgent string         `gorm:"column:user_agent;not null"`
+	RequestID string         `gorm:"column:request_id;not null"`
+	Payload   AuditPayload   `gorm:"column:payload;type:jsonb;not null"`
+	CreatedAt time.Time      `gorm:"column:created_at;type:timestamp with time zone;not null;index;index:idx_audit_events_type_created,priority:2"`
+}
+
+// AuditFilter - параметры выборки событий журнала аудита; пустые поля не ограничивают выборку
+type AuditFilter struct {
+	ListOptions
+	Types   []AuditEventType
+	Actor   string
+	Subject string
+}
+
+// RequestInfo - сведения о HTTP-запросе, в рамках которого выполняется операция, для журнала аудита
+type RequestInfo struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// requestInfoKey - ключ контекста, под которым хранятся сведения о запросе
+type requestInfoKey struct{}
+
+// WithRequestInfo - возвращает контекст со сведениями о запросе
+func WithRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext - возвращает сведения о запросе; вне HTTP-запроса (например, в фоновых задачах) они пусты
+func RequestInfoFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info
+}
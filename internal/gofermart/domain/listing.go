@@ -0,0 +1,62 @@
+package domain
+
+import (
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ListOptions - общие параметры выборки списков пользователя: период, размер страницы и курсор.
+// Нулевое значение означает выборку всех записей без ограничений.
+type ListOptions struct {
+	From  time.Time // нижняя граница периода (включительно), нулевое значение — без ограничения
+	To    time.Time // верхняя граница периода (не включительно), нулевое значение — без ограничения
+	Limit int       // размер страницы, 0 — без ограничения
+	After *Cursor   // позиция, после которой начинается страница
+
+	// Ascending - выдача от старых записей к новым; по умолчанию списки упорядочены от новых к старым
+	Ascending bool
+}
+
+// OrderFilter - параметры выборки заказов пользователя
+type OrderFilter struct {
+	ListOptions
+	Statuses []string
+}
+
+// WithdrawalFilter - параметры выборки выводов средств пользователя
+type WithdrawalFilter struct {
+	ListOptions
+}
+
+// Cursor - позиция keyset-пагинации: время и уникальный ключ последней выданной записи.
+// Списки упорядочены по паре (Time, Key) в направлении, заданном ListOptions.Ascending.
+type Cursor struct {
+	Time time.Time
+	Key  string
+}
+
+// Encode - сериализует курсор в непрозрачную строку для передачи клиенту
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.Time.UnixNano(), 10) + ":" + c.Key
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor - восстанавливает курсор из строки, полученной от Encode
+func DecodeCursor(value string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, gofermartErrors.ErrInvalidCursor
+	}
+	nanos, key, found := strings.Cut(string(raw), ":")
+	if !found || key == "" {
+		return nil, gofermartErrors.ErrInvalidCursor
+	}
+	unixNano, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, gofermartErrors.ErrInvalidCursor
+	}
+	return &Cursor{Time: time.Unix(0, unixNano).UTC(), Key: key}, nil
+}
@@ -19,16 +19,20 @@ type User struct {
 	UserID   int             `gorm:"column:user_id;primaryKey;autoIncrement"`
 	Login    string          `gorm:"column:login;unique;not null;index"`
 	Password string          `gorm:"column:password;not null"`
-	Balance  decimal.Decimal `gorm:"column:balance;type:numeric(18,2);default:0"`
+	Balance  decimal.Decimal `gorm:"column:balance;type:numeric(18,2);default:0;check:chk_users_balance_non_negative,balance >= 0"`
+	Blocked  bool            `gorm:"column:blocked;not null;default:false"`
+	Roles    Roles           `gorm:"column:roles;type:text;not null;default:'user'"`
+	// Version - увеличивается при каждом изменении баланса, заказов и выводов пользователя; используется для ETag
+	Version int64 `gorm:"column:version;not null;default:0"`
 }
 
 // Order - представляет заказ, связанный с пользователем.
 type Order struct {
 	OrderNumber  string          `gorm:"column:order_number;primaryKey;index"`
-	UserID       int             `gorm:"column:user_id;not null;index"`
+	UserID       int             `gorm:"column:user_id;not null;index;index:idx_orders_user_uploaded,priority:1"`
 	OrderStatus  string          `gorm:"column:order_status;not null"`
 	Accrual      decimal.Decimal `gorm:"column:accrual;type:numeric(18,2);default:0"`
-	UploadedAt   time.Time       `gorm:"column:uploaded_at;type:timestamp with time zone;not null;index"`
+	UploadedAt   time.Time       `gorm:"column:uploaded_at;type:timestamp with time zone;not null;index;index:idx_orders_user_uploaded,priority:2"`
 	IsProcessing bool            `gorm:"column:is_processing;default:false;index"`
 }
 
@@ -36,7 +40,26 @@ type Order struct {
 type Withdrawal struct {
 	WithdrawalID int             `gorm:"column:withdrawal_id;primaryKey;autoIncrement"`
 	OrderNumber  string          `gorm:"column:order_number;not null;index"`
-	UserID       int             `gorm:"column:user_id;not null;index"`
+	UserID       int             `gorm:"column:user_id;not null;index;index:idx_withdrawals_user_processed,priority:1"`
 	Amount       decimal.Decimal `gorm:"column:amount;type:numeric(18,2);not null"`
-	ProcessedAt  time.Time       `gorm:"column:processed_at;type:timestamp with time zone;not null"`
+	ProcessedAt  time.Time       `gorm:"column:processed_at;type:timestamp with time zone;not null;index:idx_withdrawals_user_processed,priority:2"`
+}
+
+// BalanceAdjustment - ручная корректировка баланса пользователя сотрудником поддержки.
+type BalanceAdjustment struct {
+	AdjustmentID int             `gorm:"column:adjustment_id;primaryKey;autoIncrement"`
+	UserID       int             `gorm:"column:user_id;not null;index:idx_balance_adjustments_user_created,priority:1"`
+	Amount       decimal.Decimal `gorm:"column:amount;type:numeric(18,2);not null"`
+	Reason       string          `gorm:"column:reason;not null"`
+	Actor        string          `gorm:"column:actor;not null"`
+	CreatedAt    time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null;index:idx_balance_adjustments_user_created,priority:2"`
+}
+
+// Statement - сформированная ежемесячная выписка пользователя в формате PDF.
+type Statement struct {
+	StatementID int       `gorm:"column:statement_id;primaryKey;autoIncrement"`
+	UserID      int       `gorm:"column:user_id;not null;uniqueIndex:idx_statements_user_period,priority:1"`
+	Period      string    `gorm:"column:period;not null;uniqueIndex:idx_statements_user_period,priority:2"`
+	Content     []byte    `gorm:"column:content;type:bytea;not null"`
+	CreatedAt   time.Time `gorm:"column:created_at;type:timestamp with time zone;not null"`
 }
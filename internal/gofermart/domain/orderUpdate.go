@@ -0,0 +1,13 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+)
+
+// OrderUpdate - изменение статуса или начисления по заказу, передаваемое в поток событий пользователя
+type OrderUpdate struct {
+	Order   string          `json:"order"`
+	UserID  int             `json:"user_id"`
+	Status  string          `json:"status"`
+	Accrual decimal.Decimal `json:"accrual"`
+}
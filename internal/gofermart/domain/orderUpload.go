@@ -0,0 +1,16 @@
+package domain
+
+// Результаты обработки номера заказа при пакетной загрузке
+const (
+	OrderUploadAccepted        = "ACCEPTED"
+	OrderUploadAlreadyUploaded = "ALREADY_UPLOADED"
+	OrderUploadConflict        = "CONFLICT"
+	OrderUploadInvalid         = "INVALID"
+)
+
+// OrderUploadResult - результат загрузки одного номера заказа из пакета
+type OrderUploadResult struct {
+	Number string
+	Status string
+	Reason string // причина отклонения для INVALID
+}
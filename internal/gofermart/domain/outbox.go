@@ -0,0 +1,96 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// OutboxEventType - вид события, публикуемого для внешних потребителей
+type OutboxEventType string
+
+// Виды публикуемых событий
+const (
+	OutboxOrderStatusChanged OutboxEventType = "OrderStatusChanged"
+	OutboxPointsAccrued      OutboxEventType = "PointsAccrued"
+	OutboxPointsWithdrawn    OutboxEventType = "PointsWithdrawn"
+)
+
+// OutboxPayload - тело события в формате JSON; в базе данных хранится как jsonb
+type OutboxPayload []byte
+
+// Value - реализует driver.Valuer
+func (p OutboxPayload) Value() (driver.Value, error) {
+	if len(p) == 0 {
+		return "{}", nil
+	}
+	return string(p), nil
+}
+
+// Scan - реализует sql.Scanner
+func (p *OutboxPayload) Scan(src any) error {
+	switch typed := src.(type) {
+	case nil:
+		*p = nil
+	case string:
+		*p = OutboxPayload(typed)
+	case []byte:
+		*p = append(OutboxPayload(nil), typed...)
+	default:
+		return fmt.Errorf("cannot scan %T into OutboxPayload", src)
+	}
+	return nil
+}
+
+// MarshalJSON - тело события встраивается в JSON без повторного кодирования
+func (p OutboxPayload) MarshalJSON() ([]byte, error) {
+	if len(p) == 0 {
+		return []byte("{}"), nil
+	}
+	return p, nil
+}
+
+// OutboxMessage - событие в таблице outbox. Записывается в одной транзакции с изменением данных и доставляется
+// потребителям фоновым процессом не менее одного раза; событие, исчерпавшее попытки доставки, отмечается FailedAt
+// и больше не доставляется.
+type OutboxMessage struct {
+	MessageID     int             `gorm:"column:message_id;primaryKey;autoIncrement"`
+	Type          OutboxEventType `gorm:"column:type;not null"`
+	Key           string          `gorm:"column:key;not null;index:idx_outbox_key_pending,where:delivered_at IS NULL"` // ключ упорядочивания, например номер заказа
+	Payload       OutboxPayload   `gorm:"column:payload;type:jsonb;not null"`
+	CreatedAt     time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null"`
+	Attempts      int             `gorm:"column:attempts;not null;default:0"`
+	NextAttemptAt time.Time       `gorm:"column:next_attempt_at;type:timestamp with time zone;not null;index:idx_outbox_pending,where:delivered_at IS NULL"`
+	DeliveredAt   *time.Time      `gorm:"column:delivered_at;type:timestamp with time zone"`
+	FailedAt      *time.Time      `gorm:"column:failed_at;type:timestamp with time zone"`
+	LastError     string          `gorm:"column:last_error;not null;default:''"`
+}
+
+// TableName - имя таблицы outbox
+func (OutboxMessage) TableName() string {
+	return "outbox"
+}
+
+// OrderStatusChangedEvent - тело события OrderStatusChanged
+type OrderStatusChangedEvent struct {
+	Order          string          `json:"order"`
+	UserID         int             `json:"user_id"`
+	PreviousStatus string          `json:"previous_status"`
+	Status         string          `json:"status"`
+	Accrual        decimal.Decimal `json:"accrual"`
+}
+
+// PointsAccruedEvent - тело события PointsAccrued
+type PointsAccruedEvent struct {
+	Order  string          `json:"order"`
+	UserID int             `json:"user_id"`
+	Amount decimal.Decimal `json:"amount"`
+}
+
+// PointsWithdrawnEvent - тело события PointsWithdrawn
+type PointsWithdrawnEvent struct {
+	Order  string          `json:"order"`
+	UserID int             `json:"user_id"`
+	Sum    decimal.Decimal `json:"sum"`
+}
@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Группы маршрутов с собственными ограничениями частоты запросов
+const (
+	RateLimitAuth   = "auth"   // регистрация и вход, по IP-адресу клиента
+	RateLimitAPI    = "api"    // запросы аутентифицированного пользователя
+	RateLimitOrders = "orders" // загрузка заказов, дополнительно к RateLimitAPI
+)
+
+var ErrInvalidRateLimitPolicy = errors.New(`rate limit must look like "<requests>/<period>", e.g. "60/1m"`)
+
+// RateLimitPolicy - ограничение частоты запросов по алгоритму token bucket: не больше Requests запросов подряд,
+// после чего разрешение на очередной запрос восстанавливается каждые Period/Requests. Нулевое значение — без ограничения.
+type RateLimitPolicy struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseRateLimitPolicy - разбирает ограничение вида "60/1m"; пустая строка и "0" отключают ограничение
+func ParseRateLimitPolicy(value string) (RateLimitPolicy, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return RateLimitPolicy{}, nil
+	}
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimitPolicy{}, ErrInvalidRateLimitPolicy
+	}
+	policy := RateLimitPolicy{}
+	var err error
+	if policy.Requests, err = strconv.Atoi(strings.TrimSpace(requests)); err != nil || policy.Requests <= 0 {
+		return RateLimitPolicy{}, ErrInvalidRateLimitPolicy
+	}
+	if policy.Period, err = time.ParseDuration(strings.TrimSpace(period)); err != nil || policy.Period <= 0 {
+		return RateLimitPolicy{}, ErrInvalidRateLimitPolicy
+	}
+	return policy, nil
+}
+
+// Enabled - ограничение задано
+func (p RateLimitPolicy) Enabled() bool {
+	return p.Requests > 0 && p.Period > 0
+}
+
+// String - ограничение в формате ParseRateLimitPolicy
+func (p RateLimitPolicy) String() string {
+	if !p.Enabled() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", p.Requests, p.Period)
+}
+
+// Rate - скорость восстановления разрешений, в запросах в секунду
+func (p RateLimitPolicy) Rate() float64 {
+	return float64(p.Requests) / p.Period.Seconds()
+}
+
+// Take - расходует разрешение на запрос из корзины в момент now; корзина без UpdatedAt считается полной.
+// Отклонённый запрос разрешение не расходует.
+func (p RateLimitPolicy) Take(bucket RateLimitBucket, now time.Time) RateLimitBucket {
+	tokens := float64(p.Requests)
+	if !bucket.UpdatedAt.IsZero() {
+		elapsed := math.Max(now.Sub(bucket.UpdatedAt).Seconds(), 0)
+		tokens = math.Min(tokens, bucket.Tokens+elapsed*p.Rate())
+	}
+
+	bucket.Allowed = tokens >= 1
+	if bucket.Allowed {
+		tokens--
+	}
+	bucket.Tokens = tokens
+	bucket.UpdatedAt = now
+	return bucket
+}
+
+// Decision - результат проверки по состоянию корзины после Take
+func (p RateLimitPolicy) Decision(bucket RateLimitBucket) RateLimitDecision {
+	decision := RateLimitDecision{
+		Allowed:   bucket.Allowed,
+		Limit:     p.Requests,
+		Remaining: int(math.Max(math.Floor(bucket.Tokens), 0)),
+		Reset:     secondsToDuration((float64(p.Requests) - bucket.Tokens) / p.Rate()),
+	}
+	if !bucket.Allowed {
+		decision.RetryAfter = secondsToDuration((1 - bucket.Tokens) / p.Rate())
+	}
+	return decision
+}
+
+// RateLimitDecision - результат проверки ограничения частоты запросов
+type RateLimitDecision struct {
+	Allowed    bool
+	Limit      int           // размер корзины
+	Remaining  int           // сколько запросов ещё можно выполнить подряд
+	RetryAfter time.Duration // через сколько будет разрешён следующий запрос, если текущий отклонён
+	Reset      time.Duration // через сколько корзина заполнится полностью
+}
+
+// RateLimitBucket - состояние корзины разрешений по ключу (группа маршрутов и пользователь или IP-адрес)
+type RateLimitBucket struct {
+	Key       string    `gorm:"column:key;primaryKey"`
+	Tokens    float64   `gorm:"column:tokens;not null"`
+	Allowed   bool      `gorm:"column:allowed;not null"` // разрешён ли последний запрос
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null;index"`
+}
+
+// secondsToDuration - переводит секунды в time.Duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Max(seconds, 0) * float64(time.Second))
+}
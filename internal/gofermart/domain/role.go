@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+)
+
+// Role - роль пользователя, определяющая доступные ему операции
+type Role string
+
+// Роли пользователей
+const (
+	RoleAdmin   Role = "admin"   // управление ролями и все операции поддержки
+	RolePartner Role = "partner" // пакетная загрузка заказов
+	RoleSupport Role = "support" // операции поддержки в /api/admin
+	RoleUser    Role = "user"    // роль по умолчанию для зарегистрированных пользователей
+)
+
+// knownRoles - все допустимые роли
+var knownRoles = map[Role]bool{
+	RoleAdmin:   true,
+	RolePartner: true,
+	RoleSupport: true,
+	RoleUser:    true,
+}
+
+// Valid - проверяет, что роль известна
+func (r Role) Valid() bool {
+	return knownRoles[r]
+}
+
+// Roles - набор ролей пользователя; в базе данных хранится строкой с ролями через запятую
+type Roles []Role
+
+// DefaultRoles - роли нового пользователя
+func DefaultRoles() Roles {
+	return Roles{RoleUser}
+}
+
+// ParseRoles - разбирает список ролей, отклоняя неизвестные; повторы отбрасываются
+func ParseRoles(values []string) (Roles, error) {
+	roles := make(Roles, 0, len(values))
+	for _, value := range values {
+		role := Role(strings.TrimSpace(value))
+		if !role.Valid() {
+			return nil, fmt.Errorf("unknown role %q", value)
+		}
+		if !roles.Has(role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles, nil
+}
+
+// Has - проверяет наличие роли
+func (r Roles) Has(role Role) bool {
+	for _, item := range r {
+		if item == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAny - проверяет наличие хотя бы одной из ролей
+func (r Roles) HasAny(roles ...Role) bool {
+	for _, role := range roles {
+		if r.Has(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// Strings - возвращает роли в виде строк, например для claims токена
+func (r Roles) Strings() []string {
+	values := make([]string, len(r))
+	for i, role := range r {
+		values[i] = string(role)
+	}
+	return values
+}
+
+// Value - реализует driver.Valuer
+func (r Roles) Value() (driver.Value, error) {
+	return strings.Join(r.Strings(), ","), nil
+}
+
+// Scan - реализует sql.Scanner
+func (r *Roles) Scan(src any) error {
+	var value string
+	switch typed := src.(type) {
+	case nil:
+	case string:
+		value = typed
+	case []byte:
+		value = string(typed)
+	default:
+		return fmt.Errorf("cannot scan %T into Roles", src)
+	}
+
+	*r = Roles{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*r = append(*r, Role(item))
+		}
+	}
+	return nil
+}
+
+// Principal - аутентифицированный пользователь запроса, восстановленный из claims токена
+type Principal struct {
+	UserID int
+	Login  string
+	Roles  Roles
+}
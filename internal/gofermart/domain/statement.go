@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"github.com/shopspring/decimal"
+	"time"
+)
+
+// Типы строк выписки
+const (
+	StatementEntryOpening    = "OPENING_BALANCE"
+	StatementEntryOrder      = "ORDER"
+	StatementEntryWithdrawal = "WITHDRAWAL"
+	StatementEntryAdjustment = "ADJUSTMENT"
+)
+
+// StatementEntry - строка выписки пользователя: операция и баланс после неё
+type StatementEntry struct {
+	Time        time.Time
+	Type        string
+	OrderNumber string
+	Status      string
+	Amount      decimal.Decimal // изменение баланса: начисление положительно, списание отрицательно
+	Balance     decimal.Decimal
+}
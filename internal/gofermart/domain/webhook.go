@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"slices"
+	"time"
+)
+
+// Состояния доставки webhook
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+// WebhookEventTypes - события, на которые пользователь может подписать webhook
+var WebhookEventTypes = []OutboxEventType{OutboxOrderStatusChanged, OutboxPointsAccrued}
+
+// Webhook - адрес пользователя, на который отправляются уведомления о выбранных событиях его заказов
+type Webhook struct {
+	WebhookID int               `gorm:"column:webhook_id;primaryKey;autoIncrement"`
+	UserID    int               `gorm:"column:user_id;not null;index"`
+	URL       string            `gorm:"column:url;not null"`
+	Events    []OutboxEventType `gorm:"column:events;type:text;serializer:json;not null"`
+	Secret    string            `gorm:"column:secret;not null"` // ключ HMAC-подписи доставок
+	CreatedAt time.Time         `gorm:"column:created_at;type:timestamp with time zone;not null"`
+}
+
+// Subscribed - проверяет, подписан ли webhook на событие
+func (w Webhook) Subscribed(eventType OutboxEventType) bool {
+	return slices.Contains(w.Events, eventType)
+}
+
+// WebhookDelivery - доставка события на webhook; хранит результат последней попытки и служит журналом доставок
+type WebhookDelivery struct {
+	DeliveryID     int             `gorm:"column:delivery_id;primaryKey;autoIncrement"`
+	WebhookID      int             `gorm:"column:webhook_id;not null;index"`
+	EventType      OutboxEventType `gorm:"column:event_type;not null"`
+	Payload        OutboxPayload   `gorm:"column:payload;type:jsonb;not null"`
+	Status         string          `gorm:"column:status;not null"`
+	Attempts       int             `gorm:"column:attempts;not null;default:0"`
+	ResponseStatus int             `gorm:"column:response_status;not null;default:0"` // HTTP-статус последнего ответа, 0 — ответа не было
+	LastError      string          `gorm:"column:last_error;not null;default:''"`
+	NextAttemptAt  time.Time       `gorm:"column:next_attempt_at;type:timestamp with time zone;not null;index:idx_webhook_deliveries_pending,where:status = 'PENDING'"`
+	CreatedAt      time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null"`
+	DeliveredAt    *time.Time      `gorm:"column:delivered_at;type:timestamp with time zone"`
+}
@@ -4,12 +4,23 @@ import "errors"
 
 var (
 	ErrAccrualSystemUnavailable = errors.New("accrual system unavailable")
+	ErrAdjustmentReasonRequired = errors.New("balance adjustment reason is required")
 	ErrInsufficientFunds        = errors.New("insufficient funds")
+	ErrInvalidAdjustmentAmount  = errors.New("invalid balance adjustment amount")
+	ErrInvalidRole              = errors.New("invalid role")
+	ErrInvalidCursor            = errors.New("invalid pagination cursor")
+	ErrInvalidWebhookEvents     = errors.New("webhook events must be a non-empty list of: OrderStatusChanged, PointsAccrued")
+	ErrInvalidWebhookURL        = errors.New("webhook URL must be an absolute http or https URL")
 	ErrInvalidWithdrawalAmount  = errors.New("invalid withdrawal amount")
 	ErrLoginAlreadyExists       = errors.New("login already exists")
 	ErrOrderAlreadyExists       = errors.New("order number already exists")
+	ErrOrderAlreadyProcessed    = errors.New("order already processed")
 	ErrOrderAlreadyUploaded     = errors.New("order already uploaded by this user")
 	ErrOrderNotFound            = errors.New("order not found")
 	ErrOrderUploadedByAnother   = errors.New("order already uploaded by another user")
+	ErrStatementNotFound        = errors.New("statement not found")
+	ErrTooManyOrderStreams      = errors.New("too many open order streams")
+	ErrUserBlocked              = errors.New("user is blocked")
 	ErrUserNotFound             = errors.New("user not found")
+	ErrWebhookNotFound          = errors.New("webhook not found")
 )
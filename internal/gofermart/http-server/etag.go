@@ -0,0 +1,99 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"context"
+	"fmt"
+	"github.com/go-chi/jwtauth/v5"
+	"hash/fnv"
+	"net/http"
+	"strings"
+)
+
+// userVersionGetter - получение версии данных пользователя, которая растёт при каждом изменении его баланса,
+// заказов и выводов
+type userVersionGetter interface {
+	GetVersion(ctx context.Context, userID int) (int64, error)
+}
+
+// conditionalGet - добавляет к ответам на чтение данных пользователя слабый ETag, построенный по версии его данных
+// и адресу запроса, и отвечает 304 без тела, если он совпадает с If-None-Match. Версия читается до обработчика:
+// если данные изменятся во время обработки, ETag окажется старше ответа и следующий запрос просто получит его заново.
+// Если версию получить не удалось, запрос обрабатывается как обычно, без ETag.
+func conditionalGet(versions userVersionGetter) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, claims, _ := jwtauth.FromContext(r.Context())
+			principal, ok := utils.PrincipalFromClaims(claims)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			version, err := versions.GetVersion(r.Context(), principal.UserID)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			etag := weakETag(principal.UserID, version, r)
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", "private, no-cache")
+			if etagMatches(r.Header.Get("If-None-Match"), etag) {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			next.ServeHTTP(&etagWriter{ResponseWriter: w}, r)
+		})
+	}
+}
+
+// weakETag - слабый ETag ответа: пользователь, версия его данных и хеш пути с параметрами запроса,
+// поскольку от фильтров и пагинации зависит содержимое ответа
+func weakETag(userID int, version int64, r *http.Request) string {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(r.URL.Path + "?" + r.URL.RawQuery))
+	return fmt.Sprintf(`W/"%d.%d.%08x"`, userID, version, h.Sum32())
+}
+
+// etagMatches - слабое сравнение значения If-None-Match со списком ETag или "*" с текущим ETag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
+
+// etagWriter - убирает ETag из ответа, если обработчик завершился не успешно
+type etagWriter struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (w *etagWriter) WriteHeader(code int) {
+	if !w.wroteHeader {
+		w.wroteHeader = true
+		if code < http.StatusOK || code >= http.StatusMultipleChoices {
+			w.Header().Del("ETag")
+		}
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap - исходный ResponseWriter для http.ResponseController
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
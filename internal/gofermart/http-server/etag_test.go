@@ -0,0 +1,149 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// stubVersions - версии данных пользователей для проверки conditionalGet
+type stubVersions map[int]int64
+
+func (s stubVersions) GetVersion(_ context.Context, userID int) (int64, error) {
+	version, ok := s[userID]
+	if !ok {
+		return 0, errors.New("version unavailable")
+	}
+	return version, nil
+}
+
+func TestConditionalGet(t *testing.T) {
+	versions := stubVersions{1: 3}
+	currentETag := weakETag(1, 3, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	testCases := []struct {
+		name         string
+		userID       int
+		ifNoneMatch  string
+		handlerCode  int
+		expectedCode int
+		expectETag   bool
+	}{
+		{
+			name:         "Without_If_None_Match",
+			userID:       1,
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusOK,
+			expectETag:   true,
+		},
+		{
+			name:         "Matching_ETag",
+			userID:       1,
+			ifNoneMatch:  currentETag,
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusNotModified,
+			expectETag:   true,
+		},
+		{
+			name:         "Matching_Strong_ETag_In_List",
+			userID:       1,
+			ifNoneMatch:  `W/"1.2.00000000", ` + currentETag[2:],
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusNotModified,
+			expectETag:   true,
+		},
+		{
+			name:         "Any_ETag",
+			userID:       1,
+			ifNoneMatch:  "*",
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusNotModified,
+			expectETag:   true,
+		},
+		{
+			name:         "Stale_ETag",
+			userID:       1,
+			ifNoneMatch:  weakETag(1, 2, httptest.NewRequest(http.MethodGet, "/", nil)),
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusOK,
+			expectETag:   true,
+		},
+		{
+			name:         "Error_Response_Has_No_ETag",
+			userID:       1,
+			handlerCode:  http.StatusInternalServerError,
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name:         "Version_Unavailable",
+			userID:       2,
+			ifNoneMatch:  "*",
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Anonymous_Request",
+			ifNoneMatch:  "*",
+			handlerCode:  http.StatusOK,
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := conditionalGet(versions)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.handlerCode)
+				_, _ = w.Write([]byte(`{}`))
+			}))
+
+			req := rateLimitRequest(t, "10.0.0.1", tc.userID)
+			if tc.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tc.ifNoneMatch)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, rr.Code)
+			}
+			etag := rr.Header().Get("ETag")
+			if tc.expectETag && etag != currentETag {
+				t.Errorf("expected ETag %q, got %q", currentETag, etag)
+			}
+			if !tc.expectETag && etag != "" {
+				t.Errorf("expected no ETag, got %q", etag)
+			}
+			if rr.Code == http.StatusNotModified && rr.Body.Len() != 0 {
+				t.Errorf("expected empty body, got %q", rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestWeakETag(t *testing.T) {
+	testCases := []struct {
+		name      string
+		userID    int
+		version   int64
+		target    string
+		different bool
+	}{
+		{name: "Same_Request", userID: 1, version: 3, target: "/api/user/orders"},
+		{name: "Other_Version", userID: 1, version: 4, target: "/api/user/orders", different: true},
+		{name: "Other_User", userID: 2, version: 3, target: "/api/user/orders", different: true},
+		{name: "Other_Query", userID: 1, version: 3, target: "/api/user/orders?limit=1", different: true},
+		{name: "Other_Path", userID: 1, version: 3, target: "/api/user/withdrawals", different: true},
+	}
+
+	base := weakETag(1, 3, httptest.NewRequest(http.MethodGet, "/api/user/orders", nil))
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			etag := weakETag(tc.userID, tc.version, httptest.NewRequest(http.MethodGet, tc.target, nil))
+			if (etag != base) != tc.different {
+				t.Errorf("expected ETag %q to differ from %q: %v", etag, base, tc.different)
+			}
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/api/user"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var errInvalidAuditType = errors.New("unknown audit event type")
+
+type (
+	// AuditGetHandler - обработчик HTTP-запросов администратора на просмотр журнала аудита
+	AuditGetHandler struct {
+		auditService services.AuditEventReader
+		logger       *zap.Logger
+	}
+	// AuditEventResponse - событие журнала аудита в ответе API
+	AuditEventResponse struct {
+		ID        int                 `json:"id"`
+		Type      string              `json:"type"`
+		Actor     string              `json:"actor"`
+		Subject   string              `json:"subject"`
+		IP        string              `json:"ip"`
+		UserAgent string              `json:"user_agent"`
+		RequestID string              `json:"request_id"`
+		Payload   domain.AuditPayload `json:"payload"`
+		CreatedAt string              `json:"created_at"`
+	}
+)
+
+// NewAuditGetHandler - создает новый экземпляр AuditGetHandler
+func NewAuditGetHandler(auditService services.AuditEventReader, logger *zap.Logger) *AuditGetHandler {
+	return &AuditGetHandler{
+		auditService: auditService,
+		logger:       logger,
+	}
+}
+
+// ServeHTTP - отдает страницу событий журнала аудита от новых к старым. Помимо общих параметров списков
+// поддерживаются фильтры type (список через запятую), actor и subject.
+func (h *AuditGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseAuditFilter(r.URL.Query())
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest, err.Error())
+		return
+	}
+
+	events, next, err := h.auditService.GetEvents(r.Context(), filter)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if len(events) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]AuditEventResponse, 0, len(events))
+	for _, event := range events {
+		response = append(response, AuditEventResponse{
+			ID:        event.EventID,
+			Type:      string(event.Type),
+			Actor:     event.Actor,
+			Subject:   event.Subject,
+			IP:        event.IP,
+			UserAgent: event.UserAgent,
+			RequestID: event.RequestID,
+			Payload:   event.Payload,
+			CreatedAt: event.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	user.SetNextLink(w, r, next)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
+
+// parseAuditFilter - разбирает параметры выборки журнала аудита; type можно передать списком через запятую или повторить
+func parseAuditFilter(query url.Values) (domain.AuditFilter, error) {
+	options, err := user.ParseListOptions(query)
+	if err != nil {
+		return domain.AuditFilter{}, err
+	}
+
+	filter := domain.AuditFilter{
+		ListOptions: options,
+		Actor:       query.Get("actor"),
+		Subject:     query.Get("subject"),
+	}
+	for _, value := range query["type"] {
+		for _, item := range strings.Split(value, ",") {
+			eventType := domain.AuditEventType(strings.TrimSpace(item))
+			if !eventType.Valid() {
+				return domain.AuditFilter{}, errInvalidAuditType
+			}
+			filter.Types = append(filter.Types, eventType)
+		}
+	}
+
+	return filter, nil
+}
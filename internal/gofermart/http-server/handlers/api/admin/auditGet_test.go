@@ -0,0 +1,109 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAuditGetHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuditService := mocks.NewMockAuditEventReader(ctrl)
+
+	r := chi.NewRouter()
+	r.Get("/api/admin/audit", NewAuditGetHandler(mockAuditService, zap.NewNop()).ServeHTTP)
+
+	createdAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+	event := domain.AuditEvent{
+		EventID:   7,
+		Type:      domain.AuditUserBlocked,
+		Actor:     "support",
+		Subject:   "alice",
+		IP:        "192.0.2.1",
+		UserAgent: "curl",
+		RequestID: "req-1",
+		Payload:   domain.AuditPayload{"reason": "fraud"},
+		CreatedAt: createdAt,
+	}
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedFilter *domain.AuditFilter
+		events         []domain.AuditEvent
+		next           *domain.Cursor
+		serviceErr     error
+		expectedCode   int
+		expectedBody   string
+		expectedLink   string
+	}{
+		{
+			name:           "Events_Page",
+			query:          "?type=user.blocked,user.unblocked&actor=support&subject=alice",
+			expectedFilter: &domain.AuditFilter{Types: []domain.AuditEventType{domain.AuditUserBlocked, domain.AuditUserUnblocked}, Actor: "support", Subject: "alice"},
+			events:         []domain.AuditEvent{event},
+			expectedCode:   http.StatusOK,
+			expectedBody:   `[{"id":7,"type":"user.blocked","actor":"support","subject":"alice","ip":"192.0.2.1","user_agent":"curl","request_id":"req-1","payload":{"reason":"fraud"},"created_at":"2024-05-01T12:00:00Z"}]`,
+		},
+		{
+			name:           "Next_Page_Link",
+			query:          "?limit=1",
+			expectedFilter: &domain.AuditFilter{ListOptions: domain.ListOptions{Limit: 1}},
+			events:         []domain.AuditEvent{event},
+			next:           &domain.Cursor{Time: createdAt, Key: "7"},
+			expectedCode:   http.StatusOK,
+			expectedBody:   `[{"id":7,`,
+			expectedLink:   `cursor=` + domain.Cursor{Time: createdAt, Key: "7"}.Encode(),
+		},
+		{
+			name:           "No_Events",
+			expectedFilter: &domain.AuditFilter{},
+			expectedCode:   http.StatusNoContent,
+		},
+		{
+			name:         "Unknown_Event_Type",
+			query:        "?type=user.deleted",
+			expectedCode: http.StatusBadRequest,
+			expectedBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"unknown audit event type","instance":"/api/admin/audit"}`,
+		},
+		{
+			name:           "Internal_Error",
+			expectedFilter: &domain.AuditFilter{},
+			serviceErr:     errors.New("database error"),
+			expectedCode:   http.StatusInternalServerError,
+			expectedBody:   `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/api/admin/audit"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectedFilter != nil {
+				mockAuditService.EXPECT().GetEvents(gomock.Any(), *tc.expectedFilter).Return(tc.events, tc.next, tc.serviceErr)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/audit"+tc.query, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if body := rr.Body.String(); !strings.HasPrefix(body, tc.expectedBody) {
+				t.Errorf("expected body starting with %s, got %s", tc.expectedBody, body)
+			}
+			if link := rr.Header().Get("Link"); !strings.Contains(link, tc.expectedLink) || (tc.expectedLink == "") != (link == "") {
+				t.Errorf("expected Link header with %q, got %q", tc.expectedLink, link)
+			}
+		})
+	}
+}
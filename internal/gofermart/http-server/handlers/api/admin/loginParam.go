@@ -0,0 +1,32 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// LoginParamExtractor - реализация utils.PrincipalExtractor, возвращающая пользователя, логин которого указан
+// в параметре пути {login}. Позволяет поддержке использовать пользовательские обработчики списков для просмотра
+// данных любого пользователя.
+type LoginParamExtractor struct {
+	UserService *services.UserService
+}
+
+// ExtractPrincipalFromContext - находит пользователя по логину из параметра пути запроса
+func (e LoginParamExtractor) ExtractPrincipalFromContext(r *http.Request, logger *zap.Logger) (domain.Principal, error) {
+	login := chi.URLParam(r, "login")
+	if login == "" {
+		logger.Warn("Login path parameter is missing", zap.String("path", r.URL.Path))
+		return domain.Principal{}, errors.New("login path parameter is missing")
+	}
+
+	user, err := e.UserService.GetUser(r.Context(), login)
+	if err != nil {
+		return domain.Principal{}, err
+	}
+	return domain.Principal{UserID: user.UserID, Login: user.Login, Roles: user.Roles}, nil
+}
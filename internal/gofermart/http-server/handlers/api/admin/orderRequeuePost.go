@@ -0,0 +1,38 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// OrderRequeuePostHandler - обработчик HTTP-запросов поддержки на повторную постановку заказа в очередь опроса
+// системы начисления
+type OrderRequeuePostHandler struct {
+	logger       *zap.Logger
+	orderService services.OrderServiceInterface
+}
+
+// NewOrderRequeuePostHandler - создает новый экземпляр OrderRequeuePostHandler
+func NewOrderRequeuePostHandler(orderService services.OrderServiceInterface, logger *zap.Logger) *OrderRequeuePostHandler {
+	return &OrderRequeuePostHandler{
+		logger:       logger,
+		orderService: orderService,
+	}
+}
+
+// ServeHTTP - возвращает заказ, указанный в пути, в очередь опроса
+func (h *OrderRequeuePostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	number := chi.URLParam(r, "number")
+	if err := h.orderService.RequeueOrder(r.Context(), number); err != nil {
+		if problem.KindOf(err) == problem.Internal {
+			h.logger.Error("Failed to requeue order", zap.String("order", number), zap.Error(err))
+		}
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
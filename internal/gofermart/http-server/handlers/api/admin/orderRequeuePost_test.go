@@ -0,0 +1,71 @@
+package admin
+
+import (
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOrderRequeuePostHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderService := mocks.NewMockOrderServiceInterface(ctrl)
+
+	r := chi.NewRouter()
+	r.Post("/api/admin/orders/{number}/requeue", NewOrderRequeuePostHandler(mockOrderService, zap.NewNop()).ServeHTTP)
+
+	testCases := []struct {
+		name         string
+		serviceErr   error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Requeued",
+			expectedCode: http.StatusAccepted,
+		},
+		{
+			name:         "Already_Processed",
+			serviceErr:   gofermartErrors.ErrOrderAlreadyProcessed,
+			expectedCode: http.StatusConflict,
+			expectedBody: `{"type":"/problems/order-already-processed","title":"Order already processed","status":409,"detail":"order already processed","instance":"/api/admin/orders/79927398713/requeue"}`,
+		},
+		{
+			name:         "Unknown_Order",
+			serviceErr:   gofermartErrors.ErrOrderNotFound,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"type":"/problems/order-not-found","title":"Order not found","status":404,"detail":"order not found","instance":"/api/admin/orders/79927398713/requeue"}`,
+		},
+		{
+			name:         "Internal_Error",
+			serviceErr:   errors.New("database error"),
+			expectedCode: http.StatusInternalServerError,
+			expectedBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/api/admin/orders/79927398713/requeue"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockOrderService.EXPECT().RequeueOrder(gomock.Any(), "79927398713").Return(tc.serviceErr)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/orders/79927398713/requeue", nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if body := strings.TrimSpace(rr.Body.String()); body != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type (
+	// UserAdjustmentsPostHandler - обработчик HTTP-запросов поддержки на ручную корректировку баланса пользователя
+	UserAdjustmentsPostHandler struct {
+		logger             *zap.Logger
+		principalExtractor utils.PrincipalExtractor
+		userService        *services.UserService
+	}
+	// UserAdjustmentsPostRequest - запрос на корректировку: сумма со знаком и обязательная причина
+	UserAdjustmentsPostRequest struct {
+		Amount decimal.Decimal `json:"amount"`
+		Reason string          `json:"reason"`
+	}
+)
+
+// NewUserAdjustmentsPostHandler - создает новый экземпляр UserAdjustmentsPostHandler; principalExtractor определяет
+// сотрудника поддержки, выполняющего корректировку
+func NewUserAdjustmentsPostHandler(userService *services.UserService, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *UserAdjustmentsPostHandler {
+	return &UserAdjustmentsPostHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		userService:        userService,
+	}
+}
+
+// ServeHTTP - изменяет баланс пользователя, указанного в пути, и сохраняет запись о корректировке
+func (h *UserAdjustmentsPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	var req UserAdjustmentsPostRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		h.logger.Warn("Invalid request format", zap.Error(err))
+		problem.InvalidBody(w, r, err)
+		return
+	}
+
+	err = h.userService.AdjustBalance(r.Context(), chi.URLParam(r, "login"), req.Amount, req.Reason, principal.Login)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
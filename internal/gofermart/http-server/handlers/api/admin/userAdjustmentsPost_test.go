@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUserAdjustmentsPostHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	userService, store := newUserServiceFixture(t)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+
+	r := chi.NewRouter()
+	r.Post("/api/admin/users/{login}/adjustments", NewUserAdjustmentsPostHandler(userService, mockPrincipalExtractor, zap.NewNop()).ServeHTTP)
+
+	testCases := []struct {
+		name            string
+		login           string
+		body            string
+		expectedCode    int
+		expectedBody    string
+		expectedBalance float64
+	}{
+		{
+			name:            "Debit",
+			login:           "alice",
+			body:            `{"amount": -30.5, "reason": "chargeback"}`,
+			expectedCode:    http.StatusOK,
+			expectedBalance: 69.5,
+		},
+		{
+			name:            "Debit_Below_Zero",
+			login:           "alice",
+			body:            `{"amount": -70, "reason": "chargeback"}`,
+			expectedCode:    http.StatusPaymentRequired,
+			expectedBody:    `{"type":"/problems/insufficient-funds","title":"Insufficient funds","status":402,"detail":"insufficient funds","instance":"/api/admin/users/alice/adjustments"}`,
+			expectedBalance: 69.5,
+		},
+		{
+			name:            "Missing_Reason",
+			login:           "alice",
+			body:            `{"amount": 10}`,
+			expectedCode:    http.StatusUnprocessableEntity,
+			expectedBody:    `{"type":"/problems/invalid-balance-adjustment","title":"Invalid balance adjustment","status":422,"detail":"balance adjustment reason is required","instance":"/api/admin/users/alice/adjustments"}`,
+			expectedBalance: 69.5,
+		},
+		{
+			name:            "Invalid_Body",
+			login:           "alice",
+			body:            `{"amount": "ten"}`,
+			expectedCode:    http.StatusBadRequest,
+			expectedBody:    `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/api/admin/users/alice/adjustments"}`,
+			expectedBalance: 69.5,
+		},
+		{
+			name:         "Unknown_User",
+			login:        "ghost",
+			body:         `{"amount": 10, "reason": "typo"}`,
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"type":"/problems/user-not-found","title":"User not found","status":404,"detail":"user not found","instance":"/api/admin/users/ghost/adjustments"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "support"}, nil)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/admin/users/"+tc.login+"/adjustments", strings.NewReader(tc.body))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if body := strings.TrimSpace(rr.Body.String()); body != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, body)
+			}
+			if tc.expectedBalance != 0 {
+				user, err := store.UserRepo.GetUserByLogin(context.Background(), tc.login)
+				if err != nil {
+					t.Fatalf("failed to get user: %v", err)
+				}
+				balance, err := store.UserRepo.GetUserBalance(context.Background(), user.UserID)
+				if err != nil {
+					t.Fatalf("failed to get balance: %v", err)
+				}
+				if balance.Current != tc.expectedBalance {
+					t.Errorf("expected balance %v, got %v", tc.expectedBalance, balance.Current)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// UserBlockHandler - обработчик HTTP-запросов поддержки на блокировку (PUT) и разблокировку (DELETE) пользователя
+type UserBlockHandler struct {
+	blocked            bool
+	logger             *zap.Logger
+	principalExtractor utils.PrincipalExtractor
+	userService        *services.UserService
+}
+
+// NewUserBlockHandler - создает обработчик, устанавливающий признак блокировки пользователя в значение blocked
+func NewUserBlockHandler(userService *services.UserService, principalExtractor utils.PrincipalExtractor, blocked bool, logger *zap.Logger) *UserBlockHandler {
+	return &UserBlockHandler{
+		blocked:            blocked,
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		userService:        userService,
+	}
+}
+
+// ServeHTTP - блокирует или разблокирует пользователя, указанного в пути
+func (h *UserBlockHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if err := h.userService.SetBlocked(r.Context(), chi.URLParam(r, "login"), h.blocked, principal.Login); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
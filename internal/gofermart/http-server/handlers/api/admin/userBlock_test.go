@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserBlockHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	userService, store := newUserServiceFixture(t)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+
+	r := chi.NewRouter()
+	r.Put("/api/admin/users/{login}/block", NewUserBlockHandler(userService, mockPrincipalExtractor, true, logger).ServeHTTP)
+	r.Delete("/api/admin/users/{login}/block", NewUserBlockHandler(userService, mockPrincipalExtractor, false, logger).ServeHTTP)
+
+	testCases := []struct {
+		name            string
+		method          string
+		login           string
+		expectedCode    int
+		expectedBlocked bool
+	}{
+		{name: "Block", method: http.MethodPut, login: "alice", expectedCode: http.StatusOK, expectedBlocked: true},
+		{name: "Block_Again", method: http.MethodPut, login: "alice", expectedCode: http.StatusOK, expectedBlocked: true},
+		{name: "Unblock", method: http.MethodDelete, login: "alice", expectedCode: http.StatusOK, expectedBlocked: false},
+		{name: "Unknown_User", method: http.MethodPut, login: "ghost", expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "support"}, nil)
+
+			req := httptest.NewRequest(tc.method, "/api/admin/users/"+tc.login+"/block", nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if tc.expectedCode != http.StatusOK {
+				return
+			}
+			user, err := store.UserRepo.GetUserByLogin(context.Background(), tc.login)
+			if err != nil {
+				t.Fatalf("failed to get user: %v", err)
+			}
+			if user.Blocked != tc.expectedBlocked {
+				t.Errorf("expected blocked %v, got %v", tc.expectedBlocked, user.Blocked)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+type (
+	// UserGetHandler - обработчик HTTP-запросов поддержки на просмотр пользователя, его баланса и корректировок
+	UserGetHandler struct {
+		logger      *zap.Logger
+		userService *services.UserService
+	}
+	// UserGetResponse - сведения о пользователе для поддержки
+	UserGetResponse struct {
+		Login       string               `json:"login"`
+		Blocked     bool                 `json:"blocked"`
+		Roles       []string             `json:"roles"`
+		Current     float64              `json:"current"`
+		Withdrawn   float64              `json:"withdrawn"`
+		Adjustments []AdjustmentResponse `json:"adjustments"`
+	}
+	// AdjustmentResponse - ручная корректировка баланса в ответе API
+	AdjustmentResponse struct {
+		Amount    float64 `json:"amount"`
+		Reason    string  `json:"reason"`
+		Actor     string  `json:"actor"`
+		CreatedAt string  `json:"created_at"`
+	}
+)
+
+// NewUserGetHandler - создает новый экземпляр UserGetHandler
+func NewUserGetHandler(userService *services.UserService, logger *zap.Logger) *UserGetHandler {
+	return &UserGetHandler{
+		logger:      logger,
+		userService: userService,
+	}
+}
+
+// ServeHTTP - отдает пользователя, указанного в пути
+func (h *UserGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	login := chi.URLParam(r, "login")
+
+	user, err := h.userService.GetUser(r.Context(), login)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	balance, err := h.userService.GetBalance(r.Context(), user.UserID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	adjustments, err := h.userService.GetAdjustments(r.Context(), user.UserID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	response := UserGetResponse{
+		Login:       user.Login,
+		Blocked:     user.Blocked,
+		Roles:       user.Roles.Strings(),
+		Current:     balance.Current,
+		Withdrawn:   balance.Withdrawn,
+		Adjustments: make([]AdjustmentResponse, 0, len(adjustments)),
+	}
+	for _, adjustment := range adjustments {
+		amount, _ := adjustment.Amount.Float64()
+		response.Adjustments = append(response.Adjustments, AdjustmentResponse{
+			Amount:    amount,
+			Reason:    adjustment.Reason,
+			Actor:     adjustment.Actor,
+			CreatedAt: adjustment.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
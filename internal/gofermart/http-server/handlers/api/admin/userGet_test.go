@@ -0,0 +1,71 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newUserServiceFixture — сервис пользователей поверх хранилища в памяти с пользователем alice и балансом 100
+func newUserServiceFixture(t *testing.T) (*services.UserService, *storage.Storage) {
+	t.Helper()
+	logger := zap.NewNop()
+	store := storage.NewMemoryStorage(logger)
+	userService := services.NewUserService(store.TxManager, store.UserRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.AuditRepo, store.OutboxRepo, logger)
+
+	if _, err := services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger).RegisterUser(context.Background(), "alice", "secret"); err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+	if err := userService.AdjustBalance(context.Background(), "alice", decimal.NewFromInt(100), "welcome bonus", "support"); err != nil {
+		t.Fatalf("failed to adjust balance: %v", err)
+	}
+	return userService, store
+}
+
+func TestUserGetHandler_ServeHTTP(t *testing.T) {
+	userService, _ := newUserServiceFixture(t)
+
+	r := chi.NewRouter()
+	r.Get("/api/admin/users/{login}", NewUserGetHandler(userService, zap.NewNop()).ServeHTTP)
+
+	testCases := []struct {
+		name         string
+		login        string
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Existing_User",
+			login:        "alice",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"login":"alice","blocked":false,"roles":["user"],"current":100,"withdrawn":0,"adjustments":[{"amount":100,"reason":"welcome bonus","actor":"support","created_at":"`,
+		},
+		{
+			name:         "Unknown_User",
+			login:        "ghost",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `{"type":"/problems/user-not-found","title":"User not found","status":404,"detail":"user not found","instance":"/api/admin/users/ghost"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/users/"+tc.login, nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if body := rr.Body.String(); len(body) < len(tc.expectedBody) || body[:len(tc.expectedBody)] != tc.expectedBody {
+				t.Errorf("expected body starting with %s, got %s", tc.expectedBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type (
+	// UserRolesPutHandler - обработчик HTTP-запросов администратора на замену ролей пользователя
+	UserRolesPutHandler struct {
+		logger             *zap.Logger
+		principalExtractor utils.PrincipalExtractor
+		userService        *services.UserService
+	}
+	// UserRolesRequest - новый набор ролей пользователя
+	UserRolesRequest struct {
+		Roles []string `json:"roles"`
+	}
+)
+
+// NewUserRolesPutHandler - создает новый экземпляр UserRolesPutHandler; principalExtractor определяет
+// администратора, изменяющего роли
+func NewUserRolesPutHandler(userService *services.UserService, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *UserRolesPutHandler {
+	return &UserRolesPutHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		userService:        userService,
+	}
+}
+
+// ServeHTTP - заменяет роли пользователя, указанного в пути, и возвращает сохраненный набор ролей.
+// Изменения вступают в силу при следующем входе пользователя, так как роли передаются в токене.
+func (h *UserRolesPutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	var req UserRolesRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		h.logger.Warn("Failed to decode roles request", zap.Error(err))
+		problem.InvalidBody(w, r, err)
+		return
+	}
+
+	roles, err := h.userService.SetRoles(r.Context(), chi.URLParam(r, "login"), req.Roles, principal.Login)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(UserRolesRequest{Roles: roles.Strings()}); err != nil {
+		h.logger.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
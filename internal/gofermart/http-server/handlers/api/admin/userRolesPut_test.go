@@ -0,0 +1,64 @@
+package admin
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"bytes"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUserRolesPutHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	userService, store := newUserServiceFixture(t)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+
+	r := chi.NewRouter()
+	r.Put("/api/admin/users/{login}/roles", NewUserRolesPutHandler(userService, mockPrincipalExtractor, logger).ServeHTTP)
+
+	testCases := []struct {
+		name          string
+		login         string
+		body          string
+		expectedCode  int
+		expectedRoles domain.Roles
+	}{
+		{name: "Grant_Partner", login: "alice", body: `{"roles": ["user", "partner", "partner"]}`, expectedCode: http.StatusOK, expectedRoles: domain.Roles{domain.RoleUser, domain.RolePartner}},
+		{name: "Unknown_Role", login: "alice", body: `{"roles": ["root"]}`, expectedCode: http.StatusUnprocessableEntity, expectedRoles: domain.Roles{domain.RoleUser, domain.RolePartner}},
+		{name: "Invalid_Body", login: "alice", body: `{"roles":`, expectedCode: http.StatusBadRequest, expectedRoles: domain.Roles{domain.RoleUser, domain.RolePartner}},
+		{name: "Unknown_User", login: "ghost", body: `{"roles": ["user"]}`, expectedCode: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "support"}, nil)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/admin/users/"+tc.login+"/roles", bytes.NewBufferString(tc.body))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if tc.expectedRoles == nil {
+				return
+			}
+			user, err := store.UserRepo.GetUserByLogin(context.Background(), tc.login)
+			if err != nil {
+				t.Fatalf("failed to get user: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedRoles, user.Roles); diff != "" {
+				t.Errorf("roles mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
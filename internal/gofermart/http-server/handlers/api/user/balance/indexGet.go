@@ -1,6 +1,7 @@
 package balance
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
 	"encoding/json"
@@ -11,9 +12,9 @@ import (
 type (
 	// IndexGetHandler - представляет HTTP-обработчик для получения текущего баланса пользователя.
 	IndexGetHandler struct {
-		logger            *zap.Logger
-		userService       *services.UserService
-		usernameExtractor utils.UsernameExtractor
+		logger             *zap.Logger
+		principalExtractor utils.PrincipalExtractor
+		userService        *services.UserService
 	}
 	// IndexGetResponse - представляет ответ API, содержащий информацию о балансе пользователя.
 	IndexGetResponse struct {
@@ -23,26 +24,26 @@ type (
 )
 
 // NewIndexGetHandler - создает новый экземпляр IndexGetHandler с указанными зависимостями.
-func NewIndexGetHandler(userService *services.UserService, usernameExtractor utils.UsernameExtractor, logger *zap.Logger) *IndexGetHandler {
+func NewIndexGetHandler(userService *services.UserService, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *IndexGetHandler {
 	return &IndexGetHandler{
-		logger:            logger,
-		userService:       userService,
-		usernameExtractor: usernameExtractor,
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		userService:        userService,
 	}
 }
 
 // ServeHTTP - обрабатывает HTTP-запросы для получения баланса пользователя.
 func (h *IndexGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	login, err := h.usernameExtractor.ExtractUsernameFromContext(r, h.logger)
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
-	balance, err := h.userService.GetBalance(login)
+	balance, err := h.userService.GetBalance(r.Context(), principal.UserID)
 	if err != nil {
 		h.logger.Error("Failed to get balance", zap.Error(err))
-		http.Error(w, "Failed to get balance", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -55,7 +56,7 @@ func (h *IndexGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		h.logger.Error("Failed to encode JSON response", zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Write(w, r, problem.Internal, "")
 		return
 	}
 }
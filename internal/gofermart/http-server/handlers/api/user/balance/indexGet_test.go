@@ -18,38 +18,38 @@ import (
 func TestIndexGetHandler_ServeHTTP(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
-	mockUsernameExtractor := mocks.NewMockUsernameExtractor(ctrl)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockWithdrawalRepo := mocks.NewMockWithdrawalRepository(ctrl)
 	logger := zap.NewNop()
 
 	testCases := []struct {
 		name               string
-		mockSetup          func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockUsernameExtractor)
+		mockSetup          func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockPrincipalExtractor)
 		expectedStatusCode int
 		expectedBody       string
 	}{
 		{
 			name:               "Unauthorized_Access",
 			expectedStatusCode: http.StatusInternalServerError,
-			expectedBody:       "Internal Server Error\n",
-			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockUsernameExtractor) {
-				mockExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("", http.ErrNoCookie)
+			expectedBody:       `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/balance"}` + "\n",
+			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockPrincipalExtractor) {
+				mockExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{}, http.ErrNoCookie)
 			},
 		},
 		{
 			name: "Internal_Server_Error_On_GetBalance",
-			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockUsernameExtractor) {
-				mockExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
+			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockPrincipalExtractor) {
+				mockExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
 				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), gomock.Any()).Return(nil, errors.New("failed to get balance"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
-			expectedBody:       "Failed to get balance\n",
+			expectedBody:       `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/balance"}` + "\n",
 		},
 		{
 			name: "Successful_Balance_Response",
-			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockUsernameExtractor) {
-				mockExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
+			mockSetup: func(mockUserRepo *mocks.MockUserRepository, mockExtractor *mocks.MockPrincipalExtractor) {
+				mockExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
 				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), gomock.Any()).Return(&domain.UserBalance{
 					Current:   100.50,
 					Withdrawn: 50.75,
@@ -62,10 +62,10 @@ func TestIndexGetHandler_ServeHTTP(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			tc.mockSetup(mockUserRepo, mockUsernameExtractor)
-			userService := services.NewUserService(mockUserRepo, mockWithdrawalRepo, logger)
+			tc.mockSetup(mockUserRepo, mockPrincipalExtractor)
+			userService := services.NewUserService(mocks.NewMockTxManager(ctrl), mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), logger)
 
-			handler := NewIndexGetHandler(userService, mockUsernameExtractor, logger)
+			handler := NewIndexGetHandler(userService, mockPrincipalExtractor, logger)
 
 			req := httptest.NewRequest("GET", "/balance", strings.NewReader(""))
 
@@ -1,11 +1,10 @@
 package balance
 
 import (
-	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
-	"encoding/json"
-	"errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 	"net/http"
@@ -14,9 +13,10 @@ import (
 type (
 	// WithdrawPostHandler - представляет HTTP-обработчик для вывода средств пользователем.
 	WithdrawPostHandler struct {
-		logger            *zap.Logger
-		userService       *services.UserService
-		usernameExtractor utils.UsernameExtractor
+		logger             *zap.Logger
+		principalExtractor utils.PrincipalExtractor
+		userService        *services.UserService
+		validator          validation.OrderNumberValidator
 	}
 	// WithdrawPostRequest - представляет структуру запроса на вывод средств.
 	WithdrawPostRequest struct {
@@ -26,44 +26,42 @@ type (
 )
 
 // NewWithdrawPostHandler - создает новый экземпляр WithdrawPostHandler.
-func NewWithdrawPostHandler(userService *services.UserService, usernameExtractor utils.UsernameExtractor, logger *zap.Logger) *WithdrawPostHandler {
+func NewWithdrawPostHandler(userService *services.UserService, principalExtractor utils.PrincipalExtractor, validator validation.OrderNumberValidator, logger *zap.Logger) *WithdrawPostHandler {
 	return &WithdrawPostHandler{
-		logger:            logger,
-		userService:       userService,
-		usernameExtractor: usernameExtractor,
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		userService:        userService,
+		validator:          validator,
 	}
 }
 
 // ServeHTTP - обрабатывает HTTP-запрос POST для вывода средств.
 func (h *WithdrawPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	login, err := h.usernameExtractor.ExtractUsernameFromContext(r, h.logger)
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
 	var req WithdrawPostRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		h.logger.Warn("Invalid request format", zap.Error(err))
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		problem.InvalidBody(w, r, err)
 		return
 	}
 
-	if !utils.IsValidMoon(req.Order) {
-		h.logger.Warn("Invalid order number format", zap.String("order", req.Order))
-		http.Error(w, "Invalid order number format", http.StatusUnprocessableEntity)
+	if err := h.validator.Validate(principal.Login, req.Order); err != nil {
+		h.logger.Warn("Invalid order number format", zap.String("order", req.Order), zap.Error(err))
+		problem.Error(w, r, err)
 		return
 	}
 
-	err = h.userService.Withdraw(login, req.Order, decimal.NewFromFloat(req.Sum))
+	err = h.userService.Withdraw(r.Context(), principal.UserID, req.Order, decimal.NewFromFloat(req.Sum))
 	if err != nil {
-		switch {
-		case errors.Is(err, gofermartErrors.ErrInsufficientFunds):
-			http.Error(w, "Insufficient funds", http.StatusPaymentRequired)
-		default:
+		if problem.KindOf(err) == problem.Internal {
 			h.logger.Error("Failed to process withdrawal", zap.Error(err))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		}
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -3,13 +3,14 @@ package balance
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"beliaev-aa/yp-gofermart/tests/mocks"
 	"bytes"
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -18,7 +19,12 @@ import (
 func TestWithdrawPostHandler_ServeHTTP(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
-	mockUsernameExtractor := mocks.NewMockUsernameExtractor(ctrl)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockOutboxRepo := mocks.NewMockOutboxRepository(ctrl)
+	mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockWithdrawalRepo := mocks.NewMockWithdrawalRepository(ctrl)
 
@@ -37,48 +43,51 @@ func TestWithdrawPostHandler_ServeHTTP(t *testing.T) {
 			name:        "Malformed_JSON_Body",
 			requestBody: `{"Order": "12345678903", "Sum": "invalid_number"}`,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
 			},
 			expectedStatusCode: http.StatusBadRequest,
-			expectedResponse:   "Invalid request format\n",
+			expectedResponse:   `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/withdraw"}` + "\n",
 		},
 		{
 			name:        "Unauthorized_Access",
 			requestBody: `{"Order": "12345678903", "Sum": 100.50}`,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("", http.ErrNoCookie)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{}, http.ErrNoCookie)
 			},
 			expectedStatusCode: http.StatusInternalServerError,
-			expectedResponse:   "Internal Server Error\n",
+			expectedResponse:   `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/withdraw"}` + "\n",
 		},
 		{
 			name:        "Invalid_Order_Number_Format",
 			requestBody: `{"Order": "123456", "Sum": 100.50}`,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
 			},
 			expectedStatusCode: http.StatusUnprocessableEntity,
-			expectedResponse:   "Invalid order number format\n",
+			expectedResponse:   `{"type":"/problems/invalid-order-number","title":"Invalid order number","status":422,"detail":"order number checksum is invalid","instance":"/withdraw"}` + "\n",
 		},
 		{
 			name:        "Insufficient_Funds",
 			requestBody: `{"Order": "79927398713", "Sum": 150.00}`,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100)}, nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100)}, nil)
 			},
 			expectedStatusCode: http.StatusPaymentRequired,
-			expectedResponse:   "Insufficient funds\n",
+			expectedResponse:   `{"type":"/problems/insufficient-funds","title":"Insufficient funds","status":402,"detail":"insufficient funds","instance":"/withdraw"}` + "\n",
 		},
 		{
 			name:        "Successful_Withdrawal",
 			requestBody: `{"Order": "79927398713", "Sum": 100.50}`,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "test_user").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(400)}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil).AnyTimes()
-				mockUserRepo.EXPECT().Commit(gomock.Any()).Return(nil).AnyTimes()
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil).AnyTimes()
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(400)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), decimal.NewFromFloat(-100.50)).Return(nil)
 			},
@@ -89,14 +98,15 @@ func TestWithdrawPostHandler_ServeHTTP(t *testing.T) {
 			name:        "Internal_Server_Error_On_Withdraw",
 			requestBody: `{"Order": "79927398713", "Sum": 100.50}`,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "test_user").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(400)}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil).AnyTimes()
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil).AnyTimes()
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(400)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					return fn(ctx)
+				})
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(errors.New("internal Server Error"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
-			expectedResponse:   "Internal Server Error\n",
+			expectedResponse:   `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/withdraw"}` + "\n",
 		},
 	}
 
@@ -104,9 +114,9 @@ func TestWithdrawPostHandler_ServeHTTP(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			userService := services.NewUserService(mockUserRepo, mockWithdrawalRepo, logger)
+			userService := services.NewUserService(mockTxManager, mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mockAuditRepo, mockOutboxRepo, logger)
 
-			handler := NewWithdrawPostHandler(userService, mockUsernameExtractor, logger)
+			handler := NewWithdrawPostHandler(userService, mockPrincipalExtractor, validation.LuhnValidator{}, logger)
 
 			req := httptest.NewRequest("POST", "/withdraw", bytes.NewBufferString(tc.requestBody))
 
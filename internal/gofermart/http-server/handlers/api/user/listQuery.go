@@ -0,0 +1,112 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxListLimit — максимальный размер страницы списка; больший limit ограничивается этим значением
+const maxListLimit = 1000
+
+// dateLayout — формат даты без времени, допустимый в параметрах from/to
+const dateLayout = "2006-01-02"
+
+var (
+	errInvalidLimit  = errors.New("limit must be a positive integer")
+	errInvalidPeriod = errors.New("from and to must be RFC3339 timestamps or YYYY-MM-DD dates, from before to")
+	errInvalidStatus = errors.New("unknown order status")
+)
+
+// ParseListOptions — разбирает общие параметры списков: limit, cursor, from и to; используется и в разделе поддержки.
+// Дата без времени в to означает весь указанный день включительно.
+func ParseListOptions(query url.Values) (domain.ListOptions, error) {
+	var options domain.ListOptions
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			return options, errInvalidLimit
+		}
+		options.Limit = min(limit, maxListLimit)
+	}
+
+	if value := query.Get("cursor"); value != "" {
+		cursor, err := domain.DecodeCursor(value)
+		if err != nil {
+			return options, err
+		}
+		options.After = cursor
+	}
+
+	var err error
+	if options.From, err = parseListTime(query.Get("from"), false); err != nil {
+		return options, err
+	}
+	if options.To, err = parseListTime(query.Get("to"), true); err != nil {
+		return options, err
+	}
+	if !options.From.IsZero() && !options.To.IsZero() && !options.From.Before(options.To) {
+		return options, errInvalidPeriod
+	}
+
+	return options, nil
+}
+
+// parseOrderFilter — разбирает параметры списка заказов; status можно передать списком через запятую или повторить
+func parseOrderFilter(query url.Values) (domain.OrderFilter, error) {
+	options, err := ParseListOptions(query)
+	if err != nil {
+		return domain.OrderFilter{}, err
+	}
+
+	filter := domain.OrderFilter{ListOptions: options}
+	for _, value := range query["status"] {
+		for _, status := range strings.Split(value, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			switch status {
+			case domain.OrderStatusNew, domain.OrderStatusProcessing, domain.OrderStatusRegistered,
+				domain.OrderStatusInvalid, domain.OrderStatusProcessed:
+				filter.Statuses = append(filter.Statuses, status)
+			default:
+				return domain.OrderFilter{}, errInvalidStatus
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// parseListTime — разбирает границу периода; пустое значение означает отсутствие ограничения
+func parseListTime(value string, endOfDay bool) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return time.Time{}, errInvalidPeriod
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// SetNextLink — сообщает клиенту адрес следующей страницы через заголовок Link (RFC 8288)
+func SetNextLink(w http.ResponseWriter, r *http.Request, next *domain.Cursor) {
+	if next == nil {
+		return
+	}
+	nextURL := *r.URL
+	query := nextURL.Query()
+	query.Set("cursor", next.Encode())
+	nextURL.RawQuery = query.Encode()
+	w.Header().Set("Link", "<"+nextURL.RequestURI()+`>; rel="next"`)
+}
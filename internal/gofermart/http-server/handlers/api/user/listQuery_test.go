@@ -0,0 +1,98 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseOrderFilter(t *testing.T) {
+	cursor := domain.Cursor{Time: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC), Key: "12345678903"}
+
+	testCases := []struct {
+		name           string
+		query          string
+		expectedFilter domain.OrderFilter
+		expectedErr    error
+	}{
+		{
+			name:           "Empty_Query",
+			query:          "",
+			expectedFilter: domain.OrderFilter{},
+		},
+		{
+			name:  "All_Parameters",
+			query: "limit=10&status=new,processed&status=INVALID&from=2024-05-01&to=2024-05-31&cursor=" + cursor.Encode(),
+			expectedFilter: domain.OrderFilter{
+				ListOptions: domain.ListOptions{
+					From:  time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+					To:    time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+					Limit: 10,
+					After: &cursor,
+				},
+				Statuses: []string{domain.OrderStatusNew, domain.OrderStatusProcessed, domain.OrderStatusInvalid},
+			},
+		},
+		{
+			name:  "RFC3339_Period",
+			query: "from=2024-05-01T10:00:00Z&to=2024-05-01T11:00:00Z",
+			expectedFilter: domain.OrderFilter{
+				ListOptions: domain.ListOptions{
+					From: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+					To:   time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			name:           "Limit_Capped",
+			query:          "limit=5000",
+			expectedFilter: domain.OrderFilter{ListOptions: domain.ListOptions{Limit: maxListLimit}},
+		},
+		{
+			name:        "Invalid_Limit",
+			query:       "limit=0",
+			expectedErr: errInvalidLimit,
+		},
+		{
+			name:        "Invalid_Status",
+			query:       "status=DONE",
+			expectedErr: errInvalidStatus,
+		},
+		{
+			name:        "Invalid_Date",
+			query:       "from=yesterday",
+			expectedErr: errInvalidPeriod,
+		},
+		{
+			name:        "Reversed_Period",
+			query:       "from=2024-05-31&to=2024-05-01",
+			expectedErr: errInvalidPeriod,
+		},
+		{
+			name:        "Invalid_Cursor",
+			query:       "cursor=not-a-cursor",
+			expectedErr: gofermartErrors.ErrInvalidCursor,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := url.ParseQuery(tc.query)
+			if err != nil {
+				t.Fatalf("failed to parse query: %v", err)
+			}
+
+			filter, err := parseOrderFilter(query)
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if diff := cmp.Diff(tc.expectedFilter, filter); diff != "" {
+				t.Errorf("unexpected filter (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
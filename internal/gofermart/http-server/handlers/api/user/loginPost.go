@@ -2,8 +2,9 @@ package user
 
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
-	"encoding/json"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
 	"go.uber.org/zap"
 	"net/http"
 )
@@ -26,32 +27,34 @@ func NewLoginPostHandler(authService *services.AuthService, logger *zap.Logger)
 func (h *LoginPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Декодируем тело запроса для получения логина и пароля.
 	var req domain.AuthenticationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		h.logger.Error("Failed to decode request", zap.Error(err))
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		problem.InvalidBody(w, r, err)
 		return
 	}
 
 	// Проверка аутентификационных данных.
-	authenticated, err := h.authService.AuthenticateUser(req.Login, req.Password)
+	user, err := h.authService.AuthenticateUser(r.Context(), req.Login, req.Password)
 	if err != nil {
-		h.logger.Error("Server error during authentication", zap.Error(err))
-		http.Error(w, "Server error", http.StatusInternalServerError)
+		if problem.KindOf(err) == problem.Internal {
+			h.logger.Error("Server error during authentication", zap.Error(err))
+		}
+		problem.Error(w, r, err)
 		return
 	}
 
 	// Если аутентификация не удалась, возвращаем ошибку.
-	if !authenticated {
+	if user == nil {
 		h.logger.Warn("Authentication failed", zap.String("login", req.Login))
-		http.Error(w, "Invalid login/password", http.StatusUnauthorized)
+		problem.Write(w, r, problem.InvalidCredentials, "")
 		return
 	}
 
 	// Генерация JWT токена для аутентифицированного пользователя.
-	token, err := h.authService.GenerateJWT(req.Login)
+	token, err := h.authService.GenerateJWT(r.Context(), *user)
 	if err != nil {
 		h.logger.Error("Failed to generate JWT", zap.Error(err))
-		http.Error(w, "Server error", http.StatusInternalServerError)
+		problem.Write(w, r, problem.Internal, "")
 		return
 	}
 
@@ -20,7 +20,9 @@ func TestLoginPostHandler_ServeHTTP(t *testing.T) {
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	logger := zap.NewNop()
 	jwtSecret := []byte("secret")
-	authService := services.NewAuthService(jwtSecret, mockUserRepo, logger)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authService := services.NewAuthService(jwtSecret, 0, mocks.NewMockTxManager(ctrl), mockUserRepo, mockAuditRepo, logger)
 	handler := NewLoginPostHandler(authService, logger)
 
 	testCases := []struct {
@@ -36,7 +38,21 @@ func TestLoginPostHandler_ServeHTTP(t *testing.T) {
 			requestBody:          `{invalid json}`,
 			setupMocks:           func() {},
 			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: "Invalid request format\n",
+			expectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/login"}` + "\n",
+		},
+		{
+			name:                 "Unknown_Field",
+			requestBody:          `{"login": "user1", "password": "password", "role": "admin"}`,
+			setupMocks:           func() {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/login"}` + "\n",
+		},
+		{
+			name:                 "Trailing_Data",
+			requestBody:          `{"login": "user1", "password": "password"} {}`,
+			setupMocks:           func() {},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/login"}` + "\n",
 		},
 		{
 			name:        "Authentication_Error",
@@ -45,7 +61,7 @@ func TestLoginPostHandler_ServeHTTP(t *testing.T) {
 				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, errors.New("db error"))
 			},
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: "Server error\n",
+			expectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/login"}` + "\n",
 		},
 		{
 			name:        "Invalid_Login_Or_Password",
@@ -54,7 +70,7 @@ func TestLoginPostHandler_ServeHTTP(t *testing.T) {
 				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{Login: "user1", Password: "hashed_password"}, nil)
 			},
 			expectedStatusCode:   http.StatusUnauthorized,
-			expectedResponseBody: "Invalid login/password\n",
+			expectedResponseBody: `{"type":"/problems/invalid-credentials","title":"Invalid login or password","status":401,"instance":"/login"}` + "\n",
 		},
 	}
 
@@ -0,0 +1,121 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+var errEmptyBatch = errors.New("batch contains no order numbers")
+
+type (
+	// OrdersBatchPostHandler - представляет HTTP-обработчик для пакетной загрузки номеров заказов.
+	OrdersBatchPostHandler struct {
+		limit              int
+		logger             *zap.Logger
+		orderService       services.OrderServiceInterface
+		principalExtractor utils.PrincipalExtractor
+	}
+	// OrderUploadResponse — результат загрузки одного номера заказа в формате JSON.
+	OrderUploadResponse struct {
+		Number string `json:"number"`
+		Status string `json:"status"`
+		Reason string `json:"reason,omitempty"`
+	}
+)
+
+// NewOrdersBatchPostHandler - создает новый экземпляр OrdersBatchPostHandler; limit ограничивает количество номеров в запросе.
+func NewOrdersBatchPostHandler(orderService services.OrderServiceInterface, principalExtractor utils.PrincipalExtractor, limit int, logger *zap.Logger) *OrdersBatchPostHandler {
+	return &OrdersBatchPostHandler{
+		limit:              limit,
+		logger:             logger,
+		orderService:       orderService,
+		principalExtractor: principalExtractor,
+	}
+}
+
+// ServeHTTP - обрабатывает HTTP-запросы POST с пакетом номеров заказов: JSON-массивом строк
+// (Content-Type: application/json) либо текстом, где номера разделены переводом строки.
+func (h *OrdersBatchPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	defer func() {
+		if err := r.Body.Close(); err != nil {
+			h.logger.Error("Failed to close request body", zap.Error(err))
+		}
+	}()
+
+	numbers, err := parseOrderNumbers(r)
+	if err != nil {
+		h.logger.Warn("Invalid batch request body", zap.Error(err))
+		problem.InvalidBody(w, r, err)
+		return
+	}
+
+	if h.limit > 0 && len(numbers) > h.limit {
+		h.logger.Warn("Batch limit exceeded", zap.Int("count", len(numbers)), zap.Int("limit", h.limit))
+		problem.Write(w, r, problem.PayloadTooLarge, "Too many order numbers in batch")
+		return
+	}
+
+	results, err := h.orderService.AddOrders(r.Context(), principal.UserID, numbers)
+	if err != nil {
+		h.logger.Error("Failed to add orders batch", zap.Error(err))
+		problem.Error(w, r, err)
+		return
+	}
+
+	response := make([]OrderUploadResponse, 0, len(results))
+	for _, result := range results {
+		response = append(response, OrderUploadResponse{Number: result.Number, Status: result.Status, Reason: result.Reason})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	err = json.NewEncoder(w).Encode(response)
+	if err != nil {
+		h.logger.Error("Failed to encode JSON response", zap.Error(err))
+		problem.Write(w, r, problem.Internal, "")
+		return
+	}
+}
+
+// parseOrderNumbers - извлекает номера заказов из тела запроса в зависимости от Content-Type
+func parseOrderNumbers(r *http.Request) ([]string, error) {
+	var numbers []string
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		if err := utils.DecodeJSON(r.Body, &numbers); err != nil {
+			return nil, err
+		}
+		for i := range numbers {
+			numbers[i] = strings.TrimSpace(numbers[i])
+		}
+	} else {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(body), "\n") {
+			if number := strings.TrimSpace(line); number != "" {
+				numbers = append(numbers, number)
+			}
+		}
+	}
+
+	if len(numbers) == 0 {
+		return nil, errEmptyBatch
+	}
+	return numbers, nil
+}
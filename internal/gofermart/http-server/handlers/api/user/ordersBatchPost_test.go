@@ -0,0 +1,133 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOrdersBatchPostHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockOrderService := mocks.NewMockOrderServiceInterface(ctrl)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+
+	logger := zap.NewNop()
+	handler := NewOrdersBatchPostHandler(mockOrderService, mockPrincipalExtractor, 3, logger)
+
+	results := []domain.OrderUploadResult{
+		{Number: "79927398713", Status: domain.OrderUploadAccepted},
+		{Number: "12345678903", Status: domain.OrderUploadConflict},
+		{Number: "123", Status: domain.OrderUploadInvalid, Reason: "order number checksum is invalid"},
+	}
+	expectedResults := `[{"number":"79927398713","status":"ACCEPTED"},{"number":"12345678903","status":"CONFLICT"},{"number":"123","status":"INVALID","reason":"order number checksum is invalid"}]` + "\n"
+
+	testCases := []struct {
+		name                 string
+		contentType          string
+		requestBody          string
+		setupMocks           func()
+		expectedStatusCode   int
+		expectedResponseBody string
+	}{
+		{
+			name:        "ExtractPrincipal_Error",
+			contentType: "text/plain",
+			requestBody: "79927398713",
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{}, errors.New("extraction error"))
+			},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/api/user/orders/batch"}` + "\n",
+		},
+		{
+			name:        "JSON_Array",
+			contentType: "application/json; charset=utf-8",
+			requestBody: `["79927398713", "12345678903", "123"]`,
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockOrderService.EXPECT().AddOrders(gomock.Any(), 1, []string{"79927398713", "12345678903", "123"}).Return(results, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: expectedResults,
+		},
+		{
+			name:        "Newline_Separated_Text",
+			contentType: "text/plain",
+			requestBody: "79927398713\r\n\n 12345678903 \n123\n",
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockOrderService.EXPECT().AddOrders(gomock.Any(), 1, []string{"79927398713", "12345678903", "123"}).Return(results, nil)
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedResponseBody: expectedResults,
+		},
+		{
+			name:        "Malformed_JSON",
+			contentType: "application/json",
+			requestBody: `{"number": "79927398713"}`,
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/api/user/orders/batch"}` + "\n",
+		},
+		{
+			name:        "Empty_Batch",
+			contentType: "text/plain",
+			requestBody: "\n\n",
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+			},
+			expectedStatusCode:   http.StatusBadRequest,
+			expectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/api/user/orders/batch"}` + "\n",
+		},
+		{
+			name:        "Batch_Limit_Exceeded",
+			contentType: "application/json",
+			requestBody: `["1", "2", "3", "4"]`,
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+			},
+			expectedStatusCode:   http.StatusRequestEntityTooLarge,
+			expectedResponseBody: `{"type":"/problems/payload-too-large","title":"Payload Too Large","status":413,"detail":"Too many order numbers in batch","instance":"/api/user/orders/batch"}` + "\n",
+		},
+		{
+			name:        "Service_Error",
+			contentType: "text/plain",
+			requestBody: "79927398713",
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockOrderService.EXPECT().AddOrders(gomock.Any(), 1, []string{"79927398713"}).Return(nil, errors.New("database error"))
+			},
+			expectedStatusCode:   http.StatusInternalServerError,
+			expectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/api/user/orders/batch"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/orders/batch", strings.NewReader(tc.requestBody))
+			req.Header.Set("Content-Type", tc.contentType)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatusCode {
+				t.Errorf("Expected status code %d, got %d", tc.expectedStatusCode, rec.Code)
+			}
+
+			if rec.Body.String() != tc.expectedResponseBody {
+				t.Errorf("Expected response body '%s', got '%s'", tc.expectedResponseBody, rec.Body.String())
+			}
+		})
+	}
+}
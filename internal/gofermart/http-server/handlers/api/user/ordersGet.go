@@ -2,6 +2,7 @@ package user
 
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
 	"encoding/json"
@@ -13,9 +14,9 @@ import (
 type (
 	// OrdersGetHandler - обрабатывает запросы на получение списка загруженных пользователем номеров заказов.
 	OrdersGetHandler struct {
-		logger            *zap.Logger
-		orderService      services.OrderServiceInterface
-		usernameExtractor utils.UsernameExtractor
+		logger             *zap.Logger
+		orderService       services.OrderServiceInterface
+		principalExtractor utils.PrincipalExtractor
 	}
 	// OrderResponse — структура для представления заказа в формате JSON.
 	OrderResponse struct {
@@ -27,27 +28,34 @@ type (
 )
 
 // NewOrdersGetHandler - создает новый обработчик для получения заказов.
-func NewOrdersGetHandler(orderService services.OrderServiceInterface, usernameExtractor utils.UsernameExtractor, logger *zap.Logger) *OrdersGetHandler {
+func NewOrdersGetHandler(orderService services.OrderServiceInterface, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *OrdersGetHandler {
 	return &OrdersGetHandler{
-		logger:            logger,
-		orderService:      orderService,
-		usernameExtractor: usernameExtractor,
+		logger:             logger,
+		orderService:       orderService,
+		principalExtractor: principalExtractor,
 	}
 }
 
 // ServeHTTP обрабатывает HTTP-запросы для получения списка загруженных пользователем номеров заказов.
 func (h *OrdersGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	login, err := h.usernameExtractor.ExtractUsernameFromContext(r, h.logger)
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
+		return
+	}
+
+	// Без параметров возвращается весь список, как и раньше
+	filter, err := parseOrderFilter(r.URL.Query())
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest, err.Error())
 		return
 	}
 
 	// Получаем список заказов пользователя
-	orders, err := h.orderService.GetOrders(login)
+	orders, next, err := h.orderService.GetOrders(r.Context(), principal.UserID, filter)
 	if err != nil {
 		h.logger.Error("Failed to get orders", zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -70,11 +78,12 @@ func (h *OrdersGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		response = append(response, item)
 	}
 
+	SetNextLink(w, r, next)
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		h.logger.Error("Failed to encode JSON response", zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Write(w, r, problem.Internal, "")
 		return
 	}
 }
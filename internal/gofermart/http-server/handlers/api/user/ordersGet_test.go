@@ -3,6 +3,7 @@ package user
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"beliaev-aa/yp-gofermart/tests/mocks"
 	"encoding/json"
 	"errors"
@@ -22,7 +23,7 @@ func TestOrdersGetHandler_ServeHTTP(t *testing.T) {
 
 	logger := zap.NewNop()
 	accrualMock := mocks.NewMockAccrualService(ctrl)
-	mockUsernameExtractor := mocks.NewMockUsernameExtractor(ctrl)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 
@@ -35,16 +36,15 @@ func TestOrdersGetHandler_ServeHTTP(t *testing.T) {
 		{
 			name: "Unauthorized_Access",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("", http.ErrNoCookie)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{}, http.ErrNoCookie)
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 		},
 		{
 			name: "Successful_Order_Response",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).Return(&domain.User{UserID: 1}, nil)
-				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any()).Return([]domain.Order{
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any(), domain.OrderFilter{}).Return([]domain.Order{
 					{
 						OrderNumber: "123",
 						OrderStatus: domain.OrderStatusProcessed,
@@ -66,18 +66,16 @@ func TestOrdersGetHandler_ServeHTTP(t *testing.T) {
 		{
 			name: "No_Orders",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).Return(&domain.User{UserID: 1}, nil)
-				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any()).Return([]domain.Order{}, nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any(), domain.OrderFilter{}).Return([]domain.Order{}, nil)
 			},
 			expectedStatusCode: http.StatusNoContent,
 		},
 		{
 			name: "Internal_Server_Error",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("test_user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).Return(&domain.User{UserID: 1}, nil)
-				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), gomock.Any(), domain.OrderFilter{}).Return(nil, errors.New("database error"))
 			},
 			expectedStatusCode: http.StatusInternalServerError,
 		},
@@ -86,9 +84,9 @@ func TestOrdersGetHandler_ServeHTTP(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
-			mockOrderService := services.NewOrderService(accrualMock, mockOrderRepo, mockUserRepo, logger)
+			mockOrderService := services.NewOrderService(accrualMock, mocks.NewMockTxManager(ctrl), mockOrderRepo, mockUserRepo, mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), mocks.NewMockWebhookRepository(ctrl), mocks.NewMockNotificationRepository(ctrl), validation.LuhnValidator{}, logger)
 
-			handler := NewOrdersGetHandler(mockOrderService, mockUsernameExtractor, logger)
+			handler := NewOrdersGetHandler(mockOrderService, mockPrincipalExtractor, logger)
 
 			req := httptest.NewRequest("GET", "/orders", nil)
 			rr := httptest.NewRecorder()
@@ -119,3 +117,81 @@ func TestOrdersGetHandler_ServeHTTP(t *testing.T) {
 		})
 	}
 }
+
+func TestOrdersGetHandler_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	orderService := services.NewOrderService(mocks.NewMockAccrualService(ctrl), mocks.NewMockTxManager(ctrl), mockOrderRepo, mockUserRepo, mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), mocks.NewMockWebhookRepository(ctrl), mocks.NewMockNotificationRepository(ctrl), validation.LuhnValidator{}, logger)
+	handler := NewOrdersGetHandler(orderService, mockPrincipalExtractor, logger)
+
+	uploadedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	next := domain.Cursor{Time: uploadedAt.Add(-time.Hour), Key: "9278923470"}
+
+	testCases := []struct {
+		name               string
+		target             string
+		setupMocks         func()
+		expectedStatusCode int
+		expectedLink       string
+	}{
+		{
+			name:   "Next_Page_Link",
+			target: "/api/user/orders?limit=2&status=NEW",
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1, domain.OrderFilter{
+					ListOptions: domain.ListOptions{Limit: 3},
+					Statuses:    []string{domain.OrderStatusNew},
+				}).Return([]domain.Order{
+					{OrderNumber: "12345678903", OrderStatus: domain.OrderStatusNew, UploadedAt: uploadedAt},
+					{OrderNumber: "9278923470", OrderStatus: domain.OrderStatusNew, UploadedAt: uploadedAt.Add(-time.Hour)},
+					{OrderNumber: "346436439", OrderStatus: domain.OrderStatusNew, UploadedAt: uploadedAt.Add(-2 * time.Hour)},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedLink:       "</api/user/orders?cursor=" + next.Encode() + `&limit=2&status=NEW>; rel="next"`,
+		},
+		{
+			name:   "Last_Page_Without_Link",
+			target: "/api/user/orders?limit=2",
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1, domain.OrderFilter{ListOptions: domain.ListOptions{Limit: 3}}).Return([]domain.Order{
+					{OrderNumber: "12345678903", OrderStatus: domain.OrderStatusNew, UploadedAt: uploadedAt},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+		},
+		{
+			name:   "Invalid_Parameters",
+			target: "/api/user/orders?status=UNKNOWN",
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+			},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("expected status %v, got %v", tc.expectedStatusCode, rr.Code)
+			}
+			if link := rr.Header().Get("Link"); link != tc.expectedLink {
+				t.Errorf("expected Link %q, got %q", tc.expectedLink, link)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package user
 
 import (
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"errors"
 	"go.uber.org/zap"
 	"io"
@@ -13,32 +15,39 @@ import (
 
 // OrdersPostHandler - представляет HTTP-обработчик для загрузки номера заказа пользователем.
 type OrdersPostHandler struct {
-	logger            *zap.Logger
-	orderService      services.OrderServiceInterface
-	usernameExtractor utils.UsernameExtractor
+	logger             *zap.Logger
+	orderService       services.OrderServiceInterface
+	principalExtractor utils.PrincipalExtractor
+	validator          validation.OrderNumberValidator
 }
 
 // NewOrdersPostHandler - создает новый экземпляр OrdersPostHandler с указанными зависимостями.
-func NewOrdersPostHandler(orderService services.OrderServiceInterface, usernameExtractor utils.UsernameExtractor, logger *zap.Logger) *OrdersPostHandler {
+func NewOrdersPostHandler(orderService services.OrderServiceInterface, principalExtractor utils.PrincipalExtractor, validator validation.OrderNumberValidator, logger *zap.Logger) *OrdersPostHandler {
 	return &OrdersPostHandler{
-		logger:            logger,
-		orderService:      orderService,
-		usernameExtractor: usernameExtractor,
+		logger:             logger,
+		orderService:       orderService,
+		principalExtractor: principalExtractor,
+		validator:          validator,
 	}
 }
 
 // ServeHTTP - обрабатывает HTTP-запросы POST для загрузки номера заказа.
 func (h *OrdersPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	login, err := h.usernameExtractor.ExtractUsernameFromContext(r, h.logger)
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
 	body, err := io.ReadAll(r.Body)
-	if err != nil || len(body) == 0 {
+	if err != nil {
 		h.logger.Warn("Invalid request body", zap.Error(err))
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		problem.InvalidBody(w, r, err)
+		return
+	}
+	if len(body) == 0 {
+		h.logger.Warn("Empty request body")
+		problem.Write(w, r, problem.BadRequest, "Invalid request format")
 		return
 	}
 	defer func() {
@@ -49,26 +58,23 @@ func (h *OrdersPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	orderNumber := strings.TrimSpace(string(body))
 
-	if !utils.IsValidMoon(orderNumber) {
-		h.logger.Warn("Invalid order number format", zap.String("orderNumber", orderNumber))
-		http.Error(w, "Invalid order number format", http.StatusUnprocessableEntity)
+	if err := h.validator.Validate(principal.Login, orderNumber); err != nil {
+		h.logger.Warn("Invalid order number format", zap.String("orderNumber", orderNumber), zap.Error(err))
+		problem.Error(w, r, err)
 		return
 	}
 
-	err = h.orderService.AddOrder(login, orderNumber)
+	err = h.orderService.AddOrder(r.Context(), principal.UserID, orderNumber)
 	if err != nil {
-		switch {
-		case errors.Is(err, gofermartErrors.ErrOrderAlreadyUploaded):
+		if errors.Is(err, gofermartErrors.ErrOrderAlreadyUploaded) {
 			w.WriteHeader(http.StatusOK)
 			return
-		case errors.Is(err, gofermartErrors.ErrOrderUploadedByAnother):
-			http.Error(w, "Order number already uploaded by another user", http.StatusConflict)
-			return
-		default:
+		}
+		if problem.KindOf(err) == problem.Internal {
 			h.logger.Error("Failed to add order", zap.Error(err))
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
 		}
+		problem.Error(w, r, err)
+		return
 	}
 
 	// Успешно приняли заказ в обработку
@@ -1,7 +1,9 @@
 package user
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"beliaev-aa/yp-gofermart/tests/mocks"
 	"errors"
 	"github.com/golang/mock/gomock"
@@ -17,10 +19,10 @@ func TestOrdersPostHandler_ServeHTTP(t *testing.T) {
 	defer ctrl.Finish()
 	validOrderNumber := "79927398713"
 	mockOrderService := mocks.NewMockOrderServiceInterface(ctrl)
-	mockUsernameExtractor := mocks.NewMockUsernameExtractor(ctrl)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
 
 	logger := zap.NewNop()
-	handler := NewOrdersPostHandler(mockOrderService, mockUsernameExtractor, logger)
+	handler := NewOrdersPostHandler(mockOrderService, mockPrincipalExtractor, validation.LuhnValidator{}, logger)
 
 	testCases := []struct {
 		name                 string
@@ -28,40 +30,51 @@ func TestOrdersPostHandler_ServeHTTP(t *testing.T) {
 		setupMocks           func()
 		expectedStatusCode   int
 		expectedResponseBody string
+		bodyLimit            int64
 	}{
 		{
-			name:        "ExtractUsername_Error",
+			name:        "Body_Over_Limit",
+			requestBody: strings.Repeat("1", 64),
+			bodyLimit:   32,
+			setupMocks: func() {
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+			},
+			expectedStatusCode:   http.StatusRequestEntityTooLarge,
+			expectedResponseBody: `{"type":"/problems/payload-too-large","title":"Payload Too Large","status":413,"detail":"Request body must not exceed 32 bytes","instance":"/orders"}` + "\n",
+		},
+		{
+			name:        "ExtractPrincipal_Error",
 			requestBody: "123456",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("", errors.New("extraction error"))
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{}, errors.New("extraction error"))
 			},
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: "Internal Server Error\n",
+			expectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/orders"}` + "\n",
 		},
 		{
 			name:        "Invalid_Request_Body",
 			requestBody: "",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
 			},
 			expectedStatusCode:   http.StatusBadRequest,
-			expectedResponseBody: "Invalid request format\n",
+			expectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/orders"}` + "\n",
 		},
 		{
 			name:        "Invalid_Order_Number_Format",
 			requestBody: "invalid_order",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
 			},
 			expectedStatusCode:   http.StatusUnprocessableEntity,
-			expectedResponseBody: "Invalid order number format\n",
+			expectedResponseBody: `{"type":"/problems/invalid-order-number","title":"Invalid order number","status":422,"detail":"order number must contain only digits","instance":"/orders"}` + "\n",
 		},
 		{
 			name:        "Order_Already_Uploaded",
 			requestBody: validOrderNumber,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
-				mockOrderService.EXPECT().AddOrder("user", validOrderNumber).Return(gofermartErrors.ErrOrderAlreadyUploaded)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockOrderService.EXPECT().AddOrder(gomock.Any(), 1, validOrderNumber).Return(gofermartErrors.ErrOrderAlreadyUploaded)
 			},
 			expectedStatusCode:   http.StatusOK,
 			expectedResponseBody: "",
@@ -70,28 +83,28 @@ func TestOrdersPostHandler_ServeHTTP(t *testing.T) {
 			name:        "Order_Uploaded_By_Another",
 			requestBody: validOrderNumber,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
-				mockOrderService.EXPECT().AddOrder("user", validOrderNumber).Return(gofermartErrors.ErrOrderUploadedByAnother)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockOrderService.EXPECT().AddOrder(gomock.Any(), 1, validOrderNumber).Return(gofermartErrors.ErrOrderUploadedByAnother)
 			},
 			expectedStatusCode:   http.StatusConflict,
-			expectedResponseBody: "Order number already uploaded by another user\n",
+			expectedResponseBody: `{"type":"/problems/order-uploaded-by-another","title":"Order number already uploaded by another user","status":409,"detail":"order already uploaded by another user","instance":"/orders"}` + "\n",
 		},
 		{
 			name:        "Internal_Service_Error",
 			requestBody: validOrderNumber,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
-				mockOrderService.EXPECT().AddOrder("user", validOrderNumber).Return(errors.New("internal error"))
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockOrderService.EXPECT().AddOrder(gomock.Any(), 1, validOrderNumber).Return(errors.New("internal error"))
 			},
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: "Internal Server Error\n",
+			expectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/orders"}` + "\n",
 		},
 		{
 			name:        "Successful_Order_Addition",
 			requestBody: validOrderNumber,
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
-				mockOrderService.EXPECT().AddOrder("user", validOrderNumber).Return(nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockOrderService.EXPECT().AddOrder(gomock.Any(), 1, validOrderNumber).Return(nil)
 			},
 			expectedStatusCode:   http.StatusAccepted,
 			expectedResponseBody: "",
@@ -104,6 +117,9 @@ func TestOrdersPostHandler_ServeHTTP(t *testing.T) {
 
 			req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(tc.requestBody))
 			rec := httptest.NewRecorder()
+			if tc.bodyLimit > 0 {
+				req.Body = http.MaxBytesReader(rec, req.Body, tc.bodyLimit)
+			}
 
 			handler.ServeHTTP(rec, req)
 
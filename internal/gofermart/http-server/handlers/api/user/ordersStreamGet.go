@@ -0,0 +1,128 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// orderUpdateEvent - имя события Server-Sent Events с изменением заказа
+const orderUpdateEvent = "order"
+
+type (
+	// OrdersStreamGetHandler - отдаёт поток Server-Sent Events с изменениями статусов и начислений по заказам пользователя.
+	OrdersStreamGetHandler struct {
+		heartbeat          time.Duration
+		logger             *zap.Logger
+		principalExtractor utils.PrincipalExtractor
+		subscriber         services.OrderUpdateSubscriber
+	}
+	// OrderUpdateResponse — структура для представления изменения заказа в потоке событий.
+	OrderUpdateResponse struct {
+		Number  string  `json:"number"`
+		Status  string  `json:"status"`
+		Accrual float64 `json:"accrual,omitempty"`
+	}
+)
+
+// NewOrdersStreamGetHandler - создает новый обработчик потока изменений заказов; heartbeat задаёт период
+// комментариев, которые не дают прокси закрыть простаивающее соединение (0 отключает их).
+func NewOrdersStreamGetHandler(subscriber services.OrderUpdateSubscriber, principalExtractor utils.PrincipalExtractor, heartbeat time.Duration, logger *zap.Logger) *OrdersStreamGetHandler {
+	return &OrdersStreamGetHandler{
+		heartbeat:          heartbeat,
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		subscriber:         subscriber,
+	}
+}
+
+// ServeHTTP обрабатывает HTTP-запросы на подписку на изменения заказов пользователя.
+// Поток завершается при отключении клиента или если клиент не успевает читать события;
+// в обоих случаях клиент переподключается и перечитывает список заказов.
+func (h *OrdersStreamGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		h.logger.Error("Response writer does not support flushing")
+		problem.Write(w, r, problem.Internal, "")
+		return
+	}
+
+	subscription, err := h.subscriber.Subscribe(principal.UserID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+	defer subscription.Close()
+
+	// Поток открыт дольше WriteTimeout сервера, поэтому срок записи ответа для него снимается;
+	// зависшее соединение обнаруживается по ошибке записи heartbeat
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Отключает буферизацию ответа в nginx
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	// Первый комментарий сразу отправляет клиенту заголовки ответа
+	if _, err := fmt.Fprint(w, ": connected\n\n"); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	var heartbeat <-chan time.Time
+	if h.heartbeat > 0 {
+		ticker := time.NewTicker(h.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case update, ok := <-subscription.Updates():
+			if !ok {
+				h.logger.Info("Order stream closed by broker", zap.Int("userID", principal.UserID))
+				return
+			}
+			if err := writeOrderUpdate(w, update); err != nil {
+				return
+			}
+		case <-heartbeat:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// writeOrderUpdate - записывает изменение заказа как событие Server-Sent Events
+func writeOrderUpdate(w http.ResponseWriter, update domain.OrderUpdate) error {
+	response := OrderUpdateResponse{
+		Number: update.Order,
+		Status: update.Status,
+	}
+	response.Accrual, _ = update.Accrual.Float64()
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", orderUpdateEvent, data)
+	return err
+}
@@ -0,0 +1,135 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"bufio"
+	"context"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOrdersStreamGetHandler_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		name               string
+		heartbeat          time.Duration
+		writeTimeout       time.Duration
+		openStreams        int
+		principalError     error
+		expectedStatusCode int
+		// run — действия после подключения; expectedLines — строки потока после комментария о подключении
+		run           func(broker *services.OrderUpdateBroker)
+		expectedLines []string
+	}{
+		{
+			name:               "Order_Update",
+			expectedStatusCode: http.StatusOK,
+			run: func(broker *services.OrderUpdateBroker) {
+				broker.Publish(domain.OrderUpdate{Order: "2377225624", UserID: 2, Status: domain.OrderStatusInvalid})
+				broker.Publish(domain.OrderUpdate{Order: "12345678903", UserID: 1, Status: domain.OrderStatusProcessed, Accrual: decimal.NewFromFloat(500.5)})
+			},
+			expectedLines: []string{
+				"event: order",
+				`data: {"number":"12345678903","status":"PROCESSED","accrual":500.5}`,
+				"",
+			},
+		},
+		{
+			name:               "Heartbeat",
+			heartbeat:          10 * time.Millisecond,
+			expectedStatusCode: http.StatusOK,
+			run:                func(broker *services.OrderUpdateBroker) {},
+			expectedLines:      []string{": heartbeat", ""},
+		},
+		{
+			name:               "Outlives_Server_Write_Timeout",
+			heartbeat:          10 * time.Millisecond,
+			writeTimeout:       30 * time.Millisecond,
+			expectedStatusCode: http.StatusOK,
+			run:                func(broker *services.OrderUpdateBroker) {},
+			// Десять heartbeat-комментариев приходят заведомо позже WriteTimeout сервера
+			expectedLines: strings.Split(strings.Repeat(": heartbeat\n\n", 10), "\n")[:20],
+		},
+		{
+			name:               "Too_Many_Streams",
+			openStreams:        2,
+			expectedStatusCode: http.StatusTooManyRequests,
+		},
+		{
+			name:               "Unauthorized",
+			principalError:     utils.ErrUnauthorized,
+			expectedStatusCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			logger := zap.NewNop()
+			broker := services.NewOrderUpdateBroker(services.OrderUpdateBrokerOptions{MaxStreamsPerUser: 2}, logger)
+			for i := 0; i < tc.openStreams; i++ {
+				if _, err := broker.Subscribe(1); err != nil {
+					t.Fatalf("failed to open stream: %v", err)
+				}
+			}
+
+			mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, tc.principalError)
+
+			server := httptest.NewUnstartedServer(NewOrdersStreamGetHandler(broker, mockPrincipalExtractor, tc.heartbeat, logger))
+			server.Config.WriteTimeout = tc.writeTimeout
+			server.Start()
+			defer server.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.expectedStatusCode {
+				t.Fatalf("expected status %d, got %d", tc.expectedStatusCode, resp.StatusCode)
+			}
+			if tc.expectedStatusCode != http.StatusOK {
+				return
+			}
+			if contentType := resp.Header.Get("Content-Type"); contentType != "text/event-stream" {
+				t.Errorf("expected Content-Type text/event-stream, got %q", contentType)
+			}
+
+			reader := bufio.NewReader(resp.Body)
+			readLine := func() string {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					t.Fatalf("failed to read stream: %v", err)
+				}
+				return strings.TrimSuffix(line, "\n")
+			}
+			// Подписка оформлена до отправки первого комментария, поэтому после него события не теряются
+			if line := readLine(); line != ": connected" {
+				t.Fatalf("expected connection comment, got %q", line)
+			}
+			readLine()
+
+			tc.run(broker)
+			for _, expected := range tc.expectedLines {
+				if line := readLine(); line != expected {
+					t.Errorf("expected line %q, got %q", expected, line)
+				}
+			}
+		})
+	}
+}
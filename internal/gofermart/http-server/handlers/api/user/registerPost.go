@@ -3,8 +3,9 @@ package user
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
-	"encoding/json"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
 	"errors"
 	"go.uber.org/zap"
 	"net/http"
@@ -28,30 +29,30 @@ func NewRegisterPostHandler(authService *services.AuthService, logger *zap.Logge
 func (h *RegisterPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// Декодируем запрос для получения данных пользователя
 	var req domain.AuthenticationRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
 		h.logger.Error("Failed to decode request", zap.Error(err))
-		http.Error(w, "Invalid request format", http.StatusBadRequest)
+		problem.InvalidBody(w, r, err)
 		return
 	}
 
 	// Попытка зарегистрировать пользователя
-	if err := h.authService.RegisterUser(req.Login, req.Password); err != nil {
+	user, err := h.authService.RegisterUser(r.Context(), req.Login, req.Password)
+	if err != nil {
 		// Проверка на конфликт (если логин уже существует)
 		if errors.Is(err, gofermartErrors.ErrLoginAlreadyExists) {
 			h.logger.Warn("Registration failed", zap.String("login", req.Login))
-			http.Error(w, "login already exist", http.StatusConflict)
 		} else {
 			h.logger.Error("Server error during registration", zap.Error(err))
-			http.Error(w, "Server error", http.StatusInternalServerError)
 		}
+		problem.Error(w, r, err)
 		return
 	}
 
 	// Генерация JWT токена для зарегистрированного пользователя
-	token, err := h.authService.GenerateJWT(req.Login)
+	token, err := h.authService.GenerateJWT(r.Context(), *user)
 	if err != nil {
 		h.logger.Error("Failed to generate JWT", zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Write(w, r, problem.Internal, "")
 		return
 	}
 
@@ -6,6 +6,7 @@ import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/tests/mocks"
 	"bytes"
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"go.uber.org/zap"
@@ -21,7 +22,13 @@ func TestRegisterPostHandler_ServeHTTP(t *testing.T) {
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	logger := zap.NewNop()
 	jwtSecret := []byte("secret")
-	authService := services.NewAuthService(jwtSecret, mockUserRepo, logger)
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+		return fn(ctx)
+	}).AnyTimes()
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authService := services.NewAuthService(jwtSecret, 0, mockTxManager, mockUserRepo, mockAuditRepo, logger)
 	handler := NewRegisterPostHandler(authService, logger)
 
 	testCases := []struct {
@@ -37,7 +44,14 @@ func TestRegisterPostHandler_ServeHTTP(t *testing.T) {
 			RequestBody:          `{invalid json}`,
 			SetupMocks:           func() {},
 			ExpectedStatusCode:   http.StatusBadRequest,
-			ExpectedResponseBody: "Invalid request format\n",
+			ExpectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/register"}` + "\n",
+		},
+		{
+			Name:                 "Unknown_Field",
+			RequestBody:          `{"login": "user1", "password": "password", "roles": ["admin"]}`,
+			SetupMocks:           func() {},
+			ExpectedStatusCode:   http.StatusBadRequest,
+			ExpectedResponseBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/register"}` + "\n",
 		},
 		{
 			Name:        "Registration_Conflict",
@@ -46,7 +60,7 @@ func TestRegisterPostHandler_ServeHTTP(t *testing.T) {
 				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{Login: "user1"}, nil)
 			},
 			ExpectedStatusCode:   http.StatusConflict,
-			ExpectedResponseBody: "login already exist\n",
+			ExpectedResponseBody: `{"type":"/problems/login-already-exists","title":"Login already exists","status":409,"detail":"login already exists","instance":"/register"}` + "\n",
 		},
 		{
 			Name:        "Registration_Server_Error",
@@ -56,7 +70,7 @@ func TestRegisterPostHandler_ServeHTTP(t *testing.T) {
 				mockUserRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(errors.New("db error")) // Добавлено ожидание вызова SaveUser
 			},
 			ExpectedStatusCode:   http.StatusInternalServerError,
-			ExpectedResponseBody: "Server error\n",
+			ExpectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/register"}` + "\n",
 		},
 		{
 			Name:        "Successful_Registration",
@@ -64,6 +78,7 @@ func TestRegisterPostHandler_ServeHTTP(t *testing.T) {
 			SetupMocks: func() {
 				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
 				mockUserRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Login: "user1", Roles: domain.DefaultRoles()}, nil)
 			},
 			ExpectedStatusCode: http.StatusOK,
 			ExpectedAuthHeader: "Bearer ",
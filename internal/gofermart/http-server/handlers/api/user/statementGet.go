@@ -0,0 +1,96 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/statement"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"go.uber.org/zap"
+	"net/http"
+	"time"
+)
+
+// StatementGetHandler — обработчик HTTP-запросов на выгрузку выписки пользователя в CSV, XLSX или JSON
+type StatementGetHandler struct {
+	logger             *zap.Logger
+	principalExtractor utils.PrincipalExtractor
+	statementService   services.StatementExporter
+}
+
+// NewStatementGetHandler — конструктор для создания обработчика StatementGetHandler
+func NewStatementGetHandler(statementService services.StatementExporter, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *StatementGetHandler {
+	return &StatementGetHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		statementService:   statementService,
+	}
+}
+
+// ServeHTTP — выгружает выписку потоком: строки пишутся в ответ по мере чтения из хранилища
+func (h *StatementGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		h.logger.Error("Failed to open statement", zap.Error(err))
+		problem.Error(w, r, err)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = statement.FormatJSON
+	}
+	contentType, err := statement.ContentType(format)
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest, "Unsupported format, expected csv, xlsx or json")
+		return
+	}
+
+	from, err := parseListTime(query.Get("from"), false)
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest, err.Error())
+		return
+	}
+	to, err := parseListTime(query.Get("to"), true)
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest, err.Error())
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		problem.Write(w, r, problem.BadRequest, errInvalidPeriod.Error())
+		return
+	}
+
+	entries := h.statementService.OpenStatement(principal.UserID, from, to)
+
+	// Выгрузка за большой период может длиться дольше WriteTimeout сервера, поэтому срок записи ответа для неё снимается;
+	// оборванное клиентом соединение обнаруживается по ошибке записи или отмене контекста запроса
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", `attachment; filename="statement.`+format+`"`)
+
+	// После начала записи статус ответа уже отправлен, поэтому ошибки только логируются, а документ обрывается
+	writer, err := statement.NewWriter(format, w)
+	if err != nil {
+		h.logger.Error("Failed to start statement", zap.Error(err))
+		return
+	}
+	for {
+		entry, ok, err := entries.Next(r.Context())
+		if err != nil {
+			h.logger.Error("Failed to read statement entries", zap.String("login", principal.Login), zap.Error(err))
+			return
+		}
+		if !ok {
+			break
+		}
+		if err := writer.WriteEntry(entry); err != nil {
+			h.logger.Error("Failed to write statement entry", zap.Error(err))
+			return
+		}
+	}
+	if err := writer.Close(); err != nil {
+		h.logger.Error("Failed to finish statement", zap.Error(err))
+	}
+}
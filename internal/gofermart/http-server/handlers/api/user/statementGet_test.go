@@ -0,0 +1,296 @@
+package user
+
+import (
+	"archive/zip"
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestStatementGetHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	statementService := newStatementFixture(t, logger, 0)
+	handler := NewStatementGetHandler(statementService, mockPrincipalExtractor, logger)
+
+	testCases := []struct {
+		name                string
+		target              string
+		expectedStatusCode  int
+		expectedContentType string
+		golden              string
+	}{
+		{
+			name:                "CSV_Full_History",
+			target:              "/api/user/statement?format=csv",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			golden:              "statement_full.csv",
+		},
+		{
+			name:                "JSON_By_Default",
+			target:              "/api/user/statement",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/json",
+			golden:              "statement_full.json",
+		},
+		{
+			name:                "XLSX_Full_History",
+			target:              "/api/user/statement?format=xlsx",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+			golden:              "statement_full.xlsx.golden",
+		},
+		{
+			name:                "CSV_Period_With_Opening_Balance",
+			target:              "/api/user/statement?format=csv&from=2024-05-03&to=2024-05-04",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			golden:              "statement_period.csv",
+		},
+		{
+			name:                "JSON_Empty_Period",
+			target:              "/api/user/statement?from=2024-06-01",
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/json",
+			golden:              "statement_empty.json",
+		},
+		{
+			name:               "Unsupported_Format",
+			target:             "/api/user/statement?format=pdf",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			name:               "Invalid_Period",
+			target:             "/api/user/statement?from=2024-05-04&to=2024-05-01",
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+
+			req := httptest.NewRequest(http.MethodGet, tc.target, nil)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Fatalf("expected status %v, got %v: %s", tc.expectedStatusCode, rr.Code, rr.Body.String())
+			}
+			if tc.golden == "" {
+				return
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != tc.expectedContentType {
+				t.Errorf("expected Content-Type %q, got %q", tc.expectedContentType, contentType)
+			}
+
+			body := rr.Body.Bytes()
+			if strings.HasSuffix(tc.golden, ".xlsx.golden") {
+				body = dumpXLSX(t, body)
+			}
+			assertGolden(t, tc.golden, body)
+		})
+	}
+}
+
+func TestStatementGetHandler_ReadErrorTruncatesDocument(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockStatementExporter := mocks.NewMockStatementExporter(ctrl)
+	mockEntries := mocks.NewMockEntries(ctrl)
+	handler := NewStatementGetHandler(mockStatementExporter, mockPrincipalExtractor, logger)
+
+	entry := domain.StatementEntry{
+		Time:        time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		Type:        "accrual",
+		OrderNumber: "12345678903",
+		Status:      string(domain.OrderStatusProcessed),
+		Amount:      decimal.NewFromInt(100),
+		Balance:     decimal.NewFromInt(100),
+	}
+	mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+	mockStatementExporter.EXPECT().OpenStatement(1, time.Time{}, time.Time{}).Return(mockEntries)
+	gomock.InOrder(
+		mockEntries.EXPECT().Next(gomock.Any()).Return(entry, true, nil),
+		mockEntries.EXPECT().Next(gomock.Any()).Return(domain.StatementEntry{}, false, errors.New("db error")),
+	)
+
+	rr := httptest.NewRecorder()
+	handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/user/statement?format=csv", nil))
+
+	// Заголовки уже отправлены, поэтому ошибка чтения не превращается в ответ об ошибке: документ просто обрывается,
+	// а следующие строки не читаются
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %v, got %v", http.StatusOK, rr.Code)
+	}
+	if contentType := rr.Header().Get("Content-Type"); contentType != "text/csv; charset=utf-8" {
+		t.Errorf("unexpected Content-Type %q", contentType)
+	}
+	if strings.Contains(rr.Body.String(), "application/problem+json") {
+		t.Errorf("expected no problem document in the statement body, got %q", rr.Body.String())
+	}
+}
+
+func TestStatementGetHandler_OutlivesServerWriteTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+
+	// Первая строка выписки пишется заведомо позже WriteTimeout сервера
+	server := httptest.NewUnstartedServer(NewStatementGetHandler(newStatementFixture(t, logger, 50*time.Millisecond), mockPrincipalExtractor, logger))
+	server.Config.WriteTimeout = 30 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/user/statement?format=csv")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("failed to read statement: %v", err)
+	}
+	assertGolden(t, "statement_full.csv", body)
+}
+
+// slowOrderRepository — хранилище заказов, отвечающее с задержкой, как при выгрузке большой истории
+type slowOrderRepository struct {
+	repository.OrderRepository
+	delay time.Duration
+}
+
+func (r slowOrderRepository) GetOrdersByUserID(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, error) {
+	time.Sleep(r.delay)
+	return r.OrderRepository.GetOrdersByUserID(ctx, userID, filter)
+}
+
+// newStatementFixture — хранилище в памяти с историей операций, покрывающей все виды строк выписки;
+// ненулевой ordersDelay замедляет каждое чтение страницы заказов
+func newStatementFixture(t *testing.T, logger *zap.Logger, ordersDelay time.Duration) *services.StatementService {
+	t.Helper()
+	ctx := context.Background()
+
+	db := repository.NewMemoryDB(logger)
+	userRepo := repository.NewUserRepositoryMemory(db)
+	orderRepo := repository.NewOrderRepositoryMemory(db)
+	withdrawalRepo := repository.NewWithdrawalRepositoryMemory(db)
+
+	if err := userRepo.SaveUser(ctx, domain.User{Login: "test_user", Password: "hash"}); err != nil {
+		t.Fatalf("failed to save user: %v", err)
+	}
+	user, err := userRepo.GetUserByLogin(ctx, "test_user")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+
+	day := func(d, hour int) time.Time {
+		return time.Date(2024, 5, d, hour, 0, 0, 0, time.UTC)
+	}
+	orders := []domain.Order{
+		{OrderNumber: "12345678903", OrderStatus: domain.OrderStatusProcessed, Accrual: decimal.RequireFromString("500"), UploadedAt: day(1, 10)},
+		{OrderNumber: "9278923470", OrderStatus: domain.OrderStatusNew, UploadedAt: day(2, 9)},
+		{OrderNumber: "346436439", OrderStatus: domain.OrderStatusProcessed, Accrual: decimal.RequireFromString("100.25"), UploadedAt: day(3, 12)},
+		{OrderNumber: "2377225624", OrderStatus: domain.OrderStatusInvalid, UploadedAt: day(4, 8)},
+	}
+	for _, order := range orders {
+		order.UserID = user.UserID
+		if err := orderRepo.AddOrder(ctx, order); err != nil {
+			t.Fatalf("failed to add order: %v", err)
+		}
+	}
+
+	withdrawals := []domain.Withdrawal{
+		{OrderNumber: "79927398713", Amount: decimal.RequireFromString("120.5"), ProcessedAt: day(3, 12)},
+		{OrderNumber: "4561261212345467", Amount: decimal.RequireFromString("50"), ProcessedAt: day(5, 18)},
+	}
+	for _, withdrawal := range withdrawals {
+		withdrawal.UserID = user.UserID
+		if err := withdrawalRepo.AddWithdrawal(ctx, withdrawal); err != nil {
+			t.Fatalf("failed to add withdrawal: %v", err)
+		}
+	}
+
+	var statementOrders repository.OrderRepository = orderRepo
+	if ordersDelay > 0 {
+		statementOrders = slowOrderRepository{OrderRepository: orderRepo, delay: ordersDelay}
+	}
+	return services.NewStatementService(userRepo, statementOrders, withdrawalRepo, repository.NewAdjustmentRepositoryMemory(db), repository.NewStatementRepositoryMemory(db), logger)
+}
+
+// dumpXLSX — разворачивает архив XLSX в текст, чтобы сравнение не зависело от сжатия
+func dumpXLSX(t *testing.T, body []byte) []byte {
+	t.Helper()
+	archive, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+	if err != nil {
+		t.Fatalf("response is not a valid xlsx archive: %v", err)
+	}
+
+	var dump bytes.Buffer
+	for _, file := range archive.File {
+		rc, err := file.Open()
+		if err != nil {
+			t.Fatalf("failed to open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(rc)
+		_ = rc.Close()
+		if err != nil {
+			t.Fatalf("failed to read %s: %v", file.Name, err)
+		}
+		dump.WriteString("== " + file.Name + " ==\n")
+		dump.Write(content)
+		dump.WriteString("\n")
+	}
+	return dump.Bytes()
+}
+
+// assertGolden — сравнивает вывод с эталоном из testdata; с флагом -update перезаписывает эталон
+func assertGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(want, got) {
+		t.Errorf("output differs from %s:\n--- want\n%s\n--- got\n%s", path, want, got)
+	}
+}
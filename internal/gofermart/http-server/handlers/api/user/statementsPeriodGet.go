@@ -0,0 +1,61 @@
+package user
+
+import (
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/statement"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+// StatementsPeriodGetHandler — обработчик HTTP-запросов на скачивание ежемесячной PDF-выписки пользователя
+type StatementsPeriodGetHandler struct {
+	logger             *zap.Logger
+	principalExtractor utils.PrincipalExtractor
+	statementService   services.MonthlyStatementProvider
+}
+
+// NewStatementsPeriodGetHandler — конструктор для создания обработчика StatementsPeriodGetHandler
+func NewStatementsPeriodGetHandler(statementService services.MonthlyStatementProvider, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *StatementsPeriodGetHandler {
+	return &StatementsPeriodGetHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		statementService:   statementService,
+	}
+}
+
+// ServeHTTP — отдает PDF-выписку за месяц, указанный в пути в формате YYYY-MM
+func (h *StatementsPeriodGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	period, err := statement.ParsePeriod(chi.URLParam(r, "period"))
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest, "Invalid period, expected YYYY-MM")
+		return
+	}
+
+	monthly, err := h.statementService.GetMonthlyStatement(r.Context(), principal.UserID, period)
+	if err != nil {
+		if !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+			h.logger.Error("Failed to get monthly statement", zap.Error(err))
+		}
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", `attachment; filename="statement-`+monthly.Period+`.pdf"`)
+	w.Header().Set("Content-Length", strconv.Itoa(len(monthly.Content)))
+	if _, err := w.Write(monthly.Content); err != nil {
+		h.logger.Error("Failed to write monthly statement", zap.Error(err))
+	}
+}
@@ -0,0 +1,99 @@
+package user
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestStatementsPeriodGetHandler_ServeHTTP(t *testing.T) {
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	content := []byte("%PDF-1.4 statement")
+
+	testCases := []struct {
+		name                string
+		period              string
+		setupMocks          func(mockStatementProvider *mocks.MockMonthlyStatementProvider)
+		expectedStatusCode  int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:   "Closed_Month",
+			period: "2024-05",
+			setupMocks: func(mockStatementProvider *mocks.MockMonthlyStatementProvider) {
+				mockStatementProvider.EXPECT().GetMonthlyStatement(gomock.Any(), 1, may).Return(&domain.Statement{UserID: 1, Period: "2024-05", Content: content}, nil)
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedContentType: "application/pdf",
+			expectedBody:        string(content),
+		},
+		{
+			name:   "Statement_Not_Found",
+			period: "2024-05",
+			setupMocks: func(mockStatementProvider *mocks.MockMonthlyStatementProvider) {
+				mockStatementProvider.EXPECT().GetMonthlyStatement(gomock.Any(), 1, may).Return(nil, gofermartErrors.ErrStatementNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:   "Storage_Error",
+			period: "2024-05",
+			setupMocks: func(mockStatementProvider *mocks.MockMonthlyStatementProvider) {
+				mockStatementProvider.EXPECT().GetMonthlyStatement(gomock.Any(), 1, may).Return(nil, errors.New("db error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+		},
+		{
+			name:               "Invalid_Period",
+			period:             "may-2024",
+			setupMocks:         func(mockStatementProvider *mocks.MockMonthlyStatementProvider) {},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			logger := zap.NewNop()
+			mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+			mockStatementProvider := mocks.NewMockMonthlyStatementProvider(ctrl)
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+			tc.setupMocks(mockStatementProvider)
+
+			r := chi.NewRouter()
+			r.Get("/api/user/statements/{period}", NewStatementsPeriodGetHandler(mockStatementProvider, mockPrincipalExtractor, logger).ServeHTTP)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/statements/"+tc.period, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Fatalf("expected status %v, got %v: %s", tc.expectedStatusCode, rr.Code, rr.Body.String())
+			}
+			if tc.expectedStatusCode != http.StatusOK {
+				return
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != tc.expectedContentType {
+				t.Errorf("expected Content-Type %q, got %q", tc.expectedContentType, contentType)
+			}
+			if disposition := rr.Header().Get("Content-Disposition"); disposition != `attachment; filename="statement-2024-05.pdf"` {
+				t.Errorf("unexpected Content-Disposition %q", disposition)
+			}
+			if rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
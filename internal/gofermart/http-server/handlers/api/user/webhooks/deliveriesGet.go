@@ -0,0 +1,78 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	"strconv"
+)
+
+// Размер журнала доставок в ответе: по умолчанию и наибольший
+const (
+	defaultDeliveriesLimit = 50
+	maxDeliveriesLimit     = 1000
+)
+
+// DeliveriesGetHandler - обработчик HTTP-запросов на получение журнала доставок webhook
+type DeliveriesGetHandler struct {
+	logger             *zap.Logger
+	principalExtractor utils.PrincipalExtractor
+	webhookService     services.WebhookServiceInterface
+}
+
+// NewDeliveriesGetHandler - конструктор для создания обработчика DeliveriesGetHandler
+func NewDeliveriesGetHandler(webhookService services.WebhookServiceInterface, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *DeliveriesGetHandler {
+	return &DeliveriesGetHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		webhookService:     webhookService,
+	}
+}
+
+// ServeHTTP - возвращает последние доставки webhook от новых к старым; их число задаётся параметром limit
+func (h *DeliveriesGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	webhookID, ok := webhookIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	limit := defaultDeliveriesLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit <= 0 {
+			problem.Write(w, r, problem.BadRequest, "limit must be a positive integer")
+			return
+		}
+		limit = min(limit, maxDeliveriesLimit)
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(r.Context(), principal.UserID, webhookID, limit)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if len(deliveries) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]DeliveryResponse, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		response = append(response, newDeliveryResponse(delivery))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
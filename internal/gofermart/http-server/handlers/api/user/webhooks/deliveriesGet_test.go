@@ -0,0 +1,93 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeliveriesGetHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockWebhookService := mocks.NewMockWebhookServiceInterface(ctrl)
+	handler := NewDeliveriesGetHandler(mockWebhookService, mockPrincipalExtractor, logger)
+
+	r := chi.NewRouter()
+	r.Get("/api/user/webhooks/{id}/deliveries", handler.ServeHTTP)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	deliveredAt := createdAt.Add(time.Second)
+
+	testCases := []struct {
+		name               string
+		query              string
+		setupMocks         func()
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:  "Delivery_Log",
+			query: "?limit=2",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().GetDeliveries(gomock.Any(), 1, 3, 2).Return([]domain.WebhookDelivery{
+					{DeliveryID: 8, WebhookID: 3, EventType: domain.OutboxPointsAccrued, Status: domain.WebhookDeliveryPending, Attempts: 1, ResponseStatus: 503, LastError: "webhook responded with status 503", NextAttemptAt: createdAt.Add(time.Minute), CreatedAt: createdAt},
+					{DeliveryID: 7, WebhookID: 3, EventType: domain.OutboxOrderStatusChanged, Status: domain.WebhookDeliveryDelivered, Attempts: 1, ResponseStatus: 200, CreatedAt: createdAt, DeliveredAt: &deliveredAt},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: `[{"id":8,"event":"PointsAccrued","status":"PENDING","attempts":1,"response_status":503,"last_error":"webhook responded with status 503","created_at":"2024-05-01T12:00:00Z","next_attempt_at":"2024-05-01T12:01:00Z"},` +
+				`{"id":7,"event":"OrderStatusChanged","status":"DELIVERED","attempts":1,"response_status":200,"created_at":"2024-05-01T12:00:00Z","delivered_at":"2024-05-01T12:00:01Z"}]` + "\n",
+		},
+		{
+			name: "Default_Limit_And_Empty_Log",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().GetDeliveries(gomock.Any(), 1, 3, defaultDeliveriesLimit).Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:               "Invalid_Limit",
+			query:              "?limit=-1",
+			setupMocks:         func() {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"limit must be a positive integer","instance":"/api/user/webhooks/3/deliveries"}` + "\n",
+		},
+		{
+			name: "Unknown_Webhook",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().GetDeliveries(gomock.Any(), 1, 3, defaultDeliveriesLimit).Return(nil, gofermartErrors.ErrWebhookNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedResponse:   `{"type":"/problems/webhook-not-found","title":"Webhook not found","status":404,"detail":"webhook not found","instance":"/api/user/webhooks/3/deliveries"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/webhooks/3/deliveries"+tc.query, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("expected status %v, got %v", tc.expectedStatusCode, rr.Code)
+			}
+			if rr.Body.String() != tc.expectedResponse {
+				t.Errorf("expected body %q, got %q", tc.expectedResponse, rr.Body.String())
+			}
+		})
+	}
+}
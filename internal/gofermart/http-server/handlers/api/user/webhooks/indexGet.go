@@ -0,0 +1,56 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// IndexGetHandler - обработчик HTTP-запросов на получение списка webhook пользователя
+type IndexGetHandler struct {
+	logger             *zap.Logger
+	principalExtractor utils.PrincipalExtractor
+	webhookService     services.WebhookServiceInterface
+}
+
+// NewIndexGetHandler - конструктор для создания обработчика IndexGetHandler
+func NewIndexGetHandler(webhookService services.WebhookServiceInterface, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *IndexGetHandler {
+	return &IndexGetHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		webhookService:     webhookService,
+	}
+}
+
+// ServeHTTP - возвращает webhook пользователя без секретов; при их отсутствии — 204
+func (h *IndexGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(r.Context(), principal.UserID)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	response := make([]WebhookResponse, 0, len(webhooks))
+	for _, webhook := range webhooks {
+		response = append(response, newWebhookResponse(webhook))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
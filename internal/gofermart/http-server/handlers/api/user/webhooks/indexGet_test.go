@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIndexGetHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockWebhookService := mocks.NewMockWebhookServiceInterface(ctrl)
+	handler := NewIndexGetHandler(mockWebhookService, mockPrincipalExtractor, logger)
+
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name               string
+		setupMocks         func()
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name: "Secrets_Are_Hidden",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().GetWebhooks(gomock.Any(), 1).Return([]domain.Webhook{
+					{WebhookID: 2, UserID: 1, URL: "https://example.com/hooks", Events: domain.WebhookEventTypes, Secret: "secret", CreatedAt: createdAt},
+				}, nil)
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   `[{"id":2,"url":"https://example.com/hooks","events":["OrderStatusChanged","PointsAccrued"],"created_at":"2024-05-01T12:00:00Z"}]` + "\n",
+		},
+		{
+			name: "No_Webhooks",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().GetWebhooks(gomock.Any(), 1).Return(nil, nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name: "Internal_Server_Error",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().GetWebhooks(gomock.Any(), 1).Return(nil, errors.New("db error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/api/user/webhooks"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/webhooks", nil)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("expected status %v, got %v", tc.expectedStatusCode, rr.Code)
+			}
+			if rr.Body.String() != tc.expectedResponse {
+				t.Errorf("expected body %q, got %q", tc.expectedResponse, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+type (
+	// IndexPostHandler - обработчик HTTP-запросов на регистрацию webhook пользователя
+	IndexPostHandler struct {
+		logger             *zap.Logger
+		principalExtractor utils.PrincipalExtractor
+		webhookService     services.WebhookServiceInterface
+	}
+	// IndexPostRequest - адрес webhook и события, о которых нужно уведомлять
+	IndexPostRequest struct {
+		URL    string                   `json:"url"`
+		Events []domain.OutboxEventType `json:"events"`
+	}
+)
+
+// NewIndexPostHandler - конструктор для создания обработчика IndexPostHandler
+func NewIndexPostHandler(webhookService services.WebhookServiceInterface, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *IndexPostHandler {
+	return &IndexPostHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		webhookService:     webhookService,
+	}
+}
+
+// ServeHTTP - регистрирует webhook и возвращает его вместе с секретом подписи, который больше не показывается
+func (h *IndexPostHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	var req IndexPostRequest
+	if err := utils.DecodeJSON(r.Body, &req); err != nil {
+		h.logger.Warn("Invalid request format", zap.Error(err))
+		problem.InvalidBody(w, r, err)
+		return
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(r.Context(), principal.UserID, req.URL, req.Events)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	response := newWebhookResponse(*webhook)
+	response.Secret = webhook.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		h.logger.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
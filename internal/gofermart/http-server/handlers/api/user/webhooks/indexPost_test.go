@@ -0,0 +1,112 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIndexPostHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockWebhookService := mocks.NewMockWebhookServiceInterface(ctrl)
+	handler := NewIndexPostHandler(mockWebhookService, mockPrincipalExtractor, logger)
+
+	testCases := []struct {
+		name               string
+		requestBody        string
+		setupMocks         func()
+		expectedStatusCode int
+		expectedResponse   string
+	}{
+		{
+			name:        "Created",
+			requestBody: `{"url": "https://example.com/hooks", "events": ["OrderStatusChanged"]}`,
+			setupMocks: func() {
+				mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), 1, "https://example.com/hooks", []domain.OutboxEventType{domain.OutboxOrderStatusChanged}).Return(&domain.Webhook{
+					WebhookID: 4, UserID: 1, URL: "https://example.com/hooks", Events: []domain.OutboxEventType{domain.OutboxOrderStatusChanged}, Secret: "secret", CreatedAt: time.Now(),
+				}, nil)
+			},
+			expectedStatusCode: http.StatusCreated,
+		},
+		{
+			name:               "Malformed_JSON_Body",
+			requestBody:        `{"url":`,
+			setupMocks:         func() {},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedResponse:   `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/api/user/webhooks"}` + "\n",
+		},
+		{
+			name:        "Invalid_URL",
+			requestBody: `{"url": "example.com/hooks", "events": ["OrderStatusChanged"]}`,
+			setupMocks: func() {
+				mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), 1, "example.com/hooks", gomock.Any()).Return(nil, gofermartErrors.ErrInvalidWebhookURL)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   `{"type":"/problems/invalid-webhook","title":"Invalid webhook","status":422,"detail":"webhook URL must be an absolute http or https URL","instance":"/api/user/webhooks"}` + "\n",
+		},
+		{
+			name:        "Unknown_Event",
+			requestBody: `{"url": "https://example.com/hooks", "events": ["OrderDeleted"]}`,
+			setupMocks: func() {
+				mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), 1, "https://example.com/hooks", []domain.OutboxEventType{"OrderDeleted"}).Return(nil, gofermartErrors.ErrInvalidWebhookEvents)
+			},
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			expectedResponse:   `{"type":"/problems/invalid-webhook","title":"Invalid webhook","status":422,"detail":"webhook events must be a non-empty list of: OrderStatusChanged, PointsAccrued","instance":"/api/user/webhooks"}` + "\n",
+		},
+		{
+			name:        "Internal_Server_Error",
+			requestBody: `{"url": "https://example.com/hooks", "events": ["PointsAccrued"]}`,
+			setupMocks: func() {
+				mockWebhookService.EXPECT().CreateWebhook(gomock.Any(), 1, "https://example.com/hooks", gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedResponse:   `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/api/user/webhooks"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/webhooks", bytes.NewBufferString(tc.requestBody))
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("expected status %v, got %v", tc.expectedStatusCode, rr.Code)
+			}
+			if tc.expectedResponse != "" && rr.Body.String() != tc.expectedResponse {
+				t.Errorf("expected body %q, got %q", tc.expectedResponse, rr.Body.String())
+			}
+			if rr.Code != http.StatusCreated {
+				return
+			}
+
+			var response WebhookResponse
+			if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.ID != 4 || response.Secret == "" || len(response.Events) != 1 {
+				t.Errorf("unexpected response: %+v", response)
+			}
+			if _, err := time.Parse(time.RFC3339, response.CreatedAt); err != nil {
+				t.Errorf("unexpected created_at: %q", response.CreatedAt)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// WebhookResponse - представление webhook в ответах API; секрет возвращается только при создании
+	WebhookResponse struct {
+		ID        int      `json:"id"`
+		URL       string   `json:"url"`
+		Events    []string `json:"events"`
+		Secret    string   `json:"secret,omitempty"`
+		CreatedAt string   `json:"created_at"`
+	}
+	// DeliveryResponse - представление доставки в журнале webhook
+	DeliveryResponse struct {
+		ID             int    `json:"id"`
+		Event          string `json:"event"`
+		Status         string `json:"status"`
+		Attempts       int    `json:"attempts"`
+		ResponseStatus int    `json:"response_status,omitempty"`
+		LastError      string `json:"last_error,omitempty"`
+		CreatedAt      string `json:"created_at"`
+		NextAttemptAt  string `json:"next_attempt_at,omitempty"`
+		DeliveredAt    string `json:"delivered_at,omitempty"`
+	}
+)
+
+// newWebhookResponse - формирует представление webhook без секрета
+func newWebhookResponse(webhook domain.Webhook) WebhookResponse {
+	events := make([]string, 0, len(webhook.Events))
+	for _, eventType := range webhook.Events {
+		events = append(events, string(eventType))
+	}
+	return WebhookResponse{
+		ID:        webhook.WebhookID,
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedAt: webhook.CreatedAt.Format(time.RFC3339),
+	}
+}
+
+// newDeliveryResponse - формирует представление доставки; время следующей попытки имеет смысл только для ожидающих
+func newDeliveryResponse(delivery domain.WebhookDelivery) DeliveryResponse {
+	response := DeliveryResponse{
+		ID:             delivery.DeliveryID,
+		Event:          string(delivery.EventType),
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      delivery.CreatedAt.Format(time.RFC3339),
+	}
+	if delivery.Status == domain.WebhookDeliveryPending {
+		response.NextAttemptAt = delivery.NextAttemptAt.Format(time.RFC3339)
+	}
+	if delivery.DeliveredAt != nil {
+		response.DeliveredAt = delivery.DeliveredAt.Format(time.RFC3339)
+	}
+	return response
+}
+
+// webhookIDParam - идентификатор webhook из пути; некорректный идентификатор означает несуществующий webhook
+func webhookIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	webhookID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || webhookID <= 0 {
+		problem.Write(w, r, problem.WebhookNotFound, "")
+		return 0, false
+	}
+	return webhookID, true
+}
@@ -0,0 +1,46 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// WebhookDeleteHandler - обработчик HTTP-запросов на удаление webhook пользователя
+type WebhookDeleteHandler struct {
+	logger             *zap.Logger
+	principalExtractor utils.PrincipalExtractor
+	webhookService     services.WebhookServiceInterface
+}
+
+// NewWebhookDeleteHandler - конструктор для создания обработчика WebhookDeleteHandler
+func NewWebhookDeleteHandler(webhookService services.WebhookServiceInterface, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *WebhookDeleteHandler {
+	return &WebhookDeleteHandler{
+		logger:             logger,
+		principalExtractor: principalExtractor,
+		webhookService:     webhookService,
+	}
+}
+
+// ServeHTTP - удаляет webhook вместе с журналом доставок; недоставленные события отбрасываются
+func (h *WebhookDeleteHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
+	if err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	webhookID, ok := webhookIDParam(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.webhookService.DeleteWebhook(r.Context(), principal.UserID, webhookID); err != nil {
+		problem.Error(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,72 @@
+package webhooks
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookDeleteHandler_ServeHTTP(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	logger := zap.NewNop()
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
+	mockWebhookService := mocks.NewMockWebhookServiceInterface(ctrl)
+	handler := NewWebhookDeleteHandler(mockWebhookService, mockPrincipalExtractor, logger)
+
+	r := chi.NewRouter()
+	r.Delete("/api/user/webhooks/{id}", handler.ServeHTTP)
+
+	testCases := []struct {
+		name               string
+		webhookID          string
+		setupMocks         func()
+		expectedStatusCode int
+	}{
+		{
+			name:      "Deleted",
+			webhookID: "3",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().DeleteWebhook(gomock.Any(), 1, 3).Return(nil)
+			},
+			expectedStatusCode: http.StatusNoContent,
+		},
+		{
+			name:      "Unknown_Webhook",
+			webhookID: "9",
+			setupMocks: func() {
+				mockWebhookService.EXPECT().DeleteWebhook(gomock.Any(), 1, 9).Return(gofermartErrors.ErrWebhookNotFound)
+			},
+			expectedStatusCode: http.StatusNotFound,
+		},
+		{
+			name:               "Invalid_ID",
+			webhookID:          "abc",
+			setupMocks:         func() {},
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "test_user"}, nil)
+			tc.setupMocks()
+
+			req := httptest.NewRequest(http.MethodDelete, "/api/user/webhooks/"+tc.webhookID, nil)
+			rr := httptest.NewRecorder()
+
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedStatusCode {
+				t.Errorf("expected status %v, got %v", tc.expectedStatusCode, rr.Code)
+			}
+		})
+	}
+}
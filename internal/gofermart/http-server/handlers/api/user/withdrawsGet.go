@@ -1,6 +1,8 @@
 package user
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
 	"encoding/json"
@@ -12,9 +14,9 @@ import (
 type (
 	// WithdrawalsGetHandler — обработчик HTTP-запросов для получения списка выводов пользователя
 	WithdrawalsGetHandler struct {
-		logger            *zap.Logger
-		userService       *services.UserService
-		usernameExtractor utils.UsernameExtractor
+		logger             *zap.Logger
+		principalExtractor utils.PrincipalExtractor
+		userService        *services.UserService
 	}
 	// WithdrawalResponse — структура для представления ответа о выводе средств
 	WithdrawalResponse struct {
@@ -25,26 +27,33 @@ type (
 )
 
 // NewWithdrawalsGetHandler — конструктор для создания обработчика WithdrawalsGetHandler
-func NewWithdrawalsGetHandler(userService *services.UserService, usernameExtractor utils.UsernameExtractor, logger *zap.Logger) *WithdrawalsGetHandler {
+func NewWithdrawalsGetHandler(userService *services.UserService, principalExtractor utils.PrincipalExtractor, logger *zap.Logger) *WithdrawalsGetHandler {
 	return &WithdrawalsGetHandler{
-		userService:       userService,
-		usernameExtractor: usernameExtractor,
-		logger:            logger,
+		principalExtractor: principalExtractor,
+		userService:        userService,
+		logger:             logger,
 	}
 }
 
 // ServeHTTP — основной метод для обработки входящих HTTP-запросов
 func (h *WithdrawalsGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	login, err := h.usernameExtractor.ExtractUsernameFromContext(r, h.logger)
+	principal, err := h.principalExtractor.ExtractPrincipalFromContext(r, h.logger)
 	if err != nil {
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
-	withdrawals, err := h.userService.GetWithdrawals(login)
+	// Без параметров возвращается весь список, как и раньше
+	options, err := ParseListOptions(r.URL.Query())
+	if err != nil {
+		problem.Write(w, r, problem.BadRequest, err.Error())
+		return
+	}
+
+	withdrawals, next, err := h.userService.GetWithdrawals(r.Context(), principal.UserID, domain.WithdrawalFilter{ListOptions: options})
 	if err != nil {
 		h.logger.Error("Failed to get withdrawals", zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Error(w, r, err)
 		return
 	}
 
@@ -64,11 +73,12 @@ func (h *WithdrawalsGetHandler) ServeHTTP(w http.ResponseWriter, r *http.Request
 		response = append(response, item)
 	}
 
+	SetNextLink(w, r, next)
 	w.Header().Set("Content-Type", "application/json")
 	err = json.NewEncoder(w).Encode(response)
 	if err != nil {
 		h.logger.Error("Failed to encode JSON response", zap.Error(err))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		problem.Write(w, r, problem.Internal, "")
 		return
 	}
 }
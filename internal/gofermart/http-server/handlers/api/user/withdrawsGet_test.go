@@ -20,11 +20,11 @@ func TestWithdrawalsGetHandler_ServeHTTP(t *testing.T) {
 	defer ctrl.Finish()
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockWithdrawalRepo := mocks.NewMockWithdrawalRepository(ctrl)
-	mockUsernameExtractor := mocks.NewMockUsernameExtractor(ctrl)
+	mockPrincipalExtractor := mocks.NewMockPrincipalExtractor(ctrl)
 
 	logger := zap.NewNop()
-	userService := services.NewUserService(mockUserRepo, mockWithdrawalRepo, logger)
-	handler := NewWithdrawalsGetHandler(userService, mockUsernameExtractor, logger)
+	userService := services.NewUserService(mocks.NewMockTxManager(ctrl), mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), logger)
+	handler := NewWithdrawalsGetHandler(userService, mockPrincipalExtractor, logger)
 
 	testCases := []struct {
 		name                 string
@@ -33,29 +33,27 @@ func TestWithdrawalsGetHandler_ServeHTTP(t *testing.T) {
 		expectedResponseBody string
 	}{
 		{
-			name: "ExtractUsername_Error",
+			name: "ExtractPrincipal_Error",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("", errors.New("extraction error"))
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{}, errors.New("extraction error"))
 			},
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: "Internal Server Error\n",
+			expectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/withdrawals"}` + "\n",
 		},
 		{
 			name: "GetWithdrawals_Error",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user").Return(&domain.User{UserID: 1}, nil)
-				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1).Return(nil, errors.New("db error"))
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1, domain.WithdrawalFilter{}).Return(nil, errors.New("db error"))
 			},
 			expectedStatusCode:   http.StatusInternalServerError,
-			expectedResponseBody: "Internal Server Error\n",
+			expectedResponseBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/withdrawals"}` + "\n",
 		},
 		{
 			name: "No_Content",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user").Return(&domain.User{UserID: 1}, nil)
-				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1).Return([]domain.Withdrawal{}, nil)
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1, domain.WithdrawalFilter{}).Return([]domain.Withdrawal{}, nil)
 			},
 			expectedStatusCode:   http.StatusNoContent,
 			expectedResponseBody: "",
@@ -63,9 +61,8 @@ func TestWithdrawalsGetHandler_ServeHTTP(t *testing.T) {
 		{
 			name: "Successful_Response",
 			setupMocks: func() {
-				mockUsernameExtractor.EXPECT().ExtractUsernameFromContext(gomock.Any(), gomock.Any()).Return("user", nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user").Return(&domain.User{UserID: 1}, nil)
-				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1).Return([]domain.Withdrawal{
+				mockPrincipalExtractor.EXPECT().ExtractPrincipalFromContext(gomock.Any(), gomock.Any()).Return(domain.Principal{UserID: 1, Login: "user"}, nil)
+				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1, domain.WithdrawalFilter{}).Return([]domain.Withdrawal{
 					{
 						OrderNumber: "123456789",
 						Amount:      decimal.NewFromFloat(100.50),
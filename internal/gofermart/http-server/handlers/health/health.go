@@ -0,0 +1,94 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	statusOK          = "ok"
+	statusUnavailable = "unavailable"
+
+	// pingTimeout - предельное время проверки хранилища; балансировщик не должен ждать ответа дольше своего таймаута
+	pingTimeout = 2 * time.Second
+)
+
+type (
+	// Pinger - проверяет доступность хранилища
+	Pinger interface {
+		Ping(ctx context.Context) error
+	}
+	// LivenessHandler - обработчик проверки живости: процесс запущен и обслуживает запросы
+	LivenessHandler struct {
+		logger *zap.Logger
+	}
+	// ReadinessHandler - обработчик проверки готовности: хранилище доступно и сервер не останавливается
+	ReadinessHandler struct {
+		logger       *zap.Logger
+		pinger       Pinger
+		shuttingDown atomic.Bool
+	}
+	// Response - результат проверки
+	Response struct {
+		Status string `json:"status"`
+		Reason string `json:"reason,omitempty"`
+	}
+)
+
+// NewLivenessHandler - создает новый экземпляр LivenessHandler
+func NewLivenessHandler(logger *zap.Logger) *LivenessHandler {
+	return &LivenessHandler{
+		logger: logger,
+	}
+}
+
+// ServeHTTP - отвечает, что процесс жив
+func (h *LivenessHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	writeResponse(w, http.StatusOK, Response{Status: statusOK}, h.logger)
+}
+
+// NewReadinessHandler - создает новый экземпляр ReadinessHandler
+func NewReadinessHandler(pinger Pinger, logger *zap.Logger) *ReadinessHandler {
+	return &ReadinessHandler{
+		logger: logger,
+		pinger: pinger,
+	}
+}
+
+// ShutDown - помечает сервер как останавливающийся, чтобы балансировщик перестал направлять на него запросы,
+// пока текущие завершаются; используется как http.Server.RegisterOnShutdown
+func (h *ReadinessHandler) ShutDown() {
+	h.shuttingDown.Store(true)
+}
+
+// ServeHTTP - проверяет готовность сервера принимать запросы
+func (h *ReadinessHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h.shuttingDown.Load() {
+		writeResponse(w, http.StatusServiceUnavailable, Response{Status: statusUnavailable, Reason: "shutting down"}, h.logger)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), pingTimeout)
+	defer cancel()
+	if err := h.pinger.Ping(ctx); err != nil {
+		h.logger.Warn("Readiness check failed", zap.Error(err))
+		writeResponse(w, http.StatusServiceUnavailable, Response{Status: statusUnavailable, Reason: "storage is unavailable"}, h.logger)
+		return
+	}
+
+	writeResponse(w, http.StatusOK, Response{Status: statusOK}, h.logger)
+}
+
+// writeResponse - записывает результат проверки в JSON; ответ не кешируется
+func writeResponse(w http.ResponseWriter, status int, response Response, logger *zap.Logger) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Error("Failed to encode JSON response", zap.Error(err))
+	}
+}
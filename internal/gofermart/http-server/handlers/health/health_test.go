@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pingerFunc - Pinger на основе функции
+type pingerFunc func(ctx context.Context) error
+
+func (f pingerFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+func TestLivenessHandler_ServeHTTP(t *testing.T) {
+	rr := httptest.NewRecorder()
+	NewLivenessHandler(zap.NewNop()).ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Errorf("expected status code %d, got %d", http.StatusOK, rr.Code)
+	}
+	if body := strings.TrimSpace(rr.Body.String()); body != `{"status":"ok"}` {
+		t.Errorf("unexpected body: %s", body)
+	}
+}
+
+func TestReadinessHandler_ServeHTTP(t *testing.T) {
+	testCases := []struct {
+		name         string
+		pingErr      error
+		shuttingDown bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Ready",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ok"}`,
+		},
+		{
+			name:         "Storage_Unavailable",
+			pingErr:      errors.New("connection refused"),
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"unavailable","reason":"storage is unavailable"}`,
+		},
+		{
+			name:         "Shutting_Down",
+			shuttingDown: true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"unavailable","reason":"shutting down"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewReadinessHandler(pingerFunc(func(context.Context) error { return tc.pingErr }), zap.NewNop())
+			if tc.shuttingDown {
+				handler.ShutDown()
+			}
+
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, rr.Code)
+			}
+			if body := strings.TrimSpace(rr.Body.String()); body != tc.expectedBody {
+				t.Errorf("expected body %s, got %s", tc.expectedBody, body)
+			}
+			if cacheControl := rr.Header().Get("Cache-Control"); cacheControl != "no-store" {
+				t.Errorf("unexpected Cache-Control: %s", cacheControl)
+			}
+		})
+	}
+}
@@ -0,0 +1,26 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/health"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+)
+
+// RegisterInternalRoutes регистрирует роуты служебного сервера: метрики и проверки живости и готовности.
+// Служебный сервер слушает отдельный адрес, недоступный снаружи, поэтому авторизация JWT к нему не применяется.
+func RegisterInternalRoutes(r *chi.Mux, metrics http.Handler, readiness *health.ReadinessHandler, logger *zap.Logger) {
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.NotFound, "")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.MethodNotAllowed, "")
+	})
+
+	r.Get("/healthz", health.NewLivenessHandler(logger).ServeHTTP)
+	r.Get("/readyz", readiness.ServeHTTP)
+	if metrics != nil {
+		r.Get("/metrics", metrics.ServeHTTP)
+	}
+}
@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/health"
+	"beliaev-aa/yp-gofermart/internal/gofermart/metrics"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage"
+	"github.com/go-chi/chi/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterInternalRoutes(t *testing.T) {
+	logger := zap.NewNop()
+	store := storage.NewMemoryStorage(logger)
+	registry := metrics.NewRegistry()
+
+	// Запрос к основному серверу учитывается в метриках служебного сервера
+	app := chi.NewRouter()
+	RegisterRoutes(app, &services.AppServices{
+		AuthService: services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+	}, Options{Metrics: registry}, logger)
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/user/orders", nil))
+
+	readiness := health.NewReadinessHandler(store, logger)
+	r := chi.NewRouter()
+	RegisterInternalRoutes(r, registry, readiness, logger)
+
+	testCases := []struct {
+		name         string
+		path         string
+		shutDown     bool
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Liveness",
+			path:         "/healthz",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ok"}`,
+		},
+		{
+			name:         "Readiness",
+			path:         "/readyz",
+			expectedCode: http.StatusOK,
+			expectedBody: `{"status":"ok"}`,
+		},
+		{
+			name:         "Metrics",
+			path:         "/metrics",
+			expectedCode: http.StatusOK,
+			expectedBody: `http_requests_total{method="GET",route="/api/user/orders",status="401"} 1`,
+		},
+		{
+			name:         "Unknown_Path",
+			path:         "/api/user/orders",
+			expectedCode: http.StatusNotFound,
+			expectedBody: `"status":404`,
+		},
+		{
+			name:         "Readiness_During_Shutdown",
+			path:         "/readyz",
+			shutDown:     true,
+			expectedCode: http.StatusServiceUnavailable,
+			expectedBody: `{"status":"unavailable","reason":"shutting down"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.shutDown {
+				readiness.ShutDown()
+			}
+
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status code %d, got %d", tc.expectedCode, rr.Code)
+			}
+			if !strings.Contains(rr.Body.String(), tc.expectedBody) {
+				t.Errorf("expected body to contain %s, got %s", tc.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"context"
+	"errors"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
+	"net"
+	"net/http"
+	"time"
+)
+
+// requestIDHeader - заголовок, в котором клиенту возвращается идентификатор запроса
+const requestIDHeader = "X-Request-Id"
+
+// requestTimeout - ограничивает время обработки запроса: по истечении дедлайна контекст запроса отменяется,
+// и все запросы к хранилищу, выполняемые в его рамках, прерываются
+func requestTimeout(timeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if timeout <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx, cancel := context.WithTimeout(r.Context(), timeout)
+			defer cancel()
+
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// exposeRequestID - возвращает идентификатор запроса, назначенный middleware.RequestID, в заголовке ответа
+func exposeRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requestID := middleware.GetReqID(r.Context()); requestID != "" {
+			w.Header().Set(requestIDHeader, requestID)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// auditRequestInfo - сохраняет в контексте адрес клиента, User-Agent и идентификатор запроса для журнала аудита;
+// должен следовать за middleware.RequestID
+func auditRequestInfo(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		ctx := domain.WithRequestInfo(r.Context(), domain.RequestInfo{
+			IP:        ip,
+			UserAgent: r.UserAgent(),
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// tokenVerifier - проверка токена запроса ключами подписи, которые могут заменяться во время работы
+type tokenVerifier interface {
+	VerifyRequest(r *http.Request) (jwt.Token, error)
+}
+
+// verifier - проверяет токен запроса и сохраняет результат в контексте, как jwtauth.Verifier, но берёт
+// действующие ключи подписи при каждом запросе, поэтому их замена по SIGHUP не требует перезапуска
+func verifier(v tokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, err := v.VerifyRequest(r)
+			next.ServeHTTP(w, r.WithContext(jwtauth.NewContext(r.Context(), token, err)))
+		})
+	}
+}
+
+// authenticator - пропускает только запросы с токеном, проверенным verifier и содержащим данные
+// пользователя; в отличие от jwtauth.Authenticator отвечает в формате application/problem+json
+func authenticator(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, claims, err := jwtauth.FromContext(r.Context())
+		if err != nil || token == nil {
+			problem.Write(w, r, problem.Unauthorized, "")
+			return
+		}
+		if _, ok := utils.PrincipalFromClaims(claims); !ok {
+			problem.Write(w, r, problem.Unauthorized, "")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// userLoader - получение текущих данных пользователя из хранилища
+type userLoader interface {
+	GetUser(ctx context.Context, login string) (*domain.User, error)
+}
+
+// currentPrincipalKey - ключ контекста с пользователем, роли которого прочитаны из хранилища
+type currentPrincipalKey struct{}
+
+// currentPrincipal - читает роли и блокировку пользователя из хранилища при каждом запросе, чтобы снятые роли
+// и блокировка действовали сразу, а не после истечения токена: заблокированному пользователю отвечает 403,
+// а RequireRole проверяет прочитанные роли вместо ролей из токена. Должен следовать за verifier и authenticator.
+func currentPrincipal(users userLoader) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, claims, _ := jwtauth.FromContext(r.Context())
+			principal, ok := utils.PrincipalFromClaims(claims)
+			if !ok {
+				problem.Write(w, r, problem.Unauthorized, "")
+				return
+			}
+			user, err := users.GetUser(r.Context(), principal.Login)
+			if errors.Is(err, gofermartErrors.ErrUserNotFound) || (err == nil && user.UserID != principal.UserID) {
+				problem.Write(w, r, problem.Unauthorized, "")
+				return
+			}
+			if err != nil {
+				problem.Error(w, r, err)
+				return
+			}
+			if user.Blocked {
+				problem.Write(w, r, problem.UserBlocked, "")
+				return
+			}
+			principal.Roles = user.Roles
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), currentPrincipalKey{}, principal)))
+		})
+	}
+}
+
+// RequireRole - пропускает только запросы пользователя, у которого есть хотя бы одна из ролей; роли берутся
+// из хранилища, если перед ним выполнен currentPrincipal, иначе из токена, поэтому middleware должен следовать
+// за verifier и authenticator
+func RequireRole(roles ...domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := r.Context().Value(currentPrincipalKey{}).(domain.Principal)
+			if !ok {
+				_, claims, _ := jwtauth.FromContext(r.Context())
+				if principal, ok = utils.PrincipalFromClaims(claims); !ok {
+					problem.Write(w, r, problem.Unauthorized, "")
+					return
+				}
+			}
+			if !principal.Roles.HasAny(roles...) {
+				problem.Write(w, r, problem.Forbidden, "")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,338 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrOperationNotFound — в документе нет операции для метода и пути запроса
+var ErrOperationNotFound = errors.New("operation is not described in the OpenAPI document")
+
+// httpMethods — методы, которые могут быть описаны в Path Item
+var httpMethods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// Document — разобранный документ OpenAPI для проверки запросов и ответов на соответствие схеме
+type Document struct {
+	root     map[string]any
+	patterns sync.Map // кэш скомпилированных pattern из схем
+}
+
+// Operation — операция документа: метод и шаблон пути
+type Operation struct {
+	Method   string
+	Path     string
+	node     map[string]any
+	pathItem map[string]any
+}
+
+// Load — разбирает встроенный документ OpenAPI
+func Load() (*Document, error) {
+	return Parse(spec)
+}
+
+// Parse — разбирает документ OpenAPI в формате JSON
+func Parse(data []byte) (*Document, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var root map[string]any
+	if err := decoder.Decode(&root); err != nil {
+		return nil, fmt.Errorf("parse OpenAPI document: %w", err)
+	}
+	if _, ok := root["paths"].(map[string]any); !ok {
+		return nil, errors.New("parse OpenAPI document: paths are missing")
+	}
+	return &Document{root: root}, nil
+}
+
+// Operations — возвращает все операции документа, упорядоченные по пути и методу
+func (d *Document) Operations() []Operation {
+	paths := d.root["paths"].(map[string]any)
+
+	var operations []Operation
+	for path, item := range paths {
+		pathItem, _ := item.(map[string]any)
+		for _, method := range httpMethods {
+			if node, ok := pathItem[strings.ToLower(method)].(map[string]any); ok {
+				operations = append(operations, Operation{Method: method, Path: path, node: node, pathItem: pathItem})
+			}
+		}
+	}
+
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Path != operations[j].Path {
+			return operations[i].Path < operations[j].Path
+		}
+		return operations[i].Method < operations[j].Method
+	})
+	return operations
+}
+
+// FindOperation — находит операцию по методу и фактическому пути запроса; возвращает значения параметров пути
+func (d *Document) FindOperation(method, requestPath string) (*Operation, map[string]string, error) {
+	for _, operation := range d.Operations() {
+		if operation.Method != method {
+			continue
+		}
+		if params, ok := matchPath(operation.Path, requestPath); ok {
+			return &operation, params, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: %s %s", ErrOperationNotFound, method, requestPath)
+}
+
+// ValidateRequest — проверяет параметры и тело запроса по описанию операции
+func (d *Document) ValidateRequest(r *http.Request, body []byte) error {
+	operation, pathParams, err := d.FindOperation(r.Method, r.URL.Path)
+	if err != nil {
+		return err
+	}
+	prefix := operation.Method + " " + operation.Path
+
+	parameters, err := d.parameters(operation)
+	if err != nil {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+
+	query := r.URL.Query()
+	for name := range query {
+		if _, ok := parameters["query:"+name]; !ok {
+			return fmt.Errorf("%s: unknown query parameter %q", prefix, name)
+		}
+	}
+	for key, parameter := range parameters {
+		in, name, _ := strings.Cut(key, ":")
+		var values []string
+		switch in {
+		case "query":
+			values = query[name]
+		case "path":
+			values = []string{pathParams[name]}
+		case "header":
+			values = r.Header.Values(name)
+		default:
+			continue
+		}
+		if len(values) == 0 {
+			if required, _ := parameter["required"].(bool); required {
+				return fmt.Errorf("%s: missing required %s parameter %q", prefix, in, name)
+			}
+			continue
+		}
+		for _, value := range values {
+			if err := d.validateParameter(parameter["schema"], value, in+" parameter "+name); err != nil {
+				return fmt.Errorf("%s: %w", prefix, err)
+			}
+		}
+	}
+
+	requestBody, err := d.resolve(operation.node["requestBody"])
+	if err != nil {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+	if requestBody == nil {
+		if len(body) > 0 {
+			return fmt.Errorf("%s: request body is not expected", prefix)
+		}
+		return nil
+	}
+	if len(body) == 0 {
+		if required, _ := requestBody["required"].(bool); required {
+			return fmt.Errorf("%s: request body is required", prefix)
+		}
+		return nil
+	}
+	if err := d.validateContent(requestBody, r.Header.Get("Content-Type"), body, "request body"); err != nil {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+	return nil
+}
+
+// ValidateResponse — проверяет статус, заголовки и тело ответа по описанию операции
+func (d *Document) ValidateResponse(method, requestPath string, status int, header http.Header, body []byte) error {
+	operation, _, err := d.FindOperation(method, requestPath)
+	if err != nil {
+		return err
+	}
+	prefix := operation.Method + " " + operation.Path
+
+	responses, _ := operation.node["responses"].(map[string]any)
+	described, ok := responses[strconv.Itoa(status)]
+	if !ok {
+		if described, ok = responses["default"]; !ok {
+			return fmt.Errorf("%s: response status %d is not described", prefix, status)
+		}
+	}
+	response, err := d.resolve(described)
+	if err != nil {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+
+	headers, _ := response["headers"].(map[string]any)
+	for name, item := range headers {
+		headerSpec, err := d.resolve(item)
+		if err != nil {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+		value := header.Get(name)
+		if value == "" {
+			if required, _ := headerSpec["required"].(bool); required {
+				return fmt.Errorf("%s: response %d: missing required header %q", prefix, status, name)
+			}
+			continue
+		}
+		if err := d.validateParameter(headerSpec["schema"], value, "header "+name); err != nil {
+			return fmt.Errorf("%s: response %d: %w", prefix, status, err)
+		}
+	}
+
+	if _, hasContent := response["content"]; !hasContent {
+		if len(body) > 0 {
+			return fmt.Errorf("%s: response %d: body is not expected", prefix, status)
+		}
+		return nil
+	}
+	if err := d.validateContent(response, header.Get("Content-Type"), body, fmt.Sprintf("response %d body", status)); err != nil {
+		return fmt.Errorf("%s: %w", prefix, err)
+	}
+	return nil
+}
+
+// parameters — собирает параметры Path Item и операции с ключами вида "in:name"
+func (d *Document) parameters(operation *Operation) (map[string]map[string]any, error) {
+	parameters := make(map[string]map[string]any)
+	for _, source := range []map[string]any{operation.pathItem, operation.node} {
+		list, _ := source["parameters"].([]any)
+		for _, item := range list {
+			parameter, err := d.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			in, _ := parameter["in"].(string)
+			name, _ := parameter["name"].(string)
+			parameters[in+":"+name] = parameter
+		}
+	}
+	return parameters, nil
+}
+
+// validateParameter — приводит строковое значение параметра к типу схемы и проверяет его
+func (d *Document) validateParameter(schema any, value, location string) error {
+	resolved, err := d.resolve(schema)
+	if err != nil {
+		return err
+	}
+
+	var typed any = value
+	switch resolved["type"] {
+	case "integer", "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return fmt.Errorf("%s: %q is not a number", location, value)
+		}
+		typed = json.Number(value)
+	case "boolean":
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %q is not a boolean", location, value)
+		}
+		typed = parsed
+	}
+	return d.validateSchema(schema, typed, location)
+}
+
+// validateContent — проверяет тип содержимого и, для JSON, тело по схеме
+func (d *Document) validateContent(node map[string]any, contentType string, body []byte, location string) error {
+	content, _ := node["content"].(map[string]any)
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%s: invalid Content-Type %q", location, contentType)
+	}
+	media, ok := content[mediaType].(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: Content-Type %q is not described", location, mediaType)
+	}
+	if len(body) == 0 {
+		return fmt.Errorf("%s: body is empty", location)
+	}
+
+	if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("%s: invalid JSON: %w", location, err)
+	}
+	return d.validateSchema(media["schema"], value, location)
+}
+
+// resolve — возвращает узел документа, переходя по $ref
+func (d *Document) resolve(node any) (map[string]any, error) {
+	for depth := 0; depth < 32; depth++ {
+		object, _ := node.(map[string]any)
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return object, nil
+		}
+		target, err := d.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		node = target
+	}
+	return nil, errors.New("too many nested $ref")
+}
+
+// lookup — находит узел по локальной ссылке вида #/components/schemas/Name
+func (d *Document) lookup(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	var node any = d.root
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+		if node, ok = object[token]; !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// matchPath — сопоставляет путь запроса с шаблоном пути документа
+func matchPath(template, requestPath string) (map[string]string, bool) {
+	templateParts := strings.Split(strings.Trim(template, "/"), "/")
+	requestParts := strings.Split(strings.Trim(requestPath, "/"), "/")
+	if len(templateParts) != len(requestParts) {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for i, part := range templateParts {
+		if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+			params[part[1:len(part)-1]] = requestParts[i]
+			continue
+		}
+		if part != requestParts[i] {
+			return nil, false
+		}
+	}
+	return params, true
+}
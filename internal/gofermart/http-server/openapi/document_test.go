@@ -0,0 +1,200 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDocument_ValidateResponse(t *testing.T) {
+	document, err := Load()
+	if err != nil {
+		t.Fatalf("failed to load document: %v", err)
+	}
+
+	jsonHeader := http.Header{"Content-Type": []string{"application/json"}}
+	problemHeader := http.Header{"Content-Type": []string{"application/problem+json"}}
+
+	testCases := []struct {
+		name        string
+		method      string
+		path        string
+		status      int
+		header      http.Header
+		body        string
+		expectedErr string
+	}{
+		{
+			name:   "Valid_Orders",
+			method: http.MethodGet,
+			path:   "/api/user/orders",
+			status: http.StatusOK,
+			header: jsonHeader,
+			body:   `[{"number":"9278923470","status":"PROCESSED","accrual":500,"uploaded_at":"2020-12-10T15:15:45+03:00"},{"number":"12345678903","status":"NEW","uploaded_at":"2020-12-10T15:12:01+03:00"}]`,
+		},
+		{
+			name:        "Accrual_For_Unprocessed_Order",
+			method:      http.MethodGet,
+			path:        "/api/user/orders",
+			status:      http.StatusOK,
+			header:      jsonHeader,
+			body:        `[{"number":"12345678903","status":"NEW","accrual":0,"uploaded_at":"2020-12-10T15:12:01+03:00"}]`,
+			expectedErr: "body[0].accrual: 0 must be greater than 0",
+		},
+		{
+			name:        "Unknown_Order_Property",
+			method:      http.MethodGet,
+			path:        "/api/user/orders",
+			status:      http.StatusOK,
+			header:      jsonHeader,
+			body:        `[{"number":"12345678903","status":"NEW","uploaded_at":"2020-12-10T15:12:01+03:00","user_id":1}]`,
+			expectedErr: "body[0].user_id: property is not described",
+		},
+		{
+			name:        "Invalid_Date_Time",
+			method:      http.MethodGet,
+			path:        "/api/user/withdrawals",
+			status:      http.StatusOK,
+			header:      jsonHeader,
+			body:        `[{"order":"2377225624","sum":500,"processed_at":"2020-12-09"}]`,
+			expectedErr: `body[0].processed_at: "2020-12-09" is not a date-time`,
+		},
+		{
+			name:        "Undocumented_Status",
+			method:      http.MethodGet,
+			path:        "/api/user/balance",
+			status:      http.StatusNoContent,
+			expectedErr: "response status 204 is not described",
+		},
+		{
+			name:        "Undocumented_Content_Type",
+			method:      http.MethodGet,
+			path:        "/api/user/balance",
+			status:      http.StatusOK,
+			header:      http.Header{"Content-Type": []string{"text/plain"}},
+			body:        `{"current":1,"withdrawn":0}`,
+			expectedErr: `Content-Type "text/plain" is not described`,
+		},
+		{
+			name:   "Problem_With_Path_Parameter",
+			method: http.MethodGet,
+			path:   "/api/user/statements/2999-01",
+			status: http.StatusNotFound,
+			header: problemHeader,
+			body:   `{"type":"/problems/statement-not-found","title":"Statement not found","status":404,"instance":"/api/user/statements/2999-01"}`,
+		},
+		{
+			name:        "Missing_Required_Header",
+			method:      http.MethodPost,
+			path:        "/api/user/login",
+			status:      http.StatusOK,
+			expectedErr: `missing required header "Authorization"`,
+		},
+		{
+			name:        "Unknown_Operation",
+			method:      http.MethodDelete,
+			path:        "/api/user/orders",
+			status:      http.StatusOK,
+			expectedErr: ErrOperationNotFound.Error(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := document.ValidateResponse(tc.method, tc.path, tc.status, tc.header, []byte(tc.body))
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestDocument_ValidateRequest(t *testing.T) {
+	document, err := Load()
+	if err != nil {
+		t.Fatalf("failed to load document: %v", err)
+	}
+
+	testCases := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		expectedErr string
+	}{
+		{
+			name:        "Valid_Withdraw",
+			method:      http.MethodPost,
+			target:      "/api/user/balance/withdraw",
+			contentType: "application/json",
+			body:        `{"order":"2377225624","sum":751}`,
+		},
+		{
+			name:        "Missing_Required_Property",
+			method:      http.MethodPost,
+			target:      "/api/user/balance/withdraw",
+			contentType: "application/json",
+			body:        `{"order":"2377225624"}`,
+			expectedErr: `missing required property "sum"`,
+		},
+		{
+			name:   "Valid_Query",
+			method: http.MethodGet,
+			target: "/api/user/orders?limit=10&status=NEW,PROCESSED&from=2024-05-01",
+		},
+		{
+			name:        "Unknown_Query_Parameter",
+			method:      http.MethodGet,
+			target:      "/api/user/orders?page=2",
+			expectedErr: `unknown query parameter "page"`,
+		},
+		{
+			name:        "Query_Parameter_Type",
+			method:      http.MethodGet,
+			target:      "/api/user/withdrawals?limit=ten",
+			expectedErr: `"ten" is not a number`,
+		},
+		{
+			name:        "Undocumented_Request_Content_Type",
+			method:      http.MethodPost,
+			target:      "/api/user/orders",
+			contentType: "application/json",
+			body:        `"79927398713"`,
+			expectedErr: `Content-Type "application/json" is not described`,
+		},
+		{
+			name:        "Missing_Required_Body",
+			method:      http.MethodPost,
+			target:      "/api/user/register",
+			expectedErr: "request body is required",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+
+			err := document.ValidateRequest(req, []byte(tc.body))
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expectedErr) {
+				t.Errorf("expected error containing %q, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
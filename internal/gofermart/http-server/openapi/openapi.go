@@ -0,0 +1,52 @@
+package openapi
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"embed"
+	"net/http"
+	"path"
+)
+
+// spec — документ OpenAPI 3, описывающий все маршруты RegisterRoutes
+//
+//go:embed openapi.json
+var spec []byte
+
+// swaggerUI — страница Swagger UI; статика интерфейса и документ загружаются с этого же сервера
+//
+//go:embed swagger.html
+var swaggerUI []byte
+
+// swaggerAssets — статика Swagger UI 5.18.2 из пакета swagger-ui-dist (лицензия Apache 2.0), встроенная в бинарный
+// файл, чтобы страница документации не загружала скрипты со сторонних CDN
+//
+//go:embed swagger-ui/swagger-ui-bundle.js swagger-ui/swagger-ui.css
+var swaggerAssets embed.FS
+
+// Spec — возвращает копию документа OpenAPI
+func Spec() []byte {
+	return append([]byte(nil), spec...)
+}
+
+// Handler — отдает документ OpenAPI
+func Handler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(spec)
+}
+
+// UIHandler — отдает страницу Swagger UI для документа, опубликованного по адресу openapi.json рядом с ней
+func UIHandler(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(swaggerUI)
+}
+
+// AssetHandler — отдает файл статики Swagger UI, имя которого задано последним сегментом пути запроса
+func AssetHandler(w http.ResponseWriter, r *http.Request) {
+	name := path.Join("swagger-ui", path.Base(r.URL.Path))
+	if _, err := swaggerAssets.Open(name); err != nil {
+		problem.Write(w, r, problem.NotFound, "")
+		return
+	}
+	w.Header().Set("Cache-Control", "public, max-age=86400")
+	http.ServeFileFS(w, r, swaggerAssets, name)
+}
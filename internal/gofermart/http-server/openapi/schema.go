@@ -0,0 +1,308 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// validateSchema — проверяет значение по подмножеству JSON Schema, которое используется в документе:
+// $ref, type, enum, const, строковые, числовые, объектные и массивные ограничения, not, allOf/anyOf/oneOf и if/then/else
+func (d *Document) validateSchema(schemaNode any, value any, location string) error {
+	if schemaNode == nil {
+		return nil
+	}
+	if allowed, ok := schemaNode.(bool); ok {
+		if !allowed {
+			return fmt.Errorf("%s: value is not allowed", location)
+		}
+		return nil
+	}
+	schema, ok := schemaNode.(map[string]any)
+	if !ok {
+		return fmt.Errorf("%s: invalid schema", location)
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		target, err := d.lookup(ref)
+		if err != nil {
+			return err
+		}
+		if err := d.validateSchema(target, value, location); err != nil {
+			return err
+		}
+	}
+
+	if expected, ok := schema["type"]; ok {
+		if err := checkType(expected, value, location); err != nil {
+			return err
+		}
+	}
+	if options, ok := schema["enum"].([]any); ok && !containsValue(options, value) {
+		return fmt.Errorf("%s: %v is not one of %v", location, value, options)
+	}
+	if expected, ok := schema["const"]; ok && !equalValues(expected, value) {
+		return fmt.Errorf("%s: %v is not equal to %v", location, value, expected)
+	}
+
+	var err error
+	switch typed := value.(type) {
+	case string:
+		err = d.validateString(schema, typed, location)
+	case json.Number:
+		err = validateNumber(schema, typed, location)
+	case []any:
+		err = d.validateArray(schema, typed, location)
+	case map[string]any:
+		err = d.validateObject(schema, typed, location)
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.validateCombinators(schema, value, location)
+}
+
+// validateCombinators — проверяет составные ключевые слова схемы
+func (d *Document) validateCombinators(schema map[string]any, value any, location string) error {
+	if not, ok := schema["not"]; ok {
+		if d.validateSchema(not, value, location) == nil {
+			return fmt.Errorf("%s: value matches a schema it must not match", location)
+		}
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, sub := range all {
+			if err := d.validateSchema(sub, value, location); err != nil {
+				return err
+			}
+		}
+	}
+	if anyOf, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, sub := range anyOf {
+			if d.validateSchema(sub, value, location) == nil {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return fmt.Errorf("%s: value matches none of anyOf", location)
+		}
+	}
+	if one, ok := schema["oneOf"].([]any); ok {
+		matched := 0
+		for _, sub := range one {
+			if d.validateSchema(sub, value, location) == nil {
+				matched++
+			}
+		}
+		if matched != 1 {
+			return fmt.Errorf("%s: value matches %d of oneOf, expected exactly one", location, matched)
+		}
+	}
+	if condition, ok := schema["if"]; ok {
+		branch := "else"
+		if d.validateSchema(condition, value, location) == nil {
+			branch = "then"
+		}
+		if sub, ok := schema[branch]; ok {
+			if err := d.validateSchema(sub, value, location); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateString — проверяет строковые ограничения
+func (d *Document) validateString(schema map[string]any, value, location string) error {
+	length := utf8.RuneCountInString(value)
+	if limit, ok := schemaInt(schema, "minLength"); ok && length < limit {
+		return fmt.Errorf("%s: %q is shorter than %d", location, value, limit)
+	}
+	if limit, ok := schemaInt(schema, "maxLength"); ok && length > limit {
+		return fmt.Errorf("%s: %q is longer than %d", location, value, limit)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		compiled, err := d.pattern(pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern %q: %w", location, pattern, err)
+		}
+		if !compiled.MatchString(value) {
+			return fmt.Errorf("%s: %q does not match %q", location, value, pattern)
+		}
+	}
+	if format, ok := schema["format"].(string); ok && format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("%s: %q is not a date-time", location, value)
+		}
+	}
+	return nil
+}
+
+// validateNumber — проверяет числовые границы
+func validateNumber(schema map[string]any, value json.Number, location string) error {
+	number, err := value.Float64()
+	if err != nil {
+		return fmt.Errorf("%s: %q is not a number", location, value)
+	}
+	if limit, ok := schemaFloat(schema, "minimum"); ok && number < limit {
+		return fmt.Errorf("%s: %v is less than %v", location, number, limit)
+	}
+	if limit, ok := schemaFloat(schema, "maximum"); ok && number > limit {
+		return fmt.Errorf("%s: %v is greater than %v", location, number, limit)
+	}
+	if limit, ok := schemaFloat(schema, "exclusiveMinimum"); ok && number <= limit {
+		return fmt.Errorf("%s: %v must be greater than %v", location, number, limit)
+	}
+	if limit, ok := schemaFloat(schema, "exclusiveMaximum"); ok && number >= limit {
+		return fmt.Errorf("%s: %v must be less than %v", location, number, limit)
+	}
+	return nil
+}
+
+// validateArray — проверяет размер массива и его элементы
+func (d *Document) validateArray(schema map[string]any, value []any, location string) error {
+	if limit, ok := schemaInt(schema, "minItems"); ok && len(value) < limit {
+		return fmt.Errorf("%s: array has %d items, expected at least %d", location, len(value), limit)
+	}
+	if limit, ok := schemaInt(schema, "maxItems"); ok && len(value) > limit {
+		return fmt.Errorf("%s: array has %d items, expected at most %d", location, len(value), limit)
+	}
+	if items, ok := schema["items"]; ok {
+		for i, item := range value {
+			if err := d.validateSchema(items, item, fmt.Sprintf("%s[%d]", location, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// validateObject — проверяет обязательные, описанные и лишние свойства объекта
+func (d *Document) validateObject(schema map[string]any, value map[string]any, location string) error {
+	if required, ok := schema["required"].([]any); ok {
+		for _, name := range required {
+			if _, present := value[name.(string)]; !present {
+				return fmt.Errorf("%s: missing required property %q", location, name)
+			}
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	additional, hasAdditional := schema["additionalProperties"]
+	for name, property := range value {
+		propertyLocation := location + "." + name
+		if propertySchema, ok := properties[name]; ok {
+			if err := d.validateSchema(propertySchema, property, propertyLocation); err != nil {
+				return err
+			}
+			continue
+		}
+		if hasAdditional {
+			if err := d.validateSchema(additional, property, propertyLocation); err != nil {
+				return fmt.Errorf("%s: property is not described: %w", propertyLocation, err)
+			}
+		}
+	}
+	return nil
+}
+
+// pattern — компилирует регулярное выражение схемы с кэшированием
+func (d *Document) pattern(expression string) (*regexp.Regexp, error) {
+	if cached, ok := d.patterns.Load(expression); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	compiled, err := regexp.Compile(expression)
+	if err != nil {
+		return nil, err
+	}
+	d.patterns.Store(expression, compiled)
+	return compiled, nil
+}
+
+// checkType — проверяет соответствие значения типу (или списку типов) схемы
+func checkType(expected any, value any, location string) error {
+	var types []string
+	switch typed := expected.(type) {
+	case string:
+		types = []string{typed}
+	case []any:
+		for _, item := range typed {
+			if name, ok := item.(string); ok {
+				types = append(types, name)
+			}
+		}
+	}
+
+	actual := jsonType(value)
+	for _, name := range types {
+		if name == actual || (name == "number" && actual == "integer") {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s: expected type %v, got %s", location, expected, actual)
+}
+
+// jsonType — возвращает тип JSON-значения в терминах JSON Schema
+func jsonType(value any) string {
+	switch typed := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case json.Number:
+		if _, err := strconv.ParseInt(typed.String(), 10, 64); err == nil {
+			return "integer"
+		}
+		return "number"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// containsValue — проверяет, входит ли значение в список допустимых
+func containsValue(options []any, value any) bool {
+	for _, option := range options {
+		if equalValues(option, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equalValues — сравнивает скалярные JSON-значения; числа сравниваются по величине
+func equalValues(a, b any) bool {
+	numberA, okA := a.(json.Number)
+	numberB, okB := b.(json.Number)
+	if okA && okB {
+		floatA, errA := numberA.Float64()
+		floatB, errB := numberB.Float64()
+		return errA == nil && errB == nil && floatA == floatB
+	}
+	return a == b
+}
+
+// schemaFloat — возвращает числовое ключевое слово схемы
+func schemaFloat(schema map[string]any, key string) (float64, bool) {
+	number, ok := schema[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	value, err := number.Float64()
+	return value, err == nil
+}
+
+// schemaInt — возвращает целочисленное ключевое слово схемы
+func schemaInt(schema map[string]any, key string) (int, bool) {
+	value, ok := schemaFloat(schema, key)
+	return int(value), ok
+}
@@ -0,0 +1,416 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/openapi"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// conformanceClient — выполняет запросы через роутер и проверяет запросы и ответы по документу OpenAPI
+type conformanceClient struct {
+	t        *testing.T
+	router   http.Handler
+	document *openapi.Document
+	token    string
+	// header — дополнительные заголовки запросов
+	header  http.Header
+	covered map[string]bool
+	// ctx — контекст запросов; для потока событий его отмена завершает подключение
+	ctx context.Context
+}
+
+// do — выполняет корректный запрос: и запрос, и ответ должны соответствовать документу
+func (c *conformanceClient) do(method, target, contentType, body string, expectedStatus int) *httptest.ResponseRecorder {
+	return c.send(method, target, contentType, body, expectedStatus, true)
+}
+
+// doMalformed — выполняет заведомо неверный запрос: по документу проверяется только ответ
+func (c *conformanceClient) doMalformed(method, target, contentType, body string, expectedStatus int) *httptest.ResponseRecorder {
+	return c.send(method, target, contentType, body, expectedStatus, false)
+}
+
+func (c *conformanceClient) send(method, target, contentType, body string, expectedStatus int, validateRequest bool) *httptest.ResponseRecorder {
+	c.t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if c.ctx != nil {
+		req = req.WithContext(c.ctx)
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", c.token)
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+
+	if validateRequest {
+		if err := c.document.ValidateRequest(req, []byte(body)); err != nil {
+			c.t.Errorf("request does not conform to the OpenAPI document: %v", err)
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	c.router.ServeHTTP(rr, req)
+
+	if rr.Code != expectedStatus {
+		c.t.Errorf("%s %s: expected status %d, got %d: %s", method, target, expectedStatus, rr.Code, rr.Body.String())
+	}
+	if err := c.document.ValidateResponse(method, req.URL.Path, rr.Code, rr.Header(), rr.Body.Bytes()); err != nil {
+		c.t.Errorf("response does not conform to the OpenAPI document: %v", err)
+	}
+
+	if operation, _, err := c.document.FindOperation(method, req.URL.Path); err == nil {
+		c.covered[operation.Method+" "+operation.Path] = true
+	}
+	return rr
+}
+
+func TestOpenAPI_Conformance(t *testing.T) {
+	logger := zap.NewNop()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	document, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load OpenAPI document: %v", err)
+	}
+
+	store := storage.NewMemoryStorage(logger)
+	orderService := services.NewOrderService(mocks.NewMockAccrualService(ctrl), store.TxManager, store.OrderRepo, store.UserRepo, store.AuditRepo, store.OutboxRepo, store.WebhookRepo, store.NotifyRepo, validation.LuhnValidator{}, logger)
+	appServices := &services.AppServices{
+		AuditService:     services.NewAuditService(store.AuditRepo, logger),
+		AuthService:      services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+		OrderService:     orderService,
+		OrderValidator:   validation.LuhnValidator{},
+		StatementService: services.NewStatementService(store.UserRepo, store.OrderRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.StatementRepo, logger),
+		UserService:      services.NewUserService(store.TxManager, store.UserRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.AuditRepo, store.OutboxRepo, logger),
+		WebhookService:   services.NewWebhookService(store.WebhookRepo, services.WebhookOptions{}, logger),
+		OrderUpdates:     services.NewOrderUpdateBroker(services.OrderUpdateBrokerOptions{MaxStreamsPerUser: 1}, logger),
+	}
+
+	r := chi.NewRouter()
+	RegisterRoutes(r, appServices, Options{OrdersBatchLimit: 3, MaxBodySize: 1 << 10, SwaggerUI: true}, logger)
+	client := &conformanceClient{t: t, router: r, document: document, covered: make(map[string]bool)}
+
+	// Документация
+	client.do(http.MethodGet, "/api/openapi.json", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/docs", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/docs/swagger-ui.css", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/docs/swagger-ui-bundle.js", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/docs/index.html", "", "", http.StatusNotFound)
+
+	// Регистрация и вход
+	const credentials = `{"login": "alice", "password": "secret"}`
+	client.do(http.MethodPost, "/api/user/register", "application/json", credentials, http.StatusOK)
+	client.do(http.MethodPost, "/api/user/register", "application/json", credentials, http.StatusConflict)
+	client.doMalformed(http.MethodPost, "/api/user/register", "application/json", `{"login":`, http.StatusBadRequest)
+	client.do(http.MethodPost, "/api/user/login", "application/json", `{"login": "alice", "password": "wrong"}`, http.StatusUnauthorized)
+	client.doMalformed(http.MethodPost, "/api/user/login", "application/json", `[]`, http.StatusBadRequest)
+	client.doMalformed(http.MethodPost, "/api/user/login", "application/json", `{"login": "alice", "password": "secret", "admin": true}`, http.StatusBadRequest)
+	client.doMalformed(http.MethodPost, "/api/user/login", "text/plain", credentials, http.StatusUnsupportedMediaType)
+	client.doMalformed(http.MethodPost, "/api/user/register", "application/json", `{"login": "`+strings.Repeat("a", 2<<10)+`", "password": "secret"}`, http.StatusRequestEntityTooLarge)
+	login := client.do(http.MethodPost, "/api/user/login", "application/json", credentials, http.StatusOK)
+
+	// Без токена защищенные маршруты недоступны
+	client.do(http.MethodGet, "/api/user/orders", "", "", http.StatusUnauthorized)
+	client.token = login.Header().Get("Authorization")
+
+	// Пакетная загрузка доступна только партнерам; роль назначает администратор (первому администратору
+	// роль назначается в хранилище командой gophermart users grant)
+	client.do(http.MethodPost, "/api/user/orders/batch", "application/json", `["12345678903"]`, http.StatusForbidden)
+	client.token = ""
+	client.do(http.MethodPost, "/api/user/register", "application/json", `{"login": "support", "password": "secret"}`, http.StatusOK)
+	if _, err := appServices.UserService.SetRoles(context.Background(), "support", []string{"user", "admin"}, "test"); err != nil {
+		t.Fatalf("failed to grant admin role: %v", err)
+	}
+	supportLogin := client.do(http.MethodPost, "/api/user/login", "application/json", `{"login": "support", "password": "secret"}`, http.StatusOK)
+	client.token = supportLogin.Header().Get("Authorization")
+	client.do(http.MethodPut, "/api/admin/users/alice/roles", "application/json", `{"roles": ["user", "partner"]}`, http.StatusOK)
+	client.doMalformed(http.MethodPut, "/api/admin/users/alice/roles", "application/json", `{"roles": ["root"]}`, http.StatusUnprocessableEntity)
+	client.doMalformed(http.MethodPut, "/api/admin/users/alice/roles", "application/json", `{"roles":`, http.StatusBadRequest)
+	client.do(http.MethodPut, "/api/admin/users/ghost/roles", "application/json", `{"roles": ["user"]}`, http.StatusNotFound)
+
+	// Сотрудник поддержки без роли администратора не может менять роли
+	client.do(http.MethodPost, "/api/user/register", "application/json", `{"login": "helpdesk", "password": "secret"}`, http.StatusOK)
+	client.do(http.MethodPut, "/api/admin/users/helpdesk/roles", "application/json", `{"roles": ["user", "support"]}`, http.StatusOK)
+	client.token = ""
+	helpdeskLogin := client.do(http.MethodPost, "/api/user/login", "application/json", `{"login": "helpdesk", "password": "secret"}`, http.StatusOK)
+	client.token = helpdeskLogin.Header().Get("Authorization")
+	client.do(http.MethodGet, "/api/admin/users/alice", "", "", http.StatusOK)
+	client.do(http.MethodPut, "/api/admin/users/alice/roles", "application/json", `{"roles": ["user"]}`, http.StatusForbidden)
+
+	// Новые роли попадают в токен при следующем входе
+	client.token = ""
+	login = client.do(http.MethodPost, "/api/user/login", "application/json", credentials, http.StatusOK)
+	client.token = login.Header().Get("Authorization")
+
+	// Заказы
+	client.do(http.MethodGet, "/api/user/orders", "", "", http.StatusNoContent)
+	client.do(http.MethodPost, "/api/user/orders", "text/plain", "79927398713", http.StatusAccepted)
+	client.do(http.MethodPost, "/api/user/orders", "text/plain", "79927398713", http.StatusOK)
+	client.do(http.MethodPost, "/api/user/orders", "text/plain", "79927398710", http.StatusUnprocessableEntity)
+	client.do(http.MethodPost, "/api/user/orders/batch", "application/json", `["12345678903", "79927398713", "1"]`, http.StatusOK)
+	client.do(http.MethodPost, "/api/user/orders/batch", "text/plain", "9278923470\n346436439", http.StatusOK)
+	client.do(http.MethodPost, "/api/user/orders/batch", "application/json", `["1", "2", "3", "4"]`, http.StatusRequestEntityTooLarge)
+	client.doMalformed(http.MethodPost, "/api/user/orders/batch", "application/json", `{}`, http.StatusBadRequest)
+
+	// Начисление по одному из заказов, чтобы в ответе были и PROCESSED-заказы с accrual, и заказы без него
+	ctx := context.Background()
+	user, err := store.UserRepo.GetUserByLogin(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	processed := domain.Order{OrderNumber: "79927398713", UserID: user.UserID, OrderStatus: domain.OrderStatusProcessed, Accrual: decimal.NewFromInt(500), UploadedAt: time.Now()}
+	if err := store.OrderRepo.UpdateOrder(ctx, processed); err != nil {
+		t.Fatalf("failed to update order: %v", err)
+	}
+	if err := orderService.UpdateUserBalance(ctx, user.UserID, processed.Accrual); err != nil {
+		t.Fatalf("failed to update balance: %v", err)
+	}
+
+	client.do(http.MethodGet, "/api/user/orders", "", "", http.StatusOK)
+	page := client.do(http.MethodGet, "/api/user/orders?limit=1&status=new,processed", "", "", http.StatusOK)
+	if page.Header().Get("Link") == "" {
+		t.Error("expected Link header for the first page")
+	}
+	client.doMalformed(http.MethodGet, "/api/user/orders?status=UNKNOWN", "", "", http.StatusBadRequest)
+
+	// Поток изменений заказов: подключение завершается по истечении контекста запроса, второй поток сверх лимита отклоняется
+	streamCtx, cancelStream := context.WithTimeout(ctx, 50*time.Millisecond)
+	client.ctx = streamCtx
+	stream := client.do(http.MethodGet, "/api/user/orders/stream", "", "", http.StatusOK)
+	cancelStream()
+	client.ctx = nil
+	if !strings.HasPrefix(stream.Body.String(), ": connected") {
+		t.Errorf("expected the stream to start with a comment, got %q", stream.Body.String())
+	}
+	subscription, err := appServices.OrderUpdates.Subscribe(user.UserID)
+	if err != nil {
+		t.Fatalf("failed to open order stream: %v", err)
+	}
+	client.do(http.MethodGet, "/api/user/orders/stream", "", "", http.StatusTooManyRequests)
+	subscription.Close()
+
+	// Баланс и списания; пока данные не менялись, повторное чтение с ETag отвечает 304
+	client.do(http.MethodGet, "/api/user/withdrawals", "", "", http.StatusNoContent)
+	balanceETag := client.do(http.MethodGet, "/api/user/balance", "", "", http.StatusOK).Header().Get("ETag")
+	client.header = http.Header{"If-None-Match": {balanceETag}}
+	client.do(http.MethodGet, "/api/user/balance", "", "", http.StatusNotModified)
+	client.header = nil
+	client.do(http.MethodPost, "/api/user/balance/withdraw", "application/json", `{"order": "2377225624", "sum": 100.5}`, http.StatusOK)
+	client.do(http.MethodPost, "/api/user/balance/withdraw", "application/json", `{"order": "2377225624", "sum": 1000}`, http.StatusPaymentRequired)
+	client.do(http.MethodPost, "/api/user/balance/withdraw", "application/json", `{"order": "2377225625", "sum": 1}`, http.StatusUnprocessableEntity)
+	client.doMalformed(http.MethodPost, "/api/user/balance/withdraw", "application/json", `{"order":`, http.StatusBadRequest)
+	client.header = http.Header{"If-None-Match": {balanceETag}}
+	client.do(http.MethodGet, "/api/user/balance", "", "", http.StatusOK)
+	client.header = nil
+	client.do(http.MethodGet, "/api/user/withdrawals?limit=10&from=2024-01-01", "", "", http.StatusOK)
+	for _, target := range []string{"/api/user/orders", "/api/user/withdrawals"} {
+		etag := client.do(http.MethodGet, target, "", "", http.StatusOK).Header().Get("ETag")
+		client.header = http.Header{"If-None-Match": {etag}}
+		client.do(http.MethodGet, target, "", "", http.StatusNotModified)
+		client.header = nil
+	}
+
+	// Выписки
+	client.do(http.MethodGet, "/api/user/statement", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/user/statement?format=csv&from=2024-01-01", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/user/statement?format=xlsx", "", "", http.StatusOK)
+	client.doMalformed(http.MethodGet, "/api/user/statement?format=pdf", "", "", http.StatusBadRequest)
+	// Выписка за прошедший месяц формируется, только если пользователь уже совершал операции в нём или раньше
+	historic := domain.Order{OrderNumber: "4561261212345467", UserID: user.UserID, OrderStatus: domain.OrderStatusInvalid, UploadedAt: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)}
+	if err := store.OrderRepo.AddOrder(ctx, historic); err != nil {
+		t.Fatalf("failed to add order: %v", err)
+	}
+	client.do(http.MethodGet, "/api/user/statements/2024-01", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/user/statements/1900-01", "", "", http.StatusNotFound)
+	client.do(http.MethodGet, "/api/user/statements/2999-01", "", "", http.StatusNotFound)
+	client.do(http.MethodGet, "/api/user/statements/january", "", "", http.StatusBadRequest)
+
+	// Webhook: секрет возвращается только при регистрации, журнал доставок доступен только владельцу
+	client.do(http.MethodGet, "/api/user/webhooks", "", "", http.StatusNoContent)
+	created := client.do(http.MethodPost, "/api/user/webhooks", "application/json", `{"url": "https://example.com/hooks", "events": ["OrderStatusChanged"]}`, http.StatusCreated)
+	if !strings.Contains(created.Body.String(), `"secret":"`) {
+		t.Errorf("expected the webhook secret in the registration response, got %s", created.Body.String())
+	}
+	client.doMalformed(http.MethodPost, "/api/user/webhooks", "application/json", `{"url": "https://example.com/hooks", "events": ["PointsWithdrawn"]}`, http.StatusUnprocessableEntity)
+	client.do(http.MethodPost, "/api/user/webhooks", "application/json", `{"url": "ftp://example.com", "events": ["PointsAccrued"]}`, http.StatusUnprocessableEntity)
+	client.doMalformed(http.MethodPost, "/api/user/webhooks", "application/json", `{"url":`, http.StatusBadRequest)
+	listed := client.do(http.MethodGet, "/api/user/webhooks", "", "", http.StatusOK)
+	if strings.Contains(listed.Body.String(), `"secret"`) {
+		t.Errorf("expected webhook secrets to be hidden, got %s", listed.Body.String())
+	}
+	webhooks, err := store.WebhookRepo.GetWebhooksByUserID(ctx, user.UserID)
+	if err != nil || len(webhooks) != 1 {
+		t.Fatalf("failed to get webhooks: %v", err)
+	}
+	webhookPath := "/api/user/webhooks/" + strconv.Itoa(webhooks[0].WebhookID)
+	client.do(http.MethodGet, webhookPath+"/deliveries", "", "", http.StatusNoContent)
+	delivery := domain.WebhookDelivery{
+		WebhookID:     webhooks[0].WebhookID,
+		EventType:     domain.OutboxOrderStatusChanged,
+		Payload:       domain.OutboxPayload(`{"order":"79927398713"}`),
+		Status:        domain.WebhookDeliveryPending,
+		NextAttemptAt: time.Now(),
+		CreatedAt:     time.Now(),
+	}
+	if err := store.WebhookRepo.AddDelivery(ctx, delivery); err != nil {
+		t.Fatalf("failed to add webhook delivery: %v", err)
+	}
+	client.do(http.MethodGet, webhookPath+"/deliveries?limit=10", "", "", http.StatusOK)
+	client.doMalformed(http.MethodGet, webhookPath+"/deliveries?limit=0", "", "", http.StatusBadRequest)
+	client.do(http.MethodGet, "/api/user/webhooks/999/deliveries", "", "", http.StatusNotFound)
+	client.do(http.MethodDelete, "/api/user/webhooks/999", "", "", http.StatusNotFound)
+	client.do(http.MethodDelete, webhookPath, "", "", http.StatusNoContent)
+
+	// Операции поддержки: обычному пользователю недоступны
+	userToken := client.token
+	client.do(http.MethodGet, "/api/admin/users/alice", "", "", http.StatusForbidden)
+	client.token = ""
+	client.do(http.MethodGet, "/api/admin/users/alice", "", "", http.StatusUnauthorized)
+	client.token = supportLogin.Header().Get("Authorization")
+
+	client.do(http.MethodGet, "/api/admin/users/ghost", "", "", http.StatusNotFound)
+	client.do(http.MethodGet, "/api/admin/users/alice/orders?limit=2&status=NEW", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/admin/users/support/orders", "", "", http.StatusNoContent)
+	client.do(http.MethodGet, "/api/admin/users/alice/withdrawals", "", "", http.StatusOK)
+	client.do(http.MethodGet, "/api/admin/users/ghost/withdrawals", "", "", http.StatusNotFound)
+	client.do(http.MethodPost, "/api/admin/users/alice/adjustments", "application/json", `{"amount": 50, "reason": "goodwill"}`, http.StatusOK)
+	client.do(http.MethodPost, "/api/admin/users/alice/adjustments", "application/json", `{"amount": -100000, "reason": "chargeback"}`, http.StatusPaymentRequired)
+	client.doMalformed(http.MethodPost, "/api/admin/users/alice/adjustments", "application/json", `{"amount": 10, "reason": ""}`, http.StatusUnprocessableEntity)
+	client.doMalformed(http.MethodPost, "/api/admin/users/alice/adjustments", "application/json", `{"amount":`, http.StatusBadRequest)
+	client.do(http.MethodPost, "/api/admin/users/ghost/adjustments", "application/json", `{"amount": 1, "reason": "typo"}`, http.StatusNotFound)
+	view := client.do(http.MethodGet, "/api/admin/users/alice", "", "", http.StatusOK)
+	if !strings.Contains(view.Body.String(), `"reason":"goodwill","actor":"support"`) {
+		t.Errorf("expected the adjustment to be recorded with its reason and actor, got %s", view.Body.String())
+	}
+	client.do(http.MethodPost, "/api/admin/orders/12345678903/requeue", "", "", http.StatusAccepted)
+	client.do(http.MethodPost, "/api/admin/orders/79927398713/requeue", "", "", http.StatusConflict)
+	client.do(http.MethodPost, "/api/admin/orders/2377225624/requeue", "", "", http.StatusNotFound)
+
+	// Заблокированный пользователь не может войти, загружать заказы и выводить средства
+	client.do(http.MethodPut, "/api/admin/users/alice/block", "", "", http.StatusOK)
+	client.do(http.MethodPut, "/api/admin/users/ghost/block", "", "", http.StatusNotFound)
+	client.token = userToken
+	client.do(http.MethodPost, "/api/user/orders", "text/plain", "346436439", http.StatusForbidden)
+	client.do(http.MethodPost, "/api/user/orders/batch", "text/plain", "346436439", http.StatusForbidden)
+	client.do(http.MethodPost, "/api/user/balance/withdraw", "application/json", `{"order": "2377225624", "sum": 1}`, http.StatusForbidden)
+	client.token = ""
+	client.do(http.MethodPost, "/api/user/login", "application/json", credentials, http.StatusForbidden)
+	client.token = supportLogin.Header().Get("Authorization")
+	client.do(http.MethodDelete, "/api/admin/users/alice/block", "", "", http.StatusOK)
+	client.token = ""
+	client.do(http.MethodPost, "/api/user/login", "application/json", credentials, http.StatusOK)
+
+	// Журнал аудита доступен только администраторам; события сохраняются с идентификатором запроса
+	client.token = helpdeskLogin.Header().Get("Authorization")
+	client.do(http.MethodGet, "/api/admin/audit", "", "", http.StatusForbidden)
+	client.token = supportLogin.Header().Get("Authorization")
+	audit := client.do(http.MethodGet, "/api/admin/audit?subject=alice&type=user.login_failed", "", "", http.StatusOK)
+	if !strings.Contains(audit.Body.String(), `"reason":"user_blocked"`) || strings.Contains(audit.Body.String(), `"request_id":""`) {
+		t.Errorf("expected the blocked login attempt to be audited with its request id, got %s", audit.Body.String())
+	}
+	auditPage := client.do(http.MethodGet, "/api/admin/audit?limit=1&type=balance.withdrawn,balance.adjusted", "", "", http.StatusOK)
+	if auditPage.Header().Get("Link") == "" {
+		t.Errorf("expected a Link header for the next page of audit events")
+	}
+	client.do(http.MethodGet, "/api/admin/audit?actor=ghost", "", "", http.StatusNoContent)
+	client.do(http.MethodGet, "/api/admin/audit?type=user.deleted", "", "", http.StatusBadRequest)
+
+	// Каждая операция документа должна быть проверена хотя бы одним запросом
+	for _, operation := range document.Operations() {
+		if !client.covered[operation.Method+" "+operation.Path] {
+			t.Errorf("operation %s %s is not exercised by the conformance test", operation.Method, operation.Path)
+		}
+	}
+}
+
+func TestOpenAPI_RateLimited(t *testing.T) {
+	logger := zap.NewNop()
+
+	document, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load OpenAPI document: %v", err)
+	}
+
+	store := storage.NewMemoryStorage(logger)
+	appServices := &services.AppServices{
+		AuthService: services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+		RateLimiter: services.NewRateLimiter(store.RateLimitRepo, map[string]domain.RateLimitPolicy{
+			domain.RateLimitAuth: {Requests: 1, Period: time.Hour},
+		}, logger),
+	}
+	r := chi.NewRouter()
+	RegisterRoutes(r, appServices, Options{}, logger)
+	client := &conformanceClient{t: t, router: r, document: document, covered: make(map[string]bool)}
+
+	// Ответ 429 должен содержать Retry-After и заголовки X-RateLimit-*, описанные в документе
+	const credentials = `{"login": "alice", "password": "secret"}`
+	client.do(http.MethodPost, "/api/user/login", "application/json", credentials, http.StatusUnauthorized)
+	limited := client.do(http.MethodPost, "/api/user/login", "application/json", credentials, http.StatusTooManyRequests)
+	for _, header := range []string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset"} {
+		if limited.Header().Get(header) == "" {
+			t.Errorf("expected %s header in rate limited response", header)
+		}
+	}
+}
+
+func TestOpenAPI_DescribesAllRoutes(t *testing.T) {
+	logger := zap.NewNop()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	document, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load OpenAPI document: %v", err)
+	}
+
+	appServices := &services.AppServices{
+		AuthService:  services.NewAuthService([]byte("secret"), 0, mocks.NewMockTxManager(ctrl), mocks.NewMockUserRepository(ctrl), mocks.NewMockAuditRepository(ctrl), logger),
+		OrderService: mocks.NewMockOrderServiceInterface(ctrl),
+	}
+	r := chi.NewRouter()
+	RegisterRoutes(r, appServices, Options{SwaggerUI: true}, logger)
+
+	var registered []string
+	err = chi.Walk(r, func(method string, route string, _ http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if route != "/" {
+			route = strings.TrimSuffix(route, "/")
+		}
+		registered = append(registered, method+" "+route)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %v", err)
+	}
+
+	var described []string
+	for _, operation := range document.Operations() {
+		described = append(described, operation.Method+" "+operation.Path)
+	}
+
+	sort.Strings(registered)
+	sort.Strings(described)
+	if strings.Join(registered, "\n") != strings.Join(described, "\n") {
+		t.Errorf("registered routes and OpenAPI operations differ:\nregistered:\n%s\ndescribed:\n%s",
+			strings.Join(registered, "\n"), strings.Join(described, "\n"))
+	}
+}
@@ -0,0 +1,26 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/metrics"
+	"time"
+)
+
+// Options - параметры HTTP-слоя, задаваемые конфигурацией
+type Options struct {
+	// RequestTimeout - предельное время обработки запроса; 0 отключает ограничение
+	RequestTimeout time.Duration
+	// GzipLevel - уровень сжатия ответов gzip и deflate (1–9); 0 — уровень по умолчанию
+	GzipLevel int
+	// OrdersBatchLimit - максимальное количество номеров заказов в одном пакетном запросе
+	OrdersBatchLimit int
+	// MaxBodySize - максимальный размер тела запроса в байтах; 0 отключает ограничение
+	MaxBodySize int64
+	// OrdersBatchMaxBodySize - максимальный размер тела пакетной загрузки заказов в байтах; 0 отключает ограничение
+	OrdersBatchMaxBodySize int64
+	// OrderStreamHeartbeat - период комментариев в потоке изменений заказов, удерживающих соединение открытым
+	OrderStreamHeartbeat time.Duration
+	// SwaggerUI - публикует Swagger UI по адресу /api/docs
+	SwaggerUI bool
+	// Metrics - реестр, в котором учитываются запросы; nil отключает учёт
+	Metrics *metrics.Registry
+}
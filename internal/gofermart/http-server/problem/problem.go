@@ -0,0 +1,143 @@
+package problem
+
+import (
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/go-chi/chi/v5/middleware"
+	"net/http"
+)
+
+// ContentType — тип содержимого ответа с описанием ошибки (RFC 7807)
+const ContentType = "application/problem+json"
+
+// typeBase — префикс стабильного идентификатора вида ошибки
+const typeBase = "/problems/"
+
+// Kind — вид ошибки: стабильный идентификатор, заголовок и HTTP-статус
+type Kind struct {
+	Slug   string
+	Title  string
+	Status int
+}
+
+// Type — возвращает значение поля type для вида ошибки
+func (k Kind) Type() string {
+	return typeBase + k.Slug
+}
+
+// Виды ошибок HTTP API
+var (
+	BadRequest              = Kind{Slug: "bad-request", Title: "Bad Request", Status: http.StatusBadRequest}
+	InvalidCredentials      = Kind{Slug: "invalid-credentials", Title: "Invalid login or password", Status: http.StatusUnauthorized}
+	Unauthorized            = Kind{Slug: "unauthorized", Title: "Unauthorized", Status: http.StatusUnauthorized}
+	InsufficientFunds       = Kind{Slug: "insufficient-funds", Title: "Insufficient funds", Status: http.StatusPaymentRequired}
+	Forbidden               = Kind{Slug: "forbidden", Title: "Forbidden", Status: http.StatusForbidden}
+	UserBlocked             = Kind{Slug: "user-blocked", Title: "User is blocked", Status: http.StatusForbidden}
+	NotFound                = Kind{Slug: "not-found", Title: "Not Found", Status: http.StatusNotFound}
+	OrderNotFound           = Kind{Slug: "order-not-found", Title: "Order not found", Status: http.StatusNotFound}
+	StatementNotFound       = Kind{Slug: "statement-not-found", Title: "Statement not found", Status: http.StatusNotFound}
+	UserNotFound            = Kind{Slug: "user-not-found", Title: "User not found", Status: http.StatusNotFound}
+	WebhookNotFound         = Kind{Slug: "webhook-not-found", Title: "Webhook not found", Status: http.StatusNotFound}
+	MethodNotAllowed        = Kind{Slug: "method-not-allowed", Title: "Method Not Allowed", Status: http.StatusMethodNotAllowed}
+	LoginAlreadyExists      = Kind{Slug: "login-already-exists", Title: "Login already exists", Status: http.StatusConflict}
+	OrderAlreadyProcessed   = Kind{Slug: "order-already-processed", Title: "Order already processed", Status: http.StatusConflict}
+	OrderUploadedByAnother  = Kind{Slug: "order-uploaded-by-another", Title: "Order number already uploaded by another user", Status: http.StatusConflict}
+	PayloadTooLarge         = Kind{Slug: "payload-too-large", Title: "Payload Too Large", Status: http.StatusRequestEntityTooLarge}
+	UnsupportedMediaType    = Kind{Slug: "unsupported-media-type", Title: "Unsupported Media Type", Status: http.StatusUnsupportedMediaType}
+	InvalidAdjustment       = Kind{Slug: "invalid-balance-adjustment", Title: "Invalid balance adjustment", Status: http.StatusUnprocessableEntity}
+	InvalidRole             = Kind{Slug: "invalid-role", Title: "Invalid role", Status: http.StatusUnprocessableEntity}
+	InvalidWebhook          = Kind{Slug: "invalid-webhook", Title: "Invalid webhook", Status: http.StatusUnprocessableEntity}
+	InvalidOrderNumber      = Kind{Slug: "invalid-order-number", Title: "Invalid order number", Status: http.StatusUnprocessableEntity}
+	InvalidWithdrawalAmount = Kind{Slug: "invalid-withdrawal-amount", Title: "Invalid withdrawal amount", Status: http.StatusUnprocessableEntity}
+	TooManyOrderStreams     = Kind{Slug: "too-many-order-streams", Title: "Too many open order streams", Status: http.StatusTooManyRequests}
+	RateLimited             = Kind{Slug: "rate-limited", Title: "Too Many Requests", Status: http.StatusTooManyRequests}
+	Internal                = Kind{Slug: "internal", Title: "Internal Server Error", Status: http.StatusInternalServerError}
+)
+
+// errorKinds — соответствие доменных ошибок видам ошибок HTTP API
+var errorKinds = []struct {
+	err  error
+	kind Kind
+}{
+	{err: gofermartErrors.ErrAdjustmentReasonRequired, kind: InvalidAdjustment},
+	{err: gofermartErrors.ErrInsufficientFunds, kind: InsufficientFunds},
+	{err: gofermartErrors.ErrInvalidAdjustmentAmount, kind: InvalidAdjustment},
+	{err: gofermartErrors.ErrInvalidCursor, kind: BadRequest},
+	{err: gofermartErrors.ErrInvalidRole, kind: InvalidRole},
+	{err: gofermartErrors.ErrInvalidWebhookEvents, kind: InvalidWebhook},
+	{err: gofermartErrors.ErrInvalidWebhookURL, kind: InvalidWebhook},
+	{err: gofermartErrors.ErrInvalidWithdrawalAmount, kind: InvalidWithdrawalAmount},
+	{err: gofermartErrors.ErrLoginAlreadyExists, kind: LoginAlreadyExists},
+	{err: gofermartErrors.ErrOrderAlreadyProcessed, kind: OrderAlreadyProcessed},
+	{err: gofermartErrors.ErrOrderNotFound, kind: OrderNotFound},
+	{err: gofermartErrors.ErrOrderUploadedByAnother, kind: OrderUploadedByAnother},
+	{err: gofermartErrors.ErrStatementNotFound, kind: StatementNotFound},
+	{err: gofermartErrors.ErrTooManyOrderStreams, kind: TooManyOrderStreams},
+	{err: gofermartErrors.ErrUserBlocked, kind: UserBlocked},
+	{err: gofermartErrors.ErrUserNotFound, kind: UserNotFound},
+	{err: gofermartErrors.ErrWebhookNotFound, kind: WebhookNotFound},
+	{err: utils.ErrUnauthorized, kind: Unauthorized},
+	{err: validation.ErrInvalidOrderNumber, kind: InvalidOrderNumber},
+}
+
+// Problem — тело ответа application/problem+json
+type Problem struct {
+	Type      string `json:"type"`
+	Title     string `json:"title"`
+	Status    int    `json:"status"`
+	Detail    string `json:"detail,omitempty"`
+	Instance  string `json:"instance,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// KindOf — возвращает вид ошибки для доменной ошибки; неизвестные ошибки считаются внутренними
+func KindOf(err error) Kind {
+	for _, known := range errorKinds {
+		if errors.Is(err, known.err) {
+			return known.kind
+		}
+	}
+	return Internal
+}
+
+// Write — отправляет ответ с ошибкой указанного вида; detail поясняет конкретный случай и может быть пустым
+func Write(w http.ResponseWriter, r *http.Request, kind Kind, detail string) {
+	body := Problem{
+		Type:      kind.Type(),
+		Title:     kind.Title,
+		Status:    kind.Status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		RequestID: middleware.GetReqID(r.Context()),
+	}
+
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(kind.Status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// Error — отправляет ответ, соответствующий доменной ошибке. Текст внутренних ошибок клиенту не раскрывается.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	kind := KindOf(err)
+	if kind == Internal {
+		Write(w, r, kind, "")
+		return
+	}
+	Write(w, r, kind, err.Error())
+}
+
+// InvalidBody — отправляет ответ на ошибку чтения или разбора тела запроса: 413, если тело превысило ограничение
+// размера, иначе 400
+func InvalidBody(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		Write(w, r, PayloadTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", tooLarge.Limit))
+		return
+	}
+	Write(w, r, BadRequest, "Invalid request format")
+}
@@ -0,0 +1,96 @@
+package problem
+
+import (
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestError(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedBody string
+		expectedCode int
+	}{
+		{
+			name:         "Wrapped_Sentinel_Error",
+			err:          fmt.Errorf("withdraw: %w", gofermartErrors.ErrInsufficientFunds),
+			expectedBody: `{"type":"/problems/insufficient-funds","title":"Insufficient funds","status":402,"detail":"withdraw: insufficient funds","instance":"/api/user/balance/withdraw"}` + "\n",
+			expectedCode: http.StatusPaymentRequired,
+		},
+		{
+			name:         "Order_Number_Validation_Error",
+			err:          &validation.OrderNumberError{Rule: validation.RuleLuhn, Reason: "order number checksum is invalid"},
+			expectedBody: `{"type":"/problems/invalid-order-number","title":"Invalid order number","status":422,"detail":"order number checksum is invalid","instance":"/api/user/balance/withdraw"}` + "\n",
+			expectedCode: http.StatusUnprocessableEntity,
+		},
+		{
+			name:         "Unknown_Error_Is_Not_Disclosed",
+			err:          errors.New("pq: connection refused"),
+			expectedBody: `{"type":"/problems/internal","title":"Internal Server Error","status":500,"instance":"/api/user/balance/withdraw"}` + "\n",
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/user/balance/withdraw", nil)
+			rr := httptest.NewRecorder()
+
+			Error(rr, req, tc.err)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != ContentType {
+				t.Errorf("expected Content-Type %q, got %q", ContentType, contentType)
+			}
+			if rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
+
+func TestInvalidBody(t *testing.T) {
+	testCases := []struct {
+		name         string
+		err          error
+		expectedBody string
+		expectedCode int
+	}{
+		{
+			name:         "Malformed_Body",
+			err:          errors.New("unexpected EOF"),
+			expectedBody: `{"type":"/problems/bad-request","title":"Bad Request","status":400,"detail":"Invalid request format","instance":"/api/user/login"}` + "\n",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Body_Over_Limit",
+			err:          fmt.Errorf("decode: %w", &http.MaxBytesError{Limit: 1024}),
+			expectedBody: `{"type":"/problems/payload-too-large","title":"Payload Too Large","status":413,"detail":"Request body must not exceed 1024 bytes","instance":"/api/user/login"}` + "\n",
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/user/login", nil)
+			rr := httptest.NewRecorder()
+
+			InvalidBody(rr, req, tc.err)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if rr.Body.String() != tc.expectedBody {
+				t.Errorf("expected body %q, got %q", tc.expectedBody, rr.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"github.com/go-chi/jwtauth/v5"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// rateLimitKey - возвращает ключ, по которому считаются запросы
+type rateLimitKey func(r *http.Request) string
+
+// clientIPKey - запросы считаются по IP-адресу клиента; адрес сохраняет auditRequestInfo
+func clientIPKey(r *http.Request) string {
+	return "ip:" + domain.RequestInfoFromContext(r.Context()).IP
+}
+
+// userKey - запросы считаются по пользователю из токена, а без него — по IP-адресу клиента
+func userKey(r *http.Request) string {
+	_, claims, _ := jwtauth.FromContext(r.Context())
+	if principal, ok := utils.PrincipalFromClaims(claims); ok {
+		return "user:" + strconv.Itoa(principal.UserID)
+	}
+	return clientIPKey(r)
+}
+
+// rateLimit - ограничивает частоту запросов группы маршрутов. Ответ содержит заголовки X-RateLimit-Limit,
+// X-RateLimit-Remaining и X-RateLimit-Reset (Unix-время полного восстановления); сверх ограничения
+// возвращается 429 с Retry-After в секундах. Без ограничителя запросы пропускаются как есть.
+func rateLimit(limiter *services.RateLimiter, group string, key rateLimitKey) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			decision, limited := limiter.Allow(r.Context(), group, key(r))
+			if limited {
+				w.Header().Set("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+				w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+				w.Header().Set("X-RateLimit-Reset", strconv.FormatInt(time.Now().Add(decision.Reset).Unix(), 10))
+			}
+			if !decision.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Max(math.Ceil(decision.RetryAfter.Seconds()), 1))))
+				problem.Write(w, r, problem.RateLimited, "")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"github.com/go-chi/jwtauth/v5"
+	"go.uber.org/zap"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// rateLimitRequest - запрос от клиента с адресом ip; userID > 0 добавляет в контекст токен пользователя
+func rateLimitRequest(t *testing.T, ip string, userID int) *http.Request {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	ctx := domain.WithRequestInfo(req.Context(), domain.RequestInfo{IP: ip})
+	if userID > 0 {
+		tokenAuth := jwtauth.New("HS256", []byte("secret"), nil)
+		_, tokenString, err := tokenAuth.Encode(map[string]interface{}{utils.UserIDClaim: userID, utils.UsernameClaim: "user"})
+		if err != nil {
+			t.Fatalf("failed to encode token: %v", err)
+		}
+		token, err := jwtauth.VerifyToken(tokenAuth, tokenString)
+		if err != nil {
+			t.Fatalf("failed to verify token: %v", err)
+		}
+		ctx = jwtauth.NewContext(ctx, token, nil)
+	}
+	return req.WithContext(ctx)
+}
+
+func TestRateLimit(t *testing.T) {
+	type request struct {
+		ip     string
+		userID int
+	}
+
+	testCases := []struct {
+		name          string
+		policy        string
+		key           rateLimitKey
+		requests      []request
+		expectedCodes []int
+		expectHeaders bool
+	}{
+		{
+			name:          "Requests_Over_Limit_Are_Rejected",
+			policy:        "2/1m",
+			key:           clientIPKey,
+			requests:      []request{{ip: "10.0.0.1"}, {ip: "10.0.0.1"}, {ip: "10.0.0.1"}},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expectHeaders: true,
+		},
+		{
+			name:          "Client_IPs_Are_Limited_Separately",
+			policy:        "1/1m",
+			key:           clientIPKey,
+			requests:      []request{{ip: "10.0.0.1"}, {ip: "10.0.0.2"}, {ip: "10.0.0.1"}},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expectHeaders: true,
+		},
+		{
+			name:          "Users_Behind_One_IP_Are_Limited_Separately",
+			policy:        "1/1m",
+			key:           userKey,
+			requests:      []request{{ip: "10.0.0.1", userID: 1}, {ip: "10.0.0.1", userID: 2}, {ip: "10.0.0.2", userID: 1}},
+			expectedCodes: []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests},
+			expectHeaders: true,
+		},
+		{
+			name:          "User_Key_Falls_Back_To_Client_IP",
+			policy:        "1/1m",
+			key:           userKey,
+			requests:      []request{{ip: "10.0.0.1"}, {ip: "10.0.0.1"}},
+			expectedCodes: []int{http.StatusOK, http.StatusTooManyRequests},
+			expectHeaders: true,
+		},
+		{
+			name:          "Disabled_Policy_Passes_Requests",
+			policy:        "0",
+			key:           clientIPKey,
+			requests:      []request{{ip: "10.0.0.1"}, {ip: "10.0.0.1"}},
+			expectedCodes: []int{http.StatusOK, http.StatusOK},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := domain.ParseRateLimitPolicy(tc.policy)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			limiter := services.NewRateLimiter(repository.NewRateLimitRepositoryMemory(), map[string]domain.RateLimitPolicy{domain.RateLimitAPI: policy}, zap.NewNop())
+			handler := rateLimit(limiter, domain.RateLimitAPI, tc.key)(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			for i, req := range tc.requests {
+				rr := httptest.NewRecorder()
+				handler.ServeHTTP(rr, rateLimitRequest(t, req.ip, req.userID))
+
+				if rr.Code != tc.expectedCodes[i] {
+					t.Errorf("request %d: expected status %d, got %d", i, tc.expectedCodes[i], rr.Code)
+				}
+				if hasLimit := rr.Header().Get("X-RateLimit-Limit") != ""; hasLimit != tc.expectHeaders {
+					t.Errorf("request %d: expected rate limit headers: %v, got %v", i, tc.expectHeaders, rr.Header())
+				}
+				if rr.Code != http.StatusTooManyRequests {
+					continue
+				}
+				if rr.Header().Get("Retry-After") == "" {
+					t.Errorf("request %d: expected Retry-After header", i)
+				}
+				if remaining := rr.Header().Get("X-RateLimit-Remaining"); remaining != "0" {
+					t.Errorf("request %d: expected no remaining requests, got %q", i, remaining)
+				}
+				if contentType := rr.Header().Get("Content-Type"); contentType != "application/problem+json" {
+					t.Errorf("request %d: unexpected content type: %s", i, contentType)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimit_WithoutLimiter(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := rateLimit(nil, domain.RateLimitAuth, clientIPKey)(next)
+
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		handler.ServeHTTP(rr, rateLimitRequest(t, "10.0.0.1", 0))
+		if rr.Code != http.StatusNoContent {
+			t.Fatalf("expected status %d, got %d", http.StatusNoContent, rr.Code)
+		}
+	}
+}
+
+func TestRateLimit_Headers(t *testing.T) {
+	limiter := services.NewRateLimiter(repository.NewRateLimitRepositoryMemory(), map[string]domain.RateLimitPolicy{
+		domain.RateLimitAuth: {Requests: 1, Period: time.Hour},
+	}, zap.NewNop())
+	handler := rateLimit(limiter, domain.RateLimitAuth, clientIPKey)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+
+	testCases := []struct {
+		name               string
+		expectedCode       int
+		expectedRemaining  string
+		expectedRetryAfter string
+	}{
+		{name: "Allowed_Request", expectedCode: http.StatusOK, expectedRemaining: "0"},
+		{name: "Rejected_Request", expectedCode: http.StatusTooManyRequests, expectedRemaining: "0", expectedRetryAfter: "3600"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, rateLimitRequest(t, "10.0.0.1", 0))
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, rr.Code)
+			}
+			if limit := rr.Header().Get("X-RateLimit-Limit"); limit != "1" {
+				t.Errorf("expected limit 1, got %q", limit)
+			}
+			if remaining := rr.Header().Get("X-RateLimit-Remaining"); remaining != tc.expectedRemaining {
+				t.Errorf("expected remaining %q, got %q", tc.expectedRemaining, remaining)
+			}
+			if retryAfter := rr.Header().Get("Retry-After"); retryAfter != tc.expectedRetryAfter {
+				t.Errorf("expected Retry-After %q, got %q", tc.expectedRetryAfter, retryAfter)
+			}
+			reset, err := strconv.ParseInt(rr.Header().Get("X-RateLimit-Reset"), 10, 64)
+			if err != nil {
+				t.Fatalf("invalid X-RateLimit-Reset: %v", err)
+			}
+			if until := time.Until(time.Unix(reset, 0)); until < 59*time.Minute || until > time.Hour {
+				t.Errorf("expected reset in about an hour, got %v", until)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package httpserver
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// Типы содержимого тела запроса
+const (
+	contentTypeJSON = "application/json"
+	contentTypeText = "text/plain"
+)
+
+// requestBody - проверяет тип содержимого тела запроса и ограничивает его размер
+func requestBody(maxBytes int64, mediaTypes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return requireContentType(mediaTypes...)(limitBody(maxBytes)(next))
+	}
+}
+
+// limitBody - ограничивает размер тела запроса maxBytes байтами. Запрос с большим Content-Length отклоняется
+// сразу, иначе обработчик получает *http.MaxBytesError при чтении сверх ограничения и отвечает 413.
+// Ограничение не применяется, если maxBytes не больше нуля.
+func limitBody(maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if maxBytes <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				problem.Write(w, r, problem.PayloadTooLarge, fmt.Sprintf("Request body must not exceed %d bytes", maxBytes))
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requireContentType - пропускает только запросы с телом одного из типов mediaTypes (параметры вроде charset
+// не учитываются); на остальные отвечает 415 с допустимыми типами в заголовке Accept-Post
+func requireContentType(mediaTypes ...string) func(http.Handler) http.Handler {
+	acceptPost := strings.Join(mediaTypes, ", ")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+			if err == nil {
+				for _, allowed := range mediaTypes {
+					if mediaType == allowed {
+						next.ServeHTTP(w, r)
+						return
+					}
+				}
+			}
+			w.Header().Set("Accept-Post", acceptPost)
+			problem.Write(w, r, problem.UnsupportedMediaType, "Content-Type must be "+strings.Join(mediaTypes, " or "))
+		})
+	}
+}
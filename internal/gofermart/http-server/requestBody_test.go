@@ -0,0 +1,117 @@
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestBody(t *testing.T) {
+	testCases := []struct {
+		name               string
+		maxBytes           int64
+		mediaTypes         []string
+		contentType        string
+		body               string
+		hideLength         bool
+		expectedCode       int
+		expectedAcceptPost string
+	}{
+		{
+			name:         "Allowed_Content_Type",
+			maxBytes:     16,
+			mediaTypes:   []string{contentTypeJSON},
+			contentType:  "application/json",
+			body:         `{}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Content_Type_Parameters_Are_Ignored",
+			maxBytes:     16,
+			mediaTypes:   []string{contentTypeJSON},
+			contentType:  "Application/JSON; charset=utf-8",
+			body:         `{}`,
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "One_Of_Several_Content_Types",
+			maxBytes:     16,
+			mediaTypes:   []string{contentTypeJSON, contentTypeText},
+			contentType:  "text/plain",
+			body:         "12345678903",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:               "Wrong_Content_Type",
+			maxBytes:           16,
+			mediaTypes:         []string{contentTypeText},
+			contentType:        "application/json",
+			body:               `"12345678903"`,
+			expectedCode:       http.StatusUnsupportedMediaType,
+			expectedAcceptPost: "text/plain",
+		},
+		{
+			name:               "Missing_Content_Type",
+			maxBytes:           16,
+			mediaTypes:         []string{contentTypeJSON, contentTypeText},
+			body:               `{}`,
+			expectedCode:       http.StatusUnsupportedMediaType,
+			expectedAcceptPost: "application/json, text/plain",
+		},
+		{
+			name:         "Content_Length_Over_Limit",
+			maxBytes:     16,
+			mediaTypes:   []string{contentTypeText},
+			contentType:  "text/plain",
+			body:         strings.Repeat("1", 17),
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "Chunked_Body_Over_Limit",
+			maxBytes:     16,
+			mediaTypes:   []string{contentTypeText},
+			contentType:  "text/plain",
+			body:         strings.Repeat("1", 17),
+			hideLength:   true,
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:         "Zero_Limit_Disables_Check",
+			mediaTypes:   []string{contentTypeText},
+			contentType:  "text/plain",
+			body:         strings.Repeat("1", 1024),
+			expectedCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := requestBody(tc.maxBytes, tc.mediaTypes...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if _, err := io.ReadAll(r.Body); err != nil {
+					w.WriteHeader(http.StatusRequestEntityTooLarge)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tc.body))
+			if tc.contentType != "" {
+				req.Header.Set("Content-Type", tc.contentType)
+			}
+			if tc.hideLength {
+				req.ContentLength = -1
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %d, got %d", tc.expectedCode, rr.Code)
+			}
+			if acceptPost := rr.Header().Get("Accept-Post"); acceptPost != tc.expectedAcceptPost {
+				t.Errorf("expected Accept-Post %q, got %q", tc.expectedAcceptPost, acceptPost)
+			}
+		})
+	}
+}
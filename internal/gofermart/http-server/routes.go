@@ -1,38 +1,115 @@
 package httpserver
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/api/admin"
 	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/api/user"
 	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/api/user/balance"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/handlers/api/user/webhooks"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/openapi"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
 	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-	"github.com/go-chi/jwtauth/v5"
 	"go.uber.org/zap"
+	"net/http"
 )
 
+// defaultGzipLevel - уровень сжатия ответов, если он не задан конфигурацией
+const defaultGzipLevel = 5
+
 // RegisterRoutes регистрирует роуты приложения
-func RegisterRoutes(r *chi.Mux, appServices *services.AppServices, logger *zap.Logger) {
-	JWTAuth := appServices.AuthService.GetTokenAuth()
-	compressMiddleware := middleware.Compress(5, "gzip", "deflate")
-	usernameExtractor := &utils.RealUsernameExtractor{}
+func RegisterRoutes(r *chi.Mux, appServices *services.AppServices, options Options, logger *zap.Logger) {
+	gzipLevel := options.GzipLevel
+	if gzipLevel == 0 {
+		gzipLevel = defaultGzipLevel
+	}
+	compressMiddleware := middleware.Compress(gzipLevel, "gzip", "deflate")
+	principalExtractor := &utils.RealPrincipalExtractor{}
+	jsonBody := requestBody(options.MaxBodySize, contentTypeJSON)
+
+	if options.Metrics != nil {
+		r.Use(options.Metrics.Middleware)
+	}
+
+	r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.NotFound, "")
+	})
+	r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+		problem.Write(w, r, problem.MethodNotAllowed, "")
+	})
 
 	r.Route("/api", func(r chi.Router) {
-		r.Route("/user", func(r chi.Router) {
-			r.Post("/register", user.NewRegisterPostHandler(appServices.AuthService, logger).ServeHTTP)
-			r.Post("/login", user.NewLoginPostHandler(appServices.AuthService, logger).ServeHTTP)
-
-			r.Group(func(r chi.Router) {
-				r.Use(jwtauth.Verifier(JWTAuth))
-				r.Use(jwtauth.Authenticator(JWTAuth))
-
-				r.Post("/orders", user.NewOrdersPostHandler(appServices.OrderService, usernameExtractor, logger).ServeHTTP)
-				r.With(compressMiddleware).Get("/orders", user.NewOrdersGetHandler(appServices.OrderService, usernameExtractor, logger).ServeHTTP)
-				r.Route("/balance", func(r chi.Router) {
-					r.With(compressMiddleware).Get("/", balance.NewIndexGetHandler(appServices.UserService, usernameExtractor, logger).ServeHTTP)
-					r.Post("/withdraw", balance.NewWithdrawPostHandler(appServices.UserService, usernameExtractor, logger).ServeHTTP)
+		r.Use(middleware.RequestID, exposeRequestID, auditRequestInfo)
+
+		// Поток событий остаётся открытым, пока клиент подключён, поэтому ограничение времени обработки запроса к нему не применяется
+		r.With(verifier(appServices.AuthService), authenticator).Get("/user/orders/stream", user.NewOrdersStreamGetHandler(appServices.OrderUpdates, principalExtractor, options.OrderStreamHeartbeat, logger).ServeHTTP)
+		// Выписка за большой период выгружается потоком дольше ограничения времени обработки запроса
+		r.With(verifier(appServices.AuthService), authenticator, rateLimit(appServices.RateLimiter, domain.RateLimitAPI, userKey), compressMiddleware).Get("/user/statement", user.NewStatementGetHandler(appServices.StatementService, principalExtractor, logger).ServeHTTP)
+
+		r.Group(func(r chi.Router) {
+			r.Use(requestTimeout(options.RequestTimeout))
+
+			r.Get("/openapi.json", openapi.Handler)
+			if options.SwaggerUI {
+				r.Get("/docs", openapi.UIHandler)
+				r.Get("/docs/{asset}", openapi.AssetHandler)
+			}
+
+			r.Route("/user", func(r chi.Router) {
+				// Регистрация и вход ограничиваются строже остальных запросов и по IP-адресу: пользователь ещё не известен
+				authRateLimit := rateLimit(appServices.RateLimiter, domain.RateLimitAuth, clientIPKey)
+				r.With(authRateLimit, jsonBody).Post("/register", user.NewRegisterPostHandler(appServices.AuthService, logger).ServeHTTP)
+				r.With(authRateLimit, jsonBody).Post("/login", user.NewLoginPostHandler(appServices.AuthService, logger).ServeHTTP)
+
+				r.Group(func(r chi.Router) {
+					r.Use(verifier(appServices.AuthService))
+					r.Use(authenticator)
+					r.Use(rateLimit(appServices.RateLimiter, domain.RateLimitAPI, userKey))
+
+					// Загрузка заказов обращается к хранилищу и системе начислений, поэтому ограничивается дополнительно
+					ordersRateLimit := rateLimit(appServices.RateLimiter, domain.RateLimitOrders, userKey)
+					// Чтения баланса, заказов и выводов отвечают 304, если данные пользователя не менялись
+					notModified := conditionalGet(appServices.UserService)
+					r.With(ordersRateLimit, requestBody(options.MaxBodySize, contentTypeText)).Post("/orders", user.NewOrdersPostHandler(appServices.OrderService, principalExtractor, appServices.OrderValidator, logger).ServeHTTP)
+					r.With(ordersRateLimit, RequireRole(domain.RolePartner), requestBody(options.OrdersBatchMaxBodySize, contentTypeJSON, contentTypeText)).Post("/orders/batch", user.NewOrdersBatchPostHandler(appServices.OrderService, principalExtractor, options.OrdersBatchLimit, logger).ServeHTTP)
+					r.With(notModified, compressMiddleware).Get("/orders", user.NewOrdersGetHandler(appServices.OrderService, principalExtractor, logger).ServeHTTP)
+					r.Route("/balance", func(r chi.Router) {
+						r.With(notModified, compressMiddleware).Get("/", balance.NewIndexGetHandler(appServices.UserService, principalExtractor, logger).ServeHTTP)
+						r.With(jsonBody).Post("/withdraw", balance.NewWithdrawPostHandler(appServices.UserService, principalExtractor, appServices.OrderValidator, logger).ServeHTTP)
+					})
+					r.With(notModified, compressMiddleware).Get("/withdrawals", user.NewWithdrawalsGetHandler(appServices.UserService, principalExtractor, logger).ServeHTTP)
+					r.Get("/statements/{period}", user.NewStatementsPeriodGetHandler(appServices.StatementService, principalExtractor, logger).ServeHTTP)
+					r.Route("/webhooks", func(r chi.Router) {
+						r.With(jsonBody).Post("/", webhooks.NewIndexPostHandler(appServices.WebhookService, principalExtractor, logger).ServeHTTP)
+						r.Get("/", webhooks.NewIndexGetHandler(appServices.WebhookService, principalExtractor, logger).ServeHTTP)
+						r.Delete("/{id}", webhooks.NewWebhookDeleteHandler(appServices.WebhookService, principalExtractor, logger).ServeHTTP)
+						r.Get("/{id}/deliveries", webhooks.NewDeliveriesGetHandler(appServices.WebhookService, principalExtractor, logger).ServeHTTP)
+					})
+				})
+			})
+
+			// Операции поддержки доступны сотрудникам поддержки и администраторам, управление ролями и журнал аудита —
+			// только администраторам; роли и блокировка проверяются по хранилищу при каждом запросе
+			r.Route("/admin", func(r chi.Router) {
+				r.Use(verifier(appServices.AuthService))
+				r.Use(authenticator, currentPrincipal(appServices.UserService), RequireRole(domain.RoleSupport, domain.RoleAdmin))
+				r.Use(rateLimit(appServices.RateLimiter, domain.RateLimitAPI, userKey))
+
+				loginExtractor := admin.LoginParamExtractor{UserService: appServices.UserService}
+
+				r.Route("/users/{login}", func(r chi.Router) {
+					r.Get("/", admin.NewUserGetHandler(appServices.UserService, logger).ServeHTTP)
+					r.Get("/orders", user.NewOrdersGetHandler(appServices.OrderService, loginExtractor, logger).ServeHTTP)
+					r.Get("/withdrawals", user.NewWithdrawalsGetHandler(appServices.UserService, loginExtractor, logger).ServeHTTP)
+					r.With(jsonBody).Post("/adjustments", admin.NewUserAdjustmentsPostHandler(appServices.UserService, principalExtractor, logger).ServeHTTP)
+					r.Put("/block", admin.NewUserBlockHandler(appServices.UserService, principalExtractor, true, logger).ServeHTTP)
+					r.Delete("/block", admin.NewUserBlockHandler(appServices.UserService, principalExtractor, false, logger).ServeHTTP)
+					r.With(RequireRole(domain.RoleAdmin), jsonBody).Put("/roles", admin.NewUserRolesPutHandler(appServices.UserService, principalExtractor, logger).ServeHTTP)
 				})
-				r.With(compressMiddleware).Get("/withdrawals", user.NewWithdrawalsGetHandler(appServices.UserService, usernameExtractor, logger).ServeHTTP)
+				r.Post("/orders/{number}/requeue", admin.NewOrderRequeuePostHandler(appServices.OrderService, logger).ServeHTTP)
+				r.With(RequireRole(domain.RoleAdmin)).Get("/audit", admin.NewAuditGetHandler(appServices.AuditService, logger).ServeHTTP)
 			})
 		})
 	})
@@ -1,8 +1,14 @@
 package httpserver
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/http-server/problem"
 	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"encoding/json"
 	"github.com/go-chi/chi/v5"
 	"github.com/golang/mock/gomock"
 	"go.uber.org/zap"
@@ -10,6 +16,7 @@ import (
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestRegisterRoutes(t *testing.T) {
@@ -20,18 +27,19 @@ func TestRegisterRoutes(t *testing.T) {
 	defer ctrl.Finish()
 
 	appServices := &services.AppServices{
-		UserService:  services.NewUserService(mockUserRepo, mockWithdrawalRepo, logger),
-		AuthService:  services.NewAuthService([]byte("secret"), mockUserRepo, logger),
+		UserService:  services.NewUserService(mocks.NewMockTxManager(ctrl), mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), logger),
+		AuthService:  services.NewAuthService([]byte("secret"), 0, mocks.NewMockTxManager(ctrl), mockUserRepo, mocks.NewMockAuditRepository(ctrl), logger),
 		OrderService: mocks.NewMockOrderServiceInterface(ctrl),
 	}
 
 	r := chi.NewRouter()
-	RegisterRoutes(r, appServices, logger)
+	RegisterRoutes(r, appServices, Options{}, logger)
 
 	testCases := []struct {
 		name           string
 		method         string
 		path           string
+		contentType    string
 		body           string
 		expectedCode   int
 		expectedBody   string
@@ -41,14 +49,31 @@ func TestRegisterRoutes(t *testing.T) {
 			name:         "Post_Api_User_Register",
 			method:       http.MethodPost,
 			path:         "/api/user/register",
+			contentType:  "application/json",
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name:         "Post_Api_User_Register_Without_Content_Type",
+			method:       http.MethodPost,
+			path:         "/api/user/register",
+			body:         `{"login": "user", "password": "password"}`,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
 		{
 			name:         "Post_Api_User_Login",
 			method:       http.MethodPost,
 			path:         "/api/user/login",
+			contentType:  "application/json; charset=utf-8",
 			expectedCode: http.StatusBadRequest,
 		},
+		{
+			name:         "Post_Api_User_Login_Text_Body",
+			method:       http.MethodPost,
+			path:         "/api/user/login",
+			contentType:  "text/plain",
+			body:         `{"login": "user", "password": "password"}`,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
 		{
 			name:         "Post_Api_User_Orders",
 			method:       http.MethodPost,
@@ -61,6 +86,12 @@ func TestRegisterRoutes(t *testing.T) {
 			path:         "/api/user/orders",
 			expectedCode: http.StatusUnauthorized,
 		},
+		{
+			name:         "Get_Api_User_Orders_Stream",
+			method:       http.MethodGet,
+			path:         "/api/user/orders/stream",
+			expectedCode: http.StatusUnauthorized,
+		},
 		{
 			name:         "Get_Api_User_Balance",
 			method:       http.MethodGet,
@@ -87,6 +118,9 @@ func TestRegisterRoutes(t *testing.T) {
 			if err != nil {
 				t.Fatalf("could not create request: %v", err)
 			}
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
 
 			rr := httptest.NewRecorder()
 			r.ServeHTTP(rr, req)
@@ -103,3 +137,324 @@ func TestRegisterRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestRegisterRoutes_RequestContextCancellation(t *testing.T) {
+	logger := zap.NewNop()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	const credentials = `{"login": "user", "password": "password"}`
+
+	type testCase struct {
+		name    string
+		options Options
+		ctx     func() (context.Context, context.CancelFunc)
+	}
+
+	testCases := []testCase{
+		{
+			name:    "Cancelled_Client_Request",
+			options: Options{},
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+		},
+		{
+			name:    "Request_Deadline_Exceeded",
+			options: Options{RequestTimeout: time.Nanosecond},
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := storage.NewMemoryStorage(logger)
+			appServices := &services.AppServices{
+				UserService:    services.NewUserService(store.TxManager, store.UserRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.AuditRepo, store.OutboxRepo, logger),
+				AuthService:    services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+				OrderService:   services.NewOrderService(mocks.NewMockAccrualService(ctrl), store.TxManager, store.OrderRepo, store.UserRepo, store.AuditRepo, store.OutboxRepo, store.WebhookRepo, store.NotifyRepo, validation.LuhnValidator{}, logger),
+				OrderValidator: validation.LuhnValidator{},
+			}
+
+			r := chi.NewRouter()
+			RegisterRoutes(r, appServices, tc.options, logger)
+
+			ctx, cancel := tc.ctx()
+			defer cancel()
+
+			req := httptest.NewRequest(http.MethodPost, "/api/user/register", strings.NewReader(credentials)).WithContext(ctx)
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusInternalServerError {
+				t.Errorf("expected status %v, got %v", http.StatusInternalServerError, rr.Code)
+			}
+
+			// Прерванный запрос не должен был сохранить пользователя
+			if _, err := store.UserRepo.GetUserByLogin(context.Background(), "user"); err == nil {
+				t.Error("expected user not to be saved after the request was aborted")
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_OrderStreamIgnoresRequestTimeout(t *testing.T) {
+	logger := zap.NewNop()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMemoryStorage(logger)
+	appServices := &services.AppServices{
+		AuthService:  services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+		OrderService: mocks.NewMockOrderServiceInterface(ctrl),
+		OrderUpdates: services.NewOrderUpdateBroker(services.OrderUpdateBrokerOptions{}, logger),
+	}
+
+	r := chi.NewRouter()
+	RegisterRoutes(r, appServices, Options{RequestTimeout: time.Millisecond}, logger)
+
+	token, err := appServices.AuthService.GenerateJWT(context.Background(), domain.User{UserID: 1, Login: "user", Roles: domain.Roles{domain.RoleUser}})
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+
+	// Поток должен оставаться открытым дольше предельного времени обработки запроса
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req := httptest.NewRequest(http.MethodGet, "/api/user/orders/stream", nil).WithContext(ctx)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	started := time.Now()
+	stream := httptest.NewRecorder()
+	r.ServeHTTP(stream, req)
+
+	if stream.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, stream.Code, stream.Body.String())
+	}
+	if elapsed := time.Since(started); elapsed < 50*time.Millisecond {
+		t.Errorf("expected the stream to stay open until the client disconnects, closed after %v", elapsed)
+	}
+}
+
+func TestRegisterRoutes_JWTKeyRotation(t *testing.T) {
+	logger := zap.NewNop()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := storage.NewMemoryStorage(logger)
+	appServices := &services.AppServices{
+		AuthService:  services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+		OrderService: mocks.NewMockOrderServiceInterface(ctrl),
+		UserService:  services.NewUserService(store.TxManager, store.UserRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.AuditRepo, store.OutboxRepo, logger),
+	}
+	r := chi.NewRouter()
+	RegisterRoutes(r, appServices, Options{}, logger)
+
+	user, err := appServices.AuthService.RegisterUser(context.Background(), "user", "secret")
+	if err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+	issue := func() string {
+		token, err := appServices.AuthService.GenerateJWT(context.Background(), *user)
+		if err != nil {
+			t.Fatalf("failed to issue token: %v", err)
+		}
+		return token
+	}
+	// Раздел /api/admin недоступен роли user: 403 означает, что токен принят, 401 — что отклонён
+	status := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin/audit", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	oldToken := issue()
+	appServices.AuthService.UpdateKeys([]byte("rotated"), 0)
+	newToken := issue()
+	if code := status(oldToken); code != http.StatusForbidden {
+		t.Errorf("expected token signed with the previous key to be accepted, got %d", code)
+	}
+	if code := status(newToken); code != http.StatusForbidden {
+		t.Errorf("expected token signed with the new key to be accepted, got %d", code)
+	}
+
+	appServices.AuthService.UpdateKeys([]byte("rotated-again"), 0)
+	if code := status(oldToken); code != http.StatusUnauthorized {
+		t.Errorf("expected token signed with a retired key to be rejected, got %d", code)
+	}
+}
+
+func TestRegisterRoutes_AdminAccessFollowsStoredUser(t *testing.T) {
+	logger := zap.NewNop()
+	ctx := context.Background()
+
+	store := storage.NewMemoryStorage(logger)
+	appServices := &services.AppServices{
+		AuditService: services.NewAuditService(store.AuditRepo, logger),
+		AuthService:  services.NewAuthService([]byte("secret"), 0, store.TxManager, store.UserRepo, store.AuditRepo, logger),
+		UserService:  services.NewUserService(store.TxManager, store.UserRepo, store.WithdrawalRepo, store.AdjustmentRepo, store.AuditRepo, store.OutboxRepo, logger),
+	}
+	r := chi.NewRouter()
+	RegisterRoutes(r, appServices, Options{}, logger)
+
+	if _, err := appServices.AuthService.RegisterUser(ctx, "support", "secret"); err != nil {
+		t.Fatalf("failed to register user: %v", err)
+	}
+	if _, err := appServices.UserService.SetRoles(ctx, "support", []string{"user", "admin"}, "test"); err != nil {
+		t.Fatalf("failed to grant admin role: %v", err)
+	}
+	user, err := appServices.UserService.GetUser(ctx, "support")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	// Токен выпускается один раз и остаётся действительным на всех шагах: доступ меняется только хранилищем
+	token, err := appServices.AuthService.GenerateJWT(ctx, *user)
+	if err != nil {
+		t.Fatalf("failed to issue token: %v", err)
+	}
+
+	testCases := []struct {
+		name           string
+		change         func() error
+		expectedStatus int
+		expectedType   string
+	}{
+		{
+			name:           "Stored_Admin",
+			change:         func() error { return nil },
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Revoked_Role",
+			change: func() error {
+				_, err := appServices.UserService.SetRoles(ctx, "support", []string{"user"}, "test")
+				return err
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedType:   problem.Forbidden.Type(),
+		},
+		{
+			name: "Blocked_User",
+			change: func() error {
+				if _, err := appServices.UserService.SetRoles(ctx, "support", []string{"user", "admin"}, "test"); err != nil {
+					return err
+				}
+				return appServices.UserService.SetBlocked(ctx, "support", true, "test")
+			},
+			expectedStatus: http.StatusForbidden,
+			expectedType:   problem.UserBlocked.Type(),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.change(); err != nil {
+				t.Fatalf("failed to change user: %v", err)
+			}
+			req := httptest.NewRequest(http.MethodGet, "/api/admin/audit", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			if rec.Code != tc.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tc.expectedStatus, rec.Code, rec.Body.String())
+			}
+			if tc.expectedType == "" {
+				return
+			}
+			var body struct {
+				Type string `json:"type"`
+			}
+			if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if body.Type != tc.expectedType {
+				t.Errorf("expected problem type %q, got %q", tc.expectedType, body.Type)
+			}
+		})
+	}
+}
+
+func TestRegisterRoutes_ProblemResponses(t *testing.T) {
+	logger := zap.NewNop()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	appServices := &services.AppServices{
+		AuthService:  services.NewAuthService([]byte("secret"), 0, mocks.NewMockTxManager(ctrl), mocks.NewMockUserRepository(ctrl), mocks.NewMockAuditRepository(ctrl), logger),
+		OrderService: mocks.NewMockOrderServiceInterface(ctrl),
+	}
+	r := chi.NewRouter()
+	RegisterRoutes(r, appServices, Options{}, logger)
+
+	testCases := []struct {
+		name         string
+		method       string
+		path         string
+		requestID    string
+		expectedType string
+		expectedCode int
+	}{
+		{
+			name:         "Unauthorized_With_Client_Request_ID",
+			method:       http.MethodGet,
+			path:         "/api/user/orders",
+			requestID:    "client-request-1",
+			expectedType: "/problems/unauthorized",
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Unknown_Route",
+			method:       http.MethodGet,
+			path:         "/api/user/unknown",
+			expectedType: "/problems/not-found",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Method_Not_Allowed",
+			method:       http.MethodDelete,
+			path:         "/api/user/login",
+			expectedType: "/problems/method-not-allowed",
+			expectedCode: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(tc.method, tc.path, nil)
+			if tc.requestID != "" {
+				req.Header.Set(requestIDHeader, tc.requestID)
+			}
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tc.expectedCode {
+				t.Errorf("expected status %v, got %v", tc.expectedCode, rr.Code)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != problem.ContentType {
+				t.Errorf("expected Content-Type %q, got %q", problem.ContentType, contentType)
+			}
+
+			var body problem.Problem
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode problem: %v", err)
+			}
+			if body.Type != tc.expectedType || body.Status != tc.expectedCode || body.Instance != tc.path {
+				t.Errorf("unexpected problem: %+v", body)
+			}
+			if body.RequestID == "" || rr.Header().Get(requestIDHeader) != body.RequestID {
+				t.Errorf("expected request id in body and header, got %q and %q", body.RequestID, rr.Header().Get(requestIDHeader))
+			}
+			if tc.requestID != "" && body.RequestID != tc.requestID {
+				t.Errorf("expected client request id %q, got %q", tc.requestID, body.RequestID)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Hook — компонент приложения с действиями запуска и остановки
+type Hook struct {
+	Name string
+	// OnStart — запускает компонент и не должен блокироваться: длительная работа выполняется в фоне,
+	// а о её неустранимой ошибке сообщается через Manager.Fail. Ошибка запуска прерывает запуск приложения.
+	OnStart func(ctx context.Context) error
+	// OnStop — останавливает компонент, дожидаясь завершения его работы, пока не истёк ctx
+	OnStop func(ctx context.Context) error
+	// StopTimeout — предельное время остановки компонента; 0 — значение из Options
+	StopTimeout time.Duration
+}
+
+// Options — параметры менеджера жизненного цикла
+type Options struct {
+	// StopTimeout — предельное время остановки компонента по умолчанию; 0 отключает ограничение
+	StopTimeout time.Duration
+}
+
+// Manager — запускает компоненты приложения в порядке добавления и останавливает их в обратном порядке:
+// компоненты, добавленные позже, могут пользоваться добавленными раньше до самой своей остановки.
+type Manager struct {
+	hooks    []Hook
+	options  Options
+	failures chan error
+	logger   *zap.Logger
+}
+
+// New — создаёт менеджер жизненного цикла
+func New(options Options, logger *zap.Logger) *Manager {
+	return &Manager{
+		options:  options,
+		failures: make(chan error, 1),
+		logger:   logger,
+	}
+}
+
+// Append — добавляет компоненты; вызывается до Run
+func (m *Manager) Append(hooks ...Hook) {
+	m.hooks = append(m.hooks, hooks...)
+}
+
+// Fail — сообщает о неустранимой ошибке компонента, работающего в фоне; приложение начинает остановку
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failures <- err:
+	default:
+	}
+}
+
+// Run — запускает компоненты и ждёт отмены ctx (например, по сигналу) или ошибки одного из них, после чего
+// останавливает запущенные компоненты. Возвращает ошибку запуска или работы компонента вместе с ошибками остановки.
+func (m *Manager) Run(ctx context.Context) error {
+	var runErr error
+	started := 0
+	for _, hook := range m.hooks {
+		if hook.OnStart != nil {
+			m.logger.Info("Starting component", zap.String("component", hook.Name))
+			if err := hook.OnStart(ctx); err != nil {
+				runErr = fmt.Errorf("start %s: %w", hook.Name, err)
+				m.logger.Error("Failed to start component", zap.String("component", hook.Name), zap.Error(err))
+				break
+			}
+		}
+		started++
+	}
+
+	if runErr == nil {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("Shutdown requested")
+		case runErr = <-m.failures:
+			m.logger.Error("Component failed, shutting down", zap.Error(runErr))
+		}
+	}
+
+	return errors.Join(runErr, m.stop(started))
+}
+
+// stop — останавливает первые started компонентов в обратном порядке; ошибка одного не мешает остановке остальных
+func (m *Manager) stop(started int) error {
+	var errs []error
+	for i := started - 1; i >= 0; i-- {
+		hook := m.hooks[i]
+		if hook.OnStop == nil {
+			continue
+		}
+
+		timeout := hook.StopTimeout
+		if timeout <= 0 {
+			timeout = m.options.StopTimeout
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(context.Background(), timeout)
+		}
+
+		m.logger.Info("Stopping component", zap.String("component", hook.Name))
+		if err := hook.OnStop(ctx); err != nil {
+			m.logger.Error("Failed to stop component", zap.String("component", hook.Name), zap.Error(err))
+			errs = append(errs, fmt.Errorf("stop %s: %w", hook.Name, err))
+		}
+		cancel()
+	}
+	return errors.Join(errs...)
+}
+
+// HTTPServer — компонент HTTP-сервера. Адрес занимается при запуске, поэтому ошибка привязки прерывает запуск;
+// при остановке сервер перестаёт принимать соединения и дожидается завершения текущих запросов. Сервер с заданным
+// TLSConfig принимает только HTTPS и согласует HTTP/2; сертификат берётся из TLSConfig.
+func (m *Manager) HTTPServer(name string, server *http.Server) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", server.Addr)
+			if err != nil {
+				return err
+			}
+			m.logger.Info("Listening", zap.String("component", name), zap.String("address", listener.Addr().String()),
+				zap.Bool("tls", server.TLSConfig != nil))
+			go func() {
+				serve := server.Serve
+				if server.TLSConfig != nil {
+					serve = func(listener net.Listener) error { return server.ServeTLS(listener, "", "") }
+				}
+				if err := serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					m.Fail(fmt.Errorf("%s: %w", name, err))
+				}
+			}()
+			return nil
+		},
+		OnStop: server.Shutdown,
+	}
+}
+
+// Workers — компонент из фоновых goroutine. start запускает их, добавляя каждую в wg; при остановке их контекст
+// отменяется и ожидается завершение.
+func Workers(name string, start func(ctx context.Context, wg *sync.WaitGroup)) Hook {
+	var (
+		cancel context.CancelFunc
+		wg     sync.WaitGroup
+	)
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			start(ctx, &wg)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			cancel()
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Closer — компонент, которому нужна только остановка: закрытие подключения или файла
+func Closer(name string, closeFn func() error) Hook {
+	return Hook{
+		Name: name,
+		OnStop: func(context.Context) error {
+			return closeFn()
+		},
+	}
+}
@@ -0,0 +1,306 @@
+package lifecycle
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/signal"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// recorder — запоминает порядок вызовов действий компонентов
+type recorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *recorder) hook(name string, startErr, stopErr error) Hook {
+	return Hook{
+		Name: name,
+		OnStart: func(context.Context) error {
+			r.add("start " + name)
+			return startErr
+		},
+		OnStop: func(context.Context) error {
+			r.add("stop " + name)
+			return stopErr
+		},
+	}
+}
+
+func (r *recorder) add(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func TestManager_Run(t *testing.T) {
+	errStart := errors.New("start failed")
+	errStop := errors.New("stop failed")
+	errRuntime := errors.New("component crashed")
+
+	testCases := []struct {
+		name          string
+		hooks         func(r *recorder) []Hook
+		trigger       func(m *Manager, cancel context.CancelFunc)
+		expectedCalls []string
+		expectedErrs  []error
+	}{
+		{
+			name: "Stops_In_Reverse_Order_On_Cancel",
+			hooks: func(r *recorder) []Hook {
+				return []Hook{r.hook("db", nil, nil), r.hook("workers", nil, nil), r.hook("http", nil, nil)}
+			},
+			trigger:       func(m *Manager, cancel context.CancelFunc) { cancel() },
+			expectedCalls: []string{"start db", "start workers", "start http", "stop http", "stop workers", "stop db"},
+		},
+		{
+			name: "Component_Failure_Triggers_Shutdown",
+			hooks: func(r *recorder) []Hook {
+				return []Hook{r.hook("db", nil, nil), r.hook("http", nil, nil)}
+			},
+			trigger:       func(m *Manager, cancel context.CancelFunc) { m.Fail(errRuntime) },
+			expectedCalls: []string{"start db", "start http", "stop http", "stop db"},
+			expectedErrs:  []error{errRuntime},
+		},
+		{
+			name: "Start_Failure_Stops_Started_Components",
+			hooks: func(r *recorder) []Hook {
+				return []Hook{r.hook("db", nil, nil), r.hook("workers", errStart, nil), r.hook("http", nil, nil)}
+			},
+			trigger:       func(m *Manager, cancel context.CancelFunc) {},
+			expectedCalls: []string{"start db", "start workers", "stop db"},
+			expectedErrs:  []error{errStart},
+		},
+		{
+			name: "Stop_Failure_Does_Not_Skip_Other_Components",
+			hooks: func(r *recorder) []Hook {
+				return []Hook{r.hook("db", nil, nil), r.hook("http", nil, errStop)}
+			},
+			trigger:       func(m *Manager, cancel context.CancelFunc) { cancel() },
+			expectedCalls: []string{"start db", "start http", "stop http", "stop db"},
+			expectedErrs:  []error{errStop},
+		},
+		{
+			name: "Stop_Timeout",
+			hooks: func(r *recorder) []Hook {
+				return []Hook{{
+					Name:        "stuck",
+					StopTimeout: 10 * time.Millisecond,
+					OnStop: func(ctx context.Context) error {
+						r.add("stop stuck")
+						<-ctx.Done()
+						return ctx.Err()
+					},
+				}}
+			},
+			trigger:       func(m *Manager, cancel context.CancelFunc) { cancel() },
+			expectedCalls: []string{"stop stuck"},
+			expectedErrs:  []error{context.DeadlineExceeded},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &recorder{}
+			m := New(Options{StopTimeout: time.Second}, zap.NewNop())
+			m.Append(tc.hooks(r)...)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			tc.trigger(m, cancel)
+
+			err := m.Run(ctx)
+			for _, expected := range tc.expectedErrs {
+				if !errors.Is(err, expected) {
+					t.Errorf("expected error %v, got %v", expected, err)
+				}
+			}
+			if len(tc.expectedErrs) == 0 && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !slices.Equal(r.calls, tc.expectedCalls) {
+				t.Errorf("expected calls %v, got %v", tc.expectedCalls, r.calls)
+			}
+		})
+	}
+}
+
+// TestManager_SIGTERMDrainsInFlightRequest — SIGTERM во время обработки запроса: сервер перестаёт принимать
+// соединения, дожидается ответа на текущий запрос и только после этого останавливаются фоновые процессы
+func TestManager_SIGTERMDrainsInFlightRequest(t *testing.T) {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	defer stop()
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	var requestCompleted atomic.Bool
+	server := &http.Server{
+		Addr: freeAddress(t),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(entered)
+			<-release
+			_, _ = io.WriteString(w, "done")
+			requestCompleted.Store(true)
+		}),
+	}
+
+	workerStoppedAfterRequest := make(chan bool, 1)
+	m := New(Options{StopTimeout: 5 * time.Second}, zap.NewNop())
+	m.Append(
+		Workers("workers", func(ctx context.Context, wg *sync.WaitGroup) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-ctx.Done()
+				workerStoppedAfterRequest <- requestCompleted.Load()
+			}()
+		}),
+		m.HTTPServer("http", server),
+	)
+
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- m.Run(ctx)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	type result struct {
+		body string
+		err  error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		resp, err := getWithRetry(client, "http://"+server.Addr)
+		if err != nil {
+			inFlight <- result{err: err}
+			return
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		inFlight <- result{body: string(body), err: err}
+	}()
+
+	select {
+	case <-entered:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request did not reach the handler")
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatalf("failed to send SIGTERM: %v", err)
+	}
+
+	// Новые соединения отклоняются, пока текущий запрос ещё обрабатывается
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.Dial("tcp", server.Addr)
+		if err != nil {
+			break
+		}
+		_ = conn.Close()
+		if time.Now().After(deadline) {
+			t.Fatal("server kept accepting connections after SIGTERM")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	select {
+	case <-workerStoppedAfterRequest:
+		t.Fatal("workers were stopped before the in-flight request completed")
+	default:
+	}
+
+	close(release)
+	got := <-inFlight
+	if got.err != nil || got.body != "done" {
+		t.Errorf("expected in-flight request to complete, got %q, %v", got.body, got.err)
+	}
+	if err := <-runErr; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if !<-workerStoppedAfterRequest {
+		t.Error("expected workers to stop after the in-flight request completed")
+	}
+}
+
+func TestManager_HTTPServerTLS(t *testing.T) {
+	// Сертификат тестового сервера httptest, которому доверяет его клиент
+	certified := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certified.Close()
+	clientTLS := certified.Client().Transport.(*http.Transport).TLSClientConfig.Clone()
+
+	server := &http.Server{
+		Addr: freeAddress(t),
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, r.Proto)
+		}),
+		TLSConfig: &tls.Config{Certificates: certified.TLS.Certificates},
+	}
+	m := New(Options{StopTimeout: 5 * time.Second}, zap.NewNop())
+	m.Append(m.HTTPServer("https", server))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() {
+		runErr <- m.Run(ctx)
+	}()
+
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLS, ForceAttemptHTTP2: true}}
+	resp, err := getWithRetry(client, "https://"+server.Addr)
+	if err != nil {
+		t.Fatalf("HTTPS request failed: %v", err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil || string(body) != "HTTP/2.0" {
+		t.Errorf("expected the request to be served over HTTP/2, got %q, %v", body, err)
+	}
+
+	// На HTTP-запрос к HTTPS-серверу net/http отвечает 400 без вызова обработчика
+	if resp, err := (&http.Client{}).Get("http://" + server.Addr); err == nil {
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("expected plain HTTP request to be rejected, got %d", resp.StatusCode)
+		}
+	}
+
+	cancel()
+	if err := <-runErr; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// freeAddress — возвращает свободный локальный адрес
+func freeAddress(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to find a free port: %v", err)
+	}
+	defer listener.Close()
+	return listener.Addr().String()
+}
+
+// getWithRetry — выполняет GET, повторяя попытку, пока сервер ещё не начал принимать соединения
+func getWithRetry(client *http.Client, url string) (*http.Response, error) {
+	var lastErr error
+	for attempt := 0; attempt < 100; attempt++ {
+		resp, err := client.Get(url)
+		if err == nil {
+			return resp, nil
+		}
+		lastErr = err
+		time.Sleep(10 * time.Millisecond)
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,167 @@
+package metrics
+
+import (
+	"fmt"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"io"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// unmatchedRoute - значение метки route для запросов, не совпавших ни с одним маршрутом
+const unmatchedRoute = "unmatched"
+
+// durationBuckets - границы корзин гистограммы длительности запросов, в секундах
+var durationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry - метрики HTTP-сервера и процесса в текстовом формате Prometheus
+type Registry struct {
+	mu       sync.Mutex
+	started  time.Time
+	requests map[requestKey]*requestStats
+}
+
+// requestKey - метки метрик запроса
+type requestKey struct {
+	method string
+	route  string
+	status int
+}
+
+// requestStats - количество запросов и гистограмма их длительности
+type requestStats struct {
+	count   uint64
+	sum     float64
+	buckets []uint64
+}
+
+// NewRegistry - создает реестр метрик
+func NewRegistry() *Registry {
+	return &Registry{
+		started:  time.Now(),
+		requests: make(map[requestKey]*requestStats),
+	}
+}
+
+// Middleware - учитывает запросы по методу, шаблону маршрута chi и статусу ответа. Метка route содержит шаблон
+// (/api/user/statements/{period}), а не путь запроса, поэтому число рядов метрик не зависит от параметров пути.
+func (m *Registry) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		route := unmatchedRoute
+		if routeContext := chi.RouteContext(r.Context()); routeContext != nil && routeContext.RoutePattern() != "" {
+			route = routeContext.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+		m.Observe(r.Method, route, status, time.Since(started))
+	})
+}
+
+// Observe - учитывает обработанный запрос
+func (m *Registry) Observe(method, route string, status int, duration time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := requestKey{method: method, route: route, status: status}
+	stats, ok := m.requests[key]
+	if !ok {
+		stats = &requestStats{buckets: make([]uint64, len(durationBuckets))}
+		m.requests[key] = stats
+	}
+
+	seconds := duration.Seconds()
+	stats.count++
+	stats.sum += seconds
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			stats.buckets[i]++
+		}
+	}
+}
+
+// ServeHTTP - отдает метрики в текстовом формате Prometheus
+func (m *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = m.Write(w)
+}
+
+// Write - записывает метрики в текстовом формате Prometheus
+func (m *Registry) Write(w io.Writer) error {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	var b strings.Builder
+	writeHeader(&b, "process_uptime_seconds", "gauge", "Time since the process started.")
+	fmt.Fprintf(&b, "process_uptime_seconds %s\n", formatFloat(time.Since(m.started).Seconds()))
+	writeHeader(&b, "go_goroutines", "gauge", "Number of goroutines that currently exist.")
+	fmt.Fprintf(&b, "go_goroutines %d\n", runtime.NumGoroutine())
+	writeHeader(&b, "go_memstats_heap_alloc_bytes", "gauge", "Number of heap bytes allocated and still in use.")
+	fmt.Fprintf(&b, "go_memstats_heap_alloc_bytes %d\n", memStats.HeapAlloc)
+
+	m.mu.Lock()
+	keys := make([]requestKey, 0, len(m.requests))
+	for key := range m.requests {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		if keys[i].method != keys[j].method {
+			return keys[i].method < keys[j].method
+		}
+		return keys[i].status < keys[j].status
+	})
+
+	writeHeader(&b, "http_requests_total", "counter", "Number of handled HTTP requests.")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "http_requests_total{%s} %d\n", key.labels(), m.requests[key].count)
+	}
+	writeHeader(&b, "http_request_duration_seconds", "histogram", "HTTP request handling duration.")
+	for _, key := range keys {
+		stats := m.requests[key]
+		labels := key.labels()
+		for i, bound := range durationBuckets {
+			fmt.Fprintf(&b, "http_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", labels, formatFloat(bound), stats.buckets[i])
+		}
+		fmt.Fprintf(&b, "http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, stats.count)
+		fmt.Fprintf(&b, "http_request_duration_seconds_sum{%s} %s\n", labels, formatFloat(stats.sum))
+		fmt.Fprintf(&b, "http_request_duration_seconds_count{%s} %d\n", labels, stats.count)
+	}
+	m.mu.Unlock()
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// labels - метки запроса в формате Prometheus
+func (k requestKey) labels() string {
+	return fmt.Sprintf(`method="%s",route="%s",status="%d"`, escapeLabel(k.method), escapeLabel(k.route), k.status)
+}
+
+// writeHeader - записывает описание и тип метрики
+func writeHeader(b *strings.Builder, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// escapeLabel - экранирует значение метки
+func escapeLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
+
+// formatFloat - форматирует число без экспоненты для небольших значений
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"github.com/go-chi/chi/v5"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Middleware(t *testing.T) {
+	testCases := []struct {
+		name          string
+		path          string
+		expectedLines []string
+	}{
+		{
+			name: "Route_Pattern_Is_Used_As_Label",
+			path: "/api/orders/12345",
+			expectedLines: []string{
+				`http_requests_total{method="GET",route="/api/orders/{number}",status="200"} 1`,
+				`http_request_duration_seconds_count{method="GET",route="/api/orders/{number}",status="200"} 1`,
+				`http_request_duration_seconds_bucket{method="GET",route="/api/orders/{number}",status="200",le="+Inf"} 1`,
+			},
+		},
+		{
+			name: "Error_Status_Is_Recorded",
+			path: "/api/fail",
+			expectedLines: []string{
+				`http_requests_total{method="GET",route="/api/fail",status="500"} 1`,
+			},
+		},
+		{
+			name: "Unmatched_Route",
+			path: "/unknown",
+			expectedLines: []string{
+				`http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := NewRegistry()
+			router := chi.NewRouter()
+			router.Use(registry.Middleware)
+			router.Get("/api/orders/{number}", func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("ok"))
+			})
+			router.Get("/api/fail", func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			})
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, tc.path, nil))
+
+			recorder := httptest.NewRecorder()
+			registry.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+			if contentType := recorder.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "text/plain") {
+				t.Errorf("unexpected content type: %s", contentType)
+			}
+			body := recorder.Body.String()
+			for _, line := range append(tc.expectedLines, "# TYPE go_goroutines gauge", "# TYPE http_request_duration_seconds histogram") {
+				if !strings.Contains(body, line+"\n") {
+					t.Errorf("expected line %q in:\n%s", line, body)
+				}
+			}
+		})
+	}
+}
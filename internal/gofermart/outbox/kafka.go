@@ -0,0 +1,88 @@
+package outbox
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// kafkaRESTContentType — формат тела запроса Kafka REST Proxy v2 с JSON-значениями
+const kafkaRESTContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaRESTSink - публикует события в Kafka через Kafka REST Proxy (API v2). Ключ записи — ключ события,
+// поэтому события одного заказа попадают в одну партицию и читаются по порядку.
+type KafkaRESTSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+// kafkaRecord — запись в запросе к REST Proxy
+type kafkaRecord struct {
+	Key   string   `json:"key"`
+	Value Envelope `json:"value"`
+}
+
+// kafkaProduceResponse — ответ REST Proxy с результатом записи по каждой записи запроса
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// NewKafkaRESTSink - создает получателя для топика topic на REST Proxy по адресу baseURL
+func NewKafkaRESTSink(baseURL, topic string, client *http.Client) *KafkaRESTSink {
+	return &KafkaRESTSink{
+		endpoint: strings.TrimRight(baseURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   client,
+	}
+}
+
+// Publish - записывает событие в топик; доставка подтверждается только при отсутствии ошибки записи
+func (s *KafkaRESTSink) Publish(ctx context.Context, message domain.OutboxMessage) error {
+	body, err := json.Marshal(struct {
+		Records []kafkaRecord `json:"records"`
+	}{Records: []kafkaRecord{{Key: message.Key, Value: NewEnvelope(message)}}})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaRESTContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: kafka rest proxy responded with status %d", ErrSinkDelivery, resp.StatusCode)
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("failed to decode kafka rest proxy response: %w", err)
+	}
+	if len(produced.Offsets) != 1 {
+		return fmt.Errorf("%w: kafka rest proxy returned %d offsets", ErrSinkDelivery, len(produced.Offsets))
+	}
+	if offset := produced.Offsets[0]; offset.ErrorCode != nil || offset.Error != "" {
+		return fmt.Errorf("%w: %s", ErrSinkDelivery, offset.Error)
+	}
+	return nil
+}
+
+// Close - у REST Proxy нет соединений, требующих закрытия
+func (s *KafkaRESTSink) Close() error {
+	return nil
+}
@@ -0,0 +1,175 @@
+package outbox
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsConnectCommand — параметры подключения клиента; verbose выключен, подтверждением служит ответ PONG
+const natsConnectCommand = `CONNECT {"verbose":false,"pedantic":false,"name":"gophermart-outbox","lang":"go","version":"1.0.0","protocol":0}` + "\r\n"
+
+// NATSSink - публикует события в NATS по текстовому протоколу ядра NATS. Тема события — <topic>.<тип события>.
+// После каждой публикации отправляется PING: ответ PONG означает, что сервер принял сообщение.
+// Подтверждения JetStream не используются, поэтому сохранность сообщения после приёма зависит от настроек потоков.
+type NATSSink struct {
+	address string
+	topic   string
+	timeout time.Duration
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// NewNATSSink - создает получателя для сервера NATS по адресу вида nats://host:port
+func NewNATSSink(rawURL, topic string, timeout time.Duration) (*NATSSink, error) {
+	address := rawURL
+	if strings.Contains(rawURL, "://") {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid NATS URL: %w", err)
+		}
+		if parsed.Scheme != "nats" {
+			return nil, fmt.Errorf("invalid NATS URL scheme %q", parsed.Scheme)
+		}
+		address = parsed.Host
+	}
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "4222")
+	}
+	return &NATSSink{address: address, topic: topic, timeout: timeout}, nil
+}
+
+// Publish - публикует событие и дожидается подтверждения сервера. При ошибке соединение закрывается
+// и открывается заново при следующей публикации.
+func (s *NATSSink) Publish(ctx context.Context, message domain.OutboxMessage) error {
+	payload, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.publish(ctx, s.topic+"."+string(message.Type), payload); err != nil {
+		s.closeConn()
+		return err
+	}
+	return nil
+}
+
+// publish — отправка сообщения по открытому (или вновь открытому) соединению
+func (s *NATSSink) publish(ctx context.Context, subject string, payload []byte) error {
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if err := s.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	command := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := s.conn.Write([]byte(command)); err != nil {
+		return err
+	}
+	return s.waitPong()
+}
+
+// connect — открытие соединения и рукопожатие: сервер присылает INFO, клиент отвечает CONNECT
+func (s *NATSSink) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(s.timeout)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	line, err := readNATSLine(reader)
+	if err != nil {
+		_ = conn.Close()
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		_ = conn.Close()
+		return fmt.Errorf("%w: %q", ErrNATSProtocol, line)
+	}
+	if strings.Contains(line, `"tls_required":true`) {
+		_ = conn.Close()
+		return fmt.Errorf("%w: server requires TLS", ErrNATSProtocol)
+	}
+	if _, err := conn.Write([]byte(natsConnectCommand)); err != nil {
+		_ = conn.Close()
+		return err
+	}
+
+	s.conn = conn
+	s.reader = reader
+	return nil
+}
+
+// waitPong — ожидание ответа на PING; PING сервера и повторные INFO обрабатываются по ходу
+func (s *NATSSink) waitPong() error {
+	for {
+		line, err := readNATSLine(s.reader)
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case line == "+OK" || strings.HasPrefix(line, "INFO "):
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("%w: %s", ErrSinkDelivery, strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		default:
+			return fmt.Errorf("%w: %q", ErrNATSProtocol, line)
+		}
+	}
+}
+
+// closeConn — закрытие соединения после ошибки
+func (s *NATSSink) closeConn() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn = nil
+		s.reader = nil
+	}
+}
+
+// Close - закрывает соединение с сервером
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeConn()
+	return nil
+}
+
+// readNATSLine — чтение строки протокола без завершающего CRLF
+func readNATSLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,125 @@
+package outbox
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Виды получателей событий
+const (
+	SinkNone    = "none"
+	SinkStdout  = "stdout"
+	SinkFile    = "file"
+	SinkWebhook = "webhook"
+	SinkNATS    = "nats"
+	SinkKafka   = "kafka"
+)
+
+// defaultPublishTimeout — предельное время доставки одного события, если в настройках оно не задано
+const defaultPublishTimeout = 10 * time.Second
+
+var (
+	ErrUnknownSink  = errors.New("unknown outbox sink")
+	ErrSinkURL      = errors.New("outbox sink URL is not configured")
+	ErrSinkTopic    = errors.New("outbox sink topic is not configured")
+	ErrSinkFilePath = errors.New("outbox sink file path is not configured")
+	ErrSinkDelivery = errors.New("outbox sink rejected the event")
+	ErrNATSProtocol = errors.New("unexpected NATS server response")
+)
+
+// Sink - получатель событий outbox. Publish возвращает nil только после того, как получатель принял событие;
+// при ошибке событие будет доставлено повторно, поэтому потребители должны учитывать идентификатор события.
+type Sink interface {
+	Publish(ctx context.Context, message domain.OutboxMessage) error
+	Close() error
+}
+
+// Envelope - событие в том виде, в котором оно передаётся получателям
+type Envelope struct {
+	ID        int                    `json:"id"`
+	Type      domain.OutboxEventType `json:"type"`
+	Key       string                 `json:"key"`
+	Payload   domain.OutboxPayload   `json:"payload"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// NewEnvelope - формирует передаваемое представление события
+func NewEnvelope(message domain.OutboxMessage) Envelope {
+	return Envelope{
+		ID:        message.MessageID,
+		Type:      message.Type,
+		Key:       message.Key,
+		Payload:   message.Payload,
+		CreatedAt: message.CreatedAt,
+	}
+}
+
+// Options - параметры получателя событий
+type Options struct {
+	Kind    string        // вид получателя: none, stdout, file, webhook, nats, kafka
+	URL     string        // адрес webhook, сервера NATS (nats://host:port) или Kafka REST Proxy
+	Topic   string        // префикс темы NATS или топик Kafka
+	File    string        // путь к файлу для получателя file
+	Timeout time.Duration // предельное время доставки одного события
+}
+
+// ValidKind - проверяет, что вид получателя известен
+func ValidKind(kind string) bool {
+	switch kind {
+	case "", SinkNone, SinkStdout, SinkFile, SinkWebhook, SinkNATS, SinkKafka:
+		return true
+	}
+	return false
+}
+
+// New - создает получателя событий по настройкам; для вида none возвращает nil
+func New(options Options) (Sink, error) {
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = defaultPublishTimeout
+	}
+
+	switch options.Kind {
+	case "", SinkNone:
+		return nil, nil
+	case SinkStdout:
+		return NewWriterSink(os.Stdout, nil), nil
+	case SinkFile:
+		if options.File == "" {
+			return nil, ErrSinkFilePath
+		}
+		file, err := os.OpenFile(options.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open outbox file: %w", err)
+		}
+		return NewWriterSink(file, file), nil
+	case SinkWebhook:
+		if options.URL == "" {
+			return nil, ErrSinkURL
+		}
+		return NewWebhookSink(options.URL, &http.Client{Timeout: timeout}), nil
+	case SinkNATS:
+		if options.URL == "" {
+			return nil, ErrSinkURL
+		}
+		if options.Topic == "" {
+			return nil, ErrSinkTopic
+		}
+		return NewNATSSink(options.URL, options.Topic, timeout)
+	case SinkKafka:
+		if options.URL == "" {
+			return nil, ErrSinkURL
+		}
+		if options.Topic == "" {
+			return nil, ErrSinkTopic
+		}
+		return NewKafkaRESTSink(options.URL, options.Topic, &http.Client{Timeout: timeout}), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownSink, options.Kind)
+	}
+}
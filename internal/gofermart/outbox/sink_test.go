@@ -0,0 +1,221 @@
+package outbox
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testMessage — событие, используемое в проверках получателей
+var testMessage = domain.OutboxMessage{
+	MessageID: 7,
+	Type:      domain.OutboxPointsAccrued,
+	Key:       "12345678903",
+	Payload:   domain.OutboxPayload(`{"order":"12345678903","user_id":1,"amount":"100"}`),
+	CreatedAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+}
+
+// testEnvelope — ожидаемое представление testMessage
+const testEnvelope = `{"id":7,"type":"PointsAccrued","key":"12345678903","payload":{"order":"12345678903","user_id":1,"amount":"100"},"created_at":"2024-05-01T12:00:00Z"}`
+
+func TestWriterSink_Publish(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf, nil)
+
+	if err := sink.Publish(context.Background(), testMessage); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := buf.String(); got != testEnvelope+"\n" {
+		t.Errorf("unexpected output: %s", got)
+	}
+}
+
+func TestWebhookSink_Publish(t *testing.T) {
+	testCases := []struct {
+		name      string
+		status    int
+		expectErr bool
+	}{
+		{name: "Accepted", status: http.StatusAccepted},
+		{name: "Rejected", status: http.StatusInternalServerError, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != testEnvelope {
+					t.Errorf("unexpected body: %s", body)
+				}
+				if r.Header.Get("X-Event-Id") != "7" || r.Header.Get("X-Event-Type") != "PointsAccrued" {
+					t.Errorf("unexpected headers: %v", r.Header)
+				}
+				w.WriteHeader(tc.status)
+			}))
+			defer server.Close()
+
+			err := NewWebhookSink(server.URL, server.Client()).Publish(context.Background(), testMessage)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.expectErr && !errors.Is(err, ErrSinkDelivery) {
+				t.Errorf("expected %v, got %v", ErrSinkDelivery, err)
+			}
+		})
+	}
+}
+
+func TestKafkaRESTSink_Publish(t *testing.T) {
+	testCases := []struct {
+		name      string
+		response  string
+		expectErr bool
+	}{
+		{name: "Produced", response: `{"offsets":[{"partition":0,"offset":42,"error_code":null,"error":null}]}`},
+		{name: "Partition_Error", response: `{"offsets":[{"partition":null,"offset":null,"error_code":50003,"error":"leader not available"}]}`, expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/topics/gophermart-events" || r.Header.Get("Content-Type") != kafkaRESTContentType {
+					t.Errorf("unexpected request: %s %s", r.URL.Path, r.Header.Get("Content-Type"))
+				}
+				var request struct {
+					Records []struct {
+						Key   string          `json:"key"`
+						Value json.RawMessage `json:"value"`
+					} `json:"records"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&request); err != nil || len(request.Records) != 1 {
+					t.Errorf("unexpected request body: %v", err)
+					return
+				}
+				if request.Records[0].Key != testMessage.Key || string(request.Records[0].Value) != testEnvelope {
+					t.Errorf("unexpected record: %+v", request.Records[0])
+				}
+				_, _ = w.Write([]byte(tc.response))
+			}))
+			defer server.Close()
+
+			err := NewKafkaRESTSink(server.URL+"/", "gophermart-events", server.Client()).Publish(context.Background(), testMessage)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+// fakeNATSServer — минимальный сервер NATS: принимает подключение, записывает полученные команды
+// и отвечает на PING ответом PONG либо ошибкой reply
+func fakeNATSServer(t *testing.T, reply string) (string, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	commands := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte(`INFO {"server_id":"test","max_payload":1048576}` + "\r\n"))
+
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				close(commands)
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			if line == "PING" {
+				_, _ = conn.Write([]byte(reply + "\r\n"))
+				continue
+			}
+			commands <- line
+		}
+	}()
+	return "nats://" + listener.Addr().String(), commands
+}
+
+func TestNATSSink_Publish(t *testing.T) {
+	testCases := []struct {
+		name      string
+		reply     string
+		expectErr error
+	}{
+		{name: "Acknowledged", reply: "PONG"},
+		{name: "Server_Error", reply: "-ERR 'Permissions Violation for Publish'", expectErr: ErrSinkDelivery},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			address, commands := fakeNATSServer(t, tc.reply)
+			sink, err := NewNATSSink(address, "gophermart", time.Second)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			defer sink.Close()
+
+			err = sink.Publish(context.Background(), testMessage)
+			if !errors.Is(err, tc.expectErr) {
+				t.Fatalf("expected %v, got %v", tc.expectErr, err)
+			}
+
+			connect := <-commands
+			if !strings.HasPrefix(connect, "CONNECT ") {
+				t.Errorf("expected CONNECT, got %q", connect)
+			}
+			if pub := <-commands; pub != "PUB gophermart.PointsAccrued "+strconv.Itoa(len(testEnvelope)) {
+				t.Errorf("unexpected PUB command: %q", pub)
+			}
+			if payload := <-commands; payload != testEnvelope {
+				t.Errorf("unexpected payload: %q", payload)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name      string
+		options   Options
+		expectNil bool
+		expectErr error
+	}{
+		{name: "None", options: Options{Kind: SinkNone}, expectNil: true},
+		{name: "Stdout", options: Options{Kind: SinkStdout}},
+		{name: "Webhook_Without_URL", options: Options{Kind: SinkWebhook}, expectErr: ErrSinkURL},
+		{name: "Kafka_Without_Topic", options: Options{Kind: SinkKafka, URL: "http://localhost:8082"}, expectErr: ErrSinkTopic},
+		{name: "File_Without_Path", options: Options{Kind: SinkFile}, expectErr: ErrSinkFilePath},
+		{name: "Unknown", options: Options{Kind: "rabbitmq"}, expectErr: ErrUnknownSink},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sink, err := New(tc.options)
+			if !errors.Is(err, tc.expectErr) {
+				t.Fatalf("expected %v, got %v", tc.expectErr, err)
+			}
+			if err == nil && (sink == nil) != tc.expectNil {
+				t.Errorf("unexpected sink: %v", sink)
+			}
+		})
+	}
+}
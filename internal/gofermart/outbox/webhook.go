@@ -0,0 +1,58 @@
+package outbox
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// WebhookSink - отправляет каждое событие POST-запросом с JSON-телом на заданный адрес.
+// Любой ответ 2xx считается подтверждением доставки.
+type WebhookSink struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookSink - создает получателя для webhook
+func NewWebhookSink(url string, client *http.Client) *WebhookSink {
+	return &WebhookSink{url: url, client: client}
+}
+
+// Publish - отправляет событие; идентификатор события передаётся в заголовке для дедупликации на стороне потребителя
+func (s *WebhookSink) Publish(ctx context.Context, message domain.OutboxMessage) error {
+	body, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Id", strconv.Itoa(message.MessageID))
+	req.Header.Set("X-Event-Type", string(message.Type))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Тело дочитывается, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%w: webhook responded with status %d", ErrSinkDelivery, resp.StatusCode)
+	}
+	return nil
+}
+
+// Close - у webhook нет соединений, требующих закрытия
+func (s *WebhookSink) Close() error {
+	return nil
+}
@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"encoding/json"
+	"io"
+	"sync"
+)
+
+// WriterSink - записывает события построчно в формате JSON (stdout или файл) для локальной разработки
+type WriterSink struct {
+	mu     sync.Mutex
+	w      io.Writer
+	closer io.Closer
+}
+
+// NewWriterSink - создает получателя, пишущего в w; closer (может быть nil) закрывается в Close
+func NewWriterSink(w io.Writer, closer io.Closer) *WriterSink {
+	return &WriterSink{w: w, closer: closer}
+}
+
+// Publish - записывает событие отдельной строкой
+func (s *WriterSink) Publish(ctx context.Context, message domain.OutboxMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	line, err := json.Marshal(NewEnvelope(message))
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// Close - закрывает файл, если он был открыт получателем
+func (s *WriterSink) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
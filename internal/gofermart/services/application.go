@@ -1,7 +1,17 @@
 package services
 
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
+)
+
 type AppServices struct {
-	AuthService  *AuthService
-	OrderService OrderServiceInterface
-	UserService  *UserService
+	AuditService     *AuditService
+	AuthService      *AuthService
+	OrderService     OrderServiceInterface
+	OrderUpdates     *OrderUpdateBroker
+	OrderValidator   validation.OrderNumberValidator
+	RateLimiter      *RateLimiter
+	StatementService *StatementService
+	UserService      *UserService
+	WebhookService   *WebhookService
 }
@@ -0,0 +1,71 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// AuditEventReader - чтение страниц журнала аудита
+type AuditEventReader interface {
+	GetEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, *domain.Cursor, error)
+}
+
+// AuditService - предоставляет доступ к журналу аудита. События записывают сервисы, выполняющие операции,
+// в той же транзакции, что и изменение данных.
+type AuditService struct {
+	auditRepo repository.AuditRepository
+	logger    *zap.Logger
+}
+
+// NewAuditService - создает новый экземпляр AuditService
+func NewAuditService(auditRepo repository.AuditRepository, logger *zap.Logger) *AuditService {
+	return &AuditService{
+		auditRepo: auditRepo,
+		logger:    logger,
+	}
+}
+
+// GetEvents возвращает страницу событий журнала аудита и курсор следующей страницы
+func (s *AuditService) GetEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, *domain.Cursor, error) {
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query := filter
+	if filter.Limit > 0 {
+		query.Limit = filter.Limit + 1
+	}
+
+	events, err := s.auditRepo.GetEvents(ctx, query)
+	if err != nil {
+		s.logger.Error("Failed to get audit events", zap.Error(err))
+		return nil, nil, err
+	}
+
+	if filter.Limit > 0 && len(events) > filter.Limit {
+		events = events[:filter.Limit]
+		last := events[len(events)-1]
+		return events, &domain.Cursor{Time: last.CreatedAt, Key: strconv.Itoa(last.EventID)}, nil
+	}
+
+	return events, nil, nil
+}
+
+// newAuditEvent - создает событие аудита, дополняя его сведениями о запросе из контекста
+func newAuditEvent(ctx context.Context, eventType domain.AuditEventType, actor, subject string, payload domain.AuditPayload) domain.AuditEvent {
+	info := domain.RequestInfoFromContext(ctx)
+	if payload == nil {
+		payload = domain.AuditPayload{}
+	}
+	return domain.AuditEvent{
+		Type:      eventType,
+		Actor:     actor,
+		Subject:   subject,
+		IP:        info.IP,
+		UserAgent: info.UserAgent,
+		RequestID: info.RequestID,
+		Payload:   payload,
+		CreatedAt: time.Now(),
+	}
+}
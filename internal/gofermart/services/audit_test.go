@@ -0,0 +1,96 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// auditEventMatcher - сопоставляет событие аудита по его виду
+type auditEventMatcher struct {
+	eventType domain.AuditEventType
+}
+
+func (m auditEventMatcher) Matches(x interface{}) bool {
+	event, ok := x.(domain.AuditEvent)
+	return ok && event.Type == m.eventType
+}
+
+func (m auditEventMatcher) String() string {
+	return fmt.Sprintf("audit event of type %s", m.eventType)
+}
+
+// auditEventOfType - ожидание события аудита указанного вида
+func auditEventOfType(eventType domain.AuditEventType) gomock.Matcher {
+	return auditEventMatcher{eventType: eventType}
+}
+
+func TestNewAuditEvent_RequestInfo(t *testing.T) {
+	info := domain.RequestInfo{IP: "192.0.2.1", UserAgent: "curl/8.0", RequestID: "host/abc-000001"}
+	ctx := domain.WithRequestInfo(context.Background(), info)
+
+	event := newAuditEvent(ctx, domain.AuditBalanceAdjusted, "support", "alice", domain.AuditPayload{"amount": "10"})
+
+	expected := domain.AuditEvent{
+		Type:      domain.AuditBalanceAdjusted,
+		Actor:     "support",
+		Subject:   "alice",
+		IP:        info.IP,
+		UserAgent: info.UserAgent,
+		RequestID: info.RequestID,
+		Payload:   domain.AuditPayload{"amount": "10"},
+		CreatedAt: event.CreatedAt,
+	}
+	if diff := cmp.Diff(expected, event); diff != "" {
+		t.Errorf("event mismatch (-want +got):\n%s", diff)
+	}
+	if event.CreatedAt.IsZero() {
+		t.Errorf("expected event time to be set")
+	}
+
+	// Вне HTTP-запроса сведения о запросе пусты, а подробности события не равны nil
+	event = newAuditEvent(context.Background(), domain.AuditLoginSucceeded, "alice", "alice", nil)
+	if event.IP != "" || event.RequestID != "" || event.Payload == nil {
+		t.Errorf("unexpected event without request info: %+v", event)
+	}
+}
+
+func TestAuditService_GetEvents_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	auditService := NewAuditService(mockAuditRepo, zap.NewNop())
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	events := []domain.AuditEvent{
+		{EventID: 3, Type: domain.AuditLoginFailed, CreatedAt: now},
+		{EventID: 2, Type: domain.AuditLoginFailed, CreatedAt: now.Add(-time.Minute)},
+		{EventID: 1, Type: domain.AuditLoginFailed, CreatedAt: now.Add(-2 * time.Minute)},
+	}
+
+	filter := domain.AuditFilter{ListOptions: domain.ListOptions{Limit: 2}, Types: []domain.AuditEventType{domain.AuditLoginFailed}}
+	mockAuditRepo.EXPECT().GetEvents(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, query domain.AuditFilter) ([]domain.AuditEvent, error) {
+		if query.Limit != 3 {
+			t.Errorf("expected repository limit 3, got %d", query.Limit)
+		}
+		return events, nil
+	})
+
+	page, next, err := auditService.GetEvents(context.Background(), filter)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if diff := cmp.Diff(events[:2], page); diff != "" {
+		t.Errorf("page mismatch (-want +got):\n%s", diff)
+	}
+	if diff := cmp.Diff(&domain.Cursor{Time: events[1].CreatedAt, Key: "2"}, next); diff != "" {
+		t.Errorf("cursor mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -4,92 +4,227 @@ import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
 	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"bytes"
+	"context"
 	"errors"
 	"github.com/go-chi/jwtauth/v5"
+	"github.com/lestrrat-go/jwx/v2/jwt"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"net/http"
+	"sync"
 	"time"
 )
 
+// defaultTokenTTL - время жизни токена, если оно не задано конфигурацией
+const defaultTokenTTL = 24 * time.Hour
+
 type AuthService struct {
+	auditRepo repository.AuditRepository
 	logger    *zap.Logger
-	tokenAuth *jwtauth.JWTAuth
+	txManager repository.TxManager
 	userRepo  repository.UserRepository
+
+	// Ключи подписи заменяются по SIGHUP; токены, подписанные предыдущим ключом, принимаются до истечения их срока
+	keysMu       sync.RWMutex
+	tokenAuth    *jwtauth.JWTAuth
+	previousAuth *jwtauth.JWTAuth
+	secret       []byte
+	tokenTTL     time.Duration
 }
 
-func NewAuthService(jwtSecret []byte, userRepo repository.UserRepository, logger *zap.Logger) *AuthService {
+// NewAuthService - создает сервис аутентификации, выпускающий токены со временем жизни tokenTTL (0 — 24 часа).
+// Роли в токене берутся только из хранилища. Регистрация, попытки входа и выпуск токенов записываются в журнал аудита.
+func NewAuthService(jwtSecret []byte, tokenTTL time.Duration, txManager repository.TxManager, userRepo repository.UserRepository, auditRepo repository.AuditRepository, logger *zap.Logger) *AuthService {
 	tokenAuth := jwtauth.New("HS256", jwtSecret, nil)
+	if tokenTTL <= 0 {
+		tokenTTL = defaultTokenTTL
+	}
 	return &AuthService{
+		auditRepo: auditRepo,
 		logger:    logger,
 		tokenAuth: tokenAuth,
+		secret:    jwtSecret,
+		tokenTTL:  tokenTTL,
+		txManager: txManager,
 		userRepo:  userRepo,
 	}
 }
 
-func (s *AuthService) RegisterUser(login, password string) error {
+// RegisterUser - регистрирует пользователя с ролями по умолчанию и возвращает сохраненного пользователя
+func (s *AuthService) RegisterUser(ctx context.Context, login, password string) (*domain.User, error) {
 	s.logger.Info("Attempting to register user", zap.String("login", login))
 
-	user, _ := s.userRepo.GetUserByLogin(nil, login)
+	user, err := s.userRepo.GetUserByLogin(ctx, login)
+	if err != nil && !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		s.logger.Error("Error getting user", zap.Error(err))
+		return nil, err
+	}
 	if user != nil {
 		s.logger.Warn("Login already taken", zap.String("login", login))
-		return gofermartErrors.ErrLoginAlreadyExists
+		return nil, gofermartErrors.ErrLoginAlreadyExists
 	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
 		s.logger.Error("Error generating password hash", zap.Error(err))
-		return err
+		return nil, err
 	}
 
-	url := &domain.User{Login: login, Password: string(hashedPassword)}
-	err = s.userRepo.SaveUser(nil, *url)
+	newUser := domain.User{Login: login, Password: string(hashedPassword), Roles: domain.DefaultRoles()}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.SaveUser(ctx, newUser); err != nil {
+			s.logger.Error("Error registering user", zap.String("login", login), zap.Error(err))
+			return err
+		}
+
+		// Идентификатор назначается хранилищем, а он нужен для токена
+		user, err = s.userRepo.GetUserByLogin(ctx, login)
+		if err != nil {
+			s.logger.Error("Error getting registered user", zap.String("login", login), zap.Error(err))
+			return err
+		}
+
+		return s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditUserRegistered, login, login, domain.AuditPayload{
+			"user_id": user.UserID,
+			"roles":   user.Roles.Strings(),
+		}))
+	})
 	if err != nil {
-		s.logger.Error("Error registering user", zap.String("login", login), zap.Error(err))
-		return err
+		return nil, err
 	}
 
 	s.logger.Info("User registered successfully", zap.String("login", login))
-	return nil
+	return user, nil
 }
 
-func (s *AuthService) AuthenticateUser(login, password string) (bool, error) {
+// Причины неудачного входа в журнале аудита
+const (
+	loginFailureInvalidPassword = "invalid_password"
+	loginFailureUnknownLogin    = "unknown_login"
+	loginFailureUserBlocked     = "user_blocked"
+)
+
+// AuthenticateUser - проверяет логин и пароль; при неверных данных возвращает nil без ошибки.
+// Успешные и неудачные попытки входа записываются в журнал аудита.
+func (s *AuthService) AuthenticateUser(ctx context.Context, login, password string) (*domain.User, error) {
 	s.logger.Info("Attempting to authenticate user", zap.String("login", login))
 
-	user, err := s.userRepo.GetUserByLogin(nil, login)
+	user, err := s.userRepo.GetUserByLogin(ctx, login)
 	if err != nil {
 		if errors.Is(err, gofermartErrors.ErrUserNotFound) {
 			s.logger.Warn("User not found", zap.String("login", login))
-			return false, nil
+			return nil, s.recordLoginFailure(ctx, login, loginFailureUnknownLogin)
 		}
 		s.logger.Error("Error getting user", zap.Error(err))
-		return false, err
+		return nil, err
 	}
 
 	if user == nil {
 		s.logger.Warn("Login not found", zap.String("login", login))
-		return false, nil
+		return nil, s.recordLoginFailure(ctx, login, loginFailureUnknownLogin)
 	}
 
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
 		s.logger.Warn("Invalid password", zap.String("login", login))
-		return false, nil
+		return nil, s.recordLoginFailure(ctx, login, loginFailureInvalidPassword)
+	}
+
+	// Заблокированный пользователь не может получить новый токен
+	if user.Blocked {
+		s.logger.Warn("Blocked user login attempt", zap.String("login", login))
+		if err := s.recordLoginFailure(ctx, login, loginFailureUserBlocked); err != nil {
+			return nil, err
+		}
+		return nil, gofermartErrors.ErrUserBlocked
+	}
+
+	if err := s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditLoginSucceeded, login, login, nil)); err != nil {
+		return nil, err
 	}
 
 	s.logger.Info("User authenticated successfully", zap.String("login", login))
-	return true, nil
+	return user, nil
 }
 
-func (s *AuthService) GenerateJWT(username string) (string, error) {
-	expirationTime := time.Now().Add(24 * time.Hour)
+// recordLoginFailure - записывает неудачную попытку входа в журнал аудита
+func (s *AuthService) recordLoginFailure(ctx context.Context, login, reason string) error {
+	return s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditLoginFailed, login, login, domain.AuditPayload{"reason": reason}))
+}
 
-	_, tokenString, err := s.tokenAuth.Encode(map[string]interface{}{
-		"username": username,
-		"exp":      expirationTime,
-	})
-	return tokenString, err
+// GenerateJWT - выпускает токен с идентификатором, логином и ролями пользователя и записывает выпуск в журнал аудита
+func (s *AuthService) GenerateJWT(ctx context.Context, user domain.User) (string, error) {
+	tokenAuth, _, tokenTTL := s.keys()
+	expirationTime := time.Now().Add(tokenTTL)
+
+	roles := user.Roles
+	claims := map[string]interface{}{
+		utils.UserIDClaim:   user.UserID,
+		utils.UsernameClaim: user.Login,
+		utils.RolesClaim:    roles.Strings(),
+		"exp":               expirationTime,
+	}
+
+	_, tokenString, err := tokenAuth.Encode(claims)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditTokenIssued, user.Login, user.Login, domain.AuditPayload{
+		"roles":      roles.Strings(),
+		"expires_at": expirationTime.UTC().Format(time.RFC3339),
+	}))
+	if err != nil {
+		return "", err
+	}
+	return tokenString, nil
 }
 
+// GetTokenAuth - возвращает действующий ключ подписи токенов
 func (s *AuthService) GetTokenAuth() *jwtauth.JWTAuth {
-	return s.tokenAuth
+	tokenAuth, _, _ := s.keys()
+	return tokenAuth
+}
+
+// UpdateKeys - заменяет ключ подписи и время жизни выпускаемых токенов без перезапуска. Токены, подписанные
+// прежним ключом, продолжают приниматься до истечения их срока; при повторной замене они перестают действовать.
+func (s *AuthService) UpdateKeys(jwtSecret []byte, tokenTTL time.Duration) {
+	if tokenTTL <= 0 {
+		tokenTTL = defaultTokenTTL
+	}
+
+	s.keysMu.Lock()
+	defer s.keysMu.Unlock()
+
+	tokenAuth := jwtauth.New("HS256", jwtSecret, nil)
+	if !bytes.Equal(jwtSecret, s.secret) {
+		s.previousAuth = s.tokenAuth
+		s.tokenAuth = tokenAuth
+		s.secret = jwtSecret
+		s.logger.Info("JWT signing key rotated")
+	}
+	s.tokenTTL = tokenTTL
+}
+
+// VerifyRequest - проверяет токен запроса из заголовка Authorization или cookie jwt действующим ключом,
+// а если он не подошёл — предыдущим
+func (s *AuthService) VerifyRequest(r *http.Request) (jwt.Token, error) {
+	tokenAuth, previousAuth, _ := s.keys()
+	token, err := jwtauth.VerifyRequest(tokenAuth, r, jwtauth.TokenFromHeader, jwtauth.TokenFromCookie)
+	if err == nil || previousAuth == nil || errors.Is(err, jwtauth.ErrNoTokenFound) {
+		return token, err
+	}
+	if previousToken, previousErr := jwtauth.VerifyRequest(previousAuth, r, jwtauth.TokenFromHeader, jwtauth.TokenFromCookie); previousErr == nil {
+		return previousToken, nil
+	}
+	return token, err
+}
+
+// keys - возвращает действующий и предыдущий ключи подписи и время жизни токенов
+func (s *AuthService) keys() (*jwtauth.JWTAuth, *jwtauth.JWTAuth, time.Duration) {
+	s.keysMu.RLock()
+	defer s.keysMu.RUnlock()
+	return s.tokenAuth, s.previousAuth, s.tokenTTL
 }
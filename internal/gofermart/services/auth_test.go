@@ -3,13 +3,18 @@ package services
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
 	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
+	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
-	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 )
 
 func TestNewAuthService(t *testing.T) {
@@ -20,7 +25,7 @@ func TestNewAuthService(t *testing.T) {
 		logger := zap.NewNop()
 		jwtSecret := []byte("secret")
 
-		authService := NewAuthService(jwtSecret, mockUserRepo, logger)
+		authService := NewAuthService(jwtSecret, 0, mocks.NewMockTxManager(ctrl), mockUserRepo, mocks.NewMockAuditRepository(ctrl), logger)
 
 		if authService == nil || authService.tokenAuth == nil {
 			t.Errorf("Expected AuthService to be initialized with JWTAuth")
@@ -38,6 +43,8 @@ func TestRegisterUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
 	logger := zap.NewNop()
 	jwtSecret := []byte("secret")
 
@@ -52,7 +59,15 @@ func TestRegisterUser(t *testing.T) {
 			name: "RegisterUser_Success",
 			setupMocks: func() {
 				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).Return(nil, nil)
-				mockUserRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, user domain.User) error {
+					if !cmp.Equal(user.Roles, domain.DefaultRoles()) {
+						t.Errorf("expected default roles, got %v", user.Roles)
+					}
+					return nil
+				})
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "new_user").Return(&domain.User{UserID: 1, Login: "new_user", Roles: domain.DefaultRoles()}, nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditUserRegistered)).Return(nil)
 			},
 			expectedError: nil,
 			login:         "new_user",
@@ -71,21 +86,38 @@ func TestRegisterUser(t *testing.T) {
 			name: "RegisterUser_SaveUserError",
 			setupMocks: func() {
 				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).Return(nil, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockUserRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(errors.New("failed to save user"))
 			},
 			expectedError: errors.New("failed to save user"),
 			login:         "new_user",
 			password:      "password123",
 		},
+		{
+			name: "RegisterUser_AuditError",
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).Return(nil, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().SaveUser(gomock.Any(), gomock.Any()).Return(nil)
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "new_user").Return(&domain.User{UserID: 1, Login: "new_user"}, nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(errors.New("failed to add audit event"))
+			},
+			expectedError: errors.New("failed to add audit event"),
+			login:         "new_user",
+			password:      "password123",
+		},
 	}
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			authService := NewAuthService(jwtSecret, mockUserRepo, logger)
+			authService := NewAuthService(jwtSecret, 0, mockTxManager, mockUserRepo, mockAuditRepo, logger)
 
-			err := authService.RegisterUser(tc.login, tc.password)
+			user, err := authService.RegisterUser(context.Background(), tc.login, tc.password)
+			if err == nil && (user == nil || user.UserID == 0) {
+				t.Errorf("Expected registered user with id, got %v", user)
+			}
 
 			if err != nil && tc.expectedError == nil {
 				t.Errorf("Expected no error, got %v", err)
@@ -102,61 +134,83 @@ func TestAuthenticateUser(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
 	logger := zap.NewNop()
 	jwtSecret := []byte("secret")
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 
 	testCases := []struct {
 		name          string
-		mockReturn    func(tx *gorm.DB, login string) (*domain.User, error)
+		mockReturn    func(ctx context.Context, login string) (*domain.User, error)
 		login         string
 		password      string
 		expectedAuth  bool
 		expectedError error
+		expectedEvent domain.AuditEventType
+		expectedCause string
 	}{
 		{
 			name: "AuthenticateUser_Success",
-			mockReturn: func(tx *gorm.DB, login string) (*domain.User, error) {
+			mockReturn: func(ctx context.Context, login string) (*domain.User, error) {
 				return &domain.User{Login: "test_user", Password: string(hashedPassword)}, nil
 			},
 			login:         "test_user",
 			password:      "password123",
 			expectedAuth:  true,
 			expectedError: nil,
+			expectedEvent: domain.AuditLoginSucceeded,
 		},
 		{
 			name: "AuthenticateUser_UserNotFound",
-			mockReturn: func(tx *gorm.DB, login string) (*domain.User, error) {
+			mockReturn: func(ctx context.Context, login string) (*domain.User, error) {
 				return nil, gofermartErrors.ErrUserNotFound
 			},
 			login:         "test_user",
 			password:      "password123",
 			expectedAuth:  false,
 			expectedError: nil,
+			expectedEvent: domain.AuditLoginFailed,
+			expectedCause: "unknown_login",
 		},
 		{
 			name: "AuthenticateUser_LoginNotFound",
-			mockReturn: func(tx *gorm.DB, login string) (*domain.User, error) {
+			mockReturn: func(ctx context.Context, login string) (*domain.User, error) {
 				return nil, nil
 			},
 			login:         "test_user",
 			password:      "password123",
 			expectedAuth:  false,
 			expectedError: nil,
+			expectedEvent: domain.AuditLoginFailed,
+			expectedCause: "unknown_login",
 		},
 		{
 			name: "AuthenticateUser_InvalidPassword",
-			mockReturn: func(tx *gorm.DB, login string) (*domain.User, error) {
+			mockReturn: func(ctx context.Context, login string) (*domain.User, error) {
 				return &domain.User{Login: "test_user", Password: string(hashedPassword)}, nil
 			},
 			login:         "test_user",
 			password:      "wrong_password",
 			expectedAuth:  false,
 			expectedError: nil,
+			expectedEvent: domain.AuditLoginFailed,
+			expectedCause: "invalid_password",
+		},
+		{
+			name: "AuthenticateUser_Blocked",
+			mockReturn: func(ctx context.Context, login string) (*domain.User, error) {
+				return &domain.User{Login: "test_user", Password: string(hashedPassword), Blocked: true}, nil
+			},
+			login:         "test_user",
+			password:      "password123",
+			expectedAuth:  false,
+			expectedError: gofermartErrors.ErrUserBlocked,
+			expectedEvent: domain.AuditLoginFailed,
+			expectedCause: "user_blocked",
 		},
 		{
 			name: "AuthenticateUser_GetUserError",
-			mockReturn: func(tx *gorm.DB, login string) (*domain.User, error) {
+			mockReturn: func(ctx context.Context, login string) (*domain.User, error) {
 				return nil, errors.New("db error")
 			},
 			login:         "test_user",
@@ -169,12 +223,20 @@ func TestAuthenticateUser(t *testing.T) {
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), gomock.Any()).DoAndReturn(tc.mockReturn)
+			if tc.expectedEvent != "" {
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(tc.expectedEvent)).DoAndReturn(func(ctx context.Context, event domain.AuditEvent) error {
+					if event.Actor != tc.login || event.Payload["reason"] != nil && event.Payload["reason"] != tc.expectedCause {
+						t.Errorf("unexpected audit event %+v", event)
+					}
+					return nil
+				})
+			}
 
-			authService := NewAuthService(jwtSecret, mockUserRepo, logger)
+			authService := NewAuthService(jwtSecret, 0, mocks.NewMockTxManager(ctrl), mockUserRepo, mockAuditRepo, logger)
 
-			authenticated, err := authService.AuthenticateUser(tc.login, tc.password)
+			user, err := authService.AuthenticateUser(context.Background(), tc.login, tc.password)
 
-			if authenticated != tc.expectedAuth {
+			if authenticated := user != nil; authenticated != tc.expectedAuth {
 				t.Errorf("Expected authenticated %v, got %v", tc.expectedAuth, authenticated)
 			}
 
@@ -194,12 +256,14 @@ func TestGenerateJWT(t *testing.T) {
 		ctrl := gomock.NewController(t)
 		defer ctrl.Finish()
 		mockUserRepo := mocks.NewMockUserRepository(ctrl)
+		mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
 		logger := zap.NewNop()
 		jwtSecret := []byte("secret")
 
-		authService := NewAuthService(jwtSecret, mockUserRepo, logger)
+		authService := NewAuthService(jwtSecret, 0, mocks.NewMockTxManager(ctrl), mockUserRepo, mockAuditRepo, logger)
+		mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditTokenIssued)).Return(nil)
 
-		token, err := authService.GenerateJWT("test_user")
+		token, err := authService.GenerateJWT(context.Background(), domain.User{UserID: 1, Login: "test_user", Roles: domain.DefaultRoles()})
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -211,6 +275,153 @@ func TestGenerateJWT(t *testing.T) {
 	})
 }
 
+func TestGenerateJWT_Claims(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authService := NewAuthService([]byte("secret"), 0, mocks.NewMockTxManager(ctrl), mocks.NewMockUserRepository(ctrl), mockAuditRepo, zap.NewNop())
+
+	testCases := []struct {
+		name              string
+		user              domain.User
+		expectedPrincipal domain.Principal
+	}{
+		{
+			name:              "Regular_User",
+			user:              domain.User{UserID: 1, Login: "test_user", Roles: domain.DefaultRoles()},
+			expectedPrincipal: domain.Principal{UserID: 1, Login: "test_user", Roles: domain.Roles{domain.RoleUser}},
+		},
+		{
+			name:              "Stored_Roles",
+			user:              domain.User{UserID: 2, Login: "partner", Roles: domain.Roles{domain.RoleUser, domain.RolePartner}},
+			expectedPrincipal: domain.Principal{UserID: 2, Login: "partner", Roles: domain.Roles{domain.RoleUser, domain.RolePartner}},
+		},
+		{
+			name:              "Stored_Admin_Role",
+			user:              domain.User{UserID: 3, Login: "support", Roles: domain.Roles{domain.RoleUser, domain.RoleAdmin}},
+			expectedPrincipal: domain.Principal{UserID: 3, Login: "support", Roles: domain.Roles{domain.RoleUser, domain.RoleAdmin}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tokenString, err := authService.GenerateJWT(context.Background(), tc.user)
+			if err != nil {
+				t.Fatalf("failed to generate token: %v", err)
+			}
+			token, err := authService.GetTokenAuth().Decode(tokenString)
+			if err != nil {
+				t.Fatalf("failed to decode token: %v", err)
+			}
+			claims, err := token.AsMap(context.Background())
+			if err != nil {
+				t.Fatalf("failed to read claims: %v", err)
+			}
+
+			principal, ok := utils.PrincipalFromClaims(claims)
+			if !ok {
+				t.Fatalf("token claims do not describe a principal: %v", claims)
+			}
+			if diff := cmp.Diff(tc.expectedPrincipal, principal); diff != "" {
+				t.Errorf("principal mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGenerateJWT_TokenTTL(t *testing.T) {
+	testCases := []struct {
+		name        string
+		tokenTTL    time.Duration
+		expectedTTL time.Duration
+	}{
+		{name: "Configured_TTL", tokenTTL: time.Hour, expectedTTL: time.Hour},
+		{name: "Default_TTL", tokenTTL: 0, expectedTTL: defaultTokenTTL},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+			mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil)
+			authService := NewAuthService([]byte("secret"), tc.tokenTTL, mocks.NewMockTxManager(ctrl), mocks.NewMockUserRepository(ctrl), mockAuditRepo, zap.NewNop())
+
+			issuedAt := time.Now()
+			tokenString, err := authService.GenerateJWT(context.Background(), domain.User{UserID: 1, Login: "test_user", Roles: domain.DefaultRoles()})
+			if err != nil {
+				t.Fatalf("failed to generate token: %v", err)
+			}
+			token, err := authService.GetTokenAuth().Decode(tokenString)
+			if err != nil {
+				t.Fatalf("failed to decode token: %v", err)
+			}
+			if ttl := token.Expiration().Sub(issuedAt); ttl < tc.expectedTTL-time.Minute || ttl > tc.expectedTTL+time.Minute {
+				t.Errorf("expected token TTL about %v, got %v", tc.expectedTTL, ttl)
+			}
+		})
+	}
+}
+
+func TestAuthService_UpdateKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	authService := NewAuthService([]byte("first"), 0, mocks.NewMockTxManager(ctrl), mocks.NewMockUserRepository(ctrl), mockAuditRepo, zap.NewNop())
+
+	issue := func() string {
+		token, err := authService.GenerateJWT(context.Background(), domain.User{UserID: 1, Login: "test_user", Roles: domain.DefaultRoles()})
+		if err != nil {
+			t.Fatalf("failed to generate token: %v", err)
+		}
+		return token
+	}
+	verify := func(token string) error {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		_, err := authService.VerifyRequest(req)
+		return err
+	}
+
+	first := issue()
+	authService.UpdateKeys([]byte("first"), time.Hour)
+	if err := verify(first); err != nil {
+		t.Errorf("expected token to stay valid when only the TTL changes, got %v", err)
+	}
+
+	authService.UpdateKeys([]byte("second"), time.Hour)
+	second := issue()
+	testCases := []struct {
+		name      string
+		token     string
+		expectErr bool
+	}{
+		{name: "Current_Key", token: second},
+		{name: "Previous_Key", token: first},
+		{name: "Foreign_Key", token: "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9.e30.ZRrHA1JJJW8opsbCGfG_HACGpVUMN_a9IV7pAx_Zmeo", expectErr: true},
+		{name: "No_Token", token: "", expectErr: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := verify(tc.token); (err != nil) != tc.expectErr {
+				t.Errorf("expected error: %t, got %v", tc.expectErr, err)
+			}
+		})
+	}
+
+	authService.UpdateKeys([]byte("third"), time.Hour)
+	if err := verify(first); err == nil {
+		t.Error("expected token signed with a retired key to be rejected")
+	}
+}
+
 func TestGetTokenAuth(t *testing.T) {
 	t.Run("GetTokenAuth_Success", func(t *testing.T) {
 		ctrl := gomock.NewController(t)
@@ -219,7 +430,7 @@ func TestGetTokenAuth(t *testing.T) {
 		logger := zap.NewNop()
 		jwtSecret := []byte("secret")
 
-		authService := NewAuthService(jwtSecret, mockUserRepo, logger)
+		authService := NewAuthService(jwtSecret, 0, mocks.NewMockTxManager(ctrl), mockUserRepo, mocks.NewMockAuditRepository(ctrl), logger)
 
 		tokenAuth := authService.GetTokenAuth()
 
@@ -4,50 +4,67 @@ import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
 	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"context"
 	"errors"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
-	"gorm.io/gorm"
 	"time"
 )
 
 // OrderServiceInterface - интерфейс для сервиса работы с заказами.
 type OrderServiceInterface interface {
-	AddOrder(login, number string) error
-	GetOrders(login string) ([]domain.Order, error)
+	AddOrder(ctx context.Context, userID int, number string) error
+	AddOrders(ctx context.Context, userID int, numbers []string) ([]domain.OrderUploadResult, error)
+	GetOrders(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, *domain.Cursor, error)
+	RequeueOrder(ctx context.Context, number string) error
 	UpdateOrderStatuses(ctx context.Context)
-	UpdateUserBalance(tx *gorm.DB, userID int, amount decimal.Decimal) error
+	UpdateUserBalance(ctx context.Context, userID int, amount decimal.Decimal) error
 }
 
 // OrderService - представляет сервис для работы с заказами.
 type OrderService struct {
 	accrualClient AccrualService
+	auditRepo     repository.AuditRepository
 	logger        *zap.Logger
+	notifyRepo    repository.NotificationRepository
 	orderRepo     repository.OrderRepository
+	outboxRepo    repository.OutboxRepository
+	txManager     repository.TxManager
 	userRepo      repository.UserRepository
+	validator     validation.OrderNumberValidator
+	webhookRepo   repository.WebhookRepository
 }
 
 // NewOrderService - создает новый экземпляр OrderService.
-func NewOrderService(accrualClient AccrualService, orderRepo repository.OrderRepository, userRepo repository.UserRepository, logger *zap.Logger) *OrderService {
+func NewOrderService(accrualClient AccrualService, txManager repository.TxManager, orderRepo repository.OrderRepository, userRepo repository.UserRepository, auditRepo repository.AuditRepository, outboxRepo repository.OutboxRepository, webhookRepo repository.WebhookRepository, notifyRepo repository.NotificationRepository, validator validation.OrderNumberValidator, logger *zap.Logger) *OrderService {
 	return &OrderService{
 		accrualClient: accrualClient,
+		auditRepo:     auditRepo,
 		logger:        logger,
+		notifyRepo:    notifyRepo,
 		orderRepo:     orderRepo,
+		outboxRepo:    outboxRepo,
+		txManager:     txManager,
 		userRepo:      userRepo,
+		validator:     validator,
+		webhookRepo:   webhookRepo,
 	}
 }
 
 // AddOrder - добавляет новый заказ, проверяя, не был ли он уже добавлен другим пользователем.
-func (s *OrderService) AddOrder(login, number string) error {
-	// Получаем пользователя по логину
-	user, err := s.userRepo.GetUserByLogin(nil, login)
+func (s *OrderService) AddOrder(ctx context.Context, userID int, number string) error {
+	// Токен мог быть выпущен до блокировки, поэтому признак блокировки читаем из хранилища
+	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
 		return err
 	}
+	if user.Blocked {
+		return gofermartErrors.ErrUserBlocked
+	}
 
 	// Проверяем, был ли уже добавлен заказ с таким номером
-	existingOrder, err := s.orderRepo.GetOrderByNumber(nil, number)
+	existingOrder, err := s.orderRepo.GetOrderByNumber(ctx, number)
 	if err != nil && !errors.Is(err, gofermartErrors.ErrOrderNotFound) {
 		return err
 	}
@@ -69,27 +86,154 @@ func (s *OrderService) AddOrder(login, number string) error {
 		UploadedAt:  time.Now(),
 	}
 
-	err = s.orderRepo.AddOrder(nil, order)
+	// Заказ и событие аудита о его загрузке сохраняются атомарно
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.orderRepo.AddOrder(ctx, order); err != nil {
+			return err
+		}
+		return s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditOrderUploaded, user.Login, user.Login, domain.AuditPayload{
+			"order": number,
+		}))
+	})
+}
+
+// AddOrders - пакетно добавляет заказы пользователя в одной транзакции и возвращает результат по каждому номеру
+// в порядке входного списка. Номера, не прошедшие проверку валидатором, помечаются как INVALID с причиной и не сохраняются;
+// повтор номера внутри пакета считается уже загруженным текущим пользователем.
+func (s *OrderService) AddOrders(ctx context.Context, userID int, numbers []string) ([]domain.OrderUploadResult, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if user.Blocked {
+		return nil, gofermartErrors.ErrUserBlocked
 	}
 
-	return nil
-}
+	results := make([]domain.OrderUploadResult, len(numbers))
+	pending := make(map[string]int, len(numbers))
+	uploadedAt := time.Now()
+	orders := make([]domain.Order, 0, len(numbers))
+
+	for i, number := range numbers {
+		results[i].Number = number
+		if err := s.validator.Validate(user.Login, number); err != nil {
+			results[i].Status = domain.OrderUploadInvalid
+			results[i].Reason = err.Error()
+			continue
+		}
+		if _, duplicate := pending[number]; duplicate {
+			results[i].Status = domain.OrderUploadAlreadyUploaded
+			continue
+		}
+		pending[number] = i
+		orders = append(orders, domain.Order{
+			OrderNumber: number,
+			UserID:      user.UserID,
+			OrderStatus: domain.OrderStatusNew,
+			UploadedAt:  uploadedAt,
+		})
+	}
+
+	if len(orders) == 0 {
+		return results, nil
+	}
+
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		inserted, err := s.orderRepo.AddOrders(ctx, orders)
+		if err != nil {
+			return err
+		}
+		for _, number := range inserted {
+			results[pending[number]].Status = domain.OrderUploadAccepted
+			delete(pending, number)
+		}
+		if len(inserted) > 0 {
+			err = s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditOrderBatchUploaded, user.Login, user.Login, domain.AuditPayload{
+				"orders": inserted,
+				"total":  len(numbers),
+			}))
+			if err != nil {
+				return err
+			}
+		}
+		if len(pending) == 0 {
+			return nil
+		}
 
-// GetOrders - возвращает список заказов пользователя.
-func (s *OrderService) GetOrders(login string) ([]domain.Order, error) {
-	user, err := s.userRepo.GetUserByLogin(nil, login)
+		// Оставшиеся номера уже были в хранилище: определяем, кем они загружены
+		skipped := make([]string, 0, len(pending))
+		for number := range pending {
+			skipped = append(skipped, number)
+		}
+		existing, err := s.orderRepo.GetOrdersByNumbers(ctx, skipped)
+		if err != nil {
+			return err
+		}
+		for _, order := range existing {
+			if order.UserID == user.UserID {
+				results[pending[order.OrderNumber]].Status = domain.OrderUploadAlreadyUploaded
+			} else {
+				results[pending[order.OrderNumber]].Status = domain.OrderUploadConflict
+			}
+		}
+		return nil
+	})
 	if err != nil {
+		s.logger.Error("Failed to add orders batch", zap.Int("userID", userID), zap.Error(err))
 		return nil, err
 	}
 
-	orders, err := s.orderRepo.GetOrdersByUserID(nil, user.UserID)
+	return results, nil
+}
+
+// GetOrders - возвращает страницу заказов пользователя и курсор следующей страницы (nil, если страница последняя).
+func (s *OrderService) GetOrders(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, *domain.Cursor, error) {
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query := filter
+	if filter.Limit > 0 {
+		query.Limit = filter.Limit + 1
+	}
+
+	orders, err := s.orderRepo.GetOrdersByUserID(ctx, userID, query)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return orders, nil
+	if filter.Limit > 0 && len(orders) > filter.Limit {
+		orders = orders[:filter.Limit]
+		last := orders[len(orders)-1]
+		return orders, &domain.Cursor{Time: last.UploadedAt, Key: last.OrderNumber}, nil
+	}
+
+	return orders, nil, nil
+}
+
+// RequeueOrder - возвращает заказ в очередь опроса системы начисления: статус сбрасывается в NEW, блокировка обработки
+// снимается. Заказ в статусе PROCESSED повторно не опрашивается, чтобы баллы не были начислены дважды.
+func (s *OrderService) RequeueOrder(ctx context.Context, number string) error {
+	return s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		order, err := s.orderRepo.GetOrderByNumber(ctx, number)
+		if err != nil && !errors.Is(err, gofermartErrors.ErrOrderNotFound) {
+			return err
+		}
+		if order == nil {
+			return gofermartErrors.ErrOrderNotFound
+		}
+		if order.OrderStatus == domain.OrderStatusProcessed {
+			return gofermartErrors.ErrOrderAlreadyProcessed
+		}
+
+		order.OrderStatus = domain.OrderStatusNew
+		if err := s.orderRepo.UpdateOrder(ctx, *order); err != nil {
+			return err
+		}
+		if err := s.orderRepo.UnlockOrder(ctx, number); err != nil {
+			return err
+		}
+
+		s.logger.Info("Order requeued for accrual polling", zap.String("order", number))
+		return nil
+	})
 }
 
 // UpdateOrderStatuses - обновляет статусы заказов путем запроса к внешней системе начисления.
@@ -97,82 +241,114 @@ func (s *OrderService) UpdateOrderStatuses(ctx context.Context) {
 	s.logger.Info("Starting order status update")
 
 	// Получаем заказы, которые не обрабатываются (processing = FALSE)
-	orders, err := s.orderRepo.GetOrdersForProcessing(nil)
+	orders, err := s.orderRepo.GetOrdersForProcessing(ctx)
 	if err != nil {
 		s.logger.Error("Failed to fetch orders for status update", zap.Error(err))
 		return
 	}
 
 	for _, order := range orders {
-		// Начинаем новую транзакцию для обработки каждого заказа
-		tx, err := s.userRepo.BeginTransaction()
-		if err != nil {
-			s.logger.Error("Failed to start transaction", zap.Error(err))
-			continue
-		}
-
-		// Блокируем заказ для обработки, устанавливаем processing = TRUE
-		err = s.orderRepo.LockOrderForProcessing(tx, order.OrderNumber)
-		if err != nil {
-			s.logger.Error("Failed to lock order for processing", zap.String("order", order.OrderNumber), zap.Error(err))
-			if err := s.userRepo.Rollback(tx); err != nil {
-				s.logger.Error("Failed to rollback transaction", zap.Error(err))
-			}
-			continue
-		}
-
-		success := s.processOrder(ctx, order, tx)
-
-		if success {
-			if err := s.userRepo.Commit(tx); err != nil {
-				s.logger.Error("Failed to commit transaction", zap.Error(err))
-			}
-		} else {
-			if err := s.userRepo.Rollback(tx); err != nil {
-				s.logger.Error("Failed to rollback transaction", zap.Error(err))
+		// Обрабатываем каждый заказ в отдельной транзакции
+		err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+			// Блокируем заказ для обработки, устанавливаем processing = TRUE
+			if err := s.orderRepo.LockOrderForProcessing(ctx, order.OrderNumber); err != nil {
+				s.logger.Error("Failed to lock order for processing", zap.String("order", order.OrderNumber), zap.Error(err))
+				return err
 			}
+			return s.processOrder(ctx, order)
+		})
+		if err != nil {
+			s.logger.Warn("Order processing transaction rolled back", zap.String("order", order.OrderNumber), zap.Error(err))
 		}
 	}
 }
 
-// processOrder - обрабатывает конкретный заказ в рамках транзакции
-func (s *OrderService) processOrder(ctx context.Context, order domain.Order, tx *gorm.DB) bool {
+// processOrder - обрабатывает конкретный заказ в рамках транзакции из контекста.
+// События для внешних потребителей записываются в outbox и в очередь webhook пользователя в той же транзакции,
+// а изменение заказа рассылается в потоки событий пользователя после её фиксации.
+func (s *OrderService) processOrder(ctx context.Context, order domain.Order) error {
 	accrual, status, err := s.accrualClient.GetOrderAccrual(ctx, order.OrderNumber)
 	if err != nil {
 		s.logger.Warn("Failed to fetch order accrual", zap.String("order", order.OrderNumber), zap.Error(err))
-		return false
+		return err
 	}
 
 	decimalAccrual := decimal.NewFromFloat(accrual)
+	previousStatus := order.OrderStatus
+	previousAccrual := order.Accrual
 	order.OrderStatus = status
 	order.Accrual = decimalAccrual
 
-	if err := s.orderRepo.UpdateOrder(tx, order); err != nil {
+	if err := s.orderRepo.UpdateOrder(ctx, order); err != nil {
 		s.logger.Error("Failed to update order", zap.String("order", order.OrderNumber), zap.Error(err))
-		return false
+		return err
+	}
+
+	if status != previousStatus {
+		err := s.publishEvent(ctx, domain.OutboxOrderStatusChanged, order, domain.OrderStatusChangedEvent{
+			Order:          order.OrderNumber,
+			UserID:         order.UserID,
+			PreviousStatus: previousStatus,
+			Status:         status,
+			Accrual:        decimalAccrual,
+		})
+		if err != nil {
+			s.logger.Error("Failed to add order status event", zap.String("order", order.OrderNumber), zap.Error(err))
+			return err
+		}
 	}
 
 	if status == domain.OrderStatusProcessed {
-		if err := s.UpdateUserBalance(tx, order.UserID, decimalAccrual); err != nil {
+		if err := s.UpdateUserBalance(ctx, order.UserID, decimalAccrual); err != nil {
 			s.logger.Error("Failed to update user balance", zap.Int("userID", order.UserID), zap.Error(err))
-			return false
+			return err
+		}
+		err := s.publishEvent(ctx, domain.OutboxPointsAccrued, order, domain.PointsAccruedEvent{
+			Order:  order.OrderNumber,
+			UserID: order.UserID,
+			Amount: decimalAccrual,
+		})
+		if err != nil {
+			s.logger.Error("Failed to add points accrued event", zap.String("order", order.OrderNumber), zap.Error(err))
+			return err
+		}
+	}
+
+	if status != previousStatus || !decimalAccrual.Equal(previousAccrual) {
+		err := s.notifyRepo.NotifyOrderUpdate(ctx, domain.OrderUpdate{
+			Order:   order.OrderNumber,
+			UserID:  order.UserID,
+			Status:  status,
+			Accrual: decimalAccrual,
+		})
+		if err != nil {
+			s.logger.Error("Failed to notify order update", zap.String("order", order.OrderNumber), zap.Error(err))
+			return err
 		}
 	}
 
 	// Снимаем блокировку после завершения обработки заказа (processing = FALSE)
-	if err := s.orderRepo.UnlockOrder(tx, order.OrderNumber); err != nil {
+	if err := s.orderRepo.UnlockOrder(ctx, order.OrderNumber); err != nil {
 		s.logger.Error("Failed to unlock order", zap.String("order", order.OrderNumber), zap.Error(err))
-		return false
+		return err
 	}
 
 	s.logger.Info("Order processed successfully", zap.String("order", order.OrderNumber))
 
-	return true
+	return nil
+}
+
+// publishEvent - записывает событие заказа в outbox и ставит его в очередь доставки на webhook владельца заказа
+func (s *OrderService) publishEvent(ctx context.Context, eventType domain.OutboxEventType, order domain.Order, payload any) error {
+	if err := enqueueOutboxMessage(ctx, s.outboxRepo, eventType, order.OrderNumber, payload); err != nil {
+		return err
+	}
+	return enqueueWebhookDeliveries(ctx, s.webhookRepo, order.UserID, eventType, payload)
 }
 
 // UpdateUserBalance - обновляет баланс пользователя.
-func (s *OrderService) UpdateUserBalance(tx *gorm.DB, userID int, amount decimal.Decimal) error {
-	err := s.userRepo.UpdateUserBalance(tx, userID, amount)
+func (s *OrderService) UpdateUserBalance(ctx context.Context, userID int, amount decimal.Decimal) error {
+	err := s.userRepo.UpdateUserBalance(ctx, userID, amount)
 	if err != nil {
 		s.logger.Error("Failed to update user balance", zap.Int("userID", userID), zap.Error(err))
 		return err
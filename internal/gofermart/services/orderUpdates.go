@@ -0,0 +1,140 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"go.uber.org/zap"
+	"sync"
+)
+
+// defaultOrderUpdatesBuffer - сколько изменений может ожидать отправки одному подписчику
+const defaultOrderUpdatesBuffer = 64
+
+// OrderUpdateSubscriber - интерфейс подписки на изменения заказов пользователя
+type OrderUpdateSubscriber interface {
+	Subscribe(userID int) (*OrderSubscription, error)
+}
+
+// OrderUpdateBrokerOptions - параметры брокера изменений заказов
+type OrderUpdateBrokerOptions struct {
+	// MaxStreamsPerUser - максимальное количество одновременных подписок пользователя; 0 отключает ограничение
+	MaxStreamsPerUser int
+	// BufferSize - ёмкость очереди подписчика; при переполнении подписка закрывается
+	BufferSize int
+}
+
+// OrderUpdateBroker - рассылает изменения заказов подписчикам внутри процесса.
+// Изменения поступают из LISTEN/NOTIFY, поэтому подписчики получают их независимо от того,
+// какой экземпляр приложения обработал заказ.
+type OrderUpdateBroker struct {
+	mu          sync.Mutex
+	closed      bool
+	subscribers map[int]map[*OrderSubscription]struct{}
+	options     OrderUpdateBrokerOptions
+	logger      *zap.Logger
+}
+
+// OrderSubscription - подписка на изменения заказов одного пользователя
+type OrderSubscription struct {
+	broker  *OrderUpdateBroker
+	userID  int
+	updates chan domain.OrderUpdate
+}
+
+// NewOrderUpdateBroker - создает брокер изменений заказов
+func NewOrderUpdateBroker(options OrderUpdateBrokerOptions, logger *zap.Logger) *OrderUpdateBroker {
+	if options.BufferSize <= 0 {
+		options.BufferSize = defaultOrderUpdatesBuffer
+	}
+	return &OrderUpdateBroker{
+		subscribers: make(map[int]map[*OrderSubscription]struct{}),
+		options:     options,
+		logger:      logger,
+	}
+}
+
+// Subscribe - открывает подписку на изменения заказов пользователя. После Close возвращает уже закрытую подписку.
+func (b *OrderUpdateBroker) Subscribe(userID int) (*OrderSubscription, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		subscription := &OrderSubscription{broker: b, userID: userID, updates: make(chan domain.OrderUpdate)}
+		close(subscription.updates)
+		return subscription, nil
+	}
+
+	subscriptions := b.subscribers[userID]
+	if b.options.MaxStreamsPerUser > 0 && len(subscriptions) >= b.options.MaxStreamsPerUser {
+		return nil, gofermartErrors.ErrTooManyOrderStreams
+	}
+	if subscriptions == nil {
+		subscriptions = make(map[*OrderSubscription]struct{})
+		b.subscribers[userID] = subscriptions
+	}
+
+	subscription := &OrderSubscription{
+		broker:  b,
+		userID:  userID,
+		updates: make(chan domain.OrderUpdate, b.options.BufferSize),
+	}
+	subscriptions[subscription] = struct{}{}
+	return subscription, nil
+}
+
+// Publish - передаёт изменение заказа подписчикам его владельца. Публикация не блокируется:
+// подписка, которая не успевает читать изменения, закрывается, и клиент переподключается.
+func (b *OrderUpdateBroker) Publish(update domain.OrderUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for subscription := range b.subscribers[update.UserID] {
+		select {
+		case subscription.updates <- update:
+		default:
+			b.logger.Warn("Dropping slow order stream subscriber", zap.Int("userID", update.UserID))
+			b.remove(subscription)
+		}
+	}
+}
+
+// Close - закрывает все подписки, чтобы открытые потоки событий не задерживали остановку HTTP-сервера
+func (b *OrderUpdateBroker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subscriptions := range b.subscribers {
+		for subscription := range subscriptions {
+			b.remove(subscription)
+		}
+	}
+}
+
+// remove - удаляет подписку и закрывает её канал; вызывается под блокировкой
+func (b *OrderUpdateBroker) remove(subscription *OrderSubscription) {
+	subscriptions, ok := b.subscribers[subscription.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subscriptions[subscription]; !ok {
+		return
+	}
+	delete(subscriptions, subscription)
+	if len(subscriptions) == 0 {
+		delete(b.subscribers, subscription.userID)
+	}
+	close(subscription.updates)
+}
+
+// Updates - канал изменений заказов; закрывается после Close или при переполнении очереди подписчика
+func (s *OrderSubscription) Updates() <-chan domain.OrderUpdate {
+	return s.updates
+}
+
+// Close - закрывает подписку; повторный вызов безопасен
+func (s *OrderSubscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
@@ -0,0 +1,127 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"errors"
+	"go.uber.org/zap"
+	"testing"
+)
+
+func TestOrderUpdateBroker_Publish(t *testing.T) {
+	broker := NewOrderUpdateBroker(OrderUpdateBrokerOptions{}, zap.NewNop())
+
+	first, err := broker.Subscribe(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	second, err := broker.Subscribe(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	other, err := broker.Subscribe(2)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	update := domain.OrderUpdate{Order: "12345678903", UserID: 1, Status: domain.OrderStatusProcessing}
+	broker.Publish(update)
+
+	for _, subscription := range []*OrderSubscription{first, second} {
+		select {
+		case received := <-subscription.Updates():
+			if received != update {
+				t.Errorf("expected %+v, got %+v", update, received)
+			}
+		default:
+			t.Error("expected update to be delivered to every subscription of the owner")
+		}
+	}
+	select {
+	case received := <-other.Updates():
+		t.Errorf("unexpected update for another user: %+v", received)
+	default:
+	}
+}
+
+func TestOrderUpdateBroker_Subscribe(t *testing.T) {
+	testCases := []struct {
+		name          string
+		maxStreams    int
+		open          int
+		expectedError error
+	}{
+		{name: "Within_Limit", maxStreams: 2, open: 1},
+		{name: "Limit_Reached", maxStreams: 2, open: 2, expectedError: gofermartErrors.ErrTooManyOrderStreams},
+		{name: "Unlimited", open: 10},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			broker := NewOrderUpdateBroker(OrderUpdateBrokerOptions{MaxStreamsPerUser: tc.maxStreams}, zap.NewNop())
+			for i := 0; i < tc.open; i++ {
+				if _, err := broker.Subscribe(1); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+
+			subscription, err := broker.Subscribe(1)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("expected error %v, got %v", tc.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			// Закрытая подписка освобождает место под новую
+			subscription.Close()
+			subscription.Close()
+			if _, err := broker.Subscribe(1); err != nil {
+				t.Errorf("unexpected error after close: %v", err)
+			}
+		})
+	}
+}
+
+func TestOrderUpdateBroker_SlowSubscriberIsDropped(t *testing.T) {
+	broker := NewOrderUpdateBroker(OrderUpdateBrokerOptions{MaxStreamsPerUser: 1, BufferSize: 1}, zap.NewNop())
+	subscription, err := broker.Subscribe(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	broker.Publish(domain.OrderUpdate{Order: "1", UserID: 1})
+	broker.Publish(domain.OrderUpdate{Order: "2", UserID: 1})
+
+	if update, ok := <-subscription.Updates(); !ok || update.Order != "1" {
+		t.Errorf("expected buffered update, got %+v (open: %t)", update, ok)
+	}
+	if _, ok := <-subscription.Updates(); ok {
+		t.Error("expected subscription to be closed after overflow")
+	}
+	subscription.Close()
+	if _, err := broker.Subscribe(1); err != nil {
+		t.Errorf("expected dropped subscription to free its slot, got %v", err)
+	}
+}
+
+func TestOrderUpdateBroker_Close(t *testing.T) {
+	broker := NewOrderUpdateBroker(OrderUpdateBrokerOptions{}, zap.NewNop())
+	subscription, err := broker.Subscribe(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	broker.Close()
+	if _, ok := <-subscription.Updates(); ok {
+		t.Error("expected open subscription to be closed")
+	}
+
+	late, err := broker.Subscribe(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := <-late.Updates(); ok {
+		t.Error("expected subscription opened after close to be closed")
+	}
+	late.Close()
+}
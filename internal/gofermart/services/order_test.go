@@ -3,6 +3,7 @@ package services
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/validation"
 	"beliaev-aa/yp-gofermart/tests/mocks"
 	"bytes"
 	"context"
@@ -11,8 +12,8 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gorm.io/gorm"
 	"testing"
+	"time"
 )
 
 func TestOrderService_AddOrder(t *testing.T) {
@@ -21,74 +22,101 @@ func TestOrderService_AddOrder(t *testing.T) {
 
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
 
 	logger := zap.NewNop()
-	orderService := NewOrderService(nil, mockOrderRepo, mockUserRepo, logger)
+	orderService := NewOrderService(nil, mockTxManager, mockOrderRepo, mockUserRepo, mockAuditRepo, mocks.NewMockOutboxRepository(ctrl), mocks.NewMockWebhookRepository(ctrl), mocks.NewMockNotificationRepository(ctrl), validation.LuhnValidator{}, logger)
 
 	testCases := []struct {
 		name          string
-		login         string
+		userID        int
 		orderNumber   string
 		setupMocks    func()
 		expectedError error
 	}{
 		{
 			name:        "User_Not_Found",
-			login:       "user1",
+			userID:      1,
 			orderNumber: "123456789",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(nil, gofermartErrors.ErrUserNotFound)
 			},
 			expectedError: gofermartErrors.ErrUserNotFound,
 		},
+		{
+			name:        "Blocked_User",
+			userID:      1,
+			orderNumber: "123456789",
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1, Blocked: true}, nil)
+			},
+			expectedError: gofermartErrors.ErrUserBlocked,
+		},
 		{
 			name:        "GetOrderByNumber_Unexpected_Error",
-			login:       "user1",
+			userID:      1,
 			orderNumber: "123456789",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
 				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "123456789").Return(nil, errors.New("unexpected error"))
 			},
 			expectedError: errors.New("unexpected error"),
 		},
 		{
 			name:        "Order_Already_Uploaded_Same_User",
-			login:       "user1",
+			userID:      1,
 			orderNumber: "123456789",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
 				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "123456789").Return(&domain.Order{UserID: 1}, nil)
 			},
 			expectedError: gofermartErrors.ErrOrderAlreadyUploaded,
 		},
 		{
 			name:        "Order_Already_Uploaded_Different_User",
-			login:       "user1",
+			userID:      1,
 			orderNumber: "123456789",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
 				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "123456789").Return(&domain.Order{UserID: 2}, nil)
 			},
 			expectedError: gofermartErrors.ErrOrderUploadedByAnother,
 		},
 		{
 			name:        "Order_Not_Found_Add_Success",
-			login:       "user1",
+			userID:      1,
 			orderNumber: "123456789",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
 				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "123456789").Return(nil, gofermartErrors.ErrOrderNotFound)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().AddOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditOrderUploaded)).Return(nil)
 			},
 			expectedError: nil,
 		},
+		{
+			name:        "Audit_Failure",
+			userID:      1,
+			orderNumber: "123456789",
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
+				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "123456789").Return(nil, gofermartErrors.ErrOrderNotFound)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().AddOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(errors.New("audit error"))
+			},
+			expectedError: errors.New("audit error"),
+		},
 		{
 			name:        "Add_Order_Failure",
-			login:       "user1",
+			userID:      1,
 			orderNumber: "123456789",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
 				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "123456789").Return(nil, gofermartErrors.ErrOrderNotFound)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().AddOrder(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
 			},
 			expectedError: errors.New("db error"),
@@ -99,7 +127,7 @@ func TestOrderService_AddOrder(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			err := orderService.AddOrder(tc.login, tc.orderNumber)
+			err := orderService.AddOrder(context.Background(), tc.userID, tc.orderNumber)
 
 			if err != nil && tc.expectedError == nil {
 				t.Errorf("Expected no error, got %v", err)
@@ -119,40 +147,29 @@ func TestOrderService_GetOrders(t *testing.T) {
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	logger := zap.NewNop()
-	orderService := NewOrderService(nil, mockOrderRepo, mockUserRepo, logger)
+	orderService := NewOrderService(nil, mocks.NewMockTxManager(ctrl), mockOrderRepo, mockUserRepo, mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), mocks.NewMockWebhookRepository(ctrl), mocks.NewMockNotificationRepository(ctrl), validation.LuhnValidator{}, logger)
 
 	testCases := []struct {
 		name           string
-		login          string
+		userID         int
 		setupMocks     func()
 		expectedError  error
 		expectedOrders []domain.Order
 	}{
 		{
-			name:  "User_Not_Found",
-			login: "user1",
+			name:   "GetOrdersByUserID_Error",
+			userID: 1,
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
-			},
-			expectedError:  gofermartErrors.ErrUserNotFound,
-			expectedOrders: nil,
-		},
-		{
-			name:  "GetOrdersByUserID_Error",
-			login: "user1",
-			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
-				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1).Return(nil, errors.New("db error"))
+				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1, domain.OrderFilter{}).Return(nil, errors.New("db error"))
 			},
 			expectedError:  errors.New("db error"),
 			expectedOrders: nil,
 		},
 		{
-			name:  "GetOrders_Success",
-			login: "user1",
+			name:   "GetOrders_Success",
+			userID: 1,
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
-				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1).Return([]domain.Order{
+				mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1, domain.OrderFilter{}).Return([]domain.Order{
 					{OrderNumber: "123456789", UserID: 1, OrderStatus: domain.OrderStatusNew},
 					{OrderNumber: "987654321", UserID: 1, OrderStatus: domain.OrderStatusProcessed},
 				}, nil)
@@ -169,7 +186,7 @@ func TestOrderService_GetOrders(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			orders, err := orderService.GetOrders(tc.login)
+			orders, _, err := orderService.GetOrders(context.Background(), tc.userID, domain.OrderFilter{})
 
 			if err != nil && tc.expectedError == nil {
 				t.Errorf("Expected no error, got %v", err)
@@ -187,6 +204,202 @@ func TestOrderService_GetOrders(t *testing.T) {
 	}
 }
 
+func TestOrderService_GetOrders_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	orderService := NewOrderService(nil, mocks.NewMockTxManager(ctrl), mockOrderRepo, mockUserRepo, mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), mocks.NewMockWebhookRepository(ctrl), mocks.NewMockNotificationRepository(ctrl), validation.LuhnValidator{}, zap.NewNop())
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	page := []domain.Order{
+		{OrderNumber: "3", UserID: 1, UploadedAt: now},
+		{OrderNumber: "2", UserID: 1, UploadedAt: now.Add(-time.Minute)},
+		{OrderNumber: "1", UserID: 1, UploadedAt: now.Add(-2 * time.Minute)},
+	}
+
+	testCases := []struct {
+		name           string
+		limit          int
+		repoRows       []domain.Order
+		expectedOrders []domain.Order
+		expectedNext   *domain.Cursor
+	}{
+		{
+			name:           "Has_Next_Page",
+			limit:          2,
+			repoRows:       page,
+			expectedOrders: page[:2],
+			expectedNext:   &domain.Cursor{Time: page[1].UploadedAt, Key: "2"},
+		},
+		{
+			name:           "Last_Page",
+			limit:          3,
+			repoRows:       page,
+			expectedOrders: page,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := domain.OrderFilter{
+				ListOptions: domain.ListOptions{Limit: tc.limit},
+				Statuses:    []string{domain.OrderStatusNew},
+			}
+			// Репозиторий запрашивается на одну запись больше размера страницы
+			repoFilter := filter
+			repoFilter.Limit = tc.limit + 1
+
+			mockOrderRepo.EXPECT().GetOrdersByUserID(gomock.Any(), 1, repoFilter).Return(tc.repoRows, nil)
+
+			orders, next, err := orderService.GetOrders(context.Background(), 1, filter)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedOrders, orders); diff != "" {
+				t.Errorf("unexpected orders (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedNext, next); diff != "" {
+				t.Errorf("unexpected next cursor (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOrderService_AddOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	orderService := NewOrderService(nil, mockTxManager, mockOrderRepo, mockUserRepo, mockAuditRepo, mocks.NewMockOutboxRepository(ctrl), mocks.NewMockWebhookRepository(ctrl), mocks.NewMockNotificationRepository(ctrl), validation.LuhnValidator{}, zap.NewNop())
+
+	testCases := []struct {
+		name            string
+		numbers         []string
+		setupMocks      func()
+		expectedResults []domain.OrderUploadResult
+		expectedError   bool
+	}{
+		{
+			name:    "Mixed_Batch",
+			numbers: []string{"79927398713", "12345678903", "9278923470", "123", "79927398713"},
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().AddOrders(gomock.Any(), gomock.Len(3)).Return([]string{"79927398713"}, nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditOrderBatchUploaded)).DoAndReturn(func(ctx context.Context, event domain.AuditEvent) error {
+					want := domain.AuditPayload{"orders": []string{"79927398713"}, "total": 5}
+					if diff := cmp.Diff(want, event.Payload); diff != "" {
+						t.Errorf("audit payload mismatch (-want +got):\n%s", diff)
+					}
+					return nil
+				})
+				mockOrderRepo.EXPECT().GetOrdersByNumbers(gomock.Any(), gomock.Len(2)).Return([]domain.Order{
+					{OrderNumber: "12345678903", UserID: 1},
+					{OrderNumber: "9278923470", UserID: 2},
+				}, nil)
+			},
+			expectedResults: []domain.OrderUploadResult{
+				{Number: "79927398713", Status: domain.OrderUploadAccepted},
+				{Number: "12345678903", Status: domain.OrderUploadAlreadyUploaded},
+				{Number: "9278923470", Status: domain.OrderUploadConflict},
+				{Number: "123", Status: domain.OrderUploadInvalid, Reason: "order number checksum is invalid"},
+				{Number: "79927398713", Status: domain.OrderUploadAlreadyUploaded},
+			},
+		},
+		{
+			name:    "Only_Invalid_Numbers",
+			numbers: []string{"123", "abc"},
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
+			},
+			expectedResults: []domain.OrderUploadResult{
+				{Number: "123", Status: domain.OrderUploadInvalid, Reason: "order number checksum is invalid"},
+				{Number: "abc", Status: domain.OrderUploadInvalid, Reason: "order number must contain only digits"},
+			},
+		},
+		{
+			name:    "Repository_Error",
+			numbers: []string{"79927398713"},
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByID(gomock.Any(), 1).Return(&domain.User{UserID: 1}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().AddOrders(gomock.Any(), gomock.Any()).Return(nil, errors.New("database error"))
+			},
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			results, err := orderService.AddOrders(context.Background(), 1, tc.numbers)
+			if (err != nil) != tc.expectedError {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedResults, results); diff != "" {
+				t.Errorf("unexpected results (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOrderService_RequeueOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	orderService := NewOrderService(nil, mockTxManager, mockOrderRepo, mocks.NewMockUserRepository(ctrl), mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), mocks.NewMockWebhookRepository(ctrl), mocks.NewMockNotificationRepository(ctrl), validation.LuhnValidator{}, zap.NewNop())
+
+	testCases := []struct {
+		name          string
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name: "Invalid_Order_Requeued",
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "79927398713").Return(&domain.Order{OrderNumber: "79927398713", UserID: 1, OrderStatus: domain.OrderStatusInvalid, IsProcessing: true}, nil)
+				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), domain.Order{OrderNumber: "79927398713", UserID: 1, OrderStatus: domain.OrderStatusNew, IsProcessing: true}).Return(nil)
+				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "79927398713").Return(nil)
+			},
+		},
+		{
+			name: "Processed_Order",
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "79927398713").Return(&domain.Order{OrderNumber: "79927398713", OrderStatus: domain.OrderStatusProcessed}, nil)
+			},
+			expectedError: gofermartErrors.ErrOrderAlreadyProcessed,
+		},
+		{
+			name: "Unknown_Order",
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().GetOrderByNumber(gomock.Any(), "79927398713").Return(nil, nil)
+			},
+			expectedError: gofermartErrors.ErrOrderNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			if err := orderService.RequeueOrder(context.Background(), "79927398713"); !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
 func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -194,6 +407,10 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 	mockOrderRepo := mocks.NewMockOrderRepository(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockAccrualClient := mocks.NewMockAccrualService(ctrl)
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockOutboxRepo := mocks.NewMockOutboxRepository(ctrl)
+	mockWebhookRepo := mocks.NewMockWebhookRepository(ctrl)
+	mockNotifyRepo := mocks.NewMockNotificationRepository(ctrl)
 
 	var logBuffer bytes.Buffer
 	logger := zap.New(zapcore.NewCore(
@@ -202,7 +419,7 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 		zapcore.DebugLevel,
 	))
 
-	orderService := NewOrderService(mockAccrualClient, mockOrderRepo, mockUserRepo, logger)
+	orderService := NewOrderService(mockAccrualClient, mockTxManager, mockOrderRepo, mockUserRepo, mocks.NewMockAuditRepository(ctrl), mockOutboxRepo, mockWebhookRepo, mockNotifyRepo, validation.LuhnValidator{}, logger)
 
 	testCases := []struct {
 		name        string
@@ -215,13 +432,17 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
 				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(100.0, domain.OrderStatusProcessed, nil)
 				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxPointsAccrued)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
+				mockNotifyRepo.EXPECT().NotifyOrderUpdate(gomock.Any(), gomock.Any()).Return(nil)
 				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "order123").Return(nil)
-				mockUserRepo.EXPECT().Commit(gomock.Any()).Return(nil)
 			},
 			expectedLog: `"msg":"Order processed successfully"`,
 		},
@@ -231,9 +452,8 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(errors.New("failed to lock order"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectedLog: `"msg":"Failed to lock order for processing"`,
 		},
@@ -243,13 +463,17 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
 				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(100.0, domain.OrderStatusProcessed, nil)
 				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxPointsAccrued)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
+				mockNotifyRepo.EXPECT().NotifyOrderUpdate(gomock.Any(), gomock.Any()).Return(nil)
 				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "order123").Return(nil)
-				mockUserRepo.EXPECT().Commit(gomock.Any()).Return(nil)
 			},
 			expectedLog: `"msg":"Order processed successfully"`,
 		},
@@ -266,9 +490,9 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(nil, errors.New("failed to start transaction"))
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).Return(errors.New("failed to start transaction"))
 			},
-			expectedLog: `"msg":"Failed to start transaction"`,
+			expectedLog: `"msg":"Order processing transaction rolled back"`,
 		},
 		{
 			name: "Failed_To_Commit_Transaction",
@@ -276,40 +500,36 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					if err := fn(ctx); err != nil {
+						return err
+					}
+					return errors.New("failed to commit transaction")
+				})
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
 				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(100.0, domain.OrderStatusProcessed, nil)
 				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxPointsAccrued)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
+				mockNotifyRepo.EXPECT().NotifyOrderUpdate(gomock.Any(), gomock.Any()).Return(nil)
 				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "order123").Return(nil)
-				mockUserRepo.EXPECT().Commit(gomock.Any()).Return(errors.New("failed to commit transaction"))
 			},
-			expectedLog: `"msg":"Failed to commit transaction"`,
+			expectedLog: `"msg":"Order processing transaction rolled back"`,
 		},
 		{
-			name: "Failed_To_Rollback_Transaction",
+			name: "Rollback_On_Accrual_Error",
 			setupMocks: func() {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
 				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(0.0, domain.OrderStatusNew, errors.New("accrual error"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(errors.New("failed to rollback transaction"))
 			},
-			expectedLog: `"msg":"Failed to rollback transaction"`,
-		},
-		{
-			name: "Failed_To_Rollback_Transaction_After_LockOrder_Error",
-			setupMocks: func() {
-				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
-					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
-				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
-				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(errors.New("failed to lock order for processing"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(errors.New("failed to rollback transaction"))
-			},
-			expectedLog: `"msg":"Failed to rollback transaction"`,
+			expectedLog: `"msg":"Failed to fetch order accrual"`,
 		},
 		{
 			name: "Failed_To_Update_Order",
@@ -317,11 +537,10 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
 				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(100.0, domain.OrderStatusProcessed, nil)
 				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(errors.New("failed to update order"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectedLog: `"msg":"Failed to update order"`,
 		},
@@ -331,28 +550,146 @@ func TestOrderService_UpdateOrderStatuses(t *testing.T) {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusProcessed},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
 				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(100.0, domain.OrderStatusProcessed, nil)
 				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("failed to update user balance"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectedLog: `"msg":"Failed to update user balance"`,
 		},
+		{
+			name: "Failed_To_Add_Outbox_Message",
+			setupMocks: func() {
+				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
+					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusProcessing},
+				}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
+				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(0.0, domain.OrderStatusInvalid, nil)
+				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(errors.New("db error"))
+			},
+			expectedLog: `"msg":"Failed to add order status event"`,
+		},
+		{
+			name: "Webhook_Deliveries_Enqueued",
+			setupMocks: func() {
+				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
+					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusProcessing},
+				}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
+				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(100.0, domain.OrderStatusProcessed, nil)
+				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				webhooks := []domain.Webhook{
+					{WebhookID: 1, UserID: 1, Events: []domain.OutboxEventType{domain.OutboxOrderStatusChanged}},
+					{WebhookID: 2, UserID: 1, Events: []domain.OutboxEventType{domain.OutboxPointsAccrued}},
+				}
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(webhooks, nil)
+				mockWebhookRepo.EXPECT().AddDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, delivery domain.WebhookDelivery) error {
+					if delivery.WebhookID != 1 || delivery.EventType != domain.OutboxOrderStatusChanged || delivery.Status != domain.WebhookDeliveryPending {
+						t.Errorf("unexpected delivery: %+v", delivery)
+					}
+					return nil
+				})
+				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxPointsAccrued)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(webhooks, nil)
+				mockWebhookRepo.EXPECT().AddDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, delivery domain.WebhookDelivery) error {
+					if delivery.WebhookID != 2 || delivery.EventType != domain.OutboxPointsAccrued {
+						t.Errorf("unexpected delivery: %+v", delivery)
+					}
+					return nil
+				})
+				mockNotifyRepo.EXPECT().NotifyOrderUpdate(gomock.Any(), gomock.Any()).Return(nil)
+				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "order123").Return(nil)
+			},
+			expectedLog: `"msg":"Order processed successfully"`,
+		},
+		{
+			name: "Failed_To_Add_Webhook_Delivery",
+			setupMocks: func() {
+				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
+					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusProcessing},
+				}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
+				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(0.0, domain.OrderStatusInvalid, nil)
+				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, errors.New("db error"))
+			},
+			expectedLog: `"msg":"Failed to add order status event"`,
+		},
+		{
+			name: "Order_Update_Notified",
+			setupMocks: func() {
+				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
+					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
+				}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
+				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(0.0, domain.OrderStatusProcessing, nil)
+				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
+				mockNotifyRepo.EXPECT().NotifyOrderUpdate(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, update domain.OrderUpdate) error {
+					if update.Order != "order123" || update.UserID != 1 || update.Status != domain.OrderStatusProcessing || !update.Accrual.IsZero() {
+						t.Errorf("unexpected order update: %+v", update)
+					}
+					return nil
+				})
+				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "order123").Return(nil)
+			},
+			expectedLog: `"msg":"Order processed successfully"`,
+		},
+		{
+			name: "Unchanged_Order_Not_Notified",
+			setupMocks: func() {
+				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
+					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusProcessing},
+				}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
+				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(0.0, domain.OrderStatusProcessing, nil)
+				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "order123").Return(nil)
+			},
+			expectedLog: `"msg":"Order processed successfully"`,
+		},
+		{
+			name: "Failed_To_Notify_Order_Update",
+			setupMocks: func() {
+				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
+					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusNew},
+				}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
+				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(0.0, domain.OrderStatusProcessing, nil)
+				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxOrderStatusChanged)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
+				mockNotifyRepo.EXPECT().NotifyOrderUpdate(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
+			},
+			expectedLog: `"msg":"Failed to notify order update"`,
+		},
 		{
 			name: "Failed_To_Unlock_Order",
 			setupMocks: func() {
 				mockOrderRepo.EXPECT().GetOrdersForProcessing(gomock.Any()).Return([]domain.Order{
 					{OrderNumber: "order123", UserID: 1, OrderStatus: domain.OrderStatusProcessed},
 				}, nil)
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
 				mockOrderRepo.EXPECT().LockOrderForProcessing(gomock.Any(), "order123").Return(nil)
 				mockAccrualClient.EXPECT().GetOrderAccrual(gomock.Any(), "order123").Return(100.0, domain.OrderStatusProcessed, nil)
 				mockOrderRepo.EXPECT().UpdateOrder(gomock.Any(), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxPointsAccrued)).Return(nil)
+				mockWebhookRepo.EXPECT().GetWebhooksByUserID(gomock.Any(), 1).Return(nil, nil)
+				mockNotifyRepo.EXPECT().NotifyOrderUpdate(gomock.Any(), gomock.Any()).Return(nil)
 				mockOrderRepo.EXPECT().UnlockOrder(gomock.Any(), "order123").Return(errors.New("failed to unlock order"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil)
 			},
 			expectedLog: `"msg":"Failed to unlock order"`,
 		},
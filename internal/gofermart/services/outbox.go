@@ -0,0 +1,144 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/outbox"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"time"
+)
+
+// Параметры доставки событий outbox по умолчанию
+const (
+	defaultOutboxBatchSize   = 100
+	defaultOutboxLease       = time.Minute
+	defaultOutboxRetryDelay  = time.Second
+	defaultOutboxRetryMax    = 10 * time.Minute
+	defaultOutboxMaxAttempts = 20
+)
+
+// OutboxRelayer - доставка накопившихся событий outbox, запускаемая фоновым процессом
+type OutboxRelayer interface {
+	RelayMessages(ctx context.Context) (int, error)
+}
+
+// OutboxRelayOptions - параметры доставки событий; нулевые значения заменяются значениями по умолчанию
+type OutboxRelayOptions struct {
+	BatchSize  int           // сколько событий выбирается за один проход
+	Lease      time.Duration // на сколько откладывается выбранное событие, пока идёт доставка
+	RetryDelay time.Duration // задержка перед первой повторной попыткой, далее удваивается
+	RetryMax   time.Duration // наибольшая задержка между попытками
+	// MaxAttempts — после стольких неудачных попыток событие отмечается недоставляемым и перестаёт задерживать
+	// события своего ключа
+	MaxAttempts int
+}
+
+// OutboxRelay - доставляет события из таблицы outbox получателю не менее одного раза: событие отмечается
+// доставленным только после подтверждения получателя, а при ошибке откладывается с экспоненциальной задержкой,
+// пока не будут исчерпаны попытки.
+type OutboxRelay struct {
+	outboxRepo repository.OutboxRepository
+	sink       outbox.Sink
+	options    OutboxRelayOptions
+	logger     *zap.Logger
+}
+
+// NewOutboxRelay - создает новый экземпляр OutboxRelay
+func NewOutboxRelay(outboxRepo repository.OutboxRepository, sink outbox.Sink, options OutboxRelayOptions, logger *zap.Logger) *OutboxRelay {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultOutboxBatchSize
+	}
+	if options.Lease <= 0 {
+		options.Lease = defaultOutboxLease
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = defaultOutboxRetryDelay
+	}
+	if options.RetryMax <= 0 {
+		options.RetryMax = defaultOutboxRetryMax
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultOutboxMaxAttempts
+	}
+	return &OutboxRelay{
+		outboxRepo: outboxRepo,
+		sink:       sink,
+		options:    options,
+		logger:     logger,
+	}
+}
+
+// RelayMessages - доставляет одну порцию событий и возвращает количество доставленных. Порция содержит не больше
+// одного события на ключ, поэтому ошибка доставки не даёт более поздним событиям того же ключа обогнать его.
+func (r *OutboxRelay) RelayMessages(ctx context.Context) (int, error) {
+	messages, err := r.outboxRepo.ClaimMessages(ctx, time.Now(), r.options.Lease, r.options.BatchSize)
+	if err != nil {
+		r.logger.Error("Failed to claim outbox messages", zap.Error(err))
+		return 0, err
+	}
+
+	delivered := 0
+	for _, message := range messages {
+		if err := ctx.Err(); err != nil {
+			// Невыполненные события вернутся в очередь по истечении аренды
+			return delivered, err
+		}
+
+		if err := r.sink.Publish(ctx, message); err != nil {
+			if message.Attempts+1 >= r.options.MaxAttempts {
+				r.logger.Error("Outbox message failed permanently",
+					zap.Int("messageID", message.MessageID), zap.String("type", string(message.Type)),
+					zap.String("key", message.Key), zap.Int("attempts", message.Attempts+1), zap.Error(err))
+				if err := r.outboxRepo.MarkDead(ctx, message.MessageID, time.Now(), err.Error()); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			delay := r.retryDelay(message.Attempts)
+			r.logger.Warn("Failed to publish outbox message",
+				zap.Int("messageID", message.MessageID), zap.String("type", string(message.Type)),
+				zap.Int("attempt", message.Attempts+1), zap.Duration("retry_in", delay), zap.Error(err))
+			if err := r.outboxRepo.MarkFailed(ctx, message.MessageID, time.Now().Add(delay), err.Error()); err != nil {
+				return delivered, err
+			}
+			continue
+		}
+
+		// Если отметка не сохранится, событие будет доставлено повторно после истечения аренды
+		if err := r.outboxRepo.MarkDelivered(ctx, message.MessageID, time.Now()); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+	return delivered, nil
+}
+
+// retryDelay - задержка перед следующей попыткой: удваивается с каждой неудачей, но не превышает RetryMax
+func (r *OutboxRelay) retryDelay(attempts int) time.Duration {
+	return backoffDelay(r.options.RetryDelay, r.options.RetryMax, attempts)
+}
+
+// backoffDelay - экспоненциальная задержка: base, удвоенная attempts раз, но не более maxDelay
+func backoffDelay(base, maxDelay time.Duration, attempts int) time.Duration {
+	delay := base
+	for i := 0; i < attempts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// enqueueOutboxMessage - добавляет событие в outbox в транзакции из контекста; тело события кодируется в JSON
+func enqueueOutboxMessage(ctx context.Context, outboxRepo repository.OutboxRepository, eventType domain.OutboxEventType, key string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return outboxRepo.AddMessage(ctx, domain.OutboxMessage{
+		Type:      eventType,
+		Key:       key,
+		Payload:   data,
+		CreatedAt: time.Now(),
+	})
+}
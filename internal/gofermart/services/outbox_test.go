@@ -0,0 +1,126 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+// outboxMessageMatcher - сопоставляет событие outbox по его виду
+type outboxMessageMatcher struct {
+	eventType domain.OutboxEventType
+}
+
+func (m outboxMessageMatcher) Matches(x interface{}) bool {
+	message, ok := x.(domain.OutboxMessage)
+	return ok && message.Type == m.eventType
+}
+
+func (m outboxMessageMatcher) String() string {
+	return fmt.Sprintf("outbox message of type %s", m.eventType)
+}
+
+// outboxMessageOfType - ожидание события outbox указанного вида
+func outboxMessageOfType(eventType domain.OutboxEventType) gomock.Matcher {
+	return outboxMessageMatcher{eventType: eventType}
+}
+
+func TestOutboxRelay_RelayMessages(t *testing.T) {
+	messages := []domain.OutboxMessage{
+		{MessageID: 1, Type: domain.OutboxOrderStatusChanged, Key: "12345678903"},
+		{MessageID: 2, Type: domain.OutboxPointsAccrued, Key: "12345678903", Attempts: 2},
+	}
+
+	testCases := []struct {
+		name              string
+		setupMocks        func(repo *mocks.MockOutboxRepository, sink *mocks.MockSink)
+		maxAttempts       int
+		expectedDelivered int
+		expectErr         bool
+	}{
+		{
+			name: "All_Delivered",
+			setupMocks: func(repo *mocks.MockOutboxRepository, sink *mocks.MockSink) {
+				repo.EXPECT().ClaimMessages(gomock.Any(), gomock.Any(), time.Minute, 10).Return(messages, nil)
+				gomock.InOrder(
+					sink.EXPECT().Publish(gomock.Any(), messages[0]).Return(nil),
+					repo.EXPECT().MarkDelivered(gomock.Any(), 1, gomock.Any()).Return(nil),
+					sink.EXPECT().Publish(gomock.Any(), messages[1]).Return(nil),
+					repo.EXPECT().MarkDelivered(gomock.Any(), 2, gomock.Any()).Return(nil),
+				)
+			},
+			expectedDelivered: 2,
+		},
+		{
+			name: "Failed_Message_Is_Rescheduled",
+			setupMocks: func(repo *mocks.MockOutboxRepository, sink *mocks.MockSink) {
+				repo.EXPECT().ClaimMessages(gomock.Any(), gomock.Any(), time.Minute, 10).Return(messages, nil)
+				sink.EXPECT().Publish(gomock.Any(), messages[0]).Return(nil)
+				repo.EXPECT().MarkDelivered(gomock.Any(), 1, gomock.Any()).Return(nil)
+				sink.EXPECT().Publish(gomock.Any(), messages[1]).Return(errors.New("connection refused"))
+				repo.EXPECT().MarkFailed(gomock.Any(), 2, gomock.Any(), "connection refused").DoAndReturn(func(_ context.Context, _ int, nextAttemptAt time.Time, _ string) error {
+					// После двух неудачных попыток задержка — 4 секунды
+					if delay := time.Until(nextAttemptAt); delay < 3*time.Second || delay > 4*time.Second {
+						t.Errorf("unexpected retry delay: %v", delay)
+					}
+					return nil
+				})
+			},
+			expectedDelivered: 1,
+		},
+		{
+			name: "Exhausted_Message_Is_Marked_Dead",
+			setupMocks: func(repo *mocks.MockOutboxRepository, sink *mocks.MockSink) {
+				exhausted := domain.OutboxMessage{MessageID: 3, Type: domain.OutboxPointsAccrued, Key: "79927398713", Attempts: 2}
+				repo.EXPECT().ClaimMessages(gomock.Any(), gomock.Any(), time.Minute, 10).Return([]domain.OutboxMessage{exhausted}, nil)
+				sink.EXPECT().Publish(gomock.Any(), exhausted).Return(errors.New("connection refused"))
+				repo.EXPECT().MarkDead(gomock.Any(), 3, gomock.Any(), "connection refused").Return(nil)
+			},
+			maxAttempts: 3,
+		},
+		{
+			name: "Claim_Error",
+			setupMocks: func(repo *mocks.MockOutboxRepository, sink *mocks.MockSink) {
+				repo.EXPECT().ClaimMessages(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("db error"))
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockOutboxRepository(ctrl)
+			sink := mocks.NewMockSink(ctrl)
+			tc.setupMocks(repo, sink)
+
+			relay := NewOutboxRelay(repo, sink, OutboxRelayOptions{BatchSize: 10, MaxAttempts: tc.maxAttempts}, zap.NewNop())
+			delivered, err := relay.RelayMessages(context.Background())
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if delivered != tc.expectedDelivered {
+				t.Errorf("expected %d delivered, got %d", tc.expectedDelivered, delivered)
+			}
+		})
+	}
+}
+
+func TestOutboxRelay_RetryDelay(t *testing.T) {
+	relay := NewOutboxRelay(nil, nil, OutboxRelayOptions{RetryDelay: time.Second, RetryMax: time.Minute}, zap.NewNop())
+
+	expected := map[int]time.Duration{0: time.Second, 1: 2 * time.Second, 5: 32 * time.Second, 6: time.Minute, 100: time.Minute}
+	for attempts, delay := range expected {
+		if got := relay.retryDelay(attempts); got != delay {
+			t.Errorf("attempts %d: expected %v, got %v", attempts, delay, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// RateLimiter - ограничивает частоту запросов по группам маршрутов. Корзины разрешений хранятся в репозитории:
+// в памяти процесса или в PostgreSQL, чтобы все экземпляры приложения соблюдали общее ограничение.
+type RateLimiter struct {
+	rateLimitRepo repository.RateLimitRepository
+	logger        *zap.Logger
+
+	mu       sync.RWMutex
+	policies map[string]domain.RateLimitPolicy
+}
+
+// NewRateLimiter - создает новый экземпляр RateLimiter с ограничениями групп маршрутов
+func NewRateLimiter(rateLimitRepo repository.RateLimitRepository, policies map[string]domain.RateLimitPolicy, logger *zap.Logger) *RateLimiter {
+	limiter := &RateLimiter{
+		rateLimitRepo: rateLimitRepo,
+		logger:        logger,
+	}
+	limiter.SetPolicies(policies)
+	return limiter
+}
+
+// SetPolicies - заменяет ограничения групп маршрутов; используется при перезагрузке конфигурации.
+// Накопленные корзины сохраняются и приводятся к новому размеру при следующем запросе.
+func (l *RateLimiter) SetPolicies(policies map[string]domain.RateLimitPolicy) {
+	copied := make(map[string]domain.RateLimitPolicy, len(policies))
+	for group, policy := range policies {
+		copied[group] = policy
+	}
+
+	l.mu.Lock()
+	l.policies = copied
+	l.mu.Unlock()
+}
+
+// Allow - расходует разрешение на запрос группы маршрутов по ключу (пользователь или IP-адрес). Возвращает false
+// вторым значением, если для группы ограничение не задано. Если хранилище корзин недоступно, запрос разрешается:
+// отказ ограничителя не должен останавливать API.
+func (l *RateLimiter) Allow(ctx context.Context, group, key string) (domain.RateLimitDecision, bool) {
+	l.mu.RLock()
+	policy := l.policies[group]
+	l.mu.RUnlock()
+	if !policy.Enabled() {
+		return domain.RateLimitDecision{Allowed: true}, false
+	}
+
+	decision, err := l.rateLimitRepo.Take(ctx, group+":"+key, policy, time.Now())
+	if err != nil {
+		l.logger.Warn("Rate limiter is unavailable, request allowed", zap.String("group", group), zap.Error(err))
+		return domain.RateLimitDecision{Allowed: true}, false
+	}
+	return decision, true
+}
+
+// Prune - удаляет корзины, которые за время самого долгого периода ограничения заполнились бы полностью
+func (l *RateLimiter) Prune(ctx context.Context) (int64, error) {
+	l.mu.RLock()
+	var longest time.Duration
+	for _, policy := range l.policies {
+		if policy.Enabled() && policy.Period > longest {
+			longest = policy.Period
+		}
+	}
+	l.mu.RUnlock()
+
+	pruned, err := l.rateLimitRepo.Prune(ctx, time.Now().Add(-longest))
+	if err != nil {
+		l.logger.Error("Failed to prune rate limit buckets", zap.Error(err))
+		return 0, err
+	}
+	return pruned, nil
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter_Allow(t *testing.T) {
+	policies := map[string]domain.RateLimitPolicy{
+		domain.RateLimitAuth: {Requests: 2, Period: time.Minute},
+	}
+
+	testCases := []struct {
+		name            string
+		group           string
+		keys            []string
+		expectedAllowed []bool
+		expectedLimited bool
+	}{
+		{
+			name:            "Denies_After_Policy_Is_Exhausted",
+			group:           domain.RateLimitAuth,
+			keys:            []string{"ip:192.0.2.1", "ip:192.0.2.1", "ip:192.0.2.1"},
+			expectedAllowed: []bool{true, true, false},
+			expectedLimited: true,
+		},
+		{
+			name:            "Keys_Are_Limited_Separately",
+			group:           domain.RateLimitAuth,
+			keys:            []string{"ip:192.0.2.1", "ip:192.0.2.1", "ip:192.0.2.2"},
+			expectedAllowed: []bool{true, true, true},
+			expectedLimited: true,
+		},
+		{
+			name:            "Group_Without_Policy_Is_Not_Limited",
+			group:           domain.RateLimitAPI,
+			keys:            []string{"user:1", "user:1", "user:1"},
+			expectedAllowed: []bool{true, true, true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter := NewRateLimiter(repository.NewRateLimitRepositoryMemory(), policies, zap.NewNop())
+
+			for i, key := range tc.keys {
+				decision, limited := limiter.Allow(context.Background(), tc.group, key)
+				if decision.Allowed != tc.expectedAllowed[i] {
+					t.Errorf("request %d: expected allowed %v, got %v", i+1, tc.expectedAllowed[i], decision.Allowed)
+				}
+				if limited != tc.expectedLimited {
+					t.Errorf("request %d: expected limited %v, got %v", i+1, tc.expectedLimited, limited)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimiter_AllowsWhenStoreFails(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRateLimitRepository(ctrl)
+	mockRepo.EXPECT().Take(gomock.Any(), "api:user:1", gomock.Any(), gomock.Any()).Return(domain.RateLimitDecision{}, errors.New("connection refused"))
+
+	limiter := NewRateLimiter(mockRepo, map[string]domain.RateLimitPolicy{domain.RateLimitAPI: {Requests: 1, Period: time.Second}}, zap.NewNop())
+	decision, limited := limiter.Allow(context.Background(), domain.RateLimitAPI, "user:1")
+
+	if !decision.Allowed || limited {
+		t.Errorf("expected request to be allowed without limit headers, got %+v, limited %v", decision, limited)
+	}
+}
+
+func TestRateLimiter_SetPolicies(t *testing.T) {
+	limiter := NewRateLimiter(repository.NewRateLimitRepositoryMemory(), map[string]domain.RateLimitPolicy{
+		domain.RateLimitAPI: {Requests: 1, Period: time.Hour},
+	}, zap.NewNop())
+
+	if decision, _ := limiter.Allow(context.Background(), domain.RateLimitAPI, "user:1"); !decision.Allowed {
+		t.Fatal("expected the first request to be allowed")
+	}
+	if decision, _ := limiter.Allow(context.Background(), domain.RateLimitAPI, "user:1"); decision.Allowed {
+		t.Fatal("expected the second request to be denied")
+	}
+
+	// Ограничение снимается без перезапуска
+	limiter.SetPolicies(map[string]domain.RateLimitPolicy{})
+	if decision, limited := limiter.Allow(context.Background(), domain.RateLimitAPI, "user:1"); !decision.Allowed || limited {
+		t.Errorf("expected request to be allowed after the policy was removed, got %+v, limited %v", decision, limited)
+	}
+}
+
+func TestRateLimiter_Prune(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRateLimitRepository(ctrl)
+	limiter := NewRateLimiter(mockRepo, map[string]domain.RateLimitPolicy{
+		domain.RateLimitAuth: {Requests: 10, Period: time.Minute},
+		domain.RateLimitAPI:  {Requests: 600, Period: time.Hour},
+	}, zap.NewNop())
+
+	mockRepo.EXPECT().Prune(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+		// Корзины хранятся не меньше самого долгого периода ограничения
+		if idle := time.Since(before); idle < time.Hour || idle > time.Hour+time.Minute {
+			t.Errorf("expected buckets idle for an hour to be pruned, got cutoff %v ago", idle)
+		}
+		return 3, nil
+	})
+
+	pruned, err := limiter.Prune(context.Background())
+	if err != nil || pruned != 3 {
+		t.Errorf("expected 3 pruned buckets, got %d, %v", pruned, err)
+	}
+}
@@ -0,0 +1,390 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/statement"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"strconv"
+	"time"
+)
+
+// statementPageSize — сколько записей каждого вида читается из хранилища за один запрос при построении выписки
+const statementPageSize = 500
+
+// MonthlyStatementGenerator - формирование ежемесячных выписок, запускаемое планировщиком
+type MonthlyStatementGenerator interface {
+	GenerateMonthlyStatements(ctx context.Context, period time.Time) error
+}
+
+// StatementExporter - потоковая выгрузка выписки пользователя за период
+type StatementExporter interface {
+	OpenStatement(userID int, from, to time.Time) statement.Entries
+}
+
+// MonthlyStatementProvider - получение ежемесячной PDF-выписки пользователя
+type MonthlyStatementProvider interface {
+	GetMonthlyStatement(ctx context.Context, userID int, period time.Time) (*domain.Statement, error)
+}
+
+// StatementService - строит выписку пользователя: заказы с начислениями, выводы средств и корректировки баланса
+// в хронологическом порядке, а также формирует и хранит ежемесячные PDF-выписки
+type StatementService struct {
+	adjustmentRepo repository.AdjustmentRepository
+	logger         *zap.Logger
+	now            func() time.Time
+	orderRepo      repository.OrderRepository
+	pageSize       int
+	statementRepo  repository.StatementRepository
+	userRepo       repository.UserRepository
+	withdrawalRepo repository.WithdrawalRepository
+}
+
+// NewStatementService - создает новый экземпляр StatementService
+func NewStatementService(userRepo repository.UserRepository, orderRepo repository.OrderRepository, withdrawalRepo repository.WithdrawalRepository, adjustmentRepo repository.AdjustmentRepository, statementRepo repository.StatementRepository, logger *zap.Logger) *StatementService {
+	return &StatementService{
+		adjustmentRepo: adjustmentRepo,
+		logger:         logger,
+		now:            time.Now,
+		orderRepo:      orderRepo,
+		pageSize:       statementPageSize,
+		statementRepo:  statementRepo,
+		userRepo:       userRepo,
+		withdrawalRepo: withdrawalRepo,
+	}
+}
+
+// OpenStatement - готовит потоковую выписку пользователя за период [from, to); нулевые границы не ограничивают период.
+// Если задано начало периода, первой строкой выписки идет входящий баланс.
+// Записи читаются лениво, поэтому обращений к хранилищу до первого вызова Next нет.
+func (s *StatementService) OpenStatement(userID int, from, to time.Time) statement.Entries {
+	return s.newIterator(userID, from, to)
+}
+
+// GenerateMonthlyStatements - формирует PDF-выписки за месяц, которому принадлежит period, для всех пользователей,
+// у которых их ещё нет и у которых до конца месяца были операции. Повторный запуск за тот же месяц ничего не меняет.
+func (s *StatementService) GenerateMonthlyStatements(ctx context.Context, period time.Time) error {
+	period = statement.MonthStart(period)
+	name := statement.FormatPeriod(period)
+	s.logger.Info("Generating monthly statements", zap.String("period", name))
+
+	generated := 0
+	afterID := 0
+	for {
+		users, err := s.userRepo.ListUsers(ctx, afterID, s.pageSize)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			_, err := s.statementRepo.GetStatement(ctx, user.UserID, name)
+			if err == nil {
+				continue
+			}
+			if !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+				return err
+			}
+			// Пользователям, у которых до конца месяца не было операций, выписка не формируется
+			active, err := s.hasActivityBefore(ctx, user.UserID, period.AddDate(0, 1, 0))
+			if err != nil {
+				s.logger.Error("Failed to read statement entries", zap.Int("userID", user.UserID), zap.Error(err))
+				return err
+			}
+			if !active {
+				continue
+			}
+			if _, err := s.generateMonthlyStatement(ctx, user, period); err != nil {
+				return err
+			}
+			generated++
+		}
+
+		if len(users) < s.pageSize {
+			break
+		}
+		afterID = users[len(users)-1].UserID
+	}
+
+	s.logger.Info("Monthly statements generated", zap.String("period", name), zap.Int("count", generated))
+	return nil
+}
+
+// GetMonthlyStatement - возвращает PDF-выписку пользователя за месяц. Выписку за завершившийся месяц, которую
+// планировщик ещё не сформировал, формируем по запросу; за текущий и будущие месяцы, а также за месяцы до первой
+// операции пользователя выписок нет.
+func (s *StatementService) GetMonthlyStatement(ctx context.Context, userID int, period time.Time) (*domain.Statement, error) {
+	period = statement.MonthStart(period)
+	stored, err := s.statementRepo.GetStatement(ctx, userID, statement.FormatPeriod(period))
+	if err == nil || !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+		return stored, err
+	}
+
+	if !period.Before(statement.MonthStart(s.now())) {
+		return nil, gofermartErrors.ErrStatementNotFound
+	}
+
+	// Логин нужен только для заголовка формируемой выписки
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Выписки за месяцы до первой операции пользователя не формируются и не сохраняются
+	active, err := s.hasActivityBefore(ctx, userID, period.AddDate(0, 1, 0))
+	if err != nil {
+		s.logger.Error("Failed to read statement entries", zap.Int("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	if !active {
+		return nil, gofermartErrors.ErrStatementNotFound
+	}
+	return s.generateMonthlyStatement(ctx, *user, period)
+}
+
+// hasActivityBefore — есть ли у пользователя заказы, выводы или корректировки баланса раньше момента end
+func (s *StatementService) hasActivityBefore(ctx context.Context, userID int, end time.Time) (bool, error) {
+	options := domain.ListOptions{To: end, Limit: 1}
+
+	orders, err := s.orderRepo.GetOrdersByUserID(ctx, userID, domain.OrderFilter{ListOptions: options})
+	if err != nil || len(orders) > 0 {
+		return len(orders) > 0, err
+	}
+	withdrawals, err := s.withdrawalRepo.GetWithdrawalsByUserID(ctx, userID, domain.WithdrawalFilter{ListOptions: options})
+	if err != nil || len(withdrawals) > 0 {
+		return len(withdrawals) > 0, err
+	}
+	adjustments, err := s.adjustmentRepo.GetAdjustmentsByUserID(ctx, userID, options)
+	return len(adjustments) > 0, err
+}
+
+// generateMonthlyStatement — формирует и сохраняет PDF-выписку пользователя за месяц
+func (s *StatementService) generateMonthlyStatement(ctx context.Context, user domain.User, period time.Time) (*domain.Statement, error) {
+	report := statement.MonthlyReport{Login: user.Login, Period: period}
+
+	entries := s.newIterator(user.UserID, period, period.AddDate(0, 1, 0))
+	for {
+		entry, ok, err := entries.Next(ctx)
+		if err != nil {
+			s.logger.Error("Failed to read statement entries", zap.Int("userID", user.UserID), zap.Error(err))
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+
+		switch {
+		case entry.Type == domain.StatementEntryOpening:
+			report.Opening = entry.Balance
+		case entry.Type == domain.StatementEntryWithdrawal:
+			report.Withdrawals = append(report.Withdrawals, entry)
+		case entry.Type == domain.StatementEntryAdjustment:
+			report.Adjustments = append(report.Adjustments, entry)
+		case !entry.Amount.IsZero():
+			report.Accruals = append(report.Accruals, entry)
+		}
+		report.Closing = entry.Balance
+	}
+
+	var content bytes.Buffer
+	if err := statement.WritePDF(&content, report); err != nil {
+		return nil, err
+	}
+
+	result := domain.Statement{
+		UserID:    user.UserID,
+		Period:    statement.FormatPeriod(period),
+		Content:   content.Bytes(),
+		CreatedAt: s.now(),
+	}
+	if err := s.statementRepo.SaveStatement(ctx, result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// newIterator — готовит потоковую выписку пользователя за период [from, to)
+func (s *StatementService) newIterator(userID int, from, to time.Time) *StatementIterator {
+	// Баланс накапливается с начала истории, поэтому записи до начала периода тоже читаются, но не выдаются
+	options := domain.ListOptions{To: to, Limit: s.pageSize, Ascending: true}
+	return &StatementIterator{
+		from:        from,
+		openingDone: from.IsZero(),
+		orders: &pager[domain.Order]{
+			pageSize: s.pageSize,
+			fetch: func(ctx context.Context, after *domain.Cursor) ([]domain.Order, error) {
+				filter := domain.OrderFilter{ListOptions: options}
+				filter.After = after
+				return s.orderRepo.GetOrdersByUserID(ctx, userID, filter)
+			},
+			cursor: func(order domain.Order) domain.Cursor {
+				return domain.Cursor{Time: order.UploadedAt, Key: order.OrderNumber}
+			},
+		},
+		withdrawals: &pager[domain.Withdrawal]{
+			pageSize: s.pageSize,
+			fetch: func(ctx context.Context, after *domain.Cursor) ([]domain.Withdrawal, error) {
+				filter := domain.WithdrawalFilter{ListOptions: options}
+				filter.After = after
+				return s.withdrawalRepo.GetWithdrawalsByUserID(ctx, userID, filter)
+			},
+			cursor: func(withdrawal domain.Withdrawal) domain.Cursor {
+				return domain.Cursor{Time: withdrawal.ProcessedAt, Key: strconv.Itoa(withdrawal.WithdrawalID)}
+			},
+		},
+		adjustments: &pager[domain.BalanceAdjustment]{
+			pageSize: s.pageSize,
+			fetch: func(ctx context.Context, after *domain.Cursor) ([]domain.BalanceAdjustment, error) {
+				page := options
+				page.After = after
+				return s.adjustmentRepo.GetAdjustmentsByUserID(ctx, userID, page)
+			},
+			cursor: func(adjustment domain.BalanceAdjustment) domain.Cursor {
+				return domain.Cursor{Time: adjustment.CreatedAt, Key: strconv.Itoa(adjustment.AdjustmentID)}
+			},
+		},
+	}
+}
+
+// StatementIterator - последовательно выдает строки выписки, сливая постраничные выборки заказов, выводов
+// и корректировок баланса. В памяти одновременно находится не больше одной страницы каждого вида.
+type StatementIterator struct {
+	adjustments *pager[domain.BalanceAdjustment]
+	balance     decimal.Decimal
+	from        time.Time
+	openingDone bool
+	orders      *pager[domain.Order]
+	pending     *domain.StatementEntry
+	withdrawals *pager[domain.Withdrawal]
+}
+
+// Next - возвращает следующую строку выписки; false означает, что выписка закончилась
+func (it *StatementIterator) Next(ctx context.Context) (domain.StatementEntry, bool, error) {
+	if it.pending != nil {
+		entry := *it.pending
+		it.pending = nil
+		return entry, true, nil
+	}
+
+	for {
+		entry, ok, err := it.nextOperation(ctx)
+		if err != nil || (!ok && it.openingDone) {
+			return domain.StatementEntry{}, false, err
+		}
+
+		if ok {
+			it.balance = it.balance.Add(entry.Amount)
+			entry.Balance = it.balance
+			if entry.Time.Before(it.from) {
+				continue
+			}
+		}
+
+		if !it.openingDone {
+			// Входящий баланс выдается перед первой операцией периода (или один, если операций нет)
+			it.openingDone = true
+			opening := domain.StatementEntry{Time: it.from, Type: domain.StatementEntryOpening, Balance: it.balance.Sub(entry.Amount)}
+			if ok {
+				it.pending = &entry
+			}
+			return opening, true, nil
+		}
+		return entry, true, nil
+	}
+}
+
+// nextOperation — выбирает самую раннюю из очередных операций; при равном времени заказ идет первым,
+// затем вывод, затем корректировка
+func (it *StatementIterator) nextOperation(ctx context.Context) (domain.StatementEntry, bool, error) {
+	order, hasOrder, err := it.orders.peek(ctx)
+	if err != nil {
+		return domain.StatementEntry{}, false, err
+	}
+	withdrawal, hasWithdrawal, err := it.withdrawals.peek(ctx)
+	if err != nil {
+		return domain.StatementEntry{}, false, err
+	}
+	adjustment, hasAdjustment, err := it.adjustments.peek(ctx)
+	if err != nil {
+		return domain.StatementEntry{}, false, err
+	}
+
+	// Корректировка выдается, только если она строго раньше остальных очередных операций
+	if hasAdjustment && (!hasOrder || adjustment.CreatedAt.Before(order.UploadedAt)) &&
+		(!hasWithdrawal || adjustment.CreatedAt.Before(withdrawal.ProcessedAt)) {
+		it.adjustments.pop()
+		return domain.StatementEntry{
+			Time:   adjustment.CreatedAt,
+			Type:   domain.StatementEntryAdjustment,
+			Amount: adjustment.Amount,
+		}, true, nil
+	}
+
+	switch {
+	case hasOrder && (!hasWithdrawal || !withdrawal.ProcessedAt.Before(order.UploadedAt)):
+		it.orders.pop()
+		entry := domain.StatementEntry{
+			Time:        order.UploadedAt,
+			Type:        domain.StatementEntryOrder,
+			OrderNumber: order.OrderNumber,
+			Status:      order.OrderStatus,
+		}
+		// Баланс меняют только начисления по обработанным заказам
+		if order.OrderStatus == domain.OrderStatusProcessed {
+			entry.Amount = order.Accrual
+		}
+		return entry, true, nil
+	case hasWithdrawal:
+		it.withdrawals.pop()
+		return domain.StatementEntry{
+			Time:        withdrawal.ProcessedAt,
+			Type:        domain.StatementEntryWithdrawal,
+			OrderNumber: withdrawal.OrderNumber,
+			Amount:      withdrawal.Amount.Neg(),
+		}, true, nil
+	default:
+		return domain.StatementEntry{}, false, nil
+	}
+}
+
+// pager — постраничное чтение упорядоченной выборки с keyset-курсором
+type pager[T any] struct {
+	after    *domain.Cursor
+	buffer   []T
+	cursor   func(T) domain.Cursor
+	done     bool
+	fetch    func(ctx context.Context, after *domain.Cursor) ([]T, error)
+	pageSize int
+}
+
+// peek — возвращает очередную запись, при необходимости дочитывая следующую страницу
+func (p *pager[T]) peek(ctx context.Context) (T, bool, error) {
+	var zero T
+	if len(p.buffer) == 0 && !p.done {
+		rows, err := p.fetch(ctx, p.after)
+		if err != nil {
+			return zero, false, err
+		}
+		if len(rows) < p.pageSize {
+			p.done = true
+		}
+		if len(rows) > 0 {
+			last := p.cursor(rows[len(rows)-1])
+			p.after = &last
+		}
+		p.buffer = rows
+	}
+	if len(p.buffer) == 0 {
+		return zero, false, nil
+	}
+	return p.buffer[0], true, nil
+}
+
+// pop — снимает выданную запись
+func (p *pager[T]) pop() {
+	p.buffer = p.buffer[1:]
+}
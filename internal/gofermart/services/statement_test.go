@@ -0,0 +1,223 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/statement"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestStatementService_OpenStatement(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	db := repository.NewMemoryDB(logger)
+	userRepo := repository.NewUserRepositoryMemory(db)
+	orderRepo := repository.NewOrderRepositoryMemory(db)
+	withdrawalRepo := repository.NewWithdrawalRepositoryMemory(db)
+	adjustmentRepo := repository.NewAdjustmentRepositoryMemory(db)
+
+	if err := userRepo.SaveUser(ctx, domain.User{Login: "user1", Password: "hash"}); err != nil {
+		t.Fatalf("failed to save user: %v", err)
+	}
+	user, _ := userRepo.GetUserByLogin(ctx, "user1")
+
+	base := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		err := orderRepo.AddOrder(ctx, domain.Order{
+			OrderNumber: string(rune('a' + i)), UserID: user.UserID, OrderStatus: domain.OrderStatusProcessed,
+			Accrual: decimal.NewFromInt(100), UploadedAt: base.Add(time.Duration(2*i) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("failed to add order: %v", err)
+		}
+		err = withdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{
+			OrderNumber: "w", UserID: user.UserID, Amount: decimal.NewFromInt(30), ProcessedAt: base.Add(time.Duration(2*i+1) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("failed to add withdrawal: %v", err)
+		}
+	}
+
+	// Корректировка поддержкой после всех операций тоже меняет баланс выписки
+	err := adjustmentRepo.AddAdjustment(ctx, domain.BalanceAdjustment{UserID: user.UserID, Amount: decimal.NewFromInt(50), Reason: "goodwill", Actor: "support", CreatedAt: base.Add(11 * time.Hour)})
+	if err != nil {
+		t.Fatalf("failed to add adjustment: %v", err)
+	}
+
+	collect := func(service *StatementService, from, to time.Time) []domain.StatementEntry {
+		entries := service.OpenStatement(user.UserID, from, to)
+		var result []domain.StatementEntry
+		for {
+			entry, ok, err := entries.Next(ctx)
+			if err != nil {
+				t.Fatalf("failed to read statement: %v", err)
+			}
+			if !ok {
+				return result
+			}
+			result = append(result, entry)
+		}
+	}
+
+	service := NewStatementService(userRepo, orderRepo, withdrawalRepo, adjustmentRepo, repository.NewStatementRepositoryMemory(db), logger)
+	paged := NewStatementService(userRepo, orderRepo, withdrawalRepo, adjustmentRepo, repository.NewStatementRepositoryMemory(db), logger)
+	paged.pageSize = 2
+
+	t.Run("Page_Size_Does_Not_Change_Result", func(t *testing.T) {
+		full := collect(service, time.Time{}, time.Time{})
+		if len(full) != 11 {
+			t.Fatalf("expected 11 entries, got %d", len(full))
+		}
+		if last := full[len(full)-1]; last.Type != domain.StatementEntryAdjustment || !last.Balance.Equal(decimal.NewFromInt(400)) {
+			t.Errorf("expected adjustment with closing balance 400, got %+v", last)
+		}
+		if diff := cmp.Diff(full, collect(paged, time.Time{}, time.Time{})); diff != "" {
+			t.Errorf("paged statement differs (-full +paged):\n%s", diff)
+		}
+	})
+
+	t.Run("Opening_Balance_For_Period", func(t *testing.T) {
+		entries := collect(paged, base.Add(3*time.Hour), base.Add(5*time.Hour))
+		if len(entries) != 3 {
+			t.Fatalf("expected opening balance and 2 entries, got %+v", entries)
+		}
+		if entries[0].Type != domain.StatementEntryOpening || !entries[0].Balance.Equal(decimal.NewFromInt(170)) {
+			t.Errorf("unexpected opening entry: %+v", entries[0])
+		}
+		if !entries[2].Balance.Equal(decimal.NewFromInt(240)) {
+			t.Errorf("unexpected closing balance: %s", entries[2].Balance)
+		}
+	})
+}
+
+func TestStatementService_MonthlyStatements(t *testing.T) {
+	ctx := context.Background()
+	logger := zap.NewNop()
+	db := repository.NewMemoryDB(logger)
+	userRepo := repository.NewUserRepositoryMemory(db)
+	orderRepo := repository.NewOrderRepositoryMemory(db)
+	withdrawalRepo := repository.NewWithdrawalRepositoryMemory(db)
+	adjustmentRepo := repository.NewAdjustmentRepositoryMemory(db)
+	statementRepo := repository.NewStatementRepositoryMemory(db)
+
+	for _, login := range []string{"user1", "user2", "user3"} {
+		if err := userRepo.SaveUser(ctx, domain.User{Login: login, Password: "hash"}); err != nil {
+			t.Fatalf("failed to save user: %v", err)
+		}
+	}
+	user, _ := userRepo.GetUserByLogin(ctx, "user1")
+
+	may := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	err := orderRepo.AddOrder(ctx, domain.Order{
+		OrderNumber: "12345678903", UserID: user.UserID, OrderStatus: domain.OrderStatusProcessed,
+		Accrual: decimal.NewFromInt(100), UploadedAt: may.Add(-time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("failed to add order: %v", err)
+	}
+	err = withdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{OrderNumber: "79927398713", UserID: user.UserID, Amount: decimal.NewFromInt(40), ProcessedAt: may.Add(time.Hour)})
+	if err != nil {
+		t.Fatalf("failed to add withdrawal: %v", err)
+	}
+	err = adjustmentRepo.AddAdjustment(ctx, domain.BalanceAdjustment{UserID: user.UserID, Amount: decimal.NewFromInt(-10), Reason: "chargeback", Actor: "support", CreatedAt: may.Add(2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("failed to add adjustment: %v", err)
+	}
+	// user2 начал пользоваться сервисом до мая, user3 — уже после него
+	active, _ := userRepo.GetUserByLogin(ctx, "user2")
+	err = withdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{OrderNumber: "4561261212345467", UserID: active.UserID, Amount: decimal.NewFromInt(5), ProcessedAt: may.AddDate(0, -1, 0)})
+	if err != nil {
+		t.Fatalf("failed to add withdrawal: %v", err)
+	}
+	late, _ := userRepo.GetUserByLogin(ctx, "user3")
+	err = orderRepo.AddOrder(ctx, domain.Order{OrderNumber: "49927398716", UserID: late.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: may.AddDate(0, 1, 1)})
+	if err != nil {
+		t.Fatalf("failed to add order: %v", err)
+	}
+
+	service := NewStatementService(userRepo, orderRepo, withdrawalRepo, adjustmentRepo, statementRepo, logger)
+	service.pageSize = 2
+	service.now = func() time.Time { return time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC) }
+
+	t.Run("Generate_For_Active_Users", func(t *testing.T) {
+		if err := service.GenerateMonthlyStatements(ctx, may.Add(10*24*time.Hour)); err != nil {
+			t.Fatalf("failed to generate statements: %v", err)
+		}
+		for _, login := range []string{"user1", "user2"} {
+			u, _ := userRepo.GetUserByLogin(ctx, login)
+			if _, err := statementRepo.GetStatement(ctx, u.UserID, "2024-05"); err != nil {
+				t.Errorf("expected statement for %s, got %v", login, err)
+			}
+		}
+		if _, err := statementRepo.GetStatement(ctx, late.UserID, "2024-05"); !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+			t.Errorf("expected no statement for a user active only after the period, got %v", err)
+		}
+
+		stored, _ := statementRepo.GetStatement(ctx, user.UserID, "2024-05")
+		content := string(stored.Content)
+		for _, line := range []string{"Opening balance", "100.00", "79927398713", "Adjustments", "-10.00", "Closing balance", "50.00"} {
+			if !strings.Contains(content, line) {
+				t.Errorf("expected statement to contain %q", line)
+			}
+		}
+	})
+
+	t.Run("Generation_Is_Deterministic", func(t *testing.T) {
+		first, _ := statementRepo.GetStatement(ctx, user.UserID, "2024-05")
+
+		other := NewStatementService(userRepo, orderRepo, withdrawalRepo, adjustmentRepo, repository.NewStatementRepositoryMemory(repository.NewMemoryDB(logger)), logger)
+		other.now = time.Now
+		second, err := other.GetMonthlyStatement(ctx, user.UserID, may)
+		if err != nil {
+			t.Fatalf("failed to generate statement: %v", err)
+		}
+		if diff := cmp.Diff(first.Content, second.Content); diff != "" {
+			t.Errorf("statement content is not deterministic (-first +second):\n%s", diff)
+		}
+	})
+
+	t.Run("Current_Month_Not_Available", func(t *testing.T) {
+		_, err := service.GetMonthlyStatement(ctx, user.UserID, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC))
+		if !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+			t.Errorf("expected %v, got %v", gofermartErrors.ErrStatementNotFound, err)
+		}
+	})
+
+	t.Run("Before_First_Activity_Not_Available", func(t *testing.T) {
+		testCases := []struct {
+			name   string
+			userID int
+			period time.Time
+		}{
+			{name: "Month_Before_First_Order", userID: user.UserID, period: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{name: "Distant_Past", userID: user.UserID, period: time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC)},
+			{name: "User_Active_Only_Later", userID: late.UserID, period: time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				_, err := service.GetMonthlyStatement(ctx, tc.userID, tc.period)
+				if !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+					t.Errorf("expected %v, got %v", gofermartErrors.ErrStatementNotFound, err)
+				}
+				if _, err := statementRepo.GetStatement(ctx, tc.userID, statement.FormatPeriod(tc.period)); !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+					t.Errorf("expected no stored statement, got %v", err)
+				}
+			})
+		}
+	})
+
+	t.Run("First_Activity_Month", func(t *testing.T) {
+		april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+		if _, err := service.GetMonthlyStatement(ctx, user.UserID, april); err != nil {
+			t.Errorf("expected statement for the month of the first order, got %v", err)
+		}
+	})
+}
@@ -4,32 +4,57 @@ import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
 	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
 	"errors"
+	"fmt"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
+	"strconv"
+	"strings"
 	"time"
 )
 
-// UserService - отвечает за операции с пользователями, включая получение баланса и вывод средств
+// UserService - отвечает за операции с пользователями, включая получение баланса, вывод средств
+// и действия поддержки: корректировку баланса и блокировку. Изменения записываются в журнал аудита.
 type UserService struct {
+	adjustmentRepo repository.AdjustmentRepository
+	auditRepo      repository.AuditRepository
 	logger         *zap.Logger
+	outboxRepo     repository.OutboxRepository
+	txManager      repository.TxManager
 	userRepo       repository.UserRepository
 	withdrawalRepo repository.WithdrawalRepository
 }
 
 // NewUserService - создает новый экземпляр UserService
-func NewUserService(userRepo repository.UserRepository, withdrawalRepo repository.WithdrawalRepository, logger *zap.Logger) *UserService {
+func NewUserService(txManager repository.TxManager, userRepo repository.UserRepository, withdrawalRepo repository.WithdrawalRepository, adjustmentRepo repository.AdjustmentRepository, auditRepo repository.AuditRepository, outboxRepo repository.OutboxRepository, logger *zap.Logger) *UserService {
 	return &UserService{
+		adjustmentRepo: adjustmentRepo,
+		auditRepo:      auditRepo,
 		logger:         logger,
+		outboxRepo:     outboxRepo,
+		txManager:      txManager,
 		userRepo:       userRepo,
 		withdrawalRepo: withdrawalRepo,
 	}
 }
 
-// GetBalance возвращает текущий баланс пользователя по его логину
-func (s *UserService) GetBalance(login string) (*domain.UserBalance, error) {
+// GetUser возвращает пользователя по логину
+func (s *UserService) GetUser(ctx context.Context, login string) (*domain.User, error) {
+	user, err := s.userRepo.GetUserByLogin(ctx, login)
+	if err != nil {
+		if !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+			s.logger.Error("Error getting user", zap.Error(err))
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetBalance возвращает текущий баланс пользователя по его идентификатору
+func (s *UserService) GetBalance(ctx context.Context, userID int) (*domain.UserBalance, error) {
 	// Получаем баланс пользователя и сумму снятых средств из хранилища
-	userBalance, err := s.userRepo.GetUserBalance(nil, login)
+	userBalance, err := s.userRepo.GetUserBalance(ctx, userID)
 	if err != nil {
 		s.logger.Error("Failed to get user balance", zap.Error(err))
 		return nil, err
@@ -38,90 +63,225 @@ func (s *UserService) GetBalance(login string) (*domain.UserBalance, error) {
 	return userBalance, nil
 }
 
-// Withdraw обрабатывает запрос на вывод средств для указанного пользователя и заказа
-func (s *UserService) Withdraw(login, order string, sum decimal.Decimal) error {
-	// Получаем информацию о пользователе по логину
-	user, err := s.userRepo.GetUserByLogin(nil, login)
+// GetVersion возвращает версию данных пользователя, которая растёт при каждом изменении баланса, заказов и выводов
+func (s *UserService) GetVersion(ctx context.Context, userID int) (int64, error) {
+	version, err := s.userRepo.GetUserVersion(ctx, userID)
 	if err != nil {
-		s.logger.Error("Failed to get user", zap.Error(err))
-		return err
+		if !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+			s.logger.Error("Failed to get user version", zap.Error(err))
+		}
+		return 0, err
 	}
+	return version, nil
+}
 
-	// Проверка на отрицательную сумму при выводе средств
-	if sum.LessThan(decimal.NewFromInt(0)) {
+// Withdraw обрабатывает запрос на вывод средств для указанного пользователя и заказа
+func (s *UserService) Withdraw(ctx context.Context, userID int, order string, sum decimal.Decimal) error {
+	// Сумма вывода должна быть положительной
+	if !sum.IsPositive() {
 		return gofermartErrors.ErrInvalidWithdrawalAmount
 	}
 
-	// Проверяем, достаточно ли средств для вывода
-	if user.Balance.LessThan(sum) {
-		return gofermartErrors.ErrInsufficientFunds
-	}
+	// Проверка баланса, запись о выводе и списание выполняются атомарно: строка пользователя блокируется
+	// до конца транзакции, поэтому параллельные выводы не могут пройти проверку по одному и тому же балансу
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetUserForUpdate(ctx, userID)
+		if err != nil {
+			s.logger.Error("Failed to get user", zap.Error(err))
+			return err
+		}
 
-	// Создаем запись о выводе средств
-	withdrawal := domain.Withdrawal{
-		OrderNumber: order,
-		UserID:      user.UserID,
-		Amount:      sum,
-		ProcessedAt: time.Now(),
-	}
+		// Заблокированный пользователь не может выводить средства
+		if user.Blocked {
+			return gofermartErrors.ErrUserBlocked
+		}
 
-	tx, err := s.userRepo.BeginTransaction()
-	if err != nil {
-		s.logger.Error("Failed to begin transaction", zap.Error(err))
-		return err
-	}
+		// Проверяем, достаточно ли средств для вывода
+		if user.Balance.LessThan(sum) {
+			return gofermartErrors.ErrInsufficientFunds
+		}
+
+		withdrawal := domain.Withdrawal{
+			OrderNumber: order,
+			UserID:      user.UserID,
+			Amount:      sum,
+			ProcessedAt: time.Now(),
+		}
+
+		// Добавляем информацию о выводе в хранилище
+		if err := s.withdrawalRepo.AddWithdrawal(ctx, withdrawal); err != nil {
+			s.logger.Error("Failed to add withdrawal", zap.Error(err))
+			return err
+		}
+
+		if err := s.userRepo.UpdateUserBalance(ctx, user.UserID, sum.Neg()); err != nil {
+			s.logger.Error("Failed to update user balance", zap.Error(err))
+			return err
+		}
+
+		err = s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditBalanceWithdrawn, user.Login, user.Login, domain.AuditPayload{
+			"order": order,
+			"sum":   sum.String(),
+		}))
+		if err != nil {
+			return err
+		}
 
-	// Добавляем информацию о выводе в хранилище
-	err = s.withdrawalRepo.AddWithdrawal(tx, withdrawal)
+		return enqueueOutboxMessage(ctx, s.outboxRepo, domain.OutboxPointsWithdrawn, order, domain.PointsWithdrawnEvent{
+			Order:  order,
+			UserID: user.UserID,
+			Sum:    sum,
+		})
+	})
 	if err != nil {
-		s.logger.Error("Failed to add withdrawal", zap.Error(err))
-		if rbErr := s.userRepo.Rollback(tx); rbErr != nil {
-			s.logger.Error("Failed to rollback transaction", zap.Error(rbErr))
+		if !errors.Is(err, gofermartErrors.ErrUserBlocked) && !errors.Is(err, gofermartErrors.ErrInsufficientFunds) {
+			s.logger.Error("Failed to process withdrawal", zap.Error(err))
 		}
 		return err
 	}
 
-	err = s.userRepo.UpdateUserBalance(tx, user.UserID, sum.Neg())
+	return nil
+}
+
+// GetWithdrawals возвращает страницу выводов средств пользователя по его идентификатору и курсор следующей страницы
+func (s *UserService) GetWithdrawals(ctx context.Context, userID int, filter domain.WithdrawalFilter) ([]domain.Withdrawal, *domain.Cursor, error) {
+	// Запрашиваем на одну запись больше, чтобы узнать, есть ли следующая страница
+	query := filter
+	if filter.Limit > 0 {
+		query.Limit = filter.Limit + 1
+	}
+
+	withdrawals, err := s.withdrawalRepo.GetWithdrawalsByUserID(ctx, userID, query)
 	if err != nil {
-		s.logger.Error("Failed to update user balance", zap.Error(err))
-		if rbErr := s.userRepo.Rollback(tx); rbErr != nil {
-			s.logger.Error("Failed to rollback transaction", zap.Error(rbErr))
-		}
-		return err
+		s.logger.Error("Failed to get withdrawals", zap.Error(err))
+		return nil, nil, err
 	}
 
-	err = s.userRepo.Commit(tx)
+	if filter.Limit > 0 && len(withdrawals) > filter.Limit {
+		withdrawals = withdrawals[:filter.Limit]
+		last := withdrawals[len(withdrawals)-1]
+		return withdrawals, &domain.Cursor{Time: last.ProcessedAt, Key: strconv.Itoa(last.WithdrawalID)}, nil
+	}
+
+	return withdrawals, nil, nil
+}
+
+// AdjustBalance изменяет баланс пользователя на amount (со знаком) по решению сотрудника поддержки actor.
+// Причина обязательна; корректировка и запись о ней сохраняются атомарно, баланс не может стать отрицательным.
+func (s *UserService) AdjustBalance(ctx context.Context, login string, amount decimal.Decimal, reason, actor string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return gofermartErrors.ErrAdjustmentReasonRequired
+	}
+	if amount.IsZero() {
+		return gofermartErrors.ErrInvalidAdjustmentAmount
+	}
+
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		user, err := s.userRepo.GetUserByLogin(ctx, login)
+		if err != nil {
+			return err
+		}
+		// Баланс перечитывается с блокировкой строки: параллельные выводы и начисления не должны изменить его
+		// между проверкой и корректировкой
+		if user, err = s.userRepo.GetUserForUpdate(ctx, user.UserID); err != nil {
+			return err
+		}
+		if user.Balance.Add(amount).IsNegative() {
+			return gofermartErrors.ErrInsufficientFunds
+		}
+
+		if err := s.userRepo.UpdateUserBalance(ctx, user.UserID, amount); err != nil {
+			return err
+		}
+		err = s.adjustmentRepo.AddAdjustment(ctx, domain.BalanceAdjustment{
+			UserID:    user.UserID,
+			Amount:    amount,
+			Reason:    reason,
+			Actor:     actor,
+			CreatedAt: time.Now(),
+		})
+		if err != nil {
+			return err
+		}
+		return s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditBalanceAdjusted, actor, login, domain.AuditPayload{
+			"amount": amount.String(),
+			"reason": reason,
+		}))
+	})
 	if err != nil {
-		s.logger.Error("Failed to commit transaction", zap.Error(err))
-		if rbErr := s.userRepo.Rollback(tx); rbErr != nil {
-			s.logger.Error("Failed to rollback transaction after failed commit", zap.Error(rbErr))
+		if !errors.Is(err, gofermartErrors.ErrUserNotFound) && !errors.Is(err, gofermartErrors.ErrInsufficientFunds) {
+			s.logger.Error("Failed to adjust balance", zap.String("login", login), zap.Error(err))
 		}
 		return err
 	}
 
+	s.logger.Info("User balance adjusted", zap.String("login", login), zap.String("amount", amount.String()),
+		zap.String("actor", actor), zap.String("reason", reason))
 	return nil
 }
 
-// GetWithdrawals возвращает список всех выводов средств пользователя по его логину
-func (s *UserService) GetWithdrawals(login string) ([]domain.Withdrawal, error) {
-	// Получаем информацию о пользователе по логину
-	user, err := s.userRepo.GetUserByLogin(nil, login)
+// GetAdjustments возвращает ручные корректировки баланса пользователя от новых к старым
+func (s *UserService) GetAdjustments(ctx context.Context, userID int) ([]domain.BalanceAdjustment, error) {
+	adjustments, err := s.adjustmentRepo.GetAdjustmentsByUserID(ctx, userID, domain.ListOptions{})
 	if err != nil {
-		// Проверяем, если пользователь не найден, возвращаем соответствующую ошибку
-		if errors.Is(err, gofermartErrors.ErrUserNotFound) {
-			s.logger.Warn("User not found", zap.String("login", login))
-			return nil, gofermartErrors.ErrUserNotFound
-		}
-		s.logger.Error("Error getting user", zap.Error(err))
+		s.logger.Error("Failed to get balance adjustments", zap.Error(err))
 		return nil, err
 	}
+	return adjustments, nil
+}
 
-	// Получаем список всех выводов средств пользователя
-	withdrawals, err := s.withdrawalRepo.GetWithdrawalsByUserID(nil, user.UserID)
+// SetRoles заменяет набор ролей пользователя; неизвестные роли отклоняются с ErrInvalidRole
+func (s *UserService) SetRoles(ctx context.Context, login string, roles []string, actor string) (domain.Roles, error) {
+	parsed, err := domain.ParseRoles(roles)
 	if err != nil {
-		s.logger.Error("Failed to get withdrawals", zap.Error(err))
+		return nil, fmt.Errorf("%w: %v", gofermartErrors.ErrInvalidRole, err)
+	}
+
+	user, err := s.GetUser(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.SetUserRoles(ctx, user.UserID, parsed); err != nil {
+			return err
+		}
+		return s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, domain.AuditRolesChanged, actor, login, domain.AuditPayload{
+			"previous": user.Roles.Strings(),
+			"roles":    parsed.Strings(),
+		}))
+	})
+	if err != nil {
+		s.logger.Error("Failed to set user roles", zap.String("login", login), zap.Error(err))
 		return nil, err
 	}
 
-	return withdrawals, nil
+	s.logger.Info("User roles changed", zap.String("login", login), zap.Strings("roles", parsed.Strings()), zap.String("actor", actor))
+	return parsed, nil
+}
+
+// SetBlocked блокирует или разблокирует пользователя: заблокированный пользователь не может войти,
+// загружать заказы и выводить средства
+func (s *UserService) SetBlocked(ctx context.Context, login string, blocked bool, actor string) error {
+	user, err := s.GetUser(ctx, login)
+	if err != nil {
+		return err
+	}
+	eventType := domain.AuditUserUnblocked
+	if blocked {
+		eventType = domain.AuditUserBlocked
+	}
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.userRepo.SetUserBlocked(ctx, user.UserID, blocked); err != nil {
+			return err
+		}
+		return s.auditRepo.AddEvent(ctx, newAuditEvent(ctx, eventType, actor, login, nil))
+	})
+	if err != nil {
+		s.logger.Error("Failed to set user blocked flag", zap.String("login", login), zap.Error(err))
+		return err
+	}
+
+	s.logger.Info("User blocked flag changed", zap.String("login", login), zap.Bool("blocked", blocked), zap.String("actor", actor))
+	return nil
 }
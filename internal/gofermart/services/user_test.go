@@ -4,13 +4,13 @@ import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
 	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
 	"errors"
 	"github.com/golang/mock/gomock"
 	"github.com/google/go-cmp/cmp"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
-	"gorm.io/gorm"
 	"strings"
 	"testing"
 	"time"
@@ -20,32 +20,33 @@ func TestUserService_GetBalance(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockTxManager := mocks.NewMockTxManager(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockWithdrawalRepo := mocks.NewMockWithdrawalRepository(ctrl)
 	logger := zap.NewNop()
-	userService := NewUserService(mockUserRepo, mockWithdrawalRepo, logger)
+	userService := NewUserService(mockTxManager, mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), logger)
 
 	testCases := []struct {
 		name           string
-		login          string
+		userID         int
 		setupMocks     func()
 		expectedError  error
 		expectedResult *domain.UserBalance
 	}{
 		{
-			name:  "GetBalance_Success",
-			login: "user1",
+			name:   "GetBalance_Success",
+			userID: 1,
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), "user1").Return(&domain.UserBalance{Current: 100.0}, nil)
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), 1).Return(&domain.UserBalance{Current: 100.0}, nil)
 			},
 			expectedError:  nil,
 			expectedResult: &domain.UserBalance{Current: 100.0},
 		},
 		{
-			name:  "GetBalance_Error",
-			login: "user1",
+			name:   "GetBalance_Error",
+			userID: 1,
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), "user1").Return(nil, errors.New("db error"))
+				mockUserRepo.EXPECT().GetUserBalance(gomock.Any(), 1).Return(nil, errors.New("db error"))
 			},
 			expectedError:  errors.New("db error"),
 			expectedResult: nil,
@@ -56,7 +57,7 @@ func TestUserService_GetBalance(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			result, err := userService.GetBalance(tc.login)
+			result, err := userService.GetBalance(context.Background(), tc.userID)
 
 			if err != nil && tc.expectedError == nil {
 				t.Errorf("Expected no error, got %v", err)
@@ -73,91 +74,193 @@ func TestUserService_GetBalance(t *testing.T) {
 	}
 }
 
+func TestUserService_GetVersion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	userService := NewUserService(mocks.NewMockTxManager(ctrl), mockUserRepo, mocks.NewMockWithdrawalRepository(ctrl), mocks.NewMockAdjustmentRepository(ctrl), mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), zap.NewNop())
+
+	testCases := []struct {
+		name            string
+		setupMocks      func()
+		expectedError   error
+		expectedVersion int64
+	}{
+		{
+			name: "GetVersion_Success",
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserVersion(gomock.Any(), 1).Return(int64(7), nil)
+			},
+			expectedVersion: 7,
+		},
+		{
+			name: "GetVersion_User_Not_Found",
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserVersion(gomock.Any(), 1).Return(int64(0), gofermartErrors.ErrUserNotFound)
+			},
+			expectedError: gofermartErrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			version, err := userService.GetVersion(context.Background(), 1)
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("Expected error %v, got %v", tc.expectedError, err)
+			}
+			if version != tc.expectedVersion {
+				t.Errorf("Expected version %d, got %d", tc.expectedVersion, version)
+			}
+		})
+	}
+}
+
 func TestUserService_Withdraw(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockTxManager := mocks.NewMockTxManager(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockWithdrawalRepo := mocks.NewMockWithdrawalRepository(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	mockOutboxRepo := mocks.NewMockOutboxRepository(ctrl)
 	logger := zap.NewNop()
-	userService := NewUserService(mockUserRepo, mockWithdrawalRepo, logger)
+	userService := NewUserService(mockTxManager, mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mockAuditRepo, mockOutboxRepo, logger)
 
 	testCases := []struct {
 		name          string
-		login         string
+		userID        int
 		order         string
 		sum           decimal.Decimal
 		setupMocks    func()
 		expectedError error
 	}{
 		{
-			name:  "Withdraw_Success",
-			login: "user1",
-			order: "order123",
-			sum:   decimal.NewFromFloat(50.0),
+			name:   "Withdraw_Success",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(50.0),
 			setupMocks: func() {
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
-				mockUserRepo.EXPECT().Commit(gomock.Any()).Return(nil).AnyTimes()
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil).AnyTimes()
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(200.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Login: "user1", Balance: decimal.NewFromFloat(200.0)}, nil)
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditBalanceWithdrawn)).DoAndReturn(func(_ context.Context, event domain.AuditEvent) error {
+					if event.Actor != "user1" || event.Payload["order"] != "order123" || event.Payload["sum"] != "50" {
+						t.Errorf("unexpected audit event: %+v", event)
+					}
+					return nil
+				})
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), outboxMessageOfType(domain.OutboxPointsWithdrawn)).DoAndReturn(func(_ context.Context, message domain.OutboxMessage) error {
+					if message.Key != "order123" || string(message.Payload) != `{"order":"order123","user_id":1,"sum":"50"}` {
+						t.Errorf("unexpected outbox message: %s %s", message.Key, message.Payload)
+					}
+					return nil
+				})
 			},
 			expectedError: nil,
 		},
 		{
-			name:  "Withdraw_Insufficient_Funds",
-			login: "user1",
-			order: "order123",
-			sum:   decimal.NewFromFloat(150.0),
+			name:   "Withdraw_Outbox_Error",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(50.0),
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(200.0)}, nil)
+				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
+				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), gomock.Any()).Return(errors.New("outbox error"))
 			},
-			expectedError: gofermartErrors.ErrInsufficientFunds,
+			expectedError: errors.New("outbox error"),
+		},
+		{
+			name:   "Withdraw_Audit_Error",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(50.0),
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(200.0)}, nil)
+				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
+				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(errors.New("audit error"))
+			},
+			expectedError: errors.New("audit error"),
 		},
 		{
-			name:  "Withdraw_Invalid_Amount",
-			login: "user1",
-			order: "order123",
-			sum:   decimal.NewFromFloat(-10.0),
+			name:   "Withdraw_Insufficient_Funds",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(150.0),
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
 			},
+			expectedError: gofermartErrors.ErrInsufficientFunds,
+		},
+		{
+			name:          "Withdraw_Invalid_Amount",
+			userID:        1,
+			order:         "order123",
+			sum:           decimal.NewFromFloat(-10.0),
+			setupMocks:    func() {},
+			expectedError: gofermartErrors.ErrInvalidWithdrawalAmount,
+		},
+		{
+			name:          "Withdraw_Zero_Amount",
+			userID:        1,
+			order:         "order123",
+			sum:           decimal.Zero,
+			setupMocks:    func() {},
 			expectedError: gofermartErrors.ErrInvalidWithdrawalAmount,
 		},
 		{
-			name:  "Withdraw_Add_Withdrawal_Error",
-			login: "user1",
-			order: "order123",
-			sum:   decimal.NewFromFloat(50.0),
+			name:   "Withdraw_User_Blocked",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(50.0),
 			setupMocks: func() {
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil).AnyTimes()
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Blocked: true, Balance: decimal.NewFromFloat(100.0)}, nil)
+			},
+			expectedError: gofermartErrors.ErrUserBlocked,
+		},
+		{
+			name:   "Withdraw_Add_Withdrawal_Error",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(50.0),
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(errors.New("db error"))
 			},
 			expectedError: errors.New("db error"),
 		},
 		{
-			name:  "Withdraw_Fail_Get_User_By_Login",
-			login: "user1",
-			order: "order123",
-			sum:   decimal.NewFromFloat(-10.0),
+			name:   "Withdraw_Fail_Get_User_By_Login",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(50.0),
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(nil, gofermartErrors.ErrUserNotFound)
 			},
 			expectedError: gofermartErrors.ErrUserNotFound,
 		},
 		{
-			name:  "Withdraw_Fail_Update_User_Balance",
-			login: "user1",
-			order: "order123",
-			sum:   decimal.NewFromFloat(50.0),
+			name:   "Withdraw_Fail_Update_User_Balance",
+			userID: 1,
+			order:  "order123",
+			sum:    decimal.NewFromFloat(50.0),
 			setupMocks: func() {
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
-				mockUserRepo.EXPECT().Commit(gomock.Any()).Return(nil).AnyTimes()
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(nil).AnyTimes()
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(200.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(200.0)}, nil)
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("update user balance fail"))
 			},
@@ -169,7 +272,7 @@ func TestUserService_Withdraw(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			err := userService.Withdraw(tc.login, tc.order, tc.sum)
+			err := userService.Withdraw(context.Background(), tc.userID, tc.order, tc.sum)
 
 			if err != nil && tc.expectedError == nil {
 				t.Errorf("Expected no error, got %v", err)
@@ -186,24 +289,24 @@ func TestUserService_GetWithdrawals(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockTxManager := mocks.NewMockTxManager(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockWithdrawalRepo := mocks.NewMockWithdrawalRepository(ctrl)
 	logger := zap.NewNop()
-	userService := NewUserService(mockUserRepo, mockWithdrawalRepo, logger)
+	userService := NewUserService(mockTxManager, mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mocks.NewMockAuditRepository(ctrl), mocks.NewMockOutboxRepository(ctrl), logger)
 
 	testCases := []struct {
 		name           string
-		login          string
+		userID         int
 		setupMocks     func()
 		expectedError  error
 		expectedResult []domain.Withdrawal
 	}{
 		{
-			name:  "GetWithdrawals_Success",
-			login: "user1",
+			name:   "GetWithdrawals_Success",
+			userID: 1,
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
-				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1).Return([]domain.Withdrawal{
+				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1, domain.WithdrawalFilter{}).Return([]domain.Withdrawal{
 					{OrderNumber: "order123", Amount: decimal.NewFromFloat(50.0), ProcessedAt: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)},
 					{OrderNumber: "order456", Amount: decimal.NewFromFloat(100.0), ProcessedAt: time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)},
 				}, nil)
@@ -215,29 +318,10 @@ func TestUserService_GetWithdrawals(t *testing.T) {
 			},
 		},
 		{
-			name:  "GetWithdrawals_User_Not_Found",
-			login: "user1",
-			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
-			},
-			expectedError:  gofermartErrors.ErrUserNotFound,
-			expectedResult: nil,
-		},
-		{
-			name:  "GetWithdrawals_DB_Error",
-			login: "user1",
+			name:   "GetWithdrawals_Error_Fetching_Withdrawals",
+			userID: 1,
 			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, errors.New("db error"))
-			},
-			expectedError:  errors.New("db error"),
-			expectedResult: nil,
-		},
-		{
-			name:  "GetWithdrawals_Error_Fetching_Withdrawals",
-			login: "user1",
-			setupMocks: func() {
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
-				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1).Return(nil, errors.New("db error"))
+				mockWithdrawalRepo.EXPECT().GetWithdrawalsByUserID(gomock.Any(), 1, domain.WithdrawalFilter{}).Return(nil, errors.New("db error"))
 			},
 			expectedError:  errors.New("db error"),
 			expectedResult: nil,
@@ -248,7 +332,7 @@ func TestUserService_GetWithdrawals(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			result, err := userService.GetWithdrawals(tc.login)
+			result, _, err := userService.GetWithdrawals(context.Background(), tc.userID, domain.WithdrawalFilter{})
 
 			if err != nil && tc.expectedError == nil {
 				t.Errorf("Expected no error, got %v", err)
@@ -269,13 +353,16 @@ func TestUserService_Withdraw_TransactionHandling(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
+	mockTxManager := mocks.NewMockTxManager(ctrl)
 	mockUserRepo := mocks.NewMockUserRepository(ctrl)
 	mockWithdrawalRepo := mocks.NewMockWithdrawalRepository(ctrl)
 
 	core, observedLogs := observer.New(zap.ErrorLevel)
 	logger := zap.New(core)
 
-	userService := NewUserService(mockUserRepo, mockWithdrawalRepo, logger)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	mockOutboxRepo := mocks.NewMockOutboxRepository(ctrl)
+	userService := NewUserService(mockTxManager, mockUserRepo, mockWithdrawalRepo, mocks.NewMockAdjustmentRepository(ctrl), mockAuditRepo, mockOutboxRepo, logger)
 
 	testCases := []struct {
 		name          string
@@ -284,49 +371,51 @@ func TestUserService_Withdraw_TransactionHandling(t *testing.T) {
 		expectedLog   string
 	}{
 		{
-			name: "Begin_Transaction_Error",
+			name: "Transaction_Error",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().BeginTransaction().Return(nil, errors.New("failed to begin transaction"))
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).Return(errors.New("failed to begin transaction"))
 			},
 			expectedError: errors.New("failed to begin transaction"),
-			expectedLog:   "Failed to begin transaction",
+			expectedLog:   "Failed to process withdrawal",
 		},
 		{
-			name: "Rollback_Failure_On_AddWithdrawal_Error",
+			name: "Rollback_On_AddWithdrawal_Error",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(errors.New("add withdrawal error"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(errors.New("rollback error"))
 			},
 			expectedError: errors.New("add withdrawal error"),
-			expectedLog:   "Failed to rollback transaction",
+			expectedLog:   "Failed to add withdrawal",
 		},
 		{
-			name: "Rollback_Failure_On_UpdateUserBalance_Error",
+			name: "Rollback_On_UpdateUserBalance_Error",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(100.0)}, nil)
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("update user balance error"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(errors.New("rollback error"))
 			},
 			expectedError: errors.New("update user balance error"),
-			expectedLog:   "Failed to rollback transaction",
+			expectedLog:   "Failed to update user balance",
 		},
 		{
 			name: "Commit_Failure",
 			setupMocks: func() {
-				mockUserRepo.EXPECT().BeginTransaction().Return(&gorm.DB{}, nil)
-				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(200.0)}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, fn func(ctx context.Context) error) error {
+					if err := fn(ctx); err != nil {
+						return err
+					}
+					return errors.New("commit error")
+				})
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromFloat(200.0)}, nil)
 				mockWithdrawalRepo.EXPECT().AddWithdrawal(gomock.Any(), gomock.Any()).Return(nil)
 				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
-				mockUserRepo.EXPECT().Commit(gomock.Any()).Return(errors.New("commit error"))
-				mockUserRepo.EXPECT().Rollback(gomock.Any()).Return(errors.New("rollback error"))
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), gomock.Any()).Return(nil)
+				mockOutboxRepo.EXPECT().AddMessage(gomock.Any(), gomock.Any()).Return(nil)
 			},
 			expectedError: errors.New("commit error"),
-			expectedLog:   "Failed to rollback transaction after failed commit",
+			expectedLog:   "Failed to process withdrawal",
 		},
 	}
 
@@ -334,7 +423,7 @@ func TestUserService_Withdraw_TransactionHandling(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			tc.setupMocks()
 
-			err := userService.Withdraw("user1", "order123", decimal.NewFromFloat(50.0))
+			err := userService.Withdraw(context.Background(), 1, "order123", decimal.NewFromFloat(50.0))
 
 			if err != nil && tc.expectedError == nil {
 				t.Errorf("Expected no error, got %v", err)
@@ -358,3 +447,218 @@ func TestUserService_Withdraw_TransactionHandling(t *testing.T) {
 		})
 	}
 }
+
+// runInTx - имитирует TxManager, выполняя функцию без реальной транзакции
+func TestUserService_AdjustBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockAdjustmentRepo := mocks.NewMockAdjustmentRepository(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	userService := NewUserService(mockTxManager, mockUserRepo, mocks.NewMockWithdrawalRepository(ctrl), mockAdjustmentRepo, mockAuditRepo, mocks.NewMockOutboxRepository(ctrl), zap.NewNop())
+
+	testCases := []struct {
+		name          string
+		amount        decimal.Decimal
+		reason        string
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name:   "Credit_Recorded_With_Reason_And_Actor",
+			amount: decimal.NewFromInt(50),
+			reason: " goodwill ",
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromInt(10)}, nil)
+				mockUserRepo.EXPECT().UpdateUserBalance(gomock.Any(), 1, decimal.NewFromInt(50)).Return(nil)
+				mockAdjustmentRepo.EXPECT().AddAdjustment(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, adjustment domain.BalanceAdjustment) error {
+					if adjustment.UserID != 1 || adjustment.Reason != "goodwill" || adjustment.Actor != "support" || !adjustment.Amount.Equal(decimal.NewFromInt(50)) {
+						t.Errorf("unexpected adjustment: %+v", adjustment)
+					}
+					return nil
+				})
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditBalanceAdjusted)).DoAndReturn(func(_ context.Context, event domain.AuditEvent) error {
+					if event.Actor != "support" || event.Subject != "user1" || event.Payload["reason"] != "goodwill" {
+						t.Errorf("unexpected audit event: %+v", event)
+					}
+					return nil
+				})
+			},
+		},
+		{
+			name:   "Debit_Below_Zero",
+			amount: decimal.NewFromInt(-11),
+			reason: "chargeback",
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				// Баланс, прочитанный без блокировки, уже устарел: проверка выполняется по заблокированной строке
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Balance: decimal.NewFromInt(100)}, nil)
+				mockUserRepo.EXPECT().GetUserForUpdate(gomock.Any(), 1).Return(&domain.User{UserID: 1, Balance: decimal.NewFromInt(10)}, nil)
+			},
+			expectedError: gofermartErrors.ErrInsufficientFunds,
+		},
+		{
+			name:          "Missing_Reason",
+			amount:        decimal.NewFromInt(5),
+			reason:        "  ",
+			setupMocks:    func() {},
+			expectedError: gofermartErrors.ErrAdjustmentReasonRequired,
+		},
+		{
+			name:          "Zero_Amount",
+			amount:        decimal.Zero,
+			reason:        "typo",
+			setupMocks:    func() {},
+			expectedError: gofermartErrors.ErrInvalidAdjustmentAmount,
+		},
+		{
+			name:   "Unknown_User",
+			amount: decimal.NewFromInt(5),
+			reason: "typo",
+			setupMocks: func() {
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
+			},
+			expectedError: gofermartErrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			err := userService.AdjustBalance(context.Background(), "user1", tc.amount, tc.reason, "support")
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestUserService_SetBlocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	userService := NewUserService(mockTxManager, mockUserRepo, mocks.NewMockWithdrawalRepository(ctrl), mocks.NewMockAdjustmentRepository(ctrl), mockAuditRepo, mocks.NewMockOutboxRepository(ctrl), zap.NewNop())
+
+	testCases := []struct {
+		name          string
+		blocked       bool
+		setupMocks    func()
+		expectedError error
+	}{
+		{
+			name:    "Block",
+			blocked: true,
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().SetUserBlocked(gomock.Any(), 1, true).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditUserBlocked)).Return(nil)
+			},
+		},
+		{
+			name:    "Unblock",
+			blocked: false,
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Blocked: true}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().SetUserBlocked(gomock.Any(), 1, false).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditUserUnblocked)).Return(nil)
+			},
+		},
+		{
+			name:    "Unknown_User",
+			blocked: true,
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
+			},
+			expectedError: gofermartErrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			if err := userService.SetBlocked(context.Background(), "user1", tc.blocked, "support"); !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestUserService_SetRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockTxManager := mocks.NewMockTxManager(ctrl)
+	mockUserRepo := mocks.NewMockUserRepository(ctrl)
+	mockAuditRepo := mocks.NewMockAuditRepository(ctrl)
+	userService := NewUserService(mockTxManager, mockUserRepo, mocks.NewMockWithdrawalRepository(ctrl), mocks.NewMockAdjustmentRepository(ctrl), mockAuditRepo, mocks.NewMockOutboxRepository(ctrl), zap.NewNop())
+
+	testCases := []struct {
+		name          string
+		roles         []string
+		setupMocks    func()
+		expectedRoles domain.Roles
+		expectedError error
+	}{
+		{
+			name:  "Grant_Partner",
+			roles: []string{"user", "partner", "partner"},
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(&domain.User{UserID: 1, Roles: domain.DefaultRoles()}, nil)
+				mockTxManager.EXPECT().WithinTx(gomock.Any(), gomock.Any()).DoAndReturn(runInTx)
+				mockUserRepo.EXPECT().SetUserRoles(gomock.Any(), 1, domain.Roles{domain.RoleUser, domain.RolePartner}).Return(nil)
+				mockAuditRepo.EXPECT().AddEvent(gomock.Any(), auditEventOfType(domain.AuditRolesChanged)).DoAndReturn(func(_ context.Context, event domain.AuditEvent) error {
+					want := domain.AuditPayload{"previous": []string{"user"}, "roles": []string{"user", "partner"}}
+					if diff := cmp.Diff(want, event.Payload); diff != "" || event.Actor != "admin" {
+						t.Errorf("unexpected audit event %+v (-want +got):\n%s", event, diff)
+					}
+					return nil
+				})
+			},
+			expectedRoles: domain.Roles{domain.RoleUser, domain.RolePartner},
+		},
+		{
+			name:          "Unknown_Role",
+			roles:         []string{"root"},
+			setupMocks:    func() {},
+			expectedError: gofermartErrors.ErrInvalidRole,
+		},
+		{
+			name:  "Unknown_User",
+			roles: []string{"support"},
+			setupMocks: func() {
+				mockUserRepo.EXPECT().GetUserByLogin(gomock.Any(), "user1").Return(nil, gofermartErrors.ErrUserNotFound)
+			},
+			expectedError: gofermartErrors.ErrUserNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.setupMocks()
+
+			roles, err := userService.SetRoles(context.Background(), "user1", tc.roles, "admin")
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			}
+			if diff := cmp.Diff(tc.expectedRoles, roles); diff != "" {
+				t.Errorf("roles mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func runInTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
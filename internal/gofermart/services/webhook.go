@@ -0,0 +1,405 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Заголовки запроса доставки webhook
+const (
+	WebhookSignatureHeader = "X-Gophermart-Signature"
+	WebhookTimestampHeader = "X-Gophermart-Timestamp"
+	WebhookEventHeader     = "X-Gophermart-Event"
+	WebhookDeliveryHeader  = "X-Gophermart-Delivery"
+)
+
+// Параметры доставки webhook по умолчанию
+const (
+	defaultWebhookBatchSize   = 100
+	defaultWebhookLease       = time.Minute
+	defaultWebhookMaxAttempts = 8
+	defaultWebhookRetryDelay  = 10 * time.Second
+	defaultWebhookRetryMax    = time.Hour
+	defaultWebhookTimeout     = 10 * time.Second
+	webhookSecretBytes        = 32
+)
+
+// errWebhookAddressBlocked - адрес получателя находится во внутренней сети, а доставка туда не разрешена
+var errWebhookAddressBlocked = errors.New("webhook address is not publicly routable")
+
+// WebhookDispatcher - доставка накопившихся событий на webhook пользователей, запускаемая фоновым процессом
+type WebhookDispatcher interface {
+	DeliverWebhooks(ctx context.Context) (int, error)
+}
+
+// WebhookServiceInterface - управление webhook пользователя и просмотр журнала их доставок
+type WebhookServiceInterface interface {
+	CreateWebhook(ctx context.Context, userID int, rawURL string, events []domain.OutboxEventType) (*domain.Webhook, error)
+	GetWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, userID, webhookID int) error
+	GetDeliveries(ctx context.Context, userID, webhookID, limit int) ([]domain.WebhookDelivery, error)
+}
+
+// WebhookOptions - параметры доставки webhook; нулевые значения заменяются значениями по умолчанию
+type WebhookOptions struct {
+	BatchSize            int           // сколько доставок выбирается за один проход
+	Lease                time.Duration // на сколько откладывается выбранная доставка, пока идёт запрос
+	MaxAttempts          int           // после стольких неудачных попыток доставка считается окончательно неуспешной
+	RetryDelay           time.Duration // задержка перед первой повторной попыткой, далее удваивается
+	RetryMax             time.Duration // наибольшая задержка между попытками
+	Timeout              time.Duration // время ожидания ответа получателя
+	AllowPrivateNetworks bool          // разрешает доставку на адреса внутренних сетей и loopback
+}
+
+// WebhookService - управляет webhook пользователей и доставляет на них события их заказов.
+// Каждый запрос подписывается HMAC-SHA256 секретом webhook, неудачные доставки повторяются
+// с экспоненциальной задержкой, результат каждой доставки сохраняется в журнале.
+type WebhookService struct {
+	webhookRepo repository.WebhookRepository
+	client      *http.Client
+	options     WebhookOptions
+	logger      *zap.Logger
+}
+
+// NewWebhookService - создает новый экземпляр WebhookService
+func NewWebhookService(webhookRepo repository.WebhookRepository, options WebhookOptions, logger *zap.Logger) *WebhookService {
+	if options.BatchSize <= 0 {
+		options.BatchSize = defaultWebhookBatchSize
+	}
+	if options.Lease <= 0 {
+		options.Lease = defaultWebhookLease
+	}
+	if options.MaxAttempts <= 0 {
+		options.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	if options.RetryDelay <= 0 {
+		options.RetryDelay = defaultWebhookRetryDelay
+	}
+	if options.RetryMax <= 0 {
+		options.RetryMax = defaultWebhookRetryMax
+	}
+	if options.Timeout <= 0 {
+		options.Timeout = defaultWebhookTimeout
+	}
+	return &WebhookService{
+		webhookRepo: webhookRepo,
+		client:      newWebhookClient(options),
+		options:     options,
+		logger:      logger,
+	}
+}
+
+// newWebhookClient - HTTP-клиент доставки. Адрес webhook задаёт пользователь, поэтому клиент не следует
+// перенаправлениям и, если не разрешено иное, не подключается к адресам внутренних сетей:
+// проверяется адрес, к которому фактически устанавливается соединение, уже после разрешения имени.
+func newWebhookClient(options WebhookOptions) *http.Client {
+	dialer := &net.Dialer{Timeout: options.Timeout}
+	if !options.AllowPrivateNetworks {
+		dialer.Control = func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return err
+			}
+			if !isPublicAddress(addrPort.Addr()) {
+				return fmt.Errorf("%w: %s", errWebhookAddressBlocked, addrPort.Addr())
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   options.Timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublicAddress - проверяет, что адрес не относится к loopback, частным, link-local и служебным сетям
+func isPublicAddress(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !addr.IsLoopback() && !addr.IsLinkLocalUnicast()
+}
+
+// CreateWebhook - регистрирует webhook пользователя и формирует секрет для подписи доставок
+func (s *WebhookService) CreateWebhook(ctx context.Context, userID int, rawURL string, events []domain.OutboxEventType) (*domain.Webhook, error) {
+	if err := validateWebhookURL(rawURL); err != nil {
+		return nil, err
+	}
+	events, err := normalizeWebhookEvents(events)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := make([]byte, webhookSecretBytes)
+	if _, err := rand.Read(secret); err != nil {
+		s.logger.Error("Failed to generate webhook secret", zap.Error(err))
+		return nil, err
+	}
+
+	webhook := domain.Webhook{
+		UserID:    userID,
+		URL:       rawURL,
+		Events:    events,
+		Secret:    hex.EncodeToString(secret),
+		CreatedAt: time.Now(),
+	}
+	if err := s.webhookRepo.AddWebhook(ctx, &webhook); err != nil {
+		s.logger.Error("Failed to add webhook", zap.Int("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// GetWebhooks - возвращает webhook пользователя
+func (s *WebhookService) GetWebhooks(ctx context.Context, userID int) ([]domain.Webhook, error) {
+	webhooks, err := s.webhookRepo.GetWebhooksByUserID(ctx, userID)
+	if err != nil {
+		s.logger.Error("Failed to get webhooks", zap.Int("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook - удаляет webhook пользователя; чужой webhook считается несуществующим
+func (s *WebhookService) DeleteWebhook(ctx context.Context, userID, webhookID int) error {
+	if _, err := s.getOwnWebhook(ctx, userID, webhookID); err != nil {
+		return err
+	}
+	if err := s.webhookRepo.DeleteWebhook(ctx, webhookID); err != nil {
+		if !errors.Is(err, gofermartErrors.ErrWebhookNotFound) {
+			s.logger.Error("Failed to delete webhook", zap.Int("webhookID", webhookID), zap.Error(err))
+		}
+		return err
+	}
+	return nil
+}
+
+// GetDeliveries - возвращает последние доставки webhook пользователя
+func (s *WebhookService) GetDeliveries(ctx context.Context, userID, webhookID, limit int) ([]domain.WebhookDelivery, error) {
+	if _, err := s.getOwnWebhook(ctx, userID, webhookID); err != nil {
+		return nil, err
+	}
+	deliveries, err := s.webhookRepo.GetDeliveries(ctx, webhookID, limit)
+	if err != nil {
+		s.logger.Error("Failed to get webhook deliveries", zap.Int("webhookID", webhookID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// getOwnWebhook - получает webhook, принадлежащий пользователю
+func (s *WebhookService) getOwnWebhook(ctx context.Context, userID, webhookID int) (*domain.Webhook, error) {
+	webhook, err := s.webhookRepo.GetWebhookByID(ctx, webhookID)
+	if err != nil {
+		if !errors.Is(err, gofermartErrors.ErrWebhookNotFound) {
+			s.logger.Error("Failed to get webhook", zap.Int("webhookID", webhookID), zap.Error(err))
+		}
+		return nil, err
+	}
+	if webhook.UserID != userID {
+		return nil, gofermartErrors.ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// DeliverWebhooks - выполняет одну порцию доставок и возвращает количество успешных
+func (s *WebhookService) DeliverWebhooks(ctx context.Context) (int, error) {
+	deliveries, err := s.webhookRepo.ClaimDeliveries(ctx, time.Now(), s.options.Lease, s.options.BatchSize)
+	if err != nil {
+		s.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return 0, err
+	}
+
+	webhooks := make(map[int]*domain.Webhook)
+	delivered := 0
+	for _, delivery := range deliveries {
+		if err := ctx.Err(); err != nil {
+			// Невыполненные доставки вернутся в очередь по истечении аренды
+			return delivered, err
+		}
+
+		webhook, ok := webhooks[delivery.WebhookID]
+		if !ok {
+			webhook, err = s.webhookRepo.GetWebhookByID(ctx, delivery.WebhookID)
+			if errors.Is(err, gofermartErrors.ErrWebhookNotFound) {
+				// Webhook удалён вместе с журналом, пока доставка была в работе
+				continue
+			}
+			if err != nil {
+				return delivered, err
+			}
+			webhooks[delivery.WebhookID] = webhook
+		}
+
+		delivery.Attempts++
+		status, err := s.send(ctx, webhook, delivery)
+		delivery.ResponseStatus = status
+		if err == nil {
+			now := time.Now()
+			delivery.Status = domain.WebhookDeliveryDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = &now
+			delivered++
+		} else {
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= s.options.MaxAttempts {
+				delivery.Status = domain.WebhookDeliveryFailed
+				s.logger.Warn("Webhook delivery failed permanently",
+					zap.Int("deliveryID", delivery.DeliveryID), zap.Int("webhookID", webhook.WebhookID),
+					zap.Int("attempts", delivery.Attempts), zap.Error(err))
+			} else {
+				delay := backoffDelay(s.options.RetryDelay, s.options.RetryMax, delivery.Attempts-1)
+				delivery.NextAttemptAt = time.Now().Add(delay)
+				s.logger.Warn("Failed to deliver webhook",
+					zap.Int("deliveryID", delivery.DeliveryID), zap.Int("webhookID", webhook.WebhookID),
+					zap.Int("attempt", delivery.Attempts), zap.Duration("retry_in", delay), zap.Error(err))
+			}
+		}
+
+		// Если результат не сохранится, доставка будет повторена после истечения аренды
+		if err := s.webhookRepo.UpdateDelivery(ctx, delivery); err != nil {
+			return delivered, err
+		}
+	}
+	return delivered, nil
+}
+
+// webhookBody - тело запроса доставки webhook
+type webhookBody struct {
+	ID        int                    `json:"id"`
+	Type      domain.OutboxEventType `json:"type"`
+	Payload   domain.OutboxPayload   `json:"payload"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// send - отправляет подписанный запрос и возвращает HTTP-статус ответа; успехом считается любой ответ 2xx
+func (s *WebhookService) send(ctx context.Context, webhook *domain.Webhook, delivery domain.WebhookDelivery) (int, error) {
+	body, err := json.Marshal(webhookBody{
+		ID:        delivery.DeliveryID,
+		Type:      delivery.EventType,
+		Payload:   delivery.Payload,
+		CreatedAt: delivery.CreatedAt,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(delivery.EventType))
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(delivery.DeliveryID))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Тело дочитывается, чтобы соединение вернулось в пул
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload - подпись доставки: HMAC-SHA256 секретом webhook от строки "<timestamp>.<тело>".
+// Метка времени входит в подпись, чтобы получатель мог отклонять повторно отправленные старые запросы.
+func SignWebhookPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateWebhookURL - адрес webhook должен быть абсолютным http- или https-адресом
+func validateWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" || u.User != nil {
+		return gofermartErrors.ErrInvalidWebhookURL
+	}
+	return nil
+}
+
+// normalizeWebhookEvents - проверяет список событий подписки и убирает повторы
+func normalizeWebhookEvents(events []domain.OutboxEventType) ([]domain.OutboxEventType, error) {
+	if len(events) == 0 {
+		return nil, gofermartErrors.ErrInvalidWebhookEvents
+	}
+	var normalized []domain.OutboxEventType
+	for _, eventType := range events {
+		if !slices.Contains(domain.WebhookEventTypes, eventType) {
+			return nil, gofermartErrors.ErrInvalidWebhookEvents
+		}
+		if !slices.Contains(normalized, eventType) {
+			normalized = append(normalized, eventType)
+		}
+	}
+	return normalized, nil
+}
+
+// enqueueWebhookDeliveries - ставит событие в очередь доставки на webhook пользователя, подписанные на него,
+// в транзакции из контекста
+func enqueueWebhookDeliveries(ctx context.Context, webhookRepo repository.WebhookRepository, userID int, eventType domain.OutboxEventType, payload any) error {
+	webhooks, err := webhookRepo.GetWebhooksByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhook.Subscribed(eventType) {
+			continue
+		}
+		if data == nil {
+			if data, err = json.Marshal(payload); err != nil {
+				return err
+			}
+		}
+		err := webhookRepo.AddDelivery(ctx, domain.WebhookDelivery{
+			WebhookID:     webhook.WebhookID,
+			EventType:     eventType,
+			Payload:       data,
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
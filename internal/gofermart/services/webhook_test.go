@@ -0,0 +1,277 @@
+package services
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWebhookService_CreateWebhook(t *testing.T) {
+	testCases := []struct {
+		name           string
+		url            string
+		events         []domain.OutboxEventType
+		setupMocks     func(repo *mocks.MockWebhookRepository)
+		expectedEvents []domain.OutboxEventType
+		expectedError  error
+	}{
+		{
+			name:   "Success",
+			url:    "https://example.com/hooks/gophermart",
+			events: []domain.OutboxEventType{domain.OutboxPointsAccrued, domain.OutboxOrderStatusChanged, domain.OutboxPointsAccrued},
+			setupMocks: func(repo *mocks.MockWebhookRepository) {
+				repo.EXPECT().AddWebhook(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, webhook *domain.Webhook) error {
+					webhook.WebhookID = 7
+					return nil
+				})
+			},
+			expectedEvents: []domain.OutboxEventType{domain.OutboxPointsAccrued, domain.OutboxOrderStatusChanged},
+		},
+		{
+			name:          "Relative_URL",
+			url:           "/hooks",
+			events:        domain.WebhookEventTypes,
+			setupMocks:    func(repo *mocks.MockWebhookRepository) {},
+			expectedError: gofermartErrors.ErrInvalidWebhookURL,
+		},
+		{
+			name:          "Unsupported_Scheme",
+			url:           "ftp://example.com/hooks",
+			events:        domain.WebhookEventTypes,
+			setupMocks:    func(repo *mocks.MockWebhookRepository) {},
+			expectedError: gofermartErrors.ErrInvalidWebhookURL,
+		},
+		{
+			name:          "No_Events",
+			url:           "https://example.com/hooks",
+			setupMocks:    func(repo *mocks.MockWebhookRepository) {},
+			expectedError: gofermartErrors.ErrInvalidWebhookEvents,
+		},
+		{
+			name:          "Unknown_Event",
+			url:           "https://example.com/hooks",
+			events:        []domain.OutboxEventType{domain.OutboxPointsWithdrawn},
+			setupMocks:    func(repo *mocks.MockWebhookRepository) {},
+			expectedError: gofermartErrors.ErrInvalidWebhookEvents,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockWebhookRepository(ctrl)
+			tc.setupMocks(repo)
+
+			service := NewWebhookService(repo, WebhookOptions{}, zap.NewNop())
+			webhook, err := service.CreateWebhook(context.Background(), 1, tc.url, tc.events)
+			if !errors.Is(err, tc.expectedError) {
+				t.Fatalf("expected error %v, got %v", tc.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			if webhook.WebhookID != 7 || webhook.UserID != 1 || len(webhook.Secret) != 2*webhookSecretBytes {
+				t.Errorf("unexpected webhook: %+v", webhook)
+			}
+			if strings.Join(eventNames(webhook.Events), ",") != strings.Join(eventNames(tc.expectedEvents), ",") {
+				t.Errorf("expected events %v, got %v", tc.expectedEvents, webhook.Events)
+			}
+		})
+	}
+}
+
+func eventNames(events []domain.OutboxEventType) []string {
+	names := make([]string, 0, len(events))
+	for _, eventType := range events {
+		names = append(names, string(eventType))
+	}
+	return names
+}
+
+func TestWebhookService_DeleteWebhook(t *testing.T) {
+	testCases := []struct {
+		name          string
+		setupMocks    func(repo *mocks.MockWebhookRepository)
+		expectedError error
+	}{
+		{
+			name: "Success",
+			setupMocks: func(repo *mocks.MockWebhookRepository) {
+				repo.EXPECT().GetWebhookByID(gomock.Any(), 3).Return(&domain.Webhook{WebhookID: 3, UserID: 1}, nil)
+				repo.EXPECT().DeleteWebhook(gomock.Any(), 3).Return(nil)
+			},
+		},
+		{
+			name: "Webhook_Of_Another_User",
+			setupMocks: func(repo *mocks.MockWebhookRepository) {
+				repo.EXPECT().GetWebhookByID(gomock.Any(), 3).Return(&domain.Webhook{WebhookID: 3, UserID: 2}, nil)
+			},
+			expectedError: gofermartErrors.ErrWebhookNotFound,
+		},
+		{
+			name: "Not_Found",
+			setupMocks: func(repo *mocks.MockWebhookRepository) {
+				repo.EXPECT().GetWebhookByID(gomock.Any(), 3).Return(nil, gofermartErrors.ErrWebhookNotFound)
+			},
+			expectedError: gofermartErrors.ErrWebhookNotFound,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			repo := mocks.NewMockWebhookRepository(ctrl)
+			tc.setupMocks(repo)
+
+			service := NewWebhookService(repo, WebhookOptions{}, zap.NewNop())
+			if err := service.DeleteWebhook(context.Background(), 1, 3); !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestWebhookService_DeliverWebhooks(t *testing.T) {
+	payload := domain.OutboxPayload(`{"order":"12345678903","user_id":1,"previous_status":"PROCESSING","status":"PROCESSED","accrual":"100"}`)
+
+	testCases := []struct {
+		name              string
+		responseStatus    int
+		attempts          int
+		allowPrivate      bool
+		expectedDelivered int
+		checkDelivery     func(t *testing.T, delivery domain.WebhookDelivery)
+	}{
+		{
+			name:              "Delivered",
+			responseStatus:    http.StatusNoContent,
+			allowPrivate:      true,
+			expectedDelivered: 1,
+			checkDelivery: func(t *testing.T, delivery domain.WebhookDelivery) {
+				if delivery.Status != domain.WebhookDeliveryDelivered || delivery.Attempts != 1 || delivery.ResponseStatus != http.StatusNoContent || delivery.DeliveredAt == nil {
+					t.Errorf("unexpected delivery: %+v", delivery)
+				}
+			},
+		},
+		{
+			name:           "Rejected_Delivery_Is_Rescheduled",
+			responseStatus: http.StatusInternalServerError,
+			attempts:       1,
+			allowPrivate:   true,
+			checkDelivery: func(t *testing.T, delivery domain.WebhookDelivery) {
+				if delivery.Status != domain.WebhookDeliveryPending || delivery.Attempts != 2 || delivery.ResponseStatus != http.StatusInternalServerError {
+					t.Errorf("unexpected delivery: %+v", delivery)
+				}
+				// После второй неудачной попытки задержка — 2 секунды
+				if delay := time.Until(delivery.NextAttemptAt); delay < time.Second || delay > 2*time.Second {
+					t.Errorf("unexpected retry delay: %v", delay)
+				}
+			},
+		},
+		{
+			name:           "Last_Attempt_Fails_Permanently",
+			responseStatus: http.StatusBadGateway,
+			attempts:       2,
+			allowPrivate:   true,
+			checkDelivery: func(t *testing.T, delivery domain.WebhookDelivery) {
+				if delivery.Status != domain.WebhookDeliveryFailed || delivery.Attempts != 3 || delivery.LastError != "webhook responded with status 502" {
+					t.Errorf("unexpected delivery: %+v", delivery)
+				}
+			},
+		},
+		{
+			name:           "Private_Address_Is_Blocked",
+			responseStatus: http.StatusOK,
+			checkDelivery: func(t *testing.T, delivery domain.WebhookDelivery) {
+				if delivery.Status != domain.WebhookDeliveryPending || delivery.ResponseStatus != 0 || !strings.Contains(delivery.LastError, errWebhookAddressBlocked.Error()) {
+					t.Errorf("unexpected delivery: %+v", delivery)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				timestamp, err := strconv.ParseInt(r.Header.Get(WebhookTimestampHeader), 10, 64)
+				if err != nil {
+					t.Errorf("invalid timestamp header: %v", err)
+				}
+				if r.Header.Get(WebhookSignatureHeader) != SignWebhookPayload("secret", timestamp, body) {
+					t.Errorf("invalid signature %q", r.Header.Get(WebhookSignatureHeader))
+				}
+				if r.Header.Get(WebhookEventHeader) != string(domain.OutboxOrderStatusChanged) || r.Header.Get(WebhookDeliveryHeader) != "5" {
+					t.Errorf("unexpected headers: %v", r.Header)
+				}
+				var envelope struct {
+					ID      int                    `json:"id"`
+					Type    domain.OutboxEventType `json:"type"`
+					Payload map[string]any         `json:"payload"`
+				}
+				if err := json.Unmarshal(body, &envelope); err != nil || envelope.ID != 5 || envelope.Payload["status"] != "PROCESSED" {
+					t.Errorf("unexpected body: %s", body)
+				}
+				w.WriteHeader(tc.responseStatus)
+			}))
+			defer server.Close()
+
+			repo := mocks.NewMockWebhookRepository(ctrl)
+			delivery := domain.WebhookDelivery{
+				DeliveryID: 5,
+				WebhookID:  3,
+				EventType:  domain.OutboxOrderStatusChanged,
+				Payload:    payload,
+				Status:     domain.WebhookDeliveryPending,
+				Attempts:   tc.attempts,
+			}
+			repo.EXPECT().ClaimDeliveries(gomock.Any(), gomock.Any(), time.Minute, 10).Return([]domain.WebhookDelivery{delivery}, nil)
+			repo.EXPECT().GetWebhookByID(gomock.Any(), 3).Return(&domain.Webhook{WebhookID: 3, UserID: 1, URL: server.URL, Secret: "secret"}, nil)
+			repo.EXPECT().UpdateDelivery(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, delivery domain.WebhookDelivery) error {
+				tc.checkDelivery(t, delivery)
+				return nil
+			})
+
+			service := NewWebhookService(repo, WebhookOptions{
+				BatchSize:            10,
+				MaxAttempts:          3,
+				RetryDelay:           time.Second,
+				AllowPrivateNetworks: tc.allowPrivate,
+			}, zap.NewNop())
+			delivered, err := service.DeliverWebhooks(context.Background())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if delivered != tc.expectedDelivered {
+				t.Errorf("expected %d delivered, got %d", tc.expectedDelivered, delivered)
+			}
+		})
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	// Значение получено командой: printf '1714564800.{}' | openssl dgst -sha256 -hmac secret
+	expected := "sha256=6772f83f980eaa45c478fbaeb2e3661d945e7ba97f73c65ac97333a82742e16f"
+	if got := SignWebhookPayload("secret", 1714564800, []byte("{}")); got != expected {
+		t.Errorf("expected %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,38 @@
+package statement
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"encoding/csv"
+	"io"
+)
+
+// csvWriter — выгрузка выписки в CSV; encoding/csv буферизует вывод и сбрасывает его по мере заполнения буфера
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w)}
+	if err := cw.w.Write(columns); err != nil {
+		return nil, err
+	}
+	return cw, nil
+}
+
+// WriteEntry — записывает строку выписки
+func (c *csvWriter) WriteEntry(entry domain.StatementEntry) error {
+	return c.w.Write([]string{
+		formatTime(entry.Time),
+		entry.Type,
+		entry.OrderNumber,
+		entry.Status,
+		entry.Amount.StringFixed(2),
+		entry.Balance.StringFixed(2),
+	})
+}
+
+// Close — сбрасывает буфер
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,55 @@
+package statement
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"encoding/json"
+	"io"
+)
+
+// jsonEntry — представление строки выписки в JSON; суммы передаются числами без потери точности
+type jsonEntry struct {
+	Date    string      `json:"date"`
+	Type    string      `json:"type"`
+	Order   string      `json:"order,omitempty"`
+	Status  string      `json:"status,omitempty"`
+	Amount  json.Number `json:"amount"`
+	Balance json.Number `json:"balance"`
+}
+
+// jsonWriter — выгрузка выписки JSON-массивом, элементы которого кодируются по одному
+type jsonWriter struct {
+	w       io.Writer
+	encoder *json.Encoder
+	count   int
+}
+
+func newJSONWriter(w io.Writer) (*jsonWriter, error) {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return nil, err
+	}
+	return &jsonWriter{w: w, encoder: json.NewEncoder(w)}, nil
+}
+
+// WriteEntry — записывает элемент массива
+func (j *jsonWriter) WriteEntry(entry domain.StatementEntry) error {
+	if j.count > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.count++
+	return j.encoder.Encode(jsonEntry{
+		Date:    formatTime(entry.Time),
+		Type:    entry.Type,
+		Order:   entry.OrderNumber,
+		Status:  entry.Status,
+		Amount:  json.Number(entry.Amount.StringFixed(2)),
+		Balance: json.Number(entry.Balance.StringFixed(2)),
+	})
+}
+
+// Close — закрывает массив
+func (j *jsonWriter) Close() error {
+	_, err := io.WriteString(j.w, "]\n")
+	return err
+}
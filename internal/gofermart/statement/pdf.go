@@ -0,0 +1,181 @@
+package statement
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"bytes"
+	"fmt"
+	"github.com/shopspring/decimal"
+	"io"
+	"strings"
+	"time"
+)
+
+// periodLayout — формат месяца выписки
+const periodLayout = "2006-01"
+
+// Параметры страницы PDF: A4 в пунктах, моноширинный шрифт, чтобы колонки выравнивались пробелами
+const (
+	pdfPageWidth    = 595
+	pdfPageHeight   = 842
+	pdfMarginLeft   = 50
+	pdfMarginTop    = 50
+	pdfFontSize     = 10
+	pdfLineHeight   = 14
+	pdfLinesPerPage = 52
+)
+
+// MonthlyReport - данные ежемесячной выписки пользователя
+type MonthlyReport struct {
+	Login       string
+	Period      time.Time // начало месяца
+	Opening     decimal.Decimal
+	Closing     decimal.Decimal
+	Accruals    []domain.StatementEntry
+	Withdrawals []domain.StatementEntry
+	Adjustments []domain.StatementEntry // ручные корректировки баланса поддержкой
+}
+
+// ParsePeriod - разбирает месяц выписки в формате YYYY-MM и возвращает его начало в UTC
+func ParsePeriod(value string) (time.Time, error) {
+	return time.Parse(periodLayout, value)
+}
+
+// FormatPeriod - возвращает месяц выписки в формате YYYY-MM
+func FormatPeriod(period time.Time) string {
+	return period.UTC().Format(periodLayout)
+}
+
+// MonthStart - возвращает начало месяца, которому принадлежит t, в UTC
+func MonthStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// WritePDF - формирует PDF-документ выписки. Результат зависит только от данных отчета:
+// в документ не попадают время формирования и другие изменчивые метаданные.
+func WritePDF(w io.Writer, report MonthlyReport) error {
+	pages := paginate(reportLines(report))
+
+	var doc bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, doc.Len())
+		fmt.Fprintf(&doc, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	doc.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+
+	// Объекты страниц идут парами (страница, содержимое) начиная с номера 4
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+
+	for i, lines := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+
+		footer := fmt.Sprintf("Page %d of %d", i+1, len(pages))
+		content := pageContent(append(lines, "", footer))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+	}
+
+	xref := doc.Len()
+	fmt.Fprintf(&doc, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&doc, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&doc, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(doc.Bytes())
+	return err
+}
+
+// reportLines — текст выписки построчно
+func reportLines(report MonthlyReport) []string {
+	lines := []string{
+		"Gophermart monthly statement",
+		"",
+		"User:    " + report.Login,
+		"Period:  " + FormatPeriod(report.Period),
+		"",
+		fmt.Sprintf("%-40s %15s", "Opening balance", report.Opening.StringFixed(2)),
+		"",
+		"Accruals",
+	}
+
+	total := decimal.Zero
+	for _, entry := range report.Accruals {
+		lines = append(lines, fmt.Sprintf("  %-20s %-17s %15s", entry.Time.UTC().Format(time.DateTime), entry.OrderNumber, entry.Amount.StringFixed(2)))
+		total = total.Add(entry.Amount)
+	}
+	if len(report.Accruals) == 0 {
+		lines = append(lines, "  none")
+	}
+	lines = append(lines, fmt.Sprintf("%-40s %15s", "Total accruals", total.StringFixed(2)), "", "Withdrawals")
+
+	total = decimal.Zero
+	for _, entry := range report.Withdrawals {
+		amount := entry.Amount.Neg()
+		lines = append(lines, fmt.Sprintf("  %-20s %-17s %15s", entry.Time.UTC().Format(time.DateTime), entry.OrderNumber, amount.StringFixed(2)))
+		total = total.Add(amount)
+	}
+	if len(report.Withdrawals) == 0 {
+		lines = append(lines, "  none")
+	}
+	lines = append(lines, fmt.Sprintf("%-40s %15s", "Total withdrawals", total.StringFixed(2)), "")
+
+	// Раздел корректировок выводится, только если они были в этом месяце
+	if len(report.Adjustments) > 0 {
+		lines = append(lines, "Adjustments")
+		total = decimal.Zero
+		for _, entry := range report.Adjustments {
+			lines = append(lines, fmt.Sprintf("  %-20s %-17s %15s", entry.Time.UTC().Format(time.DateTime), "", entry.Amount.StringFixed(2)))
+			total = total.Add(entry.Amount)
+		}
+		lines = append(lines, fmt.Sprintf("%-40s %15s", "Total adjustments", total.StringFixed(2)), "")
+	}
+
+	return append(lines, fmt.Sprintf("%-40s %15s", "Closing balance", report.Closing.StringFixed(2)))
+}
+
+// paginate — разбивает строки на страницы
+func paginate(lines []string) [][]string {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	return append(pages, lines)
+}
+
+// pageContent — поток команд страницы: каждая строка выводится с новой строки текста
+func pageContent(lines []string) string {
+	var content strings.Builder
+	fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMarginLeft, pdfPageHeight-pdfMarginTop)
+	for _, line := range lines {
+		content.WriteString("(" + escapePDFText(line) + ") Tj T*\n")
+	}
+	content.WriteString("ET")
+	return content.String()
+}
+
+// escapePDFText — экранирует строку PDF; символы вне ASCII стандартный шрифт не отображает и они заменяются на '?'
+func escapePDFText(text string) string {
+	var escaped strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
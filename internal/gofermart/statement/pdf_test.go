@@ -0,0 +1,135 @@
+package statement
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"bytes"
+	"flag"
+	"github.com/shopspring/decimal"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+func TestWritePDF(t *testing.T) {
+	period := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	manyAccruals := make([]domain.StatementEntry, 0, 60)
+	for i := 0; i < 60; i++ {
+		manyAccruals = append(manyAccruals, domain.StatementEntry{
+			Time:        period.Add(time.Duration(i) * time.Hour),
+			Type:        domain.StatementEntryOrder,
+			OrderNumber: strconv.Itoa(1000 + i),
+			Amount:      decimal.NewFromInt(10),
+		})
+	}
+
+	testCases := []struct {
+		name          string
+		report        MonthlyReport
+		golden        string
+		expectedPages int
+	}{
+		{
+			name: "Single_Page",
+			report: MonthlyReport{
+				Login:   "user (test)",
+				Period:  period,
+				Opening: decimal.RequireFromString("500"),
+				Closing: decimal.RequireFromString("479.75"),
+				Accruals: []domain.StatementEntry{
+					{Time: period.Add(60 * time.Hour), Type: domain.StatementEntryOrder, OrderNumber: "346436439", Amount: decimal.RequireFromString("100.25")},
+				},
+				Withdrawals: []domain.StatementEntry{
+					{Time: period.Add(60 * time.Hour), Type: domain.StatementEntryWithdrawal, OrderNumber: "79927398713", Amount: decimal.RequireFromString("-120.5")},
+				},
+			},
+			golden:        "monthly_single_page.pdf",
+			expectedPages: 1,
+		},
+		{
+			name: "Empty_Month",
+			report: MonthlyReport{
+				Login:   "пользователь",
+				Period:  period,
+				Opening: decimal.RequireFromString("10"),
+				Closing: decimal.RequireFromString("10"),
+			},
+			golden:        "monthly_empty.pdf",
+			expectedPages: 1,
+		},
+		{
+			name: "Multiple_Pages",
+			report: MonthlyReport{
+				Login:    "user",
+				Period:   period,
+				Closing:  decimal.RequireFromString("600"),
+				Accruals: manyAccruals,
+			},
+			golden:        "monthly_multiple_pages.pdf",
+			expectedPages: 2,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var first, second bytes.Buffer
+			if err := WritePDF(&first, tc.report); err != nil {
+				t.Fatalf("failed to write pdf: %v", err)
+			}
+			if err := WritePDF(&second, tc.report); err != nil {
+				t.Fatalf("failed to write pdf: %v", err)
+			}
+			if !bytes.Equal(first.Bytes(), second.Bytes()) {
+				t.Fatal("pdf output is not deterministic")
+			}
+
+			if !strings.Contains(first.String(), "/Count "+strconv.Itoa(tc.expectedPages)+" ") {
+				t.Errorf("expected %d pages", tc.expectedPages)
+			}
+
+			path := filepath.Join("testdata", tc.golden)
+			if *updateGolden {
+				if err := os.WriteFile(path, first.Bytes(), 0o644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if !bytes.Equal(want, first.Bytes()) {
+				t.Errorf("output differs from %s:\n--- want\n%s\n--- got\n%s", path, want, first.Bytes())
+			}
+		})
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	testCases := []struct {
+		name      string
+		value     string
+		expected  time.Time
+		expectErr bool
+	}{
+		{name: "Valid_Month", value: "2024-05", expected: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{name: "Date_Instead_Of_Month", value: "2024-05-01", expectErr: true},
+		{name: "Invalid_Month", value: "2024-13", expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			period, err := ParsePeriod(tc.value)
+			if (err != nil) != tc.expectErr {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tc.expectErr && !period.Equal(tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, period)
+			}
+		})
+	}
+}
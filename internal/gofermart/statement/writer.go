@@ -0,0 +1,66 @@
+package statement
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// Форматы выгрузки выписки
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatXLSX = "xlsx"
+)
+
+// ErrUnsupportedFormat - запрошен неизвестный формат выгрузки
+var ErrUnsupportedFormat = errors.New("unsupported statement format")
+
+// columns — заголовки колонок табличных форматов
+var columns = []string{"date", "type", "order", "status", "amount", "balance"}
+
+// Entries - последовательное чтение строк выписки; false означает, что выписка закончилась
+type Entries interface {
+	Next(ctx context.Context) (domain.StatementEntry, bool, error)
+}
+
+// Writer - потоковый кодировщик выписки: строки пишутся по мере поступления, Close завершает документ
+type Writer interface {
+	WriteEntry(entry domain.StatementEntry) error
+	Close() error
+}
+
+// ContentType - возвращает MIME-тип формата выгрузки
+func ContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatJSON:
+		return "application/json", nil
+	case FormatXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	default:
+		return "", ErrUnsupportedFormat
+	}
+}
+
+// NewWriter - создает кодировщик выписки в указанном формате и записывает начало документа
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatCSV:
+		return newCSVWriter(w)
+	case FormatJSON:
+		return newJSONWriter(w)
+	case FormatXLSX:
+		return newXLSXWriter(w)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// formatTime — единый формат времени во всех выгрузках
+func formatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
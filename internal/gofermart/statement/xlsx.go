@@ -0,0 +1,127 @@
+package statement
+
+import (
+	"archive/zip"
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// xlsxStaticParts — неизменяемые части книги Office Open XML с единственным листом
+var xlsxStaticParts = []struct {
+	name    string
+	content string
+}{
+	{
+		name: "[Content_Types].xml",
+		content: xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+			`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+			`<Default Extension="xml" ContentType="application/xml"/>` +
+			`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+			`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+			`</Types>`,
+	},
+	{
+		name: "_rels/.rels",
+		content: xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+			`</Relationships>`,
+	},
+	{
+		name: "xl/workbook.xml",
+		content: xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" ` +
+			`xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+			`<sheets><sheet name="Statement" sheetId="1" r:id="rId1"/></sheets></workbook>`,
+	},
+	{
+		name: "xl/_rels/workbook.xml.rels",
+		content: xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+			`</Relationships>`,
+	},
+}
+
+// xlsxWriter — выгрузка выписки в XLSX. Лист пишется последним элементом zip-архива, поэтому строки
+// уходят клиенту по мере поступления; строки хранятся как inline-строки, суммы — как числа.
+type xlsxWriter struct {
+	zw    *zip.Writer
+	sheet io.Writer
+}
+
+func newXLSXWriter(w io.Writer) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range xlsxStaticParts {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return nil, err
+		}
+	}
+
+	sheet, err := zw.CreateHeader(&zip.FileHeader{Name: "xl/worksheets/sheet1.xml", Method: zip.Deflate})
+	if err != nil {
+		return nil, err
+	}
+	_, err = io.WriteString(sheet, xml.Header+`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	if err != nil {
+		return nil, err
+	}
+
+	xw := &xlsxWriter{zw: zw, sheet: sheet}
+	header := make([]xlsxCell, 0, len(columns))
+	for _, column := range columns {
+		header = append(header, xlsxCell{value: column})
+	}
+	if err := xw.writeRow(header); err != nil {
+		return nil, err
+	}
+	return xw, nil
+}
+
+// xlsxCell — значение ячейки; числовые ячейки пишутся без кавычек
+type xlsxCell struct {
+	value   string
+	numeric bool
+}
+
+// WriteEntry — записывает строку листа
+func (x *xlsxWriter) WriteEntry(entry domain.StatementEntry) error {
+	return x.writeRow([]xlsxCell{
+		{value: formatTime(entry.Time)},
+		{value: entry.Type},
+		{value: entry.OrderNumber},
+		{value: entry.Status},
+		{value: entry.Amount.StringFixed(2), numeric: true},
+		{value: entry.Balance.StringFixed(2), numeric: true},
+	})
+}
+
+// Close — закрывает лист и записывает оглавление архива
+func (x *xlsxWriter) Close() error {
+	if _, err := io.WriteString(x.sheet, `</sheetData></worksheet>`); err != nil {
+		return err
+	}
+	return x.zw.Close()
+}
+
+func (x *xlsxWriter) writeRow(cells []xlsxCell) error {
+	var row strings.Builder
+	row.WriteString("<row>")
+	for _, cell := range cells {
+		if cell.numeric {
+			row.WriteString(`<c t="n"><v>` + cell.value + `</v></c>`)
+			continue
+		}
+		row.WriteString(`<c t="inlineStr"><is><t>`)
+		if err := xml.EscapeText(&row, []byte(cell.value)); err != nil {
+			return err
+		}
+		row.WriteString(`</t></is></c>`)
+	}
+	row.WriteString("</row>")
+	_, err := io.WriteString(x.sheet, row.String())
+	return err
+}
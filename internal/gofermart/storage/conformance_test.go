@@ -0,0 +1,1347 @@
+package storage
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"github.com/shopspring/decimal"
+	"go.uber.org/zap"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storageFactory — создаёт пустое хранилище для очередного сценария набора
+type storageFactory func(t *testing.T) *Storage
+
+// runConformanceSuite — общий набор проверок, которому должна соответствовать любая реализация хранилища
+func runConformanceSuite(t *testing.T, newStorage storageFactory) {
+	testCases := []struct {
+		name string
+		run  func(t *testing.T, s *Storage)
+	}{
+		{name: "Save_And_Get_User", run: conformanceSaveAndGetUser},
+		{name: "Duplicate_Login", run: conformanceDuplicateLogin},
+		{name: "Unknown_User", run: conformanceUnknownUser},
+		{name: "User_Balance", run: conformanceUserBalance},
+		{name: "Add_And_Get_Orders", run: conformanceAddAndGetOrders},
+		{name: "Duplicate_Order", run: conformanceDuplicateOrder},
+		{name: "Add_Orders_Batch", run: conformanceAddOrdersBatch},
+		{name: "Orders_For_Processing", run: conformanceOrdersForProcessing},
+		{name: "Update_Order", run: conformanceUpdateOrder},
+		{name: "User_Version", run: conformanceUserVersion},
+		{name: "Withdrawals_Ordering", run: conformanceWithdrawalsOrdering},
+		{name: "Orders_Filter_And_Pagination", run: conformanceOrdersFilterAndPagination},
+		{name: "Withdrawals_Pagination", run: conformanceWithdrawalsPagination},
+		{name: "List_Users", run: conformanceListUsers},
+		{name: "Save_And_Get_Statement", run: conformanceSaveAndGetStatement},
+		{name: "Block_User", run: conformanceBlockUser},
+		{name: "User_Roles", run: conformanceUserRoles},
+		{name: "Balance_Adjustments", run: conformanceBalanceAdjustments},
+		{name: "Audit_Events", run: conformanceAuditEvents},
+		{name: "Outbox_Messages", run: conformanceOutboxMessages},
+		{name: "Outbox_Key_Order", run: conformanceOutboxKeyOrder},
+		{name: "Webhooks", run: conformanceWebhooks},
+		{name: "Webhook_Deliveries", run: conformanceWebhookDeliveries},
+		{name: "Order_Updates", run: conformanceOrderUpdates},
+		{name: "Rate_Limit", run: conformanceRateLimit},
+		{name: "Transaction_Commit", run: conformanceTransactionCommit},
+		{name: "Transaction_Rollback", run: conformanceTransactionRollback},
+		{name: "Transaction_Isolation", run: conformanceTransactionIsolation},
+		{name: "Nested_Transaction", run: conformanceNestedTransaction},
+		{name: "Cancelled_Context", run: conformanceCancelledContext},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.run(t, newStorage(t))
+		})
+	}
+}
+
+func mustSaveUser(t *testing.T, s *Storage, login string) *domain.User {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.UserRepo.SaveUser(ctx, domain.User{Login: login, Password: "hash"}); err != nil {
+		t.Fatalf("failed to save user: %v", err)
+	}
+	user, err := s.UserRepo.GetUserByLogin(ctx, login)
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	return user
+}
+
+func mustAddOrder(t *testing.T, s *Storage, order domain.Order) {
+	t.Helper()
+	ctx := context.Background()
+	if err := s.OrderRepo.AddOrder(ctx, order); err != nil {
+		t.Fatalf("failed to add order: %v", err)
+	}
+}
+
+func conformanceSaveAndGetUser(t *testing.T, s *Storage) {
+	user := mustSaveUser(t, s, "alice")
+	if user.UserID == 0 || user.Login != "alice" || user.Password != "hash" {
+		t.Errorf("unexpected user: %+v", user)
+	}
+	if !user.Balance.IsZero() {
+		t.Errorf("expected zero balance, got %s", user.Balance)
+	}
+}
+
+func conformanceDuplicateLogin(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	mustSaveUser(t, s, "alice")
+	err := s.UserRepo.SaveUser(ctx, domain.User{Login: "alice", Password: "other"})
+	if !errors.Is(err, gofermartErrors.ErrLoginAlreadyExists) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrLoginAlreadyExists, err)
+	}
+}
+
+func conformanceUnknownUser(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	if _, err := s.UserRepo.GetUserByLogin(ctx, "nobody"); !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		t.Errorf("GetUserByLogin: expected %v, got %v", gofermartErrors.ErrUserNotFound, err)
+	}
+	if _, err := s.UserRepo.GetUserByID(ctx, 1000); !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		t.Errorf("GetUserByID: expected %v, got %v", gofermartErrors.ErrUserNotFound, err)
+	}
+	if _, err := s.UserRepo.GetUserBalance(ctx, 1000); !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		t.Errorf("GetUserBalance: expected %v, got %v", gofermartErrors.ErrUserNotFound, err)
+	}
+	if _, err := s.UserRepo.GetUserVersion(ctx, 1000); !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		t.Errorf("GetUserVersion: expected %v, got %v", gofermartErrors.ErrUserNotFound, err)
+	}
+}
+
+func conformanceUserBalance(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	other := mustSaveUser(t, s, "bob")
+
+	if err := s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromFloat(700.5)); err != nil {
+		t.Fatalf("failed to update balance: %v", err)
+	}
+	if err := s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(-200)); err != nil {
+		t.Fatalf("failed to update balance: %v", err)
+	}
+	for _, amount := range []int64{100, 50} {
+		err := s.WithdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{
+			OrderNumber: "2377225624", UserID: user.UserID, Amount: decimal.NewFromInt(amount), ProcessedAt: time.Now(),
+		})
+		if err != nil {
+			t.Fatalf("failed to add withdrawal: %v", err)
+		}
+	}
+	err := s.WithdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{
+		OrderNumber: "2377225624", UserID: other.UserID, Amount: decimal.NewFromInt(1), ProcessedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("failed to add withdrawal: %v", err)
+	}
+
+	balance, err := s.UserRepo.GetUserBalance(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+	if balance.Current != 500.5 || balance.Withdrawn != 150 {
+		t.Errorf("unexpected balance: %+v", balance)
+	}
+
+	empty, err := s.UserRepo.GetUserBalance(ctx, other.UserID)
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+	if empty.Current != 0 || empty.Withdrawn != 1 {
+		t.Errorf("unexpected balance: %+v", empty)
+	}
+
+	// Баланс не может стать отрицательным, даже если проверка в сервисе пропущена
+	err = s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(-1000))
+	if !errors.Is(err, gofermartErrors.ErrInsufficientFunds) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrInsufficientFunds, err)
+	}
+	err = s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		locked, err := s.UserRepo.GetUserForUpdate(ctx, user.UserID)
+		if err != nil {
+			return err
+		}
+		if !locked.Balance.Equal(decimal.NewFromFloat(500.5)) {
+			t.Errorf("unexpected locked balance: %s", locked.Balance)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to lock user: %v", err)
+	}
+	if _, err := s.UserRepo.GetUserForUpdate(ctx, 1000); !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		t.Errorf("GetUserForUpdate: expected %v, got %v", gofermartErrors.ErrUserNotFound, err)
+	}
+}
+
+func conformanceAddAndGetOrders(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	base := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	mustAddOrder(t, s, domain.Order{OrderNumber: "12345678903", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: base})
+	mustAddOrder(t, s, domain.Order{OrderNumber: "9278923470", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: base.Add(time.Hour)})
+
+	order, err := s.OrderRepo.GetOrderByNumber(ctx, "12345678903")
+	if err != nil || order == nil {
+		t.Fatalf("expected order, got %v, %v", order, err)
+	}
+	if order.UserID != user.UserID || order.OrderStatus != domain.OrderStatusNew || !order.UploadedAt.Equal(base) {
+		t.Errorf("unexpected order: %+v", order)
+	}
+
+	missing, err := s.OrderRepo.GetOrderByNumber(ctx, "79927398713")
+	if err != nil || missing != nil {
+		t.Errorf("expected nil order without error, got %v, %v", missing, err)
+	}
+
+	orders, err := s.OrderRepo.GetOrdersByUserID(ctx, user.UserID, domain.OrderFilter{})
+	if err != nil {
+		t.Fatalf("failed to get orders: %v", err)
+	}
+	if len(orders) != 2 || orders[0].OrderNumber != "9278923470" || orders[1].OrderNumber != "12345678903" {
+		t.Errorf("expected orders newest first, got %+v", orders)
+	}
+}
+
+func conformanceDuplicateOrder(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	order := domain.Order{OrderNumber: "12345678903", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: time.Now()}
+	mustAddOrder(t, s, order)
+
+	if err := s.OrderRepo.AddOrder(ctx, order); !errors.Is(err, gofermartErrors.ErrOrderAlreadyExists) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrOrderAlreadyExists, err)
+	}
+}
+
+func conformanceAddOrdersBatch(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	alice := mustSaveUser(t, s, "alice")
+	bob := mustSaveUser(t, s, "bob")
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	mustAddOrder(t, s, domain.Order{OrderNumber: "12345678903", UserID: bob.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: now})
+
+	inserted, err := s.OrderRepo.AddOrders(ctx, []domain.Order{
+		{OrderNumber: "79927398713", UserID: alice.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: now},
+		{OrderNumber: "12345678903", UserID: alice.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: now},
+		{OrderNumber: "9278923470", UserID: alice.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: now},
+	})
+	if err != nil {
+		t.Fatalf("failed to add orders: %v", err)
+	}
+	if strings.Join(inserted, ",") != "79927398713,9278923470" {
+		t.Errorf("unexpected inserted numbers: %v", inserted)
+	}
+
+	orders, err := s.OrderRepo.GetOrdersByNumbers(ctx, []string{"12345678903", "79927398713", "346436439"})
+	if err != nil {
+		t.Fatalf("failed to get orders by numbers: %v", err)
+	}
+	owners := make(map[string]int, len(orders))
+	for _, order := range orders {
+		owners[order.OrderNumber] = order.UserID
+	}
+	if len(owners) != 2 || owners["12345678903"] != bob.UserID || owners["79927398713"] != alice.UserID {
+		t.Errorf("unexpected orders: %+v", orders)
+	}
+
+	// Большой пакет не умещается в один запрос PostgreSQL (не больше 65535 параметров) и вставляется частями
+	large := make([]domain.Order, 15000)
+	for i := range large {
+		large[i] = domain.Order{OrderNumber: strconv.Itoa(1000000 + i), UserID: alice.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: now}
+	}
+	large[len(large)-1].OrderNumber = "12345678903"
+	inserted, err = s.OrderRepo.AddOrders(ctx, large)
+	if err != nil {
+		t.Fatalf("failed to add large orders batch: %v", err)
+	}
+	if len(inserted) != len(large)-1 || inserted[0] != "1000000" || inserted[len(inserted)-1] != strconv.Itoa(1000000+len(large)-2) {
+		t.Errorf("expected %d inserted numbers in input order, got %d", len(large)-1, len(inserted))
+	}
+}
+
+func conformanceOrdersForProcessing(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	now := time.Now()
+	for number, status := range map[string]string{
+		"12345678903": domain.OrderStatusNew,
+		"9278923470":  domain.OrderStatusProcessing,
+		"346436439":   domain.OrderStatusProcessed,
+		"2377225624":  domain.OrderStatusInvalid,
+	} {
+		mustAddOrder(t, s, domain.Order{OrderNumber: number, UserID: user.UserID, OrderStatus: status, UploadedAt: now})
+	}
+
+	if err := s.OrderRepo.LockOrderForProcessing(ctx, "9278923470"); err != nil {
+		t.Fatalf("failed to lock order: %v", err)
+	}
+
+	orders, err := s.OrderRepo.GetOrdersForProcessing(ctx)
+	if err != nil {
+		t.Fatalf("failed to get orders for processing: %v", err)
+	}
+	if len(orders) != 1 || orders[0].OrderNumber != "12345678903" {
+		t.Errorf("expected only the new unlocked order, got %+v", orders)
+	}
+
+	if err := s.OrderRepo.UnlockOrder(ctx, "9278923470"); err != nil {
+		t.Fatalf("failed to unlock order: %v", err)
+	}
+	orders, err = s.OrderRepo.GetOrdersForProcessing(ctx)
+	if err != nil {
+		t.Fatalf("failed to get orders for processing: %v", err)
+	}
+	if len(orders) != 2 {
+		t.Errorf("expected 2 orders after unlock, got %+v", orders)
+	}
+}
+
+func conformanceUpdateOrder(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	mustAddOrder(t, s, domain.Order{OrderNumber: "12345678903", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: time.Now()})
+
+	err := s.OrderRepo.UpdateOrder(ctx, domain.Order{OrderNumber: "12345678903", OrderStatus: domain.OrderStatusProcessed, Accrual: decimal.NewFromFloat(729.98)})
+	if err != nil {
+		t.Fatalf("failed to update order: %v", err)
+	}
+
+	order, err := s.OrderRepo.GetOrderByNumber(ctx, "12345678903")
+	if err != nil {
+		t.Fatalf("failed to get order: %v", err)
+	}
+	if order.OrderStatus != domain.OrderStatusProcessed || !order.Accrual.Equal(decimal.NewFromFloat(729.98)) || order.UserID != user.UserID {
+		t.Errorf("unexpected order: %+v", order)
+	}
+}
+
+func conformanceUserVersion(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	other := mustSaveUser(t, s, "bob")
+
+	steps := []struct {
+		name  string
+		write func() error
+	}{
+		{name: "UpdateUserBalance", write: func() error {
+			return s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(100))
+		}},
+		{name: "AddOrder", write: func() error {
+			return s.OrderRepo.AddOrder(ctx, domain.Order{OrderNumber: "12345678903", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: time.Now()})
+		}},
+		{name: "AddOrders", write: func() error {
+			_, err := s.OrderRepo.AddOrders(ctx, []domain.Order{{OrderNumber: "2377225624", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: time.Now()}})
+			return err
+		}},
+		{name: "UpdateOrder", write: func() error {
+			return s.OrderRepo.UpdateOrder(ctx, domain.Order{OrderNumber: "12345678903", OrderStatus: domain.OrderStatusProcessing})
+		}},
+		{name: "AddWithdrawal", write: func() error {
+			return s.WithdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{OrderNumber: "79927398713", UserID: user.UserID, Amount: decimal.NewFromInt(10), ProcessedAt: time.Now()})
+		}},
+	}
+
+	previous, err := s.UserRepo.GetUserVersion(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("failed to get user version: %v", err)
+	}
+	for _, step := range steps {
+		if err := step.write(); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		version, err := s.UserRepo.GetUserVersion(ctx, user.UserID)
+		if err != nil {
+			t.Fatalf("%s: failed to get user version: %v", step.name, err)
+		}
+		if version <= previous {
+			t.Errorf("%s: expected version to grow from %d, got %d", step.name, previous, version)
+		}
+		previous = version
+	}
+
+	// Пакет из уже существующих заказов ничего не меняет
+	if _, err := s.OrderRepo.AddOrders(ctx, []domain.Order{{OrderNumber: "2377225624", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: time.Now()}}); err != nil {
+		t.Fatalf("failed to add orders batch: %v", err)
+	}
+	if version, _ := s.UserRepo.GetUserVersion(ctx, user.UserID); version != previous {
+		t.Errorf("expected version %d after duplicate batch, got %d", previous, version)
+	}
+	if version, _ := s.UserRepo.GetUserVersion(ctx, other.UserID); version != 0 {
+		t.Errorf("expected untouched user to keep version 0, got %d", version)
+	}
+}
+
+func conformanceWithdrawalsOrdering(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	base := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, number := range []string{"2377225624", "12345678903"} {
+		err := s.WithdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{
+			OrderNumber: number, UserID: user.UserID, Amount: decimal.NewFromInt(10), ProcessedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("failed to add withdrawal: %v", err)
+		}
+	}
+
+	withdrawals, err := s.WithdrawalRepo.GetWithdrawalsByUserID(ctx, user.UserID, domain.WithdrawalFilter{})
+	if err != nil {
+		t.Fatalf("failed to get withdrawals: %v", err)
+	}
+	if len(withdrawals) != 2 || withdrawals[0].OrderNumber != "12345678903" {
+		t.Errorf("expected withdrawals newest first, got %+v", withdrawals)
+	}
+}
+
+func conformanceOrdersFilterAndPagination(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	other := mustSaveUser(t, s, "bob")
+	base := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	// Два заказа с одинаковым временем проверяют порядок по номеру внутри одной отметки времени
+	mustAddOrder(t, s, domain.Order{OrderNumber: "1001", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: base})
+	mustAddOrder(t, s, domain.Order{OrderNumber: "1002", UserID: user.UserID, OrderStatus: domain.OrderStatusProcessed, UploadedAt: base.Add(time.Hour)})
+	mustAddOrder(t, s, domain.Order{OrderNumber: "1003", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: base.Add(time.Hour)})
+	mustAddOrder(t, s, domain.Order{OrderNumber: "1004", UserID: user.UserID, OrderStatus: domain.OrderStatusInvalid, UploadedAt: base.Add(2 * time.Hour)})
+	mustAddOrder(t, s, domain.Order{OrderNumber: "2001", UserID: other.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: base.Add(time.Hour)})
+
+	numbers := func(orders []domain.Order) []string {
+		result := make([]string, 0, len(orders))
+		for _, order := range orders {
+			result = append(result, order.OrderNumber)
+		}
+		return result
+	}
+
+	// Постраничный обход должен вернуть все заказы пользователя ровно один раз и в порядке убывания
+	var walked []string
+	filter := domain.OrderFilter{ListOptions: domain.ListOptions{Limit: 2}}
+	for page := 0; page < 5; page++ {
+		orders, err := s.OrderRepo.GetOrdersByUserID(ctx, user.UserID, filter)
+		if err != nil {
+			t.Fatalf("failed to get orders page: %v", err)
+		}
+		walked = append(walked, numbers(orders)...)
+		if len(orders) < filter.Limit {
+			break
+		}
+		last := orders[len(orders)-1]
+		filter.After = &domain.Cursor{Time: last.UploadedAt, Key: last.OrderNumber}
+	}
+	if got := strings.Join(walked, ","); got != "1004,1003,1002,1001" {
+		t.Errorf("unexpected pagination walk: %s", got)
+	}
+
+	testCases := []struct {
+		name     string
+		filter   domain.OrderFilter
+		expected string
+	}{
+		{
+			name:     "Status",
+			filter:   domain.OrderFilter{Statuses: []string{domain.OrderStatusNew}},
+			expected: "1003,1001",
+		},
+		{
+			name:     "Period",
+			filter:   domain.OrderFilter{ListOptions: domain.ListOptions{From: base.Add(time.Hour), To: base.Add(2 * time.Hour)}},
+			expected: "1003,1002",
+		},
+		{
+			name: "Status_And_Cursor",
+			filter: domain.OrderFilter{
+				ListOptions: domain.ListOptions{After: &domain.Cursor{Time: base.Add(time.Hour), Key: "1003"}},
+				Statuses:    []string{domain.OrderStatusNew, domain.OrderStatusProcessed},
+			},
+			expected: "1002,1001",
+		},
+		{
+			name: "Ascending_With_Cursor",
+			filter: domain.OrderFilter{
+				ListOptions: domain.ListOptions{Ascending: true, After: &domain.Cursor{Time: base.Add(time.Hour), Key: "1002"}},
+			},
+			expected: "1003,1004",
+		},
+	}
+
+	for _, tc := range testCases {
+		orders, err := s.OrderRepo.GetOrdersByUserID(ctx, user.UserID, tc.filter)
+		if err != nil {
+			t.Fatalf("%s: failed to get orders: %v", tc.name, err)
+		}
+		if got := strings.Join(numbers(orders), ","); got != tc.expected {
+			t.Errorf("%s: expected %s, got %s", tc.name, tc.expected, got)
+		}
+	}
+}
+
+func conformanceWithdrawalsPagination(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	base := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, number := range []string{"2377225624", "12345678903", "9278923470"} {
+		err := s.WithdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{
+			OrderNumber: number, UserID: user.UserID, Amount: decimal.NewFromInt(10), ProcessedAt: base.Add(time.Duration(i) * time.Hour),
+		})
+		if err != nil {
+			t.Fatalf("failed to add withdrawal: %v", err)
+		}
+	}
+
+	first, err := s.WithdrawalRepo.GetWithdrawalsByUserID(ctx, user.UserID, domain.WithdrawalFilter{ListOptions: domain.ListOptions{Limit: 2}})
+	if err != nil {
+		t.Fatalf("failed to get withdrawals: %v", err)
+	}
+	if len(first) != 2 || first[0].OrderNumber != "9278923470" || first[1].OrderNumber != "12345678903" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+
+	last := first[len(first)-1]
+	cursor := &domain.Cursor{Time: last.ProcessedAt, Key: strconv.Itoa(last.WithdrawalID)}
+	second, err := s.WithdrawalRepo.GetWithdrawalsByUserID(ctx, user.UserID, domain.WithdrawalFilter{ListOptions: domain.ListOptions{Limit: 2, After: cursor}})
+	if err != nil {
+		t.Fatalf("failed to get withdrawals: %v", err)
+	}
+	if len(second) != 1 || second[0].OrderNumber != "2377225624" {
+		t.Errorf("unexpected second page: %+v", second)
+	}
+
+	ascending, err := s.WithdrawalRepo.GetWithdrawalsByUserID(ctx, user.UserID, domain.WithdrawalFilter{ListOptions: domain.ListOptions{Ascending: true, After: cursor}})
+	if err != nil {
+		t.Fatalf("failed to get withdrawals: %v", err)
+	}
+	if len(ascending) != 1 || ascending[0].OrderNumber != "9278923470" {
+		t.Errorf("unexpected ascending page: %+v", ascending)
+	}
+
+	period, err := s.WithdrawalRepo.GetWithdrawalsByUserID(ctx, user.UserID, domain.WithdrawalFilter{ListOptions: domain.ListOptions{From: base.Add(time.Hour)}})
+	if err != nil {
+		t.Fatalf("failed to get withdrawals: %v", err)
+	}
+	if len(period) != 2 {
+		t.Errorf("expected 2 withdrawals in period, got %+v", period)
+	}
+
+	_, err = s.WithdrawalRepo.GetWithdrawalsByUserID(ctx, user.UserID, domain.WithdrawalFilter{ListOptions: domain.ListOptions{After: &domain.Cursor{Time: base, Key: "abc"}}})
+	if !errors.Is(err, gofermartErrors.ErrInvalidCursor) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrInvalidCursor, err)
+	}
+}
+
+func conformanceListUsers(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	for _, login := range []string{"alice", "bob", "carol"} {
+		mustSaveUser(t, s, login)
+	}
+
+	first, err := s.UserRepo.ListUsers(ctx, 0, 2)
+	if err != nil {
+		t.Fatalf("failed to list users: %v", err)
+	}
+	if len(first) != 2 || first[0].Login != "alice" || first[1].Login != "bob" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+
+	rest, err := s.UserRepo.ListUsers(ctx, first[1].UserID, 2)
+	if err != nil {
+		t.Fatalf("failed to list users: %v", err)
+	}
+	if len(rest) != 1 || rest[0].Login != "carol" {
+		t.Errorf("unexpected second page: %+v", rest)
+	}
+}
+
+func conformanceSaveAndGetStatement(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	createdAt := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := s.StatementRepo.GetStatement(ctx, user.UserID, "2024-05"); !errors.Is(err, gofermartErrors.ErrStatementNotFound) {
+		t.Fatalf("expected %v, got %v", gofermartErrors.ErrStatementNotFound, err)
+	}
+
+	for _, content := range []string{"first", "second"} {
+		err := s.StatementRepo.SaveStatement(ctx, domain.Statement{UserID: user.UserID, Period: "2024-05", Content: []byte(content), CreatedAt: createdAt})
+		if err != nil {
+			t.Fatalf("failed to save statement: %v", err)
+		}
+	}
+
+	statement, err := s.StatementRepo.GetStatement(ctx, user.UserID, "2024-05")
+	if err != nil {
+		t.Fatalf("failed to get statement: %v", err)
+	}
+	if string(statement.Content) != "second" || !statement.CreatedAt.Equal(createdAt) {
+		t.Errorf("expected the statement to be replaced, got %+v", statement)
+	}
+}
+
+func conformanceBlockUser(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+
+	if err := s.UserRepo.SetUserBlocked(ctx, user.UserID, true); err != nil {
+		t.Fatalf("failed to block user: %v", err)
+	}
+	blocked, err := s.UserRepo.GetUserByLogin(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if !blocked.Blocked {
+		t.Error("expected user to be blocked")
+	}
+
+	if err := s.UserRepo.SetUserBlocked(ctx, user.UserID, false); err != nil {
+		t.Fatalf("failed to unblock user: %v", err)
+	}
+	unblocked, err := s.UserRepo.GetUserByLogin(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if unblocked.Blocked {
+		t.Error("expected user to be unblocked")
+	}
+
+	if err := s.UserRepo.SetUserBlocked(ctx, user.UserID+100, true); !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrUserNotFound, err)
+	}
+}
+
+func conformanceUserRoles(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	if err := s.UserRepo.SaveUser(ctx, domain.User{Login: "alice", Password: "hash", Roles: domain.DefaultRoles()}); err != nil {
+		t.Fatalf("failed to save user: %v", err)
+	}
+	user, err := s.UserRepo.GetUserByLogin(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if diff := cmp.Diff(domain.DefaultRoles(), user.Roles); diff != "" {
+		t.Errorf("default roles mismatch (-want +got):\n%s", diff)
+	}
+
+	roles := domain.Roles{domain.RoleUser, domain.RoleSupport}
+	if err := s.UserRepo.SetUserRoles(ctx, user.UserID, roles); err != nil {
+		t.Fatalf("failed to set roles: %v", err)
+	}
+	updated, err := s.UserRepo.GetUserByID(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if updated.Login != "alice" {
+		t.Errorf("expected alice, got %s", updated.Login)
+	}
+	if diff := cmp.Diff(roles, updated.Roles); diff != "" {
+		t.Errorf("roles mismatch (-want +got):\n%s", diff)
+	}
+
+	if err := s.UserRepo.SetUserRoles(ctx, user.UserID+100, roles); !errors.Is(err, gofermartErrors.ErrUserNotFound) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrUserNotFound, err)
+	}
+}
+
+func conformanceBalanceAdjustments(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	alice := mustSaveUser(t, s, "alice")
+	bob := mustSaveUser(t, s, "bob")
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	adjustments := []domain.BalanceAdjustment{
+		{UserID: alice.UserID, Amount: decimal.NewFromInt(100), Reason: "goodwill", Actor: "support", CreatedAt: base},
+		{UserID: bob.UserID, Amount: decimal.NewFromInt(5), Reason: "typo", Actor: "support", CreatedAt: base},
+		{UserID: alice.UserID, Amount: decimal.NewFromFloat(-20.5), Reason: "chargeback", Actor: "admin", CreatedAt: base.Add(time.Hour)},
+	}
+	for _, adjustment := range adjustments {
+		if err := s.AdjustmentRepo.AddAdjustment(ctx, adjustment); err != nil {
+			t.Fatalf("failed to add adjustment: %v", err)
+		}
+	}
+
+	got, err := s.AdjustmentRepo.GetAdjustmentsByUserID(ctx, alice.UserID, domain.ListOptions{})
+	if err != nil {
+		t.Fatalf("failed to get adjustments: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 adjustments, got %d", len(got))
+	}
+	if got[0].Reason != "chargeback" || !got[0].Amount.Equal(decimal.NewFromFloat(-20.5)) || got[0].Actor != "admin" || got[0].AdjustmentID == 0 {
+		t.Errorf("unexpected newest adjustment: %+v", got[0])
+	}
+	if got[1].Reason != "goodwill" || !got[1].CreatedAt.Equal(base) {
+		t.Errorf("unexpected oldest adjustment: %+v", got[1])
+	}
+
+	// Постранично от старых к новым, как их читает выписка
+	first, err := s.AdjustmentRepo.GetAdjustmentsByUserID(ctx, alice.UserID, domain.ListOptions{Limit: 1, Ascending: true})
+	if err != nil {
+		t.Fatalf("failed to get adjustments page: %v", err)
+	}
+	if len(first) != 1 || first[0].Reason != "goodwill" {
+		t.Fatalf("unexpected first page: %+v", first)
+	}
+	after := domain.Cursor{Time: first[0].CreatedAt, Key: strconv.Itoa(first[0].AdjustmentID)}
+	second, err := s.AdjustmentRepo.GetAdjustmentsByUserID(ctx, alice.UserID, domain.ListOptions{Limit: 1, Ascending: true, After: &after})
+	if err != nil {
+		t.Fatalf("failed to get adjustments page: %v", err)
+	}
+	if len(second) != 1 || second[0].Reason != "chargeback" {
+		t.Errorf("unexpected second page: %+v", second)
+	}
+	inPeriod, err := s.AdjustmentRepo.GetAdjustmentsByUserID(ctx, alice.UserID, domain.ListOptions{To: base.Add(time.Minute)})
+	if err != nil {
+		t.Fatalf("failed to get adjustments in period: %v", err)
+	}
+	if len(inPeriod) != 1 || inPeriod[0].Reason != "goodwill" {
+		t.Errorf("unexpected adjustments in period: %+v", inPeriod)
+	}
+}
+
+func conformanceAuditEvents(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	events := []domain.AuditEvent{
+		{Type: domain.AuditUserRegistered, Actor: "alice", Subject: "alice", IP: "192.0.2.1", RequestID: "req-1", Payload: domain.AuditPayload{}, CreatedAt: base},
+		{Type: domain.AuditLoginFailed, Actor: "alice", Subject: "alice", Payload: domain.AuditPayload{"reason": "invalid_password"}, CreatedAt: base.Add(time.Minute)},
+		{Type: domain.AuditBalanceAdjusted, Actor: "support", Subject: "alice", Payload: domain.AuditPayload{"amount": "10"}, CreatedAt: base.Add(2 * time.Minute)},
+		{Type: domain.AuditLoginFailed, Actor: "bob", Subject: "bob", Payload: domain.AuditPayload{"reason": "unknown_login"}, CreatedAt: base.Add(3 * time.Minute)},
+	}
+	for _, event := range events {
+		if err := s.AuditRepo.AddEvent(ctx, event); err != nil {
+			t.Fatalf("failed to add audit event: %v", err)
+		}
+	}
+
+	all, err := s.AuditRepo.GetEvents(ctx, domain.AuditFilter{})
+	if err != nil {
+		t.Fatalf("failed to get audit events: %v", err)
+	}
+	if len(all) != 4 || all[0].Actor != "bob" || all[3].Type != domain.AuditUserRegistered {
+		t.Fatalf("unexpected audit events: %+v", all)
+	}
+	if all[3].IP != "192.0.2.1" || all[3].RequestID != "req-1" || all[3].EventID == 0 {
+		t.Errorf("unexpected registration event: %+v", all[3])
+	}
+	if all[0].Payload["reason"] != "unknown_login" {
+		t.Errorf("expected payload to round-trip, got %+v", all[0].Payload)
+	}
+
+	testCases := []struct {
+		name     string
+		filter   domain.AuditFilter
+		expected []domain.AuditEventType
+	}{
+		{
+			name:     "Type",
+			filter:   domain.AuditFilter{Types: []domain.AuditEventType{domain.AuditLoginFailed}},
+			expected: []domain.AuditEventType{domain.AuditLoginFailed, domain.AuditLoginFailed},
+		},
+		{
+			name:     "Actor_And_Subject",
+			filter:   domain.AuditFilter{Actor: "support", Subject: "alice"},
+			expected: []domain.AuditEventType{domain.AuditBalanceAdjusted},
+		},
+		{
+			name:     "Period",
+			filter:   domain.AuditFilter{ListOptions: domain.ListOptions{From: base.Add(time.Minute), To: base.Add(3 * time.Minute)}},
+			expected: []domain.AuditEventType{domain.AuditBalanceAdjusted, domain.AuditLoginFailed},
+		},
+		{
+			name:     "Cursor",
+			filter:   domain.AuditFilter{ListOptions: domain.ListOptions{Limit: 2, After: &domain.Cursor{Time: all[1].CreatedAt, Key: strconv.Itoa(all[1].EventID)}}},
+			expected: []domain.AuditEventType{domain.AuditLoginFailed, domain.AuditUserRegistered},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := s.AuditRepo.GetEvents(ctx, tc.filter)
+			if err != nil {
+				t.Fatalf("failed to get audit events: %v", err)
+			}
+			types := make([]domain.AuditEventType, 0, len(got))
+			for _, event := range got {
+				types = append(types, event.Type)
+			}
+			if diff := cmp.Diff(tc.expected, types); diff != "" {
+				t.Errorf("events mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	// Событие, записанное в откатившейся транзакции, не попадает в журнал
+	errAbort := errors.New("abort")
+	err = s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.AuditRepo.AddEvent(ctx, domain.AuditEvent{Type: domain.AuditTokenIssued, Actor: "carol", Subject: "carol", Payload: domain.AuditPayload{}, CreatedAt: base}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected %v, got %v", errAbort, err)
+	}
+	got, err := s.AuditRepo.GetEvents(ctx, domain.AuditFilter{Actor: "carol"})
+	if err != nil || len(got) != 0 {
+		t.Errorf("expected audit event to be rolled back, got %v, %v", got, err)
+	}
+}
+
+func conformanceOutboxMessages(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	keys := []string{"12345678903", "9278923470", "79927398713"}
+	for i, eventType := range []domain.OutboxEventType{domain.OutboxOrderStatusChanged, domain.OutboxPointsAccrued, domain.OutboxPointsWithdrawn} {
+		message := domain.OutboxMessage{Type: eventType, Key: keys[i], Payload: domain.OutboxPayload(`{"order":"` + keys[i] + `"}`), CreatedAt: base.Add(time.Duration(i) * time.Second)}
+		if err := s.OutboxRepo.AddMessage(ctx, message); err != nil {
+			t.Fatalf("failed to add outbox message: %v", err)
+		}
+	}
+
+	// Событие из откатившейся транзакции не попадает в outbox
+	errAbort := errors.New("abort")
+	err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.OutboxRepo.AddMessage(ctx, domain.OutboxMessage{Type: domain.OutboxPointsWithdrawn, Key: "2377225624", CreatedAt: base}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected %v, got %v", errAbort, err)
+	}
+
+	now := base.Add(time.Minute)
+	claimed, err := s.OutboxRepo.ClaimMessages(ctx, now, time.Minute, 2)
+	if err != nil {
+		t.Fatalf("failed to claim outbox messages: %v", err)
+	}
+	if len(claimed) != 2 || claimed[0].Type != domain.OutboxOrderStatusChanged || claimed[1].Type != domain.OutboxPointsAccrued {
+		t.Fatalf("unexpected claimed messages: %+v", claimed)
+	}
+	// jsonb не сохраняет исходное форматирование, поэтому тело сравнивается после разбора
+	var payload map[string]string
+	if err := json.Unmarshal(claimed[0].Payload, &payload); err != nil || payload["order"] != "12345678903" {
+		t.Errorf("unexpected payload: %s", claimed[0].Payload)
+	}
+	if claimed[0].Key != "12345678903" || claimed[0].MessageID == 0 {
+		t.Errorf("unexpected claimed message: %+v", claimed[0])
+	}
+
+	// Выбранные события не выдаются повторно, пока не истекла аренда
+	rest, err := s.OutboxRepo.ClaimMessages(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to claim outbox messages: %v", err)
+	}
+	if len(rest) != 1 || rest[0].Type != domain.OutboxPointsWithdrawn {
+		t.Fatalf("unexpected remaining messages: %+v", rest)
+	}
+
+	if err := s.OutboxRepo.MarkDelivered(ctx, claimed[0].MessageID, now); err != nil {
+		t.Fatalf("failed to mark message delivered: %v", err)
+	}
+	if err := s.OutboxRepo.MarkFailed(ctx, claimed[1].MessageID, now.Add(time.Hour), "connection refused"); err != nil {
+		t.Fatalf("failed to mark message failed: %v", err)
+	}
+
+	// По истечении аренды возвращается только неподтверждённое событие; событие с ошибкой ждёт своей попытки
+	expired, err := s.OutboxRepo.ClaimMessages(ctx, now.Add(2*time.Minute), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to claim outbox messages: %v", err)
+	}
+	if len(expired) != 1 || expired[0].MessageID != rest[0].MessageID {
+		t.Fatalf("unexpected messages after lease expiry: %+v", expired)
+	}
+
+	retried, err := s.OutboxRepo.ClaimMessages(ctx, now.Add(2*time.Hour), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to claim outbox messages: %v", err)
+	}
+	if len(retried) != 2 || retried[0].MessageID != claimed[1].MessageID {
+		t.Fatalf("unexpected messages after retry delay: %+v", retried)
+	}
+	if retried[0].Attempts != 1 || retried[0].LastError != "connection refused" {
+		t.Errorf("expected failed attempt to be recorded, got %+v", retried[0])
+	}
+}
+
+func conformanceOutboxKeyOrder(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, message := range []domain.OutboxMessage{
+		{Type: domain.OutboxOrderStatusChanged, Key: "12345678903", CreatedAt: now},
+		{Type: domain.OutboxPointsAccrued, Key: "12345678903", CreatedAt: now},
+		{Type: domain.OutboxPointsWithdrawn, Key: "79927398713", CreatedAt: now},
+	} {
+		message.Payload = domain.OutboxPayload(`{}`)
+		if err := s.OutboxRepo.AddMessage(ctx, message); err != nil {
+			t.Fatalf("failed to add outbox message: %v", err)
+		}
+	}
+
+	// claim — выбирает события на момент at и возвращает их типы вместе с идентификаторами
+	claim := func(at time.Time) ([]domain.OutboxEventType, []int) {
+		t.Helper()
+		claimed, err := s.OutboxRepo.ClaimMessages(ctx, at, time.Minute, 10)
+		if err != nil {
+			t.Fatalf("failed to claim outbox messages: %v", err)
+		}
+		types := make([]domain.OutboxEventType, 0, len(claimed))
+		ids := make([]int, 0, len(claimed))
+		for _, message := range claimed {
+			types = append(types, message.Type)
+			ids = append(ids, message.MessageID)
+		}
+		return types, ids
+	}
+
+	// Следующее событие по ключу выдаётся только после доставки предыдущего
+	first, ids := claim(now)
+	if diff := cmp.Diff([]domain.OutboxEventType{domain.OutboxOrderStatusChanged, domain.OutboxPointsWithdrawn}, first); diff != "" {
+		t.Fatalf("unexpected claimed messages (-want +got):\n%s", diff)
+	}
+	if err := s.OutboxRepo.MarkFailed(ctx, ids[0], now.Add(time.Hour), "connection refused"); err != nil {
+		t.Fatalf("failed to mark message failed: %v", err)
+	}
+	if err := s.OutboxRepo.MarkDelivered(ctx, ids[1], now); err != nil {
+		t.Fatalf("failed to mark message delivered: %v", err)
+	}
+
+	// Пока событие с ошибкой ждёт повторной попытки, следующие события по его ключу не выдаются
+	if blocked, _ := claim(now.Add(2 * time.Minute)); len(blocked) != 0 {
+		t.Fatalf("expected no messages while the earlier one awaits retry, got %v", blocked)
+	}
+	if retried, _ := claim(now.Add(2 * time.Hour)); len(retried) != 1 || retried[0] != domain.OutboxOrderStatusChanged {
+		t.Fatalf("expected only the failed message to be retried, got %v", retried)
+	}
+	if err := s.OutboxRepo.MarkDelivered(ctx, ids[0], now.Add(2*time.Hour)); err != nil {
+		t.Fatalf("failed to mark message delivered: %v", err)
+	}
+	next, nextIDs := claim(now.Add(2 * time.Hour))
+	if len(next) != 1 || next[0] != domain.OutboxPointsAccrued {
+		t.Fatalf("expected the next message of the key after delivery, got %v", next)
+	}
+
+	// Событие, исчерпавшее попытки, больше не выдаётся и не задерживает следующие события своего ключа
+	if err := s.OutboxRepo.AddMessage(ctx, domain.OutboxMessage{Type: domain.OutboxOrderStatusChanged, Key: "12345678903", Payload: domain.OutboxPayload(`{}`), CreatedAt: now}); err != nil {
+		t.Fatalf("failed to add outbox message: %v", err)
+	}
+	if err := s.OutboxRepo.MarkDead(ctx, nextIDs[0], now.Add(2*time.Hour), "connection refused"); err != nil {
+		t.Fatalf("failed to mark message dead: %v", err)
+	}
+	after, afterIDs := claim(now.Add(3 * time.Hour))
+	if len(after) != 1 || after[0] != domain.OutboxOrderStatusChanged {
+		t.Fatalf("expected the message after the dead one to be claimed, got %v", after)
+	}
+	if err := s.OutboxRepo.MarkDelivered(ctx, afterIDs[0], now.Add(3*time.Hour)); err != nil {
+		t.Fatalf("failed to mark message delivered: %v", err)
+	}
+	if none, _ := claim(now.Add(4 * time.Hour)); len(none) != 0 {
+		t.Fatalf("expected the dead message not to be claimed again, got %v", none)
+	}
+}
+
+func conformanceWebhooks(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	alice := mustSaveUser(t, s, "alice")
+	bob := mustSaveUser(t, s, "bob")
+	createdAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	first := domain.Webhook{UserID: alice.UserID, URL: "https://example.com/a", Events: []domain.OutboxEventType{domain.OutboxOrderStatusChanged}, Secret: "s1", CreatedAt: createdAt}
+	second := domain.Webhook{UserID: alice.UserID, URL: "https://example.com/b", Events: domain.WebhookEventTypes, Secret: "s2", CreatedAt: createdAt}
+	other := domain.Webhook{UserID: bob.UserID, URL: "https://example.com/c", Events: domain.WebhookEventTypes, Secret: "s3", CreatedAt: createdAt}
+	for _, webhook := range []*domain.Webhook{&first, &second, &other} {
+		if err := s.WebhookRepo.AddWebhook(ctx, webhook); err != nil {
+			t.Fatalf("failed to add webhook: %v", err)
+		}
+		if webhook.WebhookID == 0 {
+			t.Fatalf("expected webhook ID to be assigned")
+		}
+	}
+
+	got, err := s.WebhookRepo.GetWebhookByID(ctx, second.WebhookID)
+	if err != nil {
+		t.Fatalf("failed to get webhook: %v", err)
+	}
+	if diff := cmp.Diff(second, *got); diff != "" {
+		t.Errorf("unexpected webhook (-want +got):\n%s", diff)
+	}
+
+	webhooks, err := s.WebhookRepo.GetWebhooksByUserID(ctx, alice.UserID)
+	if err != nil {
+		t.Fatalf("failed to get webhooks: %v", err)
+	}
+	if len(webhooks) != 2 || webhooks[0].WebhookID != first.WebhookID || webhooks[1].WebhookID != second.WebhookID {
+		t.Fatalf("unexpected webhooks: %+v", webhooks)
+	}
+
+	if err := s.WebhookRepo.DeleteWebhook(ctx, first.WebhookID); err != nil {
+		t.Fatalf("failed to delete webhook: %v", err)
+	}
+	if _, err := s.WebhookRepo.GetWebhookByID(ctx, first.WebhookID); !errors.Is(err, gofermartErrors.ErrWebhookNotFound) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrWebhookNotFound, err)
+	}
+	if err := s.WebhookRepo.DeleteWebhook(ctx, first.WebhookID); !errors.Is(err, gofermartErrors.ErrWebhookNotFound) {
+		t.Errorf("expected %v, got %v", gofermartErrors.ErrWebhookNotFound, err)
+	}
+}
+
+func conformanceWebhookDeliveries(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	alice := mustSaveUser(t, s, "alice")
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	webhook := domain.Webhook{UserID: alice.UserID, URL: "https://example.com/hook", Events: domain.WebhookEventTypes, Secret: "secret", CreatedAt: base}
+	if err := s.WebhookRepo.AddWebhook(ctx, &webhook); err != nil {
+		t.Fatalf("failed to add webhook: %v", err)
+	}
+	for i, eventType := range []domain.OutboxEventType{domain.OutboxOrderStatusChanged, domain.OutboxPointsAccrued, domain.OutboxOrderStatusChanged} {
+		delivery := domain.WebhookDelivery{
+			WebhookID:     webhook.WebhookID,
+			EventType:     eventType,
+			Payload:       domain.OutboxPayload(`{"order":"12345678903"}`),
+			Status:        domain.WebhookDeliveryPending,
+			NextAttemptAt: base,
+			CreatedAt:     base.Add(time.Duration(i) * time.Second),
+		}
+		if err := s.WebhookRepo.AddDelivery(ctx, delivery); err != nil {
+			t.Fatalf("failed to add webhook delivery: %v", err)
+		}
+	}
+
+	now := base.Add(time.Minute)
+	claimed, err := s.WebhookRepo.ClaimDeliveries(ctx, now, time.Minute, 2)
+	if err != nil {
+		t.Fatalf("failed to claim webhook deliveries: %v", err)
+	}
+	if len(claimed) != 2 || claimed[0].EventType != domain.OutboxOrderStatusChanged || claimed[1].EventType != domain.OutboxPointsAccrued {
+		t.Fatalf("unexpected claimed deliveries: %+v", claimed)
+	}
+	var payload map[string]string
+	if err := json.Unmarshal(claimed[0].Payload, &payload); err != nil || payload["order"] != "12345678903" {
+		t.Errorf("unexpected payload: %s", claimed[0].Payload)
+	}
+
+	// Выбранные доставки не выдаются повторно, пока не истекла аренда
+	rest, err := s.WebhookRepo.ClaimDeliveries(ctx, now, time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to claim webhook deliveries: %v", err)
+	}
+	if len(rest) != 1 {
+		t.Fatalf("unexpected remaining deliveries: %+v", rest)
+	}
+
+	delivered := claimed[0]
+	delivered.Status = domain.WebhookDeliveryDelivered
+	delivered.Attempts = 1
+	delivered.ResponseStatus = 200
+	delivered.DeliveredAt = &now
+	if err := s.WebhookRepo.UpdateDelivery(ctx, delivered); err != nil {
+		t.Fatalf("failed to update webhook delivery: %v", err)
+	}
+	failed := claimed[1]
+	failed.Status = domain.WebhookDeliveryFailed
+	failed.Attempts = 5
+	failed.ResponseStatus = 500
+	failed.LastError = "unexpected status 500"
+	if err := s.WebhookRepo.UpdateDelivery(ctx, failed); err != nil {
+		t.Fatalf("failed to update webhook delivery: %v", err)
+	}
+
+	// Завершённые доставки больше не выбираются
+	expired, err := s.WebhookRepo.ClaimDeliveries(ctx, now.Add(time.Hour), time.Minute, 10)
+	if err != nil {
+		t.Fatalf("failed to claim webhook deliveries: %v", err)
+	}
+	if len(expired) != 1 || expired[0].DeliveryID != rest[0].DeliveryID {
+		t.Fatalf("unexpected deliveries after lease expiry: %+v", expired)
+	}
+
+	log, err := s.WebhookRepo.GetDeliveries(ctx, webhook.WebhookID, 2)
+	if err != nil {
+		t.Fatalf("failed to get webhook deliveries: %v", err)
+	}
+	if len(log) != 2 || log[0].DeliveryID != rest[0].DeliveryID || log[1].DeliveryID != failed.DeliveryID {
+		t.Fatalf("unexpected delivery log: %+v", log)
+	}
+	if log[1].Status != domain.WebhookDeliveryFailed || log[1].ResponseStatus != 500 || log[1].LastError != failed.LastError {
+		t.Errorf("expected delivery result to be recorded, got %+v", log[1])
+	}
+
+	// Удаление webhook удаляет и журнал его доставок
+	if err := s.WebhookRepo.DeleteWebhook(ctx, webhook.WebhookID); err != nil {
+		t.Fatalf("failed to delete webhook: %v", err)
+	}
+	log, err = s.WebhookRepo.GetDeliveries(ctx, webhook.WebhookID, 0)
+	if err != nil {
+		t.Fatalf("failed to get webhook deliveries: %v", err)
+	}
+	if len(log) != 0 {
+		t.Errorf("expected delivery log to be removed, got %+v", log)
+	}
+}
+
+func conformanceRateLimit(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	policy := domain.RateLimitPolicy{Requests: 2, Period: time.Minute}
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		key      string
+		at       time.Duration
+		expected domain.RateLimitDecision
+	}{
+		{key: "api:user:1", at: 0, expected: domain.RateLimitDecision{Allowed: true, Limit: 2, Remaining: 1, Reset: 30 * time.Second}},
+		{key: "api:user:1", at: 0, expected: domain.RateLimitDecision{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Minute}},
+		{key: "api:user:1", at: 15 * time.Second, expected: domain.RateLimitDecision{Allowed: false, Limit: 2, Remaining: 0, RetryAfter: 15 * time.Second, Reset: 45 * time.Second}},
+		// Другой ключ расходует собственную корзину
+		{key: "api:user:2", at: 15 * time.Second, expected: domain.RateLimitDecision{Allowed: true, Limit: 2, Remaining: 1, Reset: 30 * time.Second}},
+		// Отклонённый запрос разрешение не расходует: через 30 секунд после исчерпания оно восстановилось
+		{key: "api:user:1", at: 30 * time.Second, expected: domain.RateLimitDecision{Allowed: true, Limit: 2, Remaining: 0, Reset: time.Minute}},
+	}
+	for i, step := range steps {
+		decision, err := s.RateLimitRepo.Take(ctx, step.key, policy, base.Add(step.at))
+		if err != nil {
+			t.Fatalf("step %d: failed to take token: %v", i+1, err)
+		}
+		// Время восстановления сравнивается с точностью до миллисекунды из-за вычислений с плавающей точкой
+		decision.RetryAfter = decision.RetryAfter.Round(time.Millisecond)
+		decision.Reset = decision.Reset.Round(time.Millisecond)
+		if diff := cmp.Diff(step.expected, decision); diff != "" {
+			t.Errorf("step %d: unexpected decision (-want +got):\n%s", i+1, diff)
+		}
+	}
+
+	pruned, err := s.RateLimitRepo.Prune(ctx, base.Add(20*time.Second))
+	if err != nil {
+		t.Fatalf("failed to prune buckets: %v", err)
+	}
+	if pruned != 1 {
+		t.Errorf("expected 1 idle bucket to be pruned, got %d", pruned)
+	}
+	// Удалённая корзина снова полна
+	decision, err := s.RateLimitRepo.Take(ctx, "api:user:2", policy, base.Add(20*time.Second))
+	if err != nil {
+		t.Fatalf("failed to take token: %v", err)
+	}
+	if decision.Remaining != 1 {
+		t.Errorf("expected pruned bucket to start full, got %d remaining", decision.Remaining)
+	}
+}
+
+func conformanceOrderUpdates(t *testing.T, s *Storage) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errAbort := errors.New("abort")
+
+	received := make(chan domain.OrderUpdate, 16)
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- s.NotifyRepo.ListenOrderUpdates(ctx, func(update domain.OrderUpdate) {
+			select {
+			case received <- update:
+			default:
+			}
+		})
+	}()
+
+	expected := domain.OrderUpdate{Order: "12345678903", UserID: 1, Status: domain.OrderStatusProcessed, Accrual: decimal.NewFromInt(500)}
+	// LISTEN в PostgreSQL начинает действовать не сразу, поэтому изменения публикуются повторно, пока не будет получено зафиксированное
+	deadline := time.After(5 * time.Second)
+	for delivered := false; !delivered; {
+		err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := s.NotifyRepo.NotifyOrderUpdate(ctx, domain.OrderUpdate{Order: "rolled-back", UserID: 1}); err != nil {
+				return err
+			}
+			return errAbort
+		})
+		if !errors.Is(err, errAbort) {
+			t.Fatalf("expected %v, got %v", errAbort, err)
+		}
+
+		err = s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := s.NotifyRepo.NotifyOrderUpdate(ctx, expected); err != nil {
+				return err
+			}
+			select {
+			case update := <-received:
+				t.Errorf("expected update to be delivered after commit, got %+v before it", update)
+			case <-time.After(10 * time.Millisecond):
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("transaction failed: %v", err)
+		}
+
+		select {
+		case update := <-received:
+			if update.Order != expected.Order || update.UserID != expected.UserID || update.Status != expected.Status || !update.Accrual.Equal(expected.Accrual) {
+				t.Fatalf("expected %+v, got %+v", expected, update)
+			}
+			delivered = true
+		case <-time.After(100 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("order update was not delivered")
+		}
+	}
+
+	cancel()
+	if err := <-listenErr; err != nil {
+		t.Errorf("expected listener to stop without error, got %v", err)
+	}
+}
+
+func conformanceTransactionCommit(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	if err := s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(10)); err != nil {
+		t.Fatalf("failed to update balance: %v", err)
+	}
+
+	err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.WithdrawalRepo.AddWithdrawal(ctx, domain.Withdrawal{OrderNumber: "2377225624", UserID: user.UserID, Amount: decimal.NewFromInt(5), ProcessedAt: time.Now()}); err != nil {
+			return err
+		}
+		return s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(-5))
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+
+	balance, err := s.UserRepo.GetUserBalance(ctx, user.UserID)
+	if err != nil {
+		t.Fatalf("failed to get balance: %v", err)
+	}
+	if balance.Current != 5 || balance.Withdrawn != 5 {
+		t.Errorf("expected committed changes, got %+v", balance)
+	}
+}
+
+func conformanceTransactionRollback(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	errAbort := errors.New("abort")
+
+	err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.OrderRepo.AddOrder(ctx, domain.Order{OrderNumber: "12345678903", UserID: user.UserID, OrderStatus: domain.OrderStatusNew, UploadedAt: time.Now()}); err != nil {
+			return err
+		}
+		if err := s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(100)); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected %v, got %v", errAbort, err)
+	}
+
+	order, err := s.OrderRepo.GetOrderByNumber(ctx, "12345678903")
+	if err != nil || order != nil {
+		t.Errorf("expected order to be rolled back, got %v, %v", order, err)
+	}
+	reloaded, err := s.UserRepo.GetUserByLogin(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if !reloaded.Balance.IsZero() {
+		t.Errorf("expected balance to be rolled back, got %s", reloaded.Balance)
+	}
+}
+
+func conformanceTransactionIsolation(t *testing.T, s *Storage) {
+	outerCtx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+
+	err := s.TxManager.WithinTx(outerCtx, func(ctx context.Context) error {
+		if err := s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(100)); err != nil {
+			return err
+		}
+
+		inside, err := s.UserRepo.GetUserByLogin(ctx, "alice")
+		if err != nil {
+			return err
+		}
+		if !inside.Balance.Equal(decimal.NewFromInt(100)) {
+			t.Errorf("expected transaction to see its own write, got %s", inside.Balance)
+		}
+
+		outside, err := s.UserRepo.GetUserByLogin(outerCtx, "alice")
+		if err != nil {
+			return err
+		}
+		if !outside.Balance.IsZero() {
+			t.Errorf("expected uncommitted write to be invisible, got %s", outside.Balance)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("transaction failed: %v", err)
+	}
+}
+
+func conformanceNestedTransaction(t *testing.T, s *Storage) {
+	ctx := context.Background()
+	user := mustSaveUser(t, s, "alice")
+	errAbort := errors.New("abort")
+
+	err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+			return s.UserRepo.UpdateUserBalance(ctx, user.UserID, decimal.NewFromInt(100))
+		})
+		if err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Fatalf("expected %v, got %v", errAbort, err)
+	}
+
+	reloaded, err := s.UserRepo.GetUserByLogin(ctx, "alice")
+	if err != nil {
+		t.Fatalf("failed to get user: %v", err)
+	}
+	if !reloaded.Balance.IsZero() {
+		t.Errorf("expected nested write to be rolled back with the outer transaction, got %s", reloaded.Balance)
+	}
+}
+
+func conformanceCancelledContext(t *testing.T, s *Storage) {
+	mustSaveUser(t, s, "alice")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := s.UserRepo.GetUserByLogin(ctx, "alice"); !errors.Is(err, context.Canceled) {
+		t.Errorf("GetUserByLogin: expected %v, got %v", context.Canceled, err)
+	}
+	if err := s.UserRepo.SaveUser(ctx, domain.User{Login: "bob", Password: "hash"}); !errors.Is(err, context.Canceled) {
+		t.Errorf("SaveUser: expected %v, got %v", context.Canceled, err)
+	}
+	err := s.TxManager.WithinTx(ctx, func(ctx context.Context) error {
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("WithinTx: expected %v, got %v", context.Canceled, err)
+	}
+}
+
+func TestMemoryStorage_Conformance(t *testing.T) {
+	runConformanceSuite(t, func(t *testing.T) *Storage {
+		return NewMemoryStorage(zap.NewNop())
+	})
+}
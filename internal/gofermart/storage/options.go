@@ -0,0 +1,99 @@
+package storage
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+	gormLogger "gorm.io/gorm/logger"
+)
+
+const (
+	initialConnectBackoff = 500 * time.Millisecond
+	maxConnectBackoff     = 5 * time.Second
+)
+
+// Options — параметры пула соединений и сессии базы данных
+type Options struct {
+	MaxOpenConns     int
+	MaxIdleConns     int
+	ConnMaxLifetime  time.Duration
+	ConnMaxIdleTime  time.Duration
+	StatementTimeout time.Duration
+	LockTimeout      time.Duration
+	PrepareStmt      bool
+	LogLevel         string
+	ConnectTimeout   time.Duration
+
+	// ReplicaDSN — необязательная реплика для чтений вне транзакций
+	ReplicaDSN string
+	// ReplicaStickyWindow — сколько после записи пользователя его чтения идут на primary
+	ReplicaStickyWindow time.Duration
+}
+
+// runtimeParams — параметры сессии PostgreSQL, устанавливаемые для каждого нового соединения
+func (o Options) runtimeParams() map[string]string {
+	params := make(map[string]string)
+	if o.StatementTimeout > 0 {
+		params["statement_timeout"] = strconv.FormatInt(o.StatementTimeout.Milliseconds(), 10)
+	}
+	if o.LockTimeout > 0 {
+		params["lock_timeout"] = strconv.FormatInt(o.LockTimeout.Milliseconds(), 10)
+	}
+	return params
+}
+
+// gormLogLevel — преобразует текстовый уровень логирования в уровень логгера GORM
+func (o Options) gormLogLevel() gormLogger.LogLevel {
+	switch o.LogLevel {
+	case "silent":
+		return gormLogger.Silent
+	case "error":
+		return gormLogger.Error
+	case "info":
+		return gormLogger.Info
+	default:
+		return gormLogger.Warn
+	}
+}
+
+// connectBackoff — задержка перед очередной попыткой подключения (экспоненциальная, с ограничением сверху)
+func connectBackoff(attempt int) time.Duration {
+	delay := initialConnectBackoff
+	for i := 0; i < attempt && delay < maxConnectBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxConnectBackoff {
+		delay = maxConnectBackoff
+	}
+	return delay
+}
+
+// retryWithBackoff — повторяет попытку подключения, пока она не завершится успешно или не истечёт timeout
+func retryWithBackoff(ctx context.Context, timeout time.Duration, connect func(ctx context.Context) error, logger *zap.Logger) error {
+	// Нулевой timeout отключает повторные попытки
+	if timeout <= 0 {
+		return connect(ctx)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	for attempt := 0; ; attempt++ {
+		err := connect(ctx)
+		if err == nil {
+			return nil
+		}
+
+		delay := connectBackoff(attempt)
+		logger.Warn("Database is not ready, retrying",
+			zap.Int("attempt", attempt+1), zap.Duration("retry_in", delay), zap.Error(err))
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"go.uber.org/zap"
+	gormLogger "gorm.io/gorm/logger"
+	"testing"
+	"time"
+)
+
+func TestOptions_RuntimeParams(t *testing.T) {
+	testCases := []struct {
+		name     string
+		options  Options
+		expected map[string]string
+	}{
+		{
+			name:     "Timeouts_Disabled",
+			options:  Options{},
+			expected: map[string]string{},
+		},
+		{
+			name:    "Timeouts_In_Milliseconds",
+			options: Options{StatementTimeout: 5 * time.Second, LockTimeout: 1500 * time.Millisecond},
+			expected: map[string]string{
+				"statement_timeout": "5000",
+				"lock_timeout":      "1500",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, tc.options.runtimeParams()); diff != "" {
+				t.Errorf("Unexpected runtime params (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestOptions_GormLogLevel(t *testing.T) {
+	testCases := map[string]gormLogger.LogLevel{
+		"silent": gormLogger.Silent,
+		"error":  gormLogger.Error,
+		"warn":   gormLogger.Warn,
+		"info":   gormLogger.Info,
+		"":       gormLogger.Warn,
+	}
+
+	for level, expected := range testCases {
+		t.Run("Level_"+level, func(t *testing.T) {
+			if got := (Options{LogLevel: level}).gormLogLevel(); got != expected {
+				t.Errorf("Expected %v, got %v", expected, got)
+			}
+		})
+	}
+}
+
+func TestConnectBackoff(t *testing.T) {
+	expected := []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for attempt, want := range expected {
+		if got := connectBackoff(attempt); got != want {
+			t.Errorf("attempt %d: expected %v, got %v", attempt, want, got)
+		}
+	}
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	errNotReady := errors.New("connection refused")
+
+	t.Run("Succeeds_After_Retry", func(t *testing.T) {
+		attempts := 0
+		err := retryWithBackoff(context.Background(), 5*time.Second, func(ctx context.Context) error {
+			attempts++
+			if attempts < 2 {
+				return errNotReady
+			}
+			return nil
+		}, zap.NewNop())
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if attempts != 2 {
+			t.Errorf("Expected 2 attempts, got %d", attempts)
+		}
+	})
+
+	t.Run("Gives_Up_After_Timeout", func(t *testing.T) {
+		err := retryWithBackoff(context.Background(), 100*time.Millisecond, func(ctx context.Context) error {
+			return errNotReady
+		}, zap.NewNop())
+
+		if !errors.Is(err, errNotReady) {
+			t.Errorf("Expected %v, got %v", errNotReady, err)
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+type AdjustmentRepository interface {
+	AddAdjustment(ctx context.Context, adjustment domain.BalanceAdjustment) error
+	GetAdjustmentsByUserID(ctx context.Context, userID int, options domain.ListOptions) ([]domain.BalanceAdjustment, error)
+}
+
+type AdjustmentRepositoryPostgres struct {
+	*BaseRepository
+}
+
+func NewAdjustmentRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) AdjustmentRepository {
+	return &AdjustmentRepositoryPostgres{
+		BaseRepository: NewBaseRepository(db, reads, logger),
+	}
+}
+
+// AddAdjustment — добавление записи о ручной корректировке баланса
+func (a *AdjustmentRepositoryPostgres) AddAdjustment(ctx context.Context, adjustment domain.BalanceAdjustment) error {
+	a.logger.Info("Adding balance adjustment", zap.Int("userID", adjustment.UserID), zap.String("actor", adjustment.Actor))
+	err := a.getDB(ctx).Create(&adjustment).Error
+	if err != nil {
+		a.logger.Error("Failed to add balance adjustment", zap.Error(err))
+		return err
+	}
+	a.markWritten(ctx, adjustment.UserID)
+	return nil
+}
+
+// GetAdjustmentsByUserID — получение корректировок баланса пользователя с фильтром по периоду и keyset-пагинацией;
+// без параметров возвращаются все корректировки от новых к старым
+func (a *AdjustmentRepositoryPostgres) GetAdjustmentsByUserID(ctx context.Context, userID int, options domain.ListOptions) ([]domain.BalanceAdjustment, error) {
+	var adjustments []domain.BalanceAdjustment
+	query := a.getReadDB(ctx, userID).Where("user_id = ?", userID)
+	if options.After != nil {
+		// Ключ курсора — числовой идентификатор, строковое сравнение дало бы неверный порядок
+		afterID, err := strconv.Atoi(options.After.Key)
+		if err != nil {
+			return nil, gofermartErrors.ErrInvalidCursor
+		}
+		comparison := "<"
+		if options.Ascending {
+			comparison = ">"
+		}
+		query = query.Where("(created_at, adjustment_id) "+comparison+" (?, ?)", options.After.Time, afterID)
+		options.After = nil
+	}
+	err := applyListOptions(query, options, "created_at", "adjustment_id").Find(&adjustments).Error
+	if err != nil {
+		a.logger.Error("Failed to get balance adjustments", zap.Int("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	return adjustments, nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"cmp"
+	"context"
+	"sort"
+	"strconv"
+)
+
+type AdjustmentRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewAdjustmentRepositoryMemory(db *MemoryDB) AdjustmentRepository {
+	return &AdjustmentRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// AddAdjustment — добавление записи о ручной корректировке баланса
+func (a *AdjustmentRepositoryMemory) AddAdjustment(ctx context.Context, adjustment domain.BalanceAdjustment) error {
+	return a.write(ctx, func(state *memoryState) error {
+		adjustment.AdjustmentID = state.nextAdjustmentID
+		state.nextAdjustmentID++
+		state.adjustments[adjustment.AdjustmentID] = adjustment
+		return nil
+	})
+}
+
+// GetAdjustmentsByUserID — получение корректировок баланса пользователя с фильтром по периоду и keyset-пагинацией;
+// без параметров возвращаются все корректировки от новых к старым
+func (a *AdjustmentRepositoryMemory) GetAdjustmentsByUserID(ctx context.Context, userID int, options domain.ListOptions) ([]domain.BalanceAdjustment, error) {
+	afterID := 0
+	if options.After != nil {
+		id, err := strconv.Atoi(options.After.Key)
+		if err != nil {
+			return nil, gofermartErrors.ErrInvalidCursor
+		}
+		afterID = id
+	}
+
+	var adjustments []domain.BalanceAdjustment
+	err := a.read(ctx, func(state *memoryState) error {
+		for _, adjustment := range state.adjustments {
+			if adjustment.UserID != userID || !inPeriod(options, adjustment.CreatedAt) {
+				continue
+			}
+			if options.After != nil && !followsCursor(options, adjustment.CreatedAt, cmp.Compare(adjustment.AdjustmentID, afterID)) {
+				continue
+			}
+			adjustments = append(adjustments, adjustment)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(adjustments, func(i, j int) bool {
+		return lessInOrder(options, adjustments[i].CreatedAt, adjustments[j].CreatedAt, cmp.Compare(adjustments[i].AdjustmentID, adjustments[j].AdjustmentID))
+	})
+	return limitRows(adjustments, options.Limit), nil
+}
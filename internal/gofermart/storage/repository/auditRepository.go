@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"strconv"
+)
+
+// AuditRepository — журнал аудита. Интерфейс намеренно не содержит операций изменения и удаления событий.
+type AuditRepository interface {
+	AddEvent(ctx context.Context, event domain.AuditEvent) error
+	GetEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error)
+}
+
+type AuditRepositoryPostgres struct {
+	*BaseRepository
+}
+
+func NewAuditRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) AuditRepository {
+	return &AuditRepositoryPostgres{
+		BaseRepository: NewBaseRepository(db, reads, logger),
+	}
+}
+
+// AddEvent — добавление события в журнал аудита; внутри транзакции событие фиксируется вместе с изменением данных
+func (a *AuditRepositoryPostgres) AddEvent(ctx context.Context, event domain.AuditEvent) error {
+	err := a.getDB(ctx).Create(&event).Error
+	if err != nil {
+		a.logger.Error("Failed to add audit event", zap.String("type", string(event.Type)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// GetEvents — получение событий журнала аудита с фильтрами и keyset-пагинацией
+func (a *AuditRepositoryPostgres) GetEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	var events []domain.AuditEvent
+	query := a.getAuditReadDB(ctx)
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if filter.Actor != "" {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if filter.Subject != "" {
+		query = query.Where("subject = ?", filter.Subject)
+	}
+	if filter.After != nil {
+		// Ключ курсора событий — числовой идентификатор, строковое сравнение дало бы неверный порядок
+		afterID, err := strconv.Atoi(filter.After.Key)
+		if err != nil {
+			return nil, gofermartErrors.ErrInvalidCursor
+		}
+		comparison := "<"
+		if filter.Ascending {
+			comparison = ">"
+		}
+		query = query.Where("(created_at, event_id) "+comparison+" (?, ?)", filter.After.Time, afterID)
+		filter.After = nil
+	}
+	query = applyListOptions(query, filter.ListOptions, "created_at", "event_id")
+	if err := query.Find(&events).Error; err != nil {
+		a.logger.Error("Failed to get audit events", zap.Error(err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// getAuditReadDB — соединение для чтения журнала: события не относятся к одному пользователю
+func (a *AuditRepositoryPostgres) getAuditReadDB(ctx context.Context) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return a.reads.Any().WithContext(ctx)
+}
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"cmp"
+	"context"
+	"slices"
+	"sort"
+	"strconv"
+)
+
+type AuditRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewAuditRepositoryMemory(db *MemoryDB) AuditRepository {
+	return &AuditRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// AddEvent — добавление события в журнал аудита; внутри транзакции событие фиксируется вместе с изменением данных
+func (a *AuditRepositoryMemory) AddEvent(ctx context.Context, event domain.AuditEvent) error {
+	return a.write(ctx, func(state *memoryState) error {
+		event.EventID = len(state.auditEvents) + 1
+		state.auditEvents = append(state.auditEvents, event)
+		return nil
+	})
+}
+
+// GetEvents — получение событий журнала аудита с фильтрами и keyset-пагинацией
+func (a *AuditRepositoryMemory) GetEvents(ctx context.Context, filter domain.AuditFilter) ([]domain.AuditEvent, error) {
+	afterID := 0
+	if filter.After != nil {
+		id, err := strconv.Atoi(filter.After.Key)
+		if err != nil {
+			return nil, gofermartErrors.ErrInvalidCursor
+		}
+		afterID = id
+	}
+
+	var events []domain.AuditEvent
+	err := a.read(ctx, func(state *memoryState) error {
+		for _, event := range state.auditEvents {
+			if len(filter.Types) > 0 && !slices.Contains(filter.Types, event.Type) {
+				continue
+			}
+			if (filter.Actor != "" && event.Actor != filter.Actor) || (filter.Subject != "" && event.Subject != filter.Subject) {
+				continue
+			}
+			if !inPeriod(filter.ListOptions, event.CreatedAt) {
+				continue
+			}
+			if filter.After != nil && !followsCursor(filter.ListOptions, event.CreatedAt, cmp.Compare(event.EventID, afterID)) {
+				continue
+			}
+			events = append(events, event)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return lessInOrder(filter.ListOptions, events[i].CreatedAt, events[j].CreatedAt, cmp.Compare(events[i].EventID, events[j].EventID))
+	})
+	return limitRows(events, filter.Limit), nil
+}
@@ -1,51 +1,62 @@
 package repository
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
 
 type BaseRepository struct {
 	db     *gorm.DB
+	reads  *ReadRouter
 	logger *zap.Logger
 }
 
-func NewBaseRepository(db *gorm.DB, logger *zap.Logger) *BaseRepository {
+func NewBaseRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) *BaseRepository {
+	if reads == nil {
+		reads = NewReadRouter(db, nil, 0)
+	}
 	return &BaseRepository{
 		db:     db,
+		reads:  reads,
 		logger: logger,
 	}
 }
 
-// Используем транзакцию, если она предоставлена, иначе используем обычное соединение
-func (b *BaseRepository) getDB(tx *gorm.DB) *gorm.DB {
-	if tx != nil {
+// Используем транзакцию из контекста, если она открыта, иначе используем обычное соединение
+func (b *BaseRepository) getDB(ctx context.Context) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
 		return tx
 	}
-	return b.db
+	return b.db.WithContext(ctx)
 }
 
-func (b *BaseRepository) BeginTransaction() (*gorm.DB, error) {
-	tx := b.db.Begin()
-	if tx.Error != nil {
-		b.logger.Error("Failed to begin transaction", zap.Error(tx.Error))
-		return nil, tx.Error
+// Внутри транзакции читаем через неё, иначе маршрутизируем чтение данных пользователя (возможно, на реплику)
+func (b *BaseRepository) getReadDB(ctx context.Context, userID int) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
 	}
-	return tx, nil
+	return b.reads.ForUser(userID).WithContext(ctx)
 }
 
-func (b *BaseRepository) Commit(tx *gorm.DB) error {
-	if err := tx.Commit().Error; err != nil {
-		b.logger.Error("Failed to commit transaction", zap.Error(err))
-		return err
-	}
-	return nil
+// inTx — проверяет, выполняется ли вызов внутри транзакции
+func (b *BaseRepository) inTx(ctx context.Context) bool {
+	_, ok := txFromContext(ctx)
+	return ok
 }
 
-func (b *BaseRepository) Rollback(tx *gorm.DB) error {
-	if err := tx.Rollback().Error; err != nil {
-		b.logger.Error("Failed to rollback transaction", zap.Error(err))
-		return err
-	}
-	return nil
+// bumpUserVersion — увеличивает версию данных пользователя после изменения его баланса, заказов или выводов
+func (b *BaseRepository) bumpUserVersion(ctx context.Context, userID int) error {
+	return b.getDB(ctx).Model(&domain.User{}).Where("user_id = ?", userID).
+		Update("version", gorm.Expr("version + 1")).Error
+}
+
+// markWritten — отмечает запись данных пользователя для гарантии read-your-writes. Внутри транзакции окно чтения
+// с primary открывается только после её фиксации: иначе долгая транзакция съедает окно, а откат открывает его зря.
+func (b *BaseRepository) markWritten(ctx context.Context, userID int) {
+	afterCommit(ctx, func() {
+		b.reads.MarkWritten(userID)
+	})
 }
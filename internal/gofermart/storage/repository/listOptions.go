@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"gorm.io/gorm"
+	"time"
+)
+
+// applyListOptions — добавляет к запросу фильтр по периоду, keyset-условие курсора, порядок и лимит.
+// Записи упорядочены по паре (timeColumn, keyColumn), поэтому следующая страница лежит строго за курсором.
+func applyListOptions(query *gorm.DB, options domain.ListOptions, timeColumn, keyColumn string) *gorm.DB {
+	comparison, direction := "<", "desc"
+	if options.Ascending {
+		comparison, direction = ">", "asc"
+	}
+
+	if !options.From.IsZero() {
+		query = query.Where(timeColumn+" >= ?", options.From)
+	}
+	if !options.To.IsZero() {
+		query = query.Where(timeColumn+" < ?", options.To)
+	}
+	if options.After != nil {
+		query = query.Where("("+timeColumn+", "+keyColumn+") "+comparison+" (?, ?)", options.After.Time, options.After.Key)
+	}
+	query = query.Order(timeColumn + " " + direction + ", " + keyColumn + " " + direction)
+	if options.Limit > 0 {
+		query = query.Limit(options.Limit)
+	}
+	return query
+}
+
+// inPeriod — проверяет попадание времени записи в период выборки (для in-memory хранилища)
+func inPeriod(options domain.ListOptions, t time.Time) bool {
+	if !options.From.IsZero() && t.Before(options.From) {
+		return false
+	}
+	if !options.To.IsZero() && !t.Before(options.To) {
+		return false
+	}
+	return true
+}
+
+// followsCursor — проверяет, что запись с временем t и результатом сравнения ключа с курсором keyCmp
+// лежит за курсором в направлении выдачи (для in-memory хранилища)
+func followsCursor(options domain.ListOptions, t time.Time, keyCmp int) bool {
+	after := options.After
+	if after == nil {
+		return true
+	}
+	if !t.Equal(after.Time) {
+		return t.After(after.Time) == options.Ascending
+	}
+	if options.Ascending {
+		return keyCmp > 0
+	}
+	return keyCmp < 0
+}
+
+// lessInOrder — задает порядок выдачи записей по времени и результату сравнения ключей (для in-memory хранилища)
+func lessInOrder(options domain.ListOptions, ti, tj time.Time, keyCmp int) bool {
+	if !ti.Equal(tj) {
+		return ti.Before(tj) == options.Ascending
+	}
+	if options.Ascending {
+		return keyCmp < 0
+	}
+	return keyCmp > 0
+}
+
+// limitRows — обрезает отсортированную выборку до размера страницы (для in-memory хранилища)
+func limitRows[T any](rows []T, limit int) []T {
+	if limit > 0 && len(rows) > limit {
+		return rows[:limit]
+	}
+	return rows
+}
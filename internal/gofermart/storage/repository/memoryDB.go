@@ -0,0 +1,254 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// memoryState — снимок данных in-memory хранилища
+type memoryState struct {
+	users            map[int]domain.User
+	userIDs          map[string]int
+	orders           map[string]domain.Order
+	withdrawals      map[int]domain.Withdrawal
+	statements       map[statementKey]domain.Statement
+	adjustments      map[int]domain.BalanceAdjustment
+	auditEvents      []domain.AuditEvent // журнал только дополняется, поэтому идентификатор события — его номер
+	outbox           map[int]domain.OutboxMessage
+	webhooks         map[int]domain.Webhook
+	deliveries       map[int]domain.WebhookDelivery
+	nextUserID       int
+	nextWithdrawalID int
+	nextStatementID  int
+	nextAdjustmentID int
+	nextOutboxID     int
+	nextWebhookID    int
+	nextDeliveryID   int
+
+	// orderUpdates — изменения заказов, опубликованные в транзакции; рассылаются подписчикам после её фиксации
+	orderUpdates []domain.OrderUpdate
+}
+
+// statementKey — уникальный ключ выписки: пользователь и месяц
+type statementKey struct {
+	userID int
+	period string
+}
+
+func newMemoryState() *memoryState {
+	return &memoryState{
+		users:            make(map[int]domain.User),
+		userIDs:          make(map[string]int),
+		orders:           make(map[string]domain.Order),
+		withdrawals:      make(map[int]domain.Withdrawal),
+		statements:       make(map[statementKey]domain.Statement),
+		adjustments:      make(map[int]domain.BalanceAdjustment),
+		outbox:           make(map[int]domain.OutboxMessage),
+		webhooks:         make(map[int]domain.Webhook),
+		deliveries:       make(map[int]domain.WebhookDelivery),
+		nextUserID:       1,
+		nextWithdrawalID: 1,
+		nextStatementID:  1,
+		nextAdjustmentID: 1,
+		nextOutboxID:     1,
+		nextWebhookID:    1,
+		nextDeliveryID:   1,
+	}
+}
+
+// clone — создаёт независимую копию данных для транзакции
+func (s *memoryState) clone() *memoryState {
+	c := &memoryState{
+		users:            make(map[int]domain.User, len(s.users)),
+		userIDs:          make(map[string]int, len(s.userIDs)),
+		orders:           make(map[string]domain.Order, len(s.orders)),
+		withdrawals:      make(map[int]domain.Withdrawal, len(s.withdrawals)),
+		statements:       make(map[statementKey]domain.Statement, len(s.statements)),
+		adjustments:      make(map[int]domain.BalanceAdjustment, len(s.adjustments)),
+		outbox:           make(map[int]domain.OutboxMessage, len(s.outbox)),
+		webhooks:         make(map[int]domain.Webhook, len(s.webhooks)),
+		deliveries:       make(map[int]domain.WebhookDelivery, len(s.deliveries)),
+		nextUserID:       s.nextUserID,
+		nextWithdrawalID: s.nextWithdrawalID,
+		nextStatementID:  s.nextStatementID,
+		nextAdjustmentID: s.nextAdjustmentID,
+		nextOutboxID:     s.nextOutboxID,
+		nextWebhookID:    s.nextWebhookID,
+		nextDeliveryID:   s.nextDeliveryID,
+		// Ёмкость ограничена длиной: добавление в транзакции копирует срез и не затрагивает зафиксированные данные
+		auditEvents: s.auditEvents[:len(s.auditEvents):len(s.auditEvents)],
+	}
+	for k, v := range s.users {
+		c.users[k] = v
+	}
+	for k, v := range s.userIDs {
+		c.userIDs[k] = v
+	}
+	for k, v := range s.orders {
+		c.orders[k] = v
+	}
+	for k, v := range s.withdrawals {
+		c.withdrawals[k] = v
+	}
+	for k, v := range s.adjustments {
+		c.adjustments[k] = v
+	}
+	for k, v := range s.outbox {
+		c.outbox[k] = v
+	}
+	// Список событий webhook не изменяется после сохранения, поэтому срез можно разделять
+	for k, v := range s.webhooks {
+		c.webhooks[k] = v
+	}
+	for k, v := range s.deliveries {
+		c.deliveries[k] = v
+	}
+	// Содержимое выписок не изменяется после сохранения, поэтому срезы байтов можно разделять
+	for k, v := range s.statements {
+		c.statements[k] = v
+	}
+	return c
+}
+
+// bumpUserVersion — увеличивает версию данных пользователя после изменения его баланса, заказов или выводов
+func (s *memoryState) bumpUserVersion(userID int) {
+	if user, ok := s.users[userID]; ok {
+		user.Version++
+		s.users[userID] = user
+	}
+}
+
+// memoryTxKey — ключ контекста, под которым хранятся данные открытой транзакции конкретного MemoryDB
+type memoryTxKey struct {
+	db *MemoryDB
+}
+
+// MemoryDB — общее in-memory хранилище репозиториев с поддержкой транзакций.
+// Пишущие операции сериализуются: транзакция работает с собственной копией данных,
+// которая становится видимой остальным только после фиксации и отбрасывается при откате.
+type MemoryDB struct {
+	writer    sync.Mutex
+	mu        sync.RWMutex
+	committed *memoryState
+
+	listenersMu    sync.Mutex
+	listeners      map[int]func(update domain.OrderUpdate)
+	nextListenerID int
+
+	logger *zap.Logger
+}
+
+// NewMemoryDB — создаёт пустое in-memory хранилище
+func NewMemoryDB(logger *zap.Logger) *MemoryDB {
+	return &MemoryDB{
+		committed: newMemoryState(),
+		listeners: make(map[int]func(update domain.OrderUpdate)),
+		logger:    logger,
+	}
+}
+
+// WithinTx — выполняет fn в транзакции над копией данных
+func (m *MemoryDB) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := m.txState(ctx); ok {
+		return fn(ctx)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.writer.Lock()
+	defer m.writer.Unlock()
+
+	m.mu.RLock()
+	state := m.committed.clone()
+	m.mu.RUnlock()
+
+	if err := fn(context.WithValue(ctx, memoryTxKey{db: m}, state)); err != nil {
+		return err
+	}
+	// Транзакция могла быть отменена, пока выполнялась fn: в этом случае изменения не фиксируются
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	m.committed = state
+	updates := state.orderUpdates
+	state.orderUpdates = nil
+	m.mu.Unlock()
+
+	m.notifyOrderUpdates(updates)
+	return nil
+}
+
+// txState — возвращает данные открытой транзакции из контекста
+func (m *MemoryDB) txState(ctx context.Context) (*memoryState, bool) {
+	state, ok := ctx.Value(memoryTxKey{db: m}).(*memoryState)
+	return state, ok
+}
+
+// read — выполняет чтение в рамках транзакции или над зафиксированными данными
+func (m *MemoryDB) read(ctx context.Context, fn func(state *memoryState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if state, ok := m.txState(ctx); ok {
+		return fn(state)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return fn(m.committed)
+}
+
+// write — выполняет изменение в рамках транзакции или атомарно над зафиксированными данными.
+// fn должна проверять условия до изменения данных, чтобы ошибка не оставляла частичных изменений.
+func (m *MemoryDB) write(ctx context.Context, fn func(state *memoryState) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if state, ok := m.txState(ctx); ok {
+		return fn(state)
+	}
+
+	m.writer.Lock()
+	defer m.writer.Unlock()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return fn(m.committed)
+}
+
+// addOrderUpdateListener — регистрирует получателя изменений заказов и возвращает его идентификатор
+func (m *MemoryDB) addOrderUpdateListener(handler func(update domain.OrderUpdate)) int {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	id := m.nextListenerID
+	m.nextListenerID++
+	m.listeners[id] = handler
+	return id
+}
+
+// removeOrderUpdateListener — отменяет регистрацию получателя изменений заказов
+func (m *MemoryDB) removeOrderUpdateListener(id int) {
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	delete(m.listeners, id)
+}
+
+// notifyOrderUpdates — передаёт зафиксированные изменения заказов всем получателям, как это делает NOTIFY в PostgreSQL
+func (m *MemoryDB) notifyOrderUpdates(updates []domain.OrderUpdate) {
+	if len(updates) == 0 {
+		return
+	}
+	m.listenersMu.Lock()
+	defer m.listenersMu.Unlock()
+	for _, update := range updates {
+		for _, handler := range m.listeners {
+			handler(update)
+		}
+	}
+}
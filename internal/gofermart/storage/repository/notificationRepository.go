@@ -0,0 +1,86 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// OrderUpdatesChannel — канал PostgreSQL LISTEN/NOTIFY, по которому экземпляры приложения обмениваются изменениями заказов
+const OrderUpdatesChannel = "order_updates"
+
+// NotificationRepository — рассылка изменений заказов всем экземплярам приложения
+type NotificationRepository interface {
+	// NotifyOrderUpdate — публикует изменение заказа; внутри транзакции оно доставляется только после фиксации
+	NotifyOrderUpdate(ctx context.Context, update domain.OrderUpdate) error
+	// ListenOrderUpdates — передаёт handler изменения заказов до отмены контекста или потери соединения
+	ListenOrderUpdates(ctx context.Context, handler func(update domain.OrderUpdate)) error
+}
+
+type NotificationRepositoryPostgres struct {
+	*BaseRepository
+	dsn string
+}
+
+// NewNotificationRepository — dsn используется для отдельного соединения LISTEN, которое не возвращается в пул
+func NewNotificationRepository(db *gorm.DB, dsn string, logger *zap.Logger) NotificationRepository {
+	return &NotificationRepositoryPostgres{
+		BaseRepository: NewBaseRepository(db, nil, logger),
+		dsn:            dsn,
+	}
+}
+
+// NotifyOrderUpdate — публикует изменение заказа через pg_notify; PostgreSQL доставляет уведомление при фиксации транзакции
+func (n *NotificationRepositoryPostgres) NotifyOrderUpdate(ctx context.Context, update domain.OrderUpdate) error {
+	payload, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	err = n.getDB(ctx).Exec("SELECT pg_notify(?, ?)", OrderUpdatesChannel, string(payload)).Error
+	if err != nil {
+		n.logger.Error("Failed to notify order update", zap.String("order", update.Order), zap.Error(err))
+	}
+	return err
+}
+
+// ListenOrderUpdates — подписывается на канал изменений заказов через отдельное соединение.
+// Возвращает nil при отмене контекста и ошибку при потере соединения; переподключение — забота вызывающего.
+func (n *NotificationRepositoryPostgres) ListenOrderUpdates(ctx context.Context, handler func(update domain.OrderUpdate)) error {
+	conn, err := pgx.Connect(ctx, n.dsn)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+	defer func() {
+		_ = conn.Close(context.Background())
+	}()
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{OrderUpdatesChannel}.Sanitize()); err != nil {
+		if ctx.Err() != nil {
+			return nil
+		}
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		var update domain.OrderUpdate
+		if err := json.Unmarshal([]byte(notification.Payload), &update); err != nil {
+			n.logger.Warn("Skipping malformed order update notification", zap.String("payload", notification.Payload), zap.Error(err))
+			continue
+		}
+		handler(update)
+	}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+)
+
+type NotificationRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewNotificationRepositoryMemory(db *MemoryDB) NotificationRepository {
+	return &NotificationRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// NotifyOrderUpdate — публикует изменение заказа; внутри транзакции оно откладывается до её фиксации
+func (n *NotificationRepositoryMemory) NotifyOrderUpdate(ctx context.Context, update domain.OrderUpdate) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if state, ok := n.txState(ctx); ok {
+		state.orderUpdates = append(state.orderUpdates, update)
+		return nil
+	}
+	n.notifyOrderUpdates([]domain.OrderUpdate{update})
+	return nil
+}
+
+// ListenOrderUpdates — передаёт handler изменения заказов до отмены контекста
+func (n *NotificationRepositoryMemory) ListenOrderUpdates(ctx context.Context, handler func(update domain.OrderUpdate)) error {
+	id := n.addOrderUpdateListener(handler)
+	defer n.removeOrderUpdateListener(id)
+
+	<-ctx.Done()
+	return nil
+}
@@ -3,43 +3,48 @@ package repository
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
 	"errors"
 	"github.com/jackc/pgx/v5/pgconn"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
+	"strings"
 )
 
 type OrderRepository interface {
-	AddOrder(tx *gorm.DB, order domain.Order) error
-	GetOrderByNumber(tx *gorm.DB, number string) (*domain.Order, error)
-	GetOrdersByUserID(tx *gorm.DB, userID int) ([]domain.Order, error)
-	GetOrdersForProcessing(tx *gorm.DB) ([]domain.Order, error)
-	LockOrderForProcessing(tx *gorm.DB, orderNumber string) error
-	UnlockOrder(tx *gorm.DB, orderNumber string) error
-	UpdateOrder(tx *gorm.DB, order domain.Order) error
-
-	BeginTransaction() (*gorm.DB, error)
-	Commit(tx *gorm.DB) error
-	Rollback(tx *gorm.DB) error
+	AddOrder(ctx context.Context, order domain.Order) error
+	AddOrders(ctx context.Context, orders []domain.Order) ([]string, error)
+	GetOrderByNumber(ctx context.Context, number string) (*domain.Order, error)
+	GetOrdersByNumbers(ctx context.Context, numbers []string) ([]domain.Order, error)
+	GetOrdersByUserID(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, error)
+	GetOrdersForProcessing(ctx context.Context) ([]domain.Order, error)
+	LockOrderForProcessing(ctx context.Context, orderNumber string) error
+	UnlockOrder(ctx context.Context, orderNumber string) error
+	UpdateOrder(ctx context.Context, order domain.Order) error
 }
 
 type OrderRepositoryPostgres struct {
 	*BaseRepository
 }
 
-func NewOrderRepository(db *gorm.DB, logger *zap.Logger) OrderRepository {
+func NewOrderRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) OrderRepository {
 	return &OrderRepositoryPostgres{
-		BaseRepository: NewBaseRepository(db, logger),
+		BaseRepository: NewBaseRepository(db, reads, logger),
 	}
 }
 
 // AddOrder — добавление нового заказа
-func (o *OrderRepositoryPostgres) AddOrder(tx *gorm.DB, order domain.Order) error {
+func (o *OrderRepositoryPostgres) AddOrder(ctx context.Context, order domain.Order) error {
 	o.logger.Info("Adding new order", zap.String("order_number", order.OrderNumber), zap.Int("userID", order.UserID))
 
-	// Использование OnConflict для игнорирования дублирующихся заказов
-	err := o.getDB(tx).Clauses(clause.OnConflict{DoNothing: true}).Create(&order).Error
+	// Использование OnConflict, чтобы дубликат не прерывал транзакцию; о конфликте сообщает RowsAffected
+	result := o.getDB(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(&order)
+	err := result.Error
+	if err == nil && result.RowsAffected == 0 {
+		o.logger.Warn("Order number already exists", zap.String("order_number", order.OrderNumber))
+		return gofermartErrors.ErrOrderAlreadyExists
+	}
 	if err != nil {
 		var pgErr *pgconn.PgError
 		// Проверка, является ли ошибка ошибкой Postgres
@@ -52,16 +57,84 @@ func (o *OrderRepositoryPostgres) AddOrder(tx *gorm.DB, order domain.Order) erro
 		o.logger.Error("Failed to add order", zap.Error(err))
 		return err
 	}
+	if err := o.bumpUserVersion(ctx, order.UserID); err != nil {
+		o.logger.Error("Failed to bump user version", zap.Error(err))
+		return err
+	}
 
+	o.markWritten(ctx, order.UserID)
 	o.logger.Info("Order added successfully", zap.String("order_number", order.OrderNumber))
 	return nil
 }
 
+// ordersInsertChunk — сколько заказов вставляется одним запросом: у каждого 5 параметров, а PostgreSQL принимает
+// не больше 65535 параметров в запросе
+const ordersInsertChunk = 1000
+
+// AddOrders — пакетное добавление заказов запросами по ordersInsertChunk строк в одной транзакции. Уже существующие
+// номера пропускаются (ON CONFLICT DO NOTHING), возвращаются номера фактически добавленных заказов.
+func (o *OrderRepositoryPostgres) AddOrders(ctx context.Context, orders []domain.Order) ([]string, error) {
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	o.logger.Info("Adding orders batch", zap.Int("count", len(orders)))
+
+	var inserted []string
+	err := o.getDB(ctx).Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(orders); start += ordersInsertChunk {
+			chunk := orders[start:min(start+ordersInsertChunk, len(orders))]
+			placeholders := make([]string, 0, len(chunk))
+			args := make([]interface{}, 0, len(chunk)*5)
+			for _, order := range chunk {
+				placeholders = append(placeholders, "(?, ?, ?, ?, ?)")
+				args = append(args, order.OrderNumber, order.UserID, order.OrderStatus, order.Accrual, order.UploadedAt)
+			}
+
+			var chunkInserted []string
+			err := tx.Raw("INSERT INTO orders (order_number, user_id, order_status, accrual, uploaded_at) VALUES "+
+				strings.Join(placeholders, ", ")+" ON CONFLICT (order_number) DO NOTHING RETURNING order_number", args...).
+				Scan(&chunkInserted).Error
+			if err != nil {
+				return err
+			}
+			inserted = append(inserted, chunkInserted...)
+		}
+		return nil
+	})
+	if err != nil {
+		o.logger.Error("Failed to add orders batch", zap.Error(err))
+		return nil, err
+	}
+
+	// Версия увеличивается только у пользователей, чьи заказы действительно добавлены
+	added := make(map[string]struct{}, len(inserted))
+	for _, number := range inserted {
+		added[number] = struct{}{}
+	}
+	bumped := make(map[int]struct{})
+	for _, order := range orders {
+		o.markWritten(ctx, order.UserID)
+		if _, ok := added[order.OrderNumber]; !ok {
+			continue
+		}
+		if _, ok := bumped[order.UserID]; ok {
+			continue
+		}
+		bumped[order.UserID] = struct{}{}
+		if err := o.bumpUserVersion(ctx, order.UserID); err != nil {
+			o.logger.Error("Failed to bump user version", zap.Error(err))
+			return nil, err
+		}
+	}
+	o.logger.Info("Orders batch added", zap.Int("requested", len(orders)), zap.Int("inserted", len(inserted)))
+	return inserted, nil
+}
+
 // GetOrderByNumber — получение заказа по номеру
-func (o *OrderRepositoryPostgres) GetOrderByNumber(tx *gorm.DB, number string) (*domain.Order, error) {
+func (o *OrderRepositoryPostgres) GetOrderByNumber(ctx context.Context, number string) (*domain.Order, error) {
 	o.logger.Info("Getting order by number", zap.String("order_number", number))
 	var order domain.Order
-	err := o.getDB(tx).Where("order_number = ?", number).First(&order).Error
+	err := o.getDB(ctx).Where("order_number = ?", number).First(&order).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			o.logger.Warn("Order not found", zap.String("order_number", number))
@@ -74,11 +147,30 @@ func (o *OrderRepositoryPostgres) GetOrderByNumber(tx *gorm.DB, number string) (
 	return &order, nil
 }
 
-// GetOrdersByUserID — получение списка заказов пользователя
-func (o *OrderRepositoryPostgres) GetOrdersByUserID(tx *gorm.DB, userID int) ([]domain.Order, error) {
+// GetOrdersByNumbers — получение заказов по списку номеров; отсутствующие номера пропускаются
+func (o *OrderRepositoryPostgres) GetOrdersByNumbers(ctx context.Context, numbers []string) ([]domain.Order, error) {
+	if len(numbers) == 0 {
+		return nil, nil
+	}
+	var orders []domain.Order
+	err := o.getDB(ctx).Where("order_number IN ?", numbers).Find(&orders).Error
+	if err != nil {
+		o.logger.Error("Failed to get orders by numbers", zap.Error(err))
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetOrdersByUserID — получение списка заказов пользователя с фильтрами и keyset-пагинацией
+func (o *OrderRepositoryPostgres) GetOrdersByUserID(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, error) {
 	o.logger.Info("Getting orders for user", zap.Int("userID", userID))
 	var orders []domain.Order
-	err := o.getDB(tx).Where("user_id = ?", userID).Order("uploaded_at desc").Find(&orders).Error
+	query := o.getReadDB(ctx, userID).Where("user_id = ?", userID)
+	if len(filter.Statuses) > 0 {
+		query = query.Where("order_status IN ?", filter.Statuses)
+	}
+	query = applyListOptions(query, filter.ListOptions, "uploaded_at", "order_number")
+	err := query.Find(&orders).Error
 	if err != nil {
 		o.logger.Error("Failed to get orders", zap.Error(err))
 		return nil, err
@@ -88,10 +180,10 @@ func (o *OrderRepositoryPostgres) GetOrdersByUserID(tx *gorm.DB, userID int) ([]
 }
 
 // GetOrdersForProcessing — получение заказов для обработки
-func (o *OrderRepositoryPostgres) GetOrdersForProcessing(tx *gorm.DB) ([]domain.Order, error) {
+func (o *OrderRepositoryPostgres) GetOrdersForProcessing(ctx context.Context) ([]domain.Order, error) {
 	var orders []domain.Order
 	// Получение заказов со статусом для обработки
-	err := o.getDB(tx).Where("order_status IN ? AND is_processing = ?", []string{
+	err := o.getDB(ctx).Where("order_status IN ? AND is_processing = ?", []string{
 		domain.OrderStatusNew, domain.OrderStatusRegistered, domain.OrderStatusProcessing}, false).
 		Clauses(clause.Locking{Strength: "UPDATE"}).
 		Find(&orders).Error
@@ -103,8 +195,8 @@ func (o *OrderRepositoryPostgres) GetOrdersForProcessing(tx *gorm.DB) ([]domain.
 }
 
 // LockOrderForProcessing — блокировка заказа для обработки
-func (o *OrderRepositoryPostgres) LockOrderForProcessing(tx *gorm.DB, orderNumber string) error {
-	err := o.getDB(tx).Model(&domain.Order{}).
+func (o *OrderRepositoryPostgres) LockOrderForProcessing(ctx context.Context, orderNumber string) error {
+	err := o.getDB(ctx).Model(&domain.Order{}).
 		Where("order_number = ?", orderNumber).
 		Update("is_processing", true).Error
 	if err != nil {
@@ -114,8 +206,8 @@ func (o *OrderRepositoryPostgres) LockOrderForProcessing(tx *gorm.DB, orderNumbe
 }
 
 // UnlockOrder — разблокировка заказа
-func (o *OrderRepositoryPostgres) UnlockOrder(tx *gorm.DB, orderNumber string) error {
-	err := o.getDB(tx).Model(&domain.Order{}).
+func (o *OrderRepositoryPostgres) UnlockOrder(ctx context.Context, orderNumber string) error {
+	err := o.getDB(ctx).Model(&domain.Order{}).
 		Where("order_number = ?", orderNumber).
 		Update("is_processing", false).Error
 	if err != nil {
@@ -125,9 +217,9 @@ func (o *OrderRepositoryPostgres) UnlockOrder(tx *gorm.DB, orderNumber string) e
 }
 
 // UpdateOrder — обновление данных о заказе
-func (o *OrderRepositoryPostgres) UpdateOrder(tx *gorm.DB, order domain.Order) error {
+func (o *OrderRepositoryPostgres) UpdateOrder(ctx context.Context, order domain.Order) error {
 	o.logger.Info("Updating order", zap.String("order_number", order.OrderNumber))
-	err := o.getDB(tx).Model(&domain.Order{}).Where("order_number = ?", order.OrderNumber).Updates(domain.Order{
+	err := o.getDB(ctx).Model(&domain.Order{}).Where("order_number = ?", order.OrderNumber).Updates(domain.Order{
 		OrderStatus: order.OrderStatus,
 		Accrual:     order.Accrual,
 	}).Error
@@ -135,6 +227,15 @@ func (o *OrderRepositoryPostgres) UpdateOrder(tx *gorm.DB, order domain.Order) e
 		o.logger.Error("Failed to update order", zap.Error(err))
 		return err
 	}
+	// Владелец заказа определяется по номеру: вызывающий код может не заполнять UserID
+	err = o.getDB(ctx).Model(&domain.User{}).
+		Where("user_id = (?)", o.getDB(ctx).Model(&domain.Order{}).Select("user_id").Where("order_number = ?", order.OrderNumber)).
+		Update("version", gorm.Expr("version + 1")).Error
+	if err != nil {
+		o.logger.Error("Failed to bump user version", zap.Error(err))
+		return err
+	}
+	o.markWritten(ctx, order.UserID)
 	o.logger.Info("Order updated successfully", zap.String("order_number", order.OrderNumber))
 	return nil
 }
@@ -0,0 +1,178 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type OrderRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewOrderRepositoryMemory(db *MemoryDB) OrderRepository {
+	return &OrderRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// AddOrder — добавление нового заказа
+func (o *OrderRepositoryMemory) AddOrder(ctx context.Context, order domain.Order) error {
+	return o.write(ctx, func(state *memoryState) error {
+		if _, exists := state.orders[order.OrderNumber]; exists {
+			return gofermartErrors.ErrOrderAlreadyExists
+		}
+		state.orders[order.OrderNumber] = order
+		state.bumpUserVersion(order.UserID)
+		return nil
+	})
+}
+
+// AddOrders — пакетное добавление заказов; уже существующие номера пропускаются, возвращаются номера добавленных
+func (o *OrderRepositoryMemory) AddOrders(ctx context.Context, orders []domain.Order) ([]string, error) {
+	var inserted []string
+	err := o.write(ctx, func(state *memoryState) error {
+		for _, order := range orders {
+			if _, exists := state.orders[order.OrderNumber]; exists {
+				continue
+			}
+			state.orders[order.OrderNumber] = order
+			state.bumpUserVersion(order.UserID)
+			inserted = append(inserted, order.OrderNumber)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inserted, nil
+}
+
+// GetOrderByNumber — получение заказа по номеру
+func (o *OrderRepositoryMemory) GetOrderByNumber(ctx context.Context, number string) (*domain.Order, error) {
+	var result *domain.Order
+	err := o.read(ctx, func(state *memoryState) error {
+		if order, ok := state.orders[number]; ok {
+			result = &order
+		}
+		return nil
+	})
+	return result, err
+}
+
+// GetOrdersByNumbers — получение заказов по списку номеров; отсутствующие номера пропускаются
+func (o *OrderRepositoryMemory) GetOrdersByNumbers(ctx context.Context, numbers []string) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := o.read(ctx, func(state *memoryState) error {
+		for _, number := range numbers {
+			if order, ok := state.orders[number]; ok {
+				orders = append(orders, order)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetOrdersByUserID — получение списка заказов пользователя с фильтрами и keyset-пагинацией
+func (o *OrderRepositoryMemory) GetOrdersByUserID(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := o.read(ctx, func(state *memoryState) error {
+		for _, order := range state.orders {
+			if order.UserID == userID && orderMatches(order, filter) {
+				orders = append(orders, order)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(orders, func(i, j int) bool {
+		return lessInOrder(filter.ListOptions, orders[i].UploadedAt, orders[j].UploadedAt, strings.Compare(orders[i].OrderNumber, orders[j].OrderNumber))
+	})
+	return limitRows(orders, filter.Limit), nil
+}
+
+// GetOrdersForProcessing — получение заказов для обработки
+func (o *OrderRepositoryMemory) GetOrdersForProcessing(ctx context.Context) ([]domain.Order, error) {
+	var orders []domain.Order
+	err := o.read(ctx, func(state *memoryState) error {
+		for _, order := range state.orders {
+			if order.IsProcessing {
+				continue
+			}
+			switch order.OrderStatus {
+			case domain.OrderStatusNew, domain.OrderStatusRegistered, domain.OrderStatusProcessing:
+				orders = append(orders, order)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return orders, nil
+}
+
+// LockOrderForProcessing — блокировка заказа для обработки
+func (o *OrderRepositoryMemory) LockOrderForProcessing(ctx context.Context, orderNumber string) error {
+	return o.setProcessing(ctx, orderNumber, true)
+}
+
+// UnlockOrder — разблокировка заказа
+func (o *OrderRepositoryMemory) UnlockOrder(ctx context.Context, orderNumber string) error {
+	return o.setProcessing(ctx, orderNumber, false)
+}
+
+// UpdateOrder — обновление данных о заказе (как и в GORM Updates, нулевые значения не перезаписываются)
+func (o *OrderRepositoryMemory) UpdateOrder(ctx context.Context, order domain.Order) error {
+	return o.write(ctx, func(state *memoryState) error {
+		existing, ok := state.orders[order.OrderNumber]
+		if !ok {
+			return nil
+		}
+		if order.OrderStatus != "" {
+			existing.OrderStatus = order.OrderStatus
+		}
+		if !order.Accrual.IsZero() {
+			existing.Accrual = order.Accrual
+		}
+		state.orders[order.OrderNumber] = existing
+		state.bumpUserVersion(existing.UserID)
+		return nil
+	})
+}
+
+// orderMatches — проверяет соответствие заказа фильтру и его позицию после курсора
+func orderMatches(order domain.Order, filter domain.OrderFilter) bool {
+	if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, order.OrderStatus) {
+		return false
+	}
+	if !inPeriod(filter.ListOptions, order.UploadedAt) {
+		return false
+	}
+	if filter.After != nil {
+		return followsCursor(filter.ListOptions, order.UploadedAt, strings.Compare(order.OrderNumber, filter.After.Key))
+	}
+	return true
+}
+
+func (o *OrderRepositoryMemory) setProcessing(ctx context.Context, orderNumber string, processing bool) error {
+	return o.write(ctx, func(state *memoryState) error {
+		order, ok := state.orders[orderNumber]
+		if !ok {
+			return nil
+		}
+		order.IsProcessing = processing
+		state.orders[orderNumber] = order
+		return nil
+	})
+}
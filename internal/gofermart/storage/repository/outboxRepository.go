@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+// OutboxRepository — таблица outbox с событиями для внешних потребителей
+type OutboxRepository interface {
+	AddMessage(ctx context.Context, message domain.OutboxMessage) error
+	ClaimMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error)
+	MarkDelivered(ctx context.Context, messageID int, deliveredAt time.Time) error
+	MarkFailed(ctx context.Context, messageID int, nextAttemptAt time.Time, lastError string) error
+	MarkDead(ctx context.Context, messageID int, failedAt time.Time, lastError string) error
+}
+
+type OutboxRepositoryPostgres struct {
+	*BaseRepository
+}
+
+func NewOutboxRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) OutboxRepository {
+	return &OutboxRepositoryPostgres{
+		BaseRepository: NewBaseRepository(db, reads, logger),
+	}
+}
+
+// AddMessage — добавление события в outbox; внутри транзакции событие фиксируется вместе с изменением данных
+func (o *OutboxRepositoryPostgres) AddMessage(ctx context.Context, message domain.OutboxMessage) error {
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = message.CreatedAt
+	}
+	err := o.getDB(ctx).Create(&message).Error
+	if err != nil {
+		o.logger.Error("Failed to add outbox message", zap.String("type", string(message.Type)), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ClaimMessages — выбор недоставленных событий, срок доставки которых наступил, в порядке добавления.
+// По каждому ключу выдаётся только самое раннее недоставленное событие: следующее событие с тем же ключом
+// станет доступно после его доставки или после того, как оно исчерпает попытки, поэтому повторная попытка
+// не обгоняется более поздними событиями.
+// Выбранные события откладываются на время аренды, чтобы их не взял другой экземпляр приложения;
+// если доставка не будет подтверждена, по истечении аренды событие будет доставлено повторно.
+func (o *OutboxRepositoryPostgres) ClaimMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+	err := o.getDB(ctx).Raw(`UPDATE outbox SET next_attempt_at = ?
+		WHERE message_id IN (
+			SELECT message_id FROM outbox AS pending
+			WHERE delivered_at IS NULL AND failed_at IS NULL AND next_attempt_at <= ?
+				AND NOT EXISTS (
+					SELECT 1 FROM outbox AS earlier
+					WHERE earlier.key = pending.key AND earlier.delivered_at IS NULL AND earlier.failed_at IS NULL
+						AND earlier.message_id < pending.message_id
+				)
+			ORDER BY message_id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), now, limit).Scan(&messages).Error
+	if err != nil {
+		o.logger.Error("Failed to claim outbox messages", zap.Error(err))
+		return nil, err
+	}
+	// RETURNING не гарантирует порядок строк
+	sortOutboxMessages(messages)
+	return messages, nil
+}
+
+// MarkDelivered — отметка о доставке события
+func (o *OutboxRepositoryPostgres) MarkDelivered(ctx context.Context, messageID int, deliveredAt time.Time) error {
+	err := o.getDB(ctx).Model(&domain.OutboxMessage{}).
+		Where("message_id = ?", messageID).
+		Updates(map[string]any{"delivered_at": deliveredAt, "last_error": ""}).Error
+	if err != nil {
+		o.logger.Error("Failed to mark outbox message delivered", zap.Int("messageID", messageID), zap.Error(err))
+	}
+	return err
+}
+
+// MarkFailed — учёт неудачной попытки доставки и перенос следующей попытки
+func (o *OutboxRepositoryPostgres) MarkFailed(ctx context.Context, messageID int, nextAttemptAt time.Time, lastError string) error {
+	err := o.getDB(ctx).Model(&domain.OutboxMessage{}).
+		Where("message_id = ?", messageID).
+		Updates(map[string]any{
+			"attempts":        gorm.Expr("attempts + 1"),
+			"next_attempt_at": nextAttemptAt,
+			"last_error":      lastError,
+		}).Error
+	if err != nil {
+		o.logger.Error("Failed to mark outbox message failed", zap.Int("messageID", messageID), zap.Error(err))
+	}
+	return err
+}
+
+// MarkDead — учёт последней неудачной попытки: событие больше не доставляется и не задерживает события своего ключа
+func (o *OutboxRepositoryPostgres) MarkDead(ctx context.Context, messageID int, failedAt time.Time, lastError string) error {
+	err := o.getDB(ctx).Model(&domain.OutboxMessage{}).
+		Where("message_id = ?", messageID).
+		Updates(map[string]any{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"failed_at":  failedAt,
+			"last_error": lastError,
+		}).Error
+	if err != nil {
+		o.logger.Error("Failed to mark outbox message dead", zap.Int("messageID", messageID), zap.Error(err))
+	}
+	return err
+}
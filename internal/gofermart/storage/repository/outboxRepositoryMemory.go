@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"sort"
+	"time"
+)
+
+type OutboxRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewOutboxRepositoryMemory(db *MemoryDB) OutboxRepository {
+	return &OutboxRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// AddMessage — добавление события в outbox; внутри транзакции событие фиксируется вместе с изменением данных
+func (o *OutboxRepositoryMemory) AddMessage(ctx context.Context, message domain.OutboxMessage) error {
+	return o.write(ctx, func(state *memoryState) error {
+		message.MessageID = state.nextOutboxID
+		state.nextOutboxID++
+		if message.NextAttemptAt.IsZero() {
+			message.NextAttemptAt = message.CreatedAt
+		}
+		state.outbox[message.MessageID] = message
+		return nil
+	})
+}
+
+// ClaimMessages — выбор недоставленных событий, срок доставки которых наступил, с откладыванием на время аренды;
+// по каждому ключу выдаётся только самое раннее недоставленное событие, не исчерпавшее попытки
+func (o *OutboxRepositoryMemory) ClaimMessages(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.OutboxMessage, error) {
+	var messages []domain.OutboxMessage
+	err := o.write(ctx, func(state *memoryState) error {
+		var pending []domain.OutboxMessage
+		for _, message := range state.outbox {
+			if message.DeliveredAt == nil && message.FailedAt == nil {
+				pending = append(pending, message)
+			}
+		}
+		sortOutboxMessages(pending)
+		heads := make(map[string]struct{}, len(pending))
+		for _, message := range pending {
+			if _, ok := heads[message.Key]; ok {
+				continue
+			}
+			heads[message.Key] = struct{}{}
+			if !message.NextAttemptAt.After(now) {
+				messages = append(messages, message)
+			}
+		}
+		messages = limitRows(messages, limit)
+		for i := range messages {
+			messages[i].NextAttemptAt = now.Add(lease)
+			state.outbox[messages[i].MessageID] = messages[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MarkDelivered — отметка о доставке события
+func (o *OutboxRepositoryMemory) MarkDelivered(ctx context.Context, messageID int, deliveredAt time.Time) error {
+	return o.write(ctx, func(state *memoryState) error {
+		message, ok := state.outbox[messageID]
+		if !ok {
+			return nil
+		}
+		message.DeliveredAt = &deliveredAt
+		message.LastError = ""
+		state.outbox[messageID] = message
+		return nil
+	})
+}
+
+// MarkFailed — учёт неудачной попытки доставки и перенос следующей попытки
+func (o *OutboxRepositoryMemory) MarkFailed(ctx context.Context, messageID int, nextAttemptAt time.Time, lastError string) error {
+	return o.write(ctx, func(state *memoryState) error {
+		message, ok := state.outbox[messageID]
+		if !ok {
+			return nil
+		}
+		message.Attempts++
+		message.NextAttemptAt = nextAttemptAt
+		message.LastError = lastError
+		state.outbox[messageID] = message
+		return nil
+	})
+}
+
+// MarkDead — учёт последней неудачной попытки: событие больше не доставляется и не задерживает события своего ключа
+func (o *OutboxRepositoryMemory) MarkDead(ctx context.Context, messageID int, failedAt time.Time, lastError string) error {
+	return o.write(ctx, func(state *memoryState) error {
+		message, ok := state.outbox[messageID]
+		if !ok {
+			return nil
+		}
+		message.Attempts++
+		message.FailedAt = &failedAt
+		message.LastError = lastError
+		state.outbox[messageID] = message
+		return nil
+	})
+}
+
+// sortOutboxMessages — упорядочивает события в порядке добавления
+func sortOutboxMessages(messages []domain.OutboxMessage) {
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].MessageID < messages[j].MessageID
+	})
+}
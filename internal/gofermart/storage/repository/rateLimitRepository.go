@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+// RateLimitRepository — корзины разрешений ограничения частоты запросов
+type RateLimitRepository interface {
+	Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitDecision, error)
+	Prune(ctx context.Context, before time.Time) (int64, error)
+}
+
+type RateLimitRepositoryPostgres struct {
+	*BaseRepository
+}
+
+func NewRateLimitRepository(db *gorm.DB, logger *zap.Logger) RateLimitRepository {
+	return &RateLimitRepositoryPostgres{
+		BaseRepository: NewBaseRepository(db, nil, logger),
+	}
+}
+
+// rateLimitRefillSQL — количество разрешений в корзине после восстановления за время, прошедшее с прошлого запроса
+const rateLimitRefillSQL = `LEAST(CAST(@requests AS double precision),
+	b.tokens + GREATEST(CAST(EXTRACT(EPOCH FROM EXCLUDED.updated_at - b.updated_at) AS double precision), 0) * @rate)`
+
+// rateLimitTakeSQL — расходует разрешение одним запросом, поэтому несколько экземпляров приложения
+// делят одну корзину без гонок; отклонённый запрос разрешение не расходует
+const rateLimitTakeSQL = `INSERT INTO rate_limit_buckets AS b (key, tokens, allowed, updated_at)
+VALUES (@key, @requests - 1, true, @now)
+ON CONFLICT (key) DO UPDATE SET
+	tokens = CASE WHEN ` + rateLimitRefillSQL + ` >= 1 THEN ` + rateLimitRefillSQL + ` - 1 ELSE ` + rateLimitRefillSQL + ` END,
+	allowed = ` + rateLimitRefillSQL + ` >= 1,
+	updated_at = EXCLUDED.updated_at
+RETURNING key, tokens, allowed, updated_at`
+
+// Take — расходует разрешение на запрос по ключу и возвращает результат проверки
+func (l *RateLimitRepositoryPostgres) Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitDecision, error) {
+	var bucket domain.RateLimitBucket
+	err := l.getDB(ctx).Raw(rateLimitTakeSQL, map[string]any{
+		"key":      key,
+		"requests": policy.Requests,
+		"rate":     policy.Rate(),
+		"now":      now,
+	}).Scan(&bucket).Error
+	if err != nil {
+		l.logger.Error("Failed to take rate limit token", zap.String("key", key), zap.Error(err))
+		return domain.RateLimitDecision{}, err
+	}
+	return policy.Decision(bucket), nil
+}
+
+// Prune — удаляет корзины, к которым не обращались с before: к этому времени они заполнены и равнозначны отсутствующим
+func (l *RateLimitRepositoryPostgres) Prune(ctx context.Context, before time.Time) (int64, error) {
+	result := l.getDB(ctx).Where("updated_at < ?", before).Delete(&domain.RateLimitBucket{})
+	if result.Error != nil {
+		l.logger.Error("Failed to prune rate limit buckets", zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
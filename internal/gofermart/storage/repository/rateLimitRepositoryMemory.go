@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitRepositoryMemory — корзины разрешений в памяти процесса. Не участвует в транзакциях хранилища
+// и не делится с другими экземплярами приложения, поэтому подходит и как локальный ограничитель при PostgreSQL.
+type RateLimitRepositoryMemory struct {
+	mu      sync.Mutex
+	buckets map[string]domain.RateLimitBucket
+}
+
+func NewRateLimitRepositoryMemory() RateLimitRepository {
+	return &RateLimitRepositoryMemory{
+		buckets: make(map[string]domain.RateLimitBucket),
+	}
+}
+
+// Take — расходует разрешение на запрос по ключу и возвращает результат проверки
+func (l *RateLimitRepositoryMemory) Take(ctx context.Context, key string, policy domain.RateLimitPolicy, now time.Time) (domain.RateLimitDecision, error) {
+	if err := ctx.Err(); err != nil {
+		return domain.RateLimitDecision{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	bucket := policy.Take(l.buckets[key], now)
+	bucket.Key = key
+	l.buckets[key] = bucket
+	return policy.Decision(bucket), nil
+}
+
+// Prune — удаляет корзины, к которым не обращались с before
+func (l *RateLimitRepositoryMemory) Prune(ctx context.Context, before time.Time) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var pruned int64
+	for key, bucket := range l.buckets {
+		if bucket.UpdatedAt.Before(before) {
+			delete(l.buckets, key)
+			pruned++
+		}
+	}
+	return pruned, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReadRouter — выбирает соединение для чтения вне транзакции.
+// Если настроена реплика, чтения направляются на неё, за исключением пользователей,
+// недавно выполнивших запись: для них в течение stickyWindow используется primary (read-your-writes).
+// Окна хранятся в памяти процесса: при нескольких экземплярах сервиса read-your-writes гарантируется только
+// для запросов, попавших на тот же экземпляр, что и запись.
+type ReadRouter struct {
+	primary      *gorm.DB
+	replica      *gorm.DB
+	stickyWindow time.Duration
+
+	mu     sync.Mutex
+	sticky map[int]time.Time
+	now    func() time.Time
+}
+
+// NewReadRouter — создаёт маршрутизатор чтений; replica может быть nil, тогда все чтения идут на primary
+func NewReadRouter(primary, replica *gorm.DB, stickyWindow time.Duration) *ReadRouter {
+	return &ReadRouter{
+		primary:      primary,
+		replica:      replica,
+		stickyWindow: stickyWindow,
+		sticky:       make(map[int]time.Time),
+		now:          time.Now,
+	}
+}
+
+// HasReplica — сообщает, настроена ли реплика
+func (r *ReadRouter) HasReplica() bool {
+	return r.replica != nil
+}
+
+// ForUser — возвращает соединение для чтения данных указанного пользователя
+func (r *ReadRouter) ForUser(userID int) *gorm.DB {
+	if r.replica == nil || r.IsSticky(userID) {
+		return r.primary
+	}
+	return r.replica
+}
+
+// Any — возвращает соединение для чтения, когда пользователь заранее неизвестен
+func (r *ReadRouter) Any() *gorm.DB {
+	if r.replica == nil {
+		return r.primary
+	}
+	return r.replica
+}
+
+// Primary — возвращает соединение с primary
+func (r *ReadRouter) Primary() *gorm.DB {
+	return r.primary
+}
+
+// MarkWritten — фиксирует запись данных пользователя, открывая окно чтения с primary
+func (r *ReadRouter) MarkWritten(userID int) {
+	if r.replica == nil || userID == 0 {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.sticky[userID] = now.Add(r.stickyWindow)
+
+	// Попутно удаляем истёкшие окна, чтобы карта не росла бесконечно
+	for id, until := range r.sticky {
+		if !now.Before(until) {
+			delete(r.sticky, id)
+		}
+	}
+}
+
+// IsSticky — проверяет, действует ли для пользователя окно чтения с primary
+func (r *ReadRouter) IsSticky(userID int) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	until, ok := r.sticky[userID]
+	return ok && r.now().Before(until)
+}
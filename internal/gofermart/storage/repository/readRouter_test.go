@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+func TestReadRouter(t *testing.T) {
+	primary := &gorm.DB{}
+	replica := &gorm.DB{}
+	now := time.Date(2024, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name     string
+		replica  *gorm.DB
+		setup    func(r *ReadRouter)
+		expected *gorm.DB
+	}{
+		{
+			name:     "Without_Replica_Reads_From_Primary",
+			replica:  nil,
+			setup:    func(r *ReadRouter) {},
+			expected: primary,
+		},
+		{
+			name:     "With_Replica_Reads_From_Replica",
+			replica:  replica,
+			setup:    func(r *ReadRouter) {},
+			expected: replica,
+		},
+		{
+			name:    "Recent_Write_Reads_From_Primary",
+			replica: replica,
+			setup: func(r *ReadRouter) {
+				r.MarkWritten(1)
+			},
+			expected: primary,
+		},
+		{
+			name:    "Write_Of_Other_User_Reads_From_Replica",
+			replica: replica,
+			setup: func(r *ReadRouter) {
+				r.MarkWritten(2)
+			},
+			expected: replica,
+		},
+		{
+			name:    "Expired_Window_Reads_From_Replica",
+			replica: replica,
+			setup: func(r *ReadRouter) {
+				r.MarkWritten(1)
+				r.now = func() time.Time { return now.Add(10 * time.Second) }
+			},
+			expected: replica,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := NewReadRouter(primary, tc.replica, 5*time.Second)
+			router.now = func() time.Time { return now }
+
+			tc.setup(router)
+
+			if got := router.ForUser(1); got != tc.expected {
+				t.Errorf("Expected %p, got %p", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestReadRouter_MarkWritten_PrunesExpiredWindows(t *testing.T) {
+	router := NewReadRouter(&gorm.DB{}, &gorm.DB{}, time.Second)
+	now := time.Now()
+	router.now = func() time.Time { return now }
+
+	router.MarkWritten(1)
+	now = now.Add(2 * time.Second)
+	router.MarkWritten(2)
+
+	if len(router.sticky) != 1 {
+		t.Errorf("Expected 1 sticky user, got %d", len(router.sticky))
+	}
+}
+
+func TestBaseRepository_MarkWritten(t *testing.T) {
+	testCases := []struct {
+		name           string
+		inTx           bool
+		committed      bool
+		expectedSticky bool
+	}{
+		{name: "Outside_Transaction", expectedSticky: true},
+		{name: "Open_Transaction", inTx: true},
+		{name: "Committed_Transaction", inTx: true, committed: true, expectedSticky: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			router := NewReadRouter(&gorm.DB{}, &gorm.DB{}, time.Minute)
+			base := NewBaseRepository(&gorm.DB{}, router, zap.NewNop())
+
+			ctx := context.Background()
+			state := &txState{db: &gorm.DB{}}
+			if tc.inTx {
+				ctx = context.WithValue(ctx, txKey{}, state)
+			}
+			base.markWritten(ctx, 1)
+			// Фиксация транзакции: TxManager выполняет отложенные действия после успешного Commit
+			if tc.committed {
+				for _, action := range state.afterCommit {
+					action()
+				}
+			}
+
+			if sticky := router.IsSticky(1); sticky != tc.expectedSticky {
+				t.Errorf("Expected sticky %v, got %v", tc.expectedSticky, sticky)
+			}
+		})
+	}
+}
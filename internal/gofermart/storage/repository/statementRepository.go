@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StatementRepository interface {
+	GetStatement(ctx context.Context, userID int, period string) (*domain.Statement, error)
+	SaveStatement(ctx context.Context, statement domain.Statement) error
+}
+
+type StatementRepositoryPostgres struct {
+	*BaseRepository
+}
+
+func NewStatementRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) StatementRepository {
+	return &StatementRepositoryPostgres{
+		BaseRepository: NewBaseRepository(db, reads, logger),
+	}
+}
+
+// GetStatement — получение выписки пользователя за месяц
+func (s *StatementRepositoryPostgres) GetStatement(ctx context.Context, userID int, period string) (*domain.Statement, error) {
+	var statement domain.Statement
+	err := s.getReadDB(ctx, userID).Where("user_id = ? AND period = ?", userID, period).First(&statement).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, gofermartErrors.ErrStatementNotFound
+		}
+		s.logger.Error("Failed to get statement", zap.Int("userID", userID), zap.String("period", period), zap.Error(err))
+		return nil, err
+	}
+	return &statement, nil
+}
+
+// SaveStatement — сохранение выписки; повторное формирование за тот же месяц заменяет содержимое
+func (s *StatementRepositoryPostgres) SaveStatement(ctx context.Context, statement domain.Statement) error {
+	s.logger.Info("Saving statement", zap.Int("userID", statement.UserID), zap.String("period", statement.Period))
+	err := s.getDB(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "period"}},
+		DoUpdates: clause.AssignmentColumns([]string{"content", "created_at"}),
+	}).Create(&statement).Error
+	if err != nil {
+		s.logger.Error("Failed to save statement", zap.Error(err))
+		return err
+	}
+	s.markWritten(ctx, statement.UserID)
+	return nil
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+)
+
+type StatementRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewStatementRepositoryMemory(db *MemoryDB) StatementRepository {
+	return &StatementRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// GetStatement — получение выписки пользователя за месяц
+func (s *StatementRepositoryMemory) GetStatement(ctx context.Context, userID int, period string) (*domain.Statement, error) {
+	var result *domain.Statement
+	err := s.read(ctx, func(state *memoryState) error {
+		statement, ok := state.statements[statementKey{userID: userID, period: period}]
+		if !ok {
+			return gofermartErrors.ErrStatementNotFound
+		}
+		result = &statement
+		return nil
+	})
+	return result, err
+}
+
+// SaveStatement — сохранение выписки; повторное формирование за тот же месяц заменяет содержимое
+func (s *StatementRepositoryMemory) SaveStatement(ctx context.Context, statement domain.Statement) error {
+	return s.write(ctx, func(state *memoryState) error {
+		key := statementKey{userID: statement.UserID, period: statement.Period}
+		if existing, ok := state.statements[key]; ok {
+			statement.StatementID = existing.StatementID
+		} else {
+			statement.StatementID = state.nextStatementID
+			state.nextStatementID++
+		}
+		state.statements[key] = statement
+		return nil
+	})
+}
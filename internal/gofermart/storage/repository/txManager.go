@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// TxManager — управляет транзакциями хранилища. Транзакция передаётся репозиториям через контекст,
+// поэтому вызовы разных репозиториев внутри fn выполняются атомарно.
+type TxManager interface {
+	// WithinTx — выполняет fn в транзакции: фиксирует её при успехе и откатывает при ошибке или панике.
+	// Вложенный вызов присоединяется к уже открытой транзакции.
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// txKey — ключ контекста, под которым хранится открытая транзакция GORM
+type txKey struct{}
+
+// txState — открытая транзакция GORM и действия, которые выполняются только после её фиксации
+type txState struct {
+	db          *gorm.DB
+	afterCommit []func()
+}
+
+// txFromContext — возвращает транзакцию GORM из контекста, если она открыта
+func txFromContext(ctx context.Context) (*gorm.DB, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return state.db, true
+}
+
+// afterCommit — выполняет fn после фиксации открытой транзакции или сразу, если транзакции нет.
+// При откате транзакции fn не выполняется.
+func afterCommit(ctx context.Context, fn func()) {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok {
+		state.afterCommit = append(state.afterCommit, fn)
+		return
+	}
+	fn()
+}
+
+type TxManagerPostgres struct {
+	db     *gorm.DB
+	logger *zap.Logger
+}
+
+func NewTxManager(db *gorm.DB, logger *zap.Logger) TxManager {
+	return &TxManagerPostgres{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// WithinTx — выполняет fn в транзакции PostgreSQL
+func (m *TxManagerPostgres) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	state := &txState{}
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		state.db = tx
+		return fn(context.WithValue(ctx, txKey{}, state))
+	})
+	if err != nil {
+		return err
+	}
+	for _, action := range state.afterCommit {
+		action()
+	}
+	return nil
+}
@@ -3,61 +3,118 @@ package repository
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
 	"errors"
-	"github.com/lib/pq"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/shopspring/decimal"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
-	GetUserBalance(tx *gorm.DB, login string) (*domain.UserBalance, error)
-	GetUserByLogin(tx *gorm.DB, login string) (*domain.User, error)
-	SaveUser(tx *gorm.DB, user domain.User) error
-	UpdateUserBalance(tx *gorm.DB, userID int, amount decimal.Decimal) error
-
-	BeginTransaction() (*gorm.DB, error)
-	Commit(tx *gorm.DB) error
-	Rollback(tx *gorm.DB) error
+	GetUserBalance(ctx context.Context, userID int) (*domain.UserBalance, error)
+	GetUserByID(ctx context.Context, userID int) (*domain.User, error)
+	GetUserByLogin(ctx context.Context, login string) (*domain.User, error)
+	GetUserForUpdate(ctx context.Context, userID int) (*domain.User, error)
+	GetUserVersion(ctx context.Context, userID int) (int64, error)
+	ListUsers(ctx context.Context, afterID int, limit int) ([]domain.User, error)
+	SaveUser(ctx context.Context, user domain.User) error
+	SetUserBlocked(ctx context.Context, userID int, blocked bool) error
+	SetUserRoles(ctx context.Context, userID int, roles domain.Roles) error
+	UpdateUserBalance(ctx context.Context, userID int, amount decimal.Decimal) error
 }
 
 type UserRepositoryPostgres struct {
 	*BaseRepository
 }
 
-func NewUserRepository(db *gorm.DB, logger *zap.Logger) UserRepository {
+func NewUserRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) UserRepository {
 	return &UserRepositoryPostgres{
-		BaseRepository: NewBaseRepository(db, logger),
+		BaseRepository: NewBaseRepository(db, reads, logger),
 	}
 }
 
 // GetUserBalance — получение баланса и общей суммы выводов пользователя
-func (u *UserRepositoryPostgres) GetUserBalance(tx *gorm.DB, login string) (userBalance *domain.UserBalance, err error) {
+func (u *UserRepositoryPostgres) GetUserBalance(ctx context.Context, userID int) (*domain.UserBalance, error) {
 	var result *domain.UserBalance
 	// Получение баланса пользователя и общей суммы выводов через Join
-	err = u.getDB(tx).Table("users").
+	err := u.getReadDB(ctx, userID).Table("users").
 		Select("users.balance AS current, COALESCE(SUM(withdrawals.amount), 0) AS withdrawn").
 		Joins("LEFT JOIN withdrawals ON users.user_id = withdrawals.user_id").
-		Where("users.login = ?", login).
-		Group("users.balance").
+		Where("users.user_id = ?", userID).
+		Group("users.user_id, users.balance").
 		Scan(&result).Error
+	if err != nil {
+		u.logger.Error("Failed to get user balance", zap.Error(err))
+		return nil, err
+	}
 
+	if result == nil {
+		return nil, gofermartErrors.ErrUserNotFound
+	}
+
+	return result, nil
+}
+
+// GetUserByID — получение пользователя по идентификатору
+func (u *UserRepositoryPostgres) GetUserByID(ctx context.Context, userID int) (*domain.User, error) {
+	var user domain.User
+	err := u.getReadDB(ctx, userID).Where("user_id = ?", userID).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
+			u.logger.Warn("User not found", zap.Int("userID", userID))
 			return nil, gofermartErrors.ErrUserNotFound
 		}
-		u.logger.Error("Failed to get user balance", zap.Error(err))
+		u.logger.Error("Failed to get user by id", zap.Error(err))
 		return nil, err
 	}
+	return &user, nil
+}
 
-	return result, nil
+// GetUserForUpdate — получение пользователя с основного сервера с блокировкой строки до конца транзакции;
+// используется перед проверкой и изменением баланса, чтобы параллельные списания не прошли проверку одновременно
+func (u *UserRepositoryPostgres) GetUserForUpdate(ctx context.Context, userID int) (*domain.User, error) {
+	var user domain.User
+	err := u.getDB(ctx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&user).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			u.logger.Warn("User not found", zap.Int("userID", userID))
+			return nil, gofermartErrors.ErrUserNotFound
+		}
+		u.logger.Error("Failed to lock user", zap.Error(err))
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserVersion — получение версии данных пользователя без чтения его заказов и выводов
+func (u *UserRepositoryPostgres) GetUserVersion(ctx context.Context, userID int) (int64, error) {
+	var versions []int64
+	err := u.getReadDB(ctx, userID).Model(&domain.User{}).Where("user_id = ?", userID).Limit(1).Pluck("version", &versions).Error
+	if err != nil {
+		u.logger.Error("Failed to get user version", zap.Error(err))
+		return 0, err
+	}
+	if len(versions) == 0 {
+		return 0, gofermartErrors.ErrUserNotFound
+	}
+	return versions[0], nil
 }
 
 // GetUserByLogin — получение пользователя по логину
-func (u *UserRepositoryPostgres) GetUserByLogin(tx *gorm.DB, login string) (*domain.User, error) {
+func (u *UserRepositoryPostgres) GetUserByLogin(ctx context.Context, login string) (*domain.User, error) {
 	u.logger.Info("Getting user by login", zap.String("login", login))
 	var user domain.User
-	err := u.getDB(tx).Where("login = ?", login).First(&user).Error
+	err := u.getLoginReadDB(ctx).Where("login = ?", login).First(&user).Error
+
+	// Реплика может отставать: перечитываем с primary, если пользователь ещё не реплицирован или недавно менял данные
+	if !u.inTx(ctx) && u.reads.HasReplica() &&
+		(errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && u.reads.IsSticky(user.UserID))) {
+		user = domain.User{}
+		err = u.reads.Primary().WithContext(ctx).Where("login = ?", login).First(&user).Error
+	}
+
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			u.logger.Warn("User not found", zap.String("login", login))
@@ -70,12 +127,23 @@ func (u *UserRepositoryPostgres) GetUserByLogin(tx *gorm.DB, login string) (*dom
 	return &user, nil
 }
 
+// ListUsers — постраничное получение пользователей в порядке идентификаторов, начиная после afterID
+func (u *UserRepositoryPostgres) ListUsers(ctx context.Context, afterID int, limit int) ([]domain.User, error) {
+	var users []domain.User
+	err := u.getDB(ctx).Where("user_id > ?", afterID).Order("user_id").Limit(limit).Find(&users).Error
+	if err != nil {
+		u.logger.Error("Failed to list users", zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
 // SaveUser — сохранение нового пользователя
-func (u *UserRepositoryPostgres) SaveUser(tx *gorm.DB, user domain.User) error {
+func (u *UserRepositoryPostgres) SaveUser(ctx context.Context, user domain.User) error {
 	u.logger.Info("Saving new user", zap.String("login", user.Login))
-	err := u.getDB(tx).Create(&user).Error
+	err := u.getDB(ctx).Create(&user).Error
 	if err != nil {
-		var pgErr *pq.Error
+		var pgErr *pgconn.PgError
 		// Проверка на ошибку уникальности (уникальный логин)
 		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
 			u.logger.Warn("Login already exists", zap.String("login", user.Login))
@@ -88,15 +156,62 @@ func (u *UserRepositoryPostgres) SaveUser(tx *gorm.DB, user domain.User) error {
 	return nil
 }
 
+// SetUserBlocked — блокировка или разблокировка пользователя
+func (u *UserRepositoryPostgres) SetUserBlocked(ctx context.Context, userID int, blocked bool) error {
+	u.logger.Info("Setting user blocked flag", zap.Int("userID", userID), zap.Bool("blocked", blocked))
+	result := u.getDB(ctx).Model(&domain.User{}).Where("user_id = ?", userID).Update("blocked", blocked)
+	if result.Error != nil {
+		u.logger.Error("Failed to set user blocked flag", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gofermartErrors.ErrUserNotFound
+	}
+	u.markWritten(ctx, userID)
+	return nil
+}
+
+// SetUserRoles — замена набора ролей пользователя
+func (u *UserRepositoryPostgres) SetUserRoles(ctx context.Context, userID int, roles domain.Roles) error {
+	u.logger.Info("Setting user roles", zap.Int("userID", userID), zap.Strings("roles", roles.Strings()))
+	result := u.getDB(ctx).Model(&domain.User{}).Where("user_id = ?", userID).Update("roles", roles)
+	if result.Error != nil {
+		u.logger.Error("Failed to set user roles", zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gofermartErrors.ErrUserNotFound
+	}
+	u.markWritten(ctx, userID)
+	return nil
+}
+
 // UpdateUserBalance — обновление баланса пользователя
-func (u *UserRepositoryPostgres) UpdateUserBalance(tx *gorm.DB, userID int, amount decimal.Decimal) error {
+func (u *UserRepositoryPostgres) UpdateUserBalance(ctx context.Context, userID int, amount decimal.Decimal) error {
 	u.logger.Info("Updating user balance", zap.Int("userID", userID), zap.String("amount", amount.String()))
-	// Увеличение баланса пользователя
-	err := u.getDB(tx).Model(&domain.User{}).Where("user_id = ?", userID).Update("balance", gorm.Expr("balance + ?", amount)).Error
+	// Увеличение баланса и версии данных пользователя
+	err := u.getDB(ctx).Model(&domain.User{}).Where("user_id = ?", userID).Updates(map[string]interface{}{
+		"balance": gorm.Expr("balance + ?", amount),
+		"version": gorm.Expr("version + 1"),
+	}).Error
 	if err != nil {
+		// Ограничение chk_users_balance_non_negative не даёт балансу стать отрицательным
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23514" {
+			return gofermartErrors.ErrInsufficientFunds
+		}
 		u.logger.Error("Failed to update user balance", zap.Error(err))
 		return err
 	}
+	u.markWritten(ctx, userID)
 	u.logger.Info("User balance updated successfully", zap.Int("userID", userID))
 	return nil
 }
+
+// getLoginReadDB — соединение для чтения по логину, когда идентификатор пользователя ещё неизвестен
+func (u *UserRepositoryPostgres) getLoginReadDB(ctx context.Context) *gorm.DB {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return u.reads.Any().WithContext(ctx)
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"github.com/shopspring/decimal"
+	"sort"
+)
+
+type UserRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewUserRepositoryMemory(db *MemoryDB) UserRepository {
+	return &UserRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// GetUserBalance — получение баланса и общей суммы выводов пользователя
+func (u *UserRepositoryMemory) GetUserBalance(ctx context.Context, userID int) (*domain.UserBalance, error) {
+	var result *domain.UserBalance
+	err := u.read(ctx, func(state *memoryState) error {
+		if _, ok := state.users[userID]; !ok {
+			return gofermartErrors.ErrUserNotFound
+		}
+
+		withdrawn := decimal.Zero
+		for _, w := range state.withdrawals {
+			if w.UserID == userID {
+				withdrawn = withdrawn.Add(w.Amount)
+			}
+		}
+
+		current, _ := state.users[userID].Balance.Float64()
+		withdrawnFloat, _ := withdrawn.Float64()
+		result = &domain.UserBalance{Current: current, Withdrawn: withdrawnFloat}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetUserByID — получение пользователя по идентификатору
+func (u *UserRepositoryMemory) GetUserByID(ctx context.Context, userID int) (*domain.User, error) {
+	var user domain.User
+	err := u.read(ctx, func(state *memoryState) error {
+		stored, ok := state.users[userID]
+		if !ok {
+			return gofermartErrors.ErrUserNotFound
+		}
+		user = stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetUserForUpdate — получение пользователя для изменения баланса; транзакции MemoryDB сериализуются,
+// поэтому отдельная блокировка не нужна
+func (u *UserRepositoryMemory) GetUserForUpdate(ctx context.Context, userID int) (*domain.User, error) {
+	return u.GetUserByID(ctx, userID)
+}
+
+// GetUserVersion — получение версии данных пользователя
+func (u *UserRepositoryMemory) GetUserVersion(ctx context.Context, userID int) (int64, error) {
+	var version int64
+	err := u.read(ctx, func(state *memoryState) error {
+		user, ok := state.users[userID]
+		if !ok {
+			return gofermartErrors.ErrUserNotFound
+		}
+		version = user.Version
+		return nil
+	})
+	return version, err
+}
+
+// GetUserByLogin — получение пользователя по логину
+func (u *UserRepositoryMemory) GetUserByLogin(ctx context.Context, login string) (*domain.User, error) {
+	var user domain.User
+	err := u.read(ctx, func(state *memoryState) error {
+		userID, ok := state.userIDs[login]
+		if !ok {
+			return gofermartErrors.ErrUserNotFound
+		}
+		user = state.users[userID]
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// ListUsers — постраничное получение пользователей в порядке идентификаторов, начиная после afterID
+func (u *UserRepositoryMemory) ListUsers(ctx context.Context, afterID int, limit int) ([]domain.User, error) {
+	var users []domain.User
+	err := u.read(ctx, func(state *memoryState) error {
+		for id, user := range state.users {
+			if id > afterID {
+				users = append(users, user)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].UserID < users[j].UserID
+	})
+	return limitRows(users, limit), nil
+}
+
+// SaveUser — сохранение нового пользователя
+func (u *UserRepositoryMemory) SaveUser(ctx context.Context, user domain.User) error {
+	return u.write(ctx, func(state *memoryState) error {
+		if _, exists := state.userIDs[user.Login]; exists {
+			return gofermartErrors.ErrLoginAlreadyExists
+		}
+		user.UserID = state.nextUserID
+		state.nextUserID++
+		state.users[user.UserID] = user
+		state.userIDs[user.Login] = user.UserID
+		return nil
+	})
+}
+
+// SetUserBlocked — блокировка или разблокировка пользователя
+func (u *UserRepositoryMemory) SetUserBlocked(ctx context.Context, userID int, blocked bool) error {
+	return u.write(ctx, func(state *memoryState) error {
+		user, ok := state.users[userID]
+		if !ok {
+			return gofermartErrors.ErrUserNotFound
+		}
+		user.Blocked = blocked
+		state.users[userID] = user
+		return nil
+	})
+}
+
+// SetUserRoles — замена набора ролей пользователя
+func (u *UserRepositoryMemory) SetUserRoles(ctx context.Context, userID int, roles domain.Roles) error {
+	return u.write(ctx, func(state *memoryState) error {
+		user, ok := state.users[userID]
+		if !ok {
+			return gofermartErrors.ErrUserNotFound
+		}
+		// Срез копируется: сохранённые пользователи не должны разделять его с вызывающим кодом
+		user.Roles = append(domain.Roles{}, roles...)
+		state.users[userID] = user
+		return nil
+	})
+}
+
+// UpdateUserBalance — обновление баланса пользователя
+func (u *UserRepositoryMemory) UpdateUserBalance(ctx context.Context, userID int, amount decimal.Decimal) error {
+	return u.write(ctx, func(state *memoryState) error {
+		user, ok := state.users[userID]
+		if !ok {
+			return nil
+		}
+		// Как и ограничение в PostgreSQL, баланс не может стать отрицательным
+		if user.Balance.Add(amount).IsNegative() {
+			return gofermartErrors.ErrInsufficientFunds
+		}
+		user.Balance = user.Balance.Add(amount)
+		user.Version++
+		state.users[userID] = user
+		return nil
+	})
+}
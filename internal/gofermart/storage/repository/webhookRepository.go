@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"time"
+)
+
+// WebhookRepository — webhook пользователей и очередь их доставок
+type WebhookRepository interface {
+	AddWebhook(ctx context.Context, webhook *domain.Webhook) error
+	GetWebhookByID(ctx context.Context, webhookID int) (*domain.Webhook, error)
+	GetWebhooksByUserID(ctx context.Context, userID int) ([]domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, webhookID int) error
+	AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error)
+	UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error
+	GetDeliveries(ctx context.Context, webhookID int, limit int) ([]domain.WebhookDelivery, error)
+}
+
+type WebhookRepositoryPostgres struct {
+	*BaseRepository
+}
+
+func NewWebhookRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) WebhookRepository {
+	return &WebhookRepositoryPostgres{
+		BaseRepository: NewBaseRepository(db, reads, logger),
+	}
+}
+
+// AddWebhook — добавление webhook; идентификатор записывается в переданную структуру
+func (w *WebhookRepositoryPostgres) AddWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	err := w.getDB(ctx).Create(webhook).Error
+	if err != nil {
+		w.logger.Error("Failed to add webhook", zap.Int("userID", webhook.UserID), zap.Error(err))
+		return err
+	}
+	w.markWritten(ctx, webhook.UserID)
+	return nil
+}
+
+// GetWebhookByID — получение webhook по идентификатору
+func (w *WebhookRepositoryPostgres) GetWebhookByID(ctx context.Context, webhookID int) (*domain.Webhook, error) {
+	var webhook domain.Webhook
+	err := w.getDB(ctx).Where("webhook_id = ?", webhookID).First(&webhook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, gofermartErrors.ErrWebhookNotFound
+	}
+	if err != nil {
+		w.logger.Error("Failed to get webhook", zap.Int("webhookID", webhookID), zap.Error(err))
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// GetWebhooksByUserID — получение webhook пользователя в порядке создания
+func (w *WebhookRepositoryPostgres) GetWebhooksByUserID(ctx context.Context, userID int) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := w.getReadDB(ctx, userID).Where("user_id = ?", userID).Order("webhook_id").Find(&webhooks).Error
+	if err != nil {
+		w.logger.Error("Failed to get webhooks", zap.Int("userID", userID), zap.Error(err))
+		return nil, err
+	}
+	return webhooks, nil
+}
+
+// DeleteWebhook — удаление webhook вместе с журналом его доставок
+func (w *WebhookRepositoryPostgres) DeleteWebhook(ctx context.Context, webhookID int) error {
+	db := w.getDB(ctx)
+	if err := db.Where("webhook_id = ?", webhookID).Delete(&domain.WebhookDelivery{}).Error; err != nil {
+		w.logger.Error("Failed to delete webhook deliveries", zap.Int("webhookID", webhookID), zap.Error(err))
+		return err
+	}
+	result := db.Where("webhook_id = ?", webhookID).Delete(&domain.Webhook{})
+	if result.Error != nil {
+		w.logger.Error("Failed to delete webhook", zap.Int("webhookID", webhookID), zap.Error(result.Error))
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gofermartErrors.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// AddDelivery — постановка события в очередь доставки webhook
+func (w *WebhookRepositoryPostgres) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	err := w.getDB(ctx).Create(&delivery).Error
+	if err != nil {
+		w.logger.Error("Failed to add webhook delivery", zap.Int("webhookID", delivery.WebhookID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ClaimDeliveries — выбор ожидающих доставок, срок которых наступил, с откладыванием на время аренды,
+// чтобы их не взял другой экземпляр приложения
+func (w *WebhookRepositoryPostgres) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := w.getDB(ctx).Raw(`UPDATE webhook_deliveries SET next_attempt_at = ?
+		WHERE delivery_id IN (
+			SELECT delivery_id FROM webhook_deliveries
+			WHERE status = ? AND next_attempt_at <= ?
+			ORDER BY delivery_id
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`, now.Add(lease), domain.WebhookDeliveryPending, now, limit).Scan(&deliveries).Error
+	if err != nil {
+		w.logger.Error("Failed to claim webhook deliveries", zap.Error(err))
+		return nil, err
+	}
+	sortWebhookDeliveries(deliveries, true)
+	return deliveries, nil
+}
+
+// UpdateDelivery — сохранение результата попытки доставки
+func (w *WebhookRepositoryPostgres) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	err := w.getDB(ctx).Model(&domain.WebhookDelivery{}).
+		Where("delivery_id = ?", delivery.DeliveryID).
+		Updates(map[string]any{
+			"status":          delivery.Status,
+			"attempts":        delivery.Attempts,
+			"response_status": delivery.ResponseStatus,
+			"last_error":      delivery.LastError,
+			"next_attempt_at": delivery.NextAttemptAt,
+			"delivered_at":    delivery.DeliveredAt,
+		}).Error
+	if err != nil {
+		w.logger.Error("Failed to update webhook delivery", zap.Int("deliveryID", delivery.DeliveryID), zap.Error(err))
+	}
+	return err
+}
+
+// GetDeliveries — последние доставки webhook от новых к старым
+func (w *WebhookRepositoryPostgres) GetDeliveries(ctx context.Context, webhookID int, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	query := w.getDB(ctx).Where("webhook_id = ?", webhookID).Order("delivery_id DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&deliveries).Error; err != nil {
+		w.logger.Error("Failed to get webhook deliveries", zap.Int("webhookID", webhookID), zap.Error(err))
+		return nil, err
+	}
+	return deliveries, nil
+}
@@ -0,0 +1,165 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
+	"slices"
+	"sort"
+	"time"
+)
+
+type WebhookRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewWebhookRepositoryMemory(db *MemoryDB) WebhookRepository {
+	return &WebhookRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// AddWebhook — добавление webhook; идентификатор записывается в переданную структуру
+func (w *WebhookRepositoryMemory) AddWebhook(ctx context.Context, webhook *domain.Webhook) error {
+	return w.write(ctx, func(state *memoryState) error {
+		stored := *webhook
+		stored.WebhookID = state.nextWebhookID
+		stored.Events = slices.Clone(webhook.Events)
+		state.nextWebhookID++
+		state.webhooks[stored.WebhookID] = stored
+		webhook.WebhookID = stored.WebhookID
+		return nil
+	})
+}
+
+// GetWebhookByID — получение webhook по идентификатору
+func (w *WebhookRepositoryMemory) GetWebhookByID(ctx context.Context, webhookID int) (*domain.Webhook, error) {
+	var webhook *domain.Webhook
+	err := w.read(ctx, func(state *memoryState) error {
+		stored, ok := state.webhooks[webhookID]
+		if !ok {
+			return gofermartErrors.ErrWebhookNotFound
+		}
+		webhook = &stored
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+// GetWebhooksByUserID — получение webhook пользователя в порядке создания
+func (w *WebhookRepositoryMemory) GetWebhooksByUserID(ctx context.Context, userID int) ([]domain.Webhook, error) {
+	var webhooks []domain.Webhook
+	err := w.read(ctx, func(state *memoryState) error {
+		for _, webhook := range state.webhooks {
+			if webhook.UserID == userID {
+				webhooks = append(webhooks, webhook)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(webhooks, func(i, j int) bool {
+		return webhooks[i].WebhookID < webhooks[j].WebhookID
+	})
+	return webhooks, nil
+}
+
+// DeleteWebhook — удаление webhook вместе с журналом его доставок
+func (w *WebhookRepositoryMemory) DeleteWebhook(ctx context.Context, webhookID int) error {
+	return w.write(ctx, func(state *memoryState) error {
+		if _, ok := state.webhooks[webhookID]; !ok {
+			return gofermartErrors.ErrWebhookNotFound
+		}
+		delete(state.webhooks, webhookID)
+		for id, delivery := range state.deliveries {
+			if delivery.WebhookID == webhookID {
+				delete(state.deliveries, id)
+			}
+		}
+		return nil
+	})
+}
+
+// AddDelivery — постановка события в очередь доставки webhook
+func (w *WebhookRepositoryMemory) AddDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	return w.write(ctx, func(state *memoryState) error {
+		delivery.DeliveryID = state.nextDeliveryID
+		state.nextDeliveryID++
+		state.deliveries[delivery.DeliveryID] = delivery
+		return nil
+	})
+}
+
+// ClaimDeliveries — выбор ожидающих доставок, срок которых наступил, с откладыванием на время аренды
+func (w *WebhookRepositoryMemory) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := w.write(ctx, func(state *memoryState) error {
+		for _, delivery := range state.deliveries {
+			if delivery.Status == domain.WebhookDeliveryPending && !delivery.NextAttemptAt.After(now) {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		sortWebhookDeliveries(deliveries, true)
+		deliveries = limitRows(deliveries, limit)
+		for i := range deliveries {
+			deliveries[i].NextAttemptAt = now.Add(lease)
+			state.deliveries[deliveries[i].DeliveryID] = deliveries[i]
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// UpdateDelivery — сохранение результата попытки доставки
+func (w *WebhookRepositoryMemory) UpdateDelivery(ctx context.Context, delivery domain.WebhookDelivery) error {
+	return w.write(ctx, func(state *memoryState) error {
+		stored, ok := state.deliveries[delivery.DeliveryID]
+		if !ok {
+			return nil
+		}
+		stored.Status = delivery.Status
+		stored.Attempts = delivery.Attempts
+		stored.ResponseStatus = delivery.ResponseStatus
+		stored.LastError = delivery.LastError
+		stored.NextAttemptAt = delivery.NextAttemptAt
+		stored.DeliveredAt = delivery.DeliveredAt
+		state.deliveries[delivery.DeliveryID] = stored
+		return nil
+	})
+}
+
+// GetDeliveries — последние доставки webhook от новых к старым
+func (w *WebhookRepositoryMemory) GetDeliveries(ctx context.Context, webhookID int, limit int) ([]domain.WebhookDelivery, error) {
+	var deliveries []domain.WebhookDelivery
+	err := w.read(ctx, func(state *memoryState) error {
+		for _, delivery := range state.deliveries {
+			if delivery.WebhookID == webhookID {
+				deliveries = append(deliveries, delivery)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sortWebhookDeliveries(deliveries, false)
+	return limitRows(deliveries, limit), nil
+}
+
+// sortWebhookDeliveries — упорядочивает доставки по идентификатору
+func sortWebhookDeliveries(deliveries []domain.WebhookDelivery, ascending bool) {
+	sort.Slice(deliveries, func(i, j int) bool {
+		if ascending {
+			return deliveries[i].DeliveryID < deliveries[j].DeliveryID
+		}
+		return deliveries[i].DeliveryID > deliveries[j].DeliveryID
+	})
+}
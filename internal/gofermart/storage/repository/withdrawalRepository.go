@@ -2,46 +2,65 @@ package repository
 
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"context"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"strconv"
 )
 
 type WithdrawalRepository interface {
-	AddWithdrawal(tx *gorm.DB, withdrawal domain.Withdrawal) error
-	GetWithdrawalsByUserID(tx *gorm.DB, userID int) ([]domain.Withdrawal, error)
-
-	BeginTransaction() (*gorm.DB, error)
-	Commit(tx *gorm.DB) error
-	Rollback(tx *gorm.DB) error
+	AddWithdrawal(ctx context.Context, withdrawal domain.Withdrawal) error
+	GetWithdrawalsByUserID(ctx context.Context, userID int, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error)
 }
 
 type WithdrawalRepositoryPostgres struct {
 	*BaseRepository
 }
 
-func NewWithdrawalRepository(db *gorm.DB, logger *zap.Logger) WithdrawalRepository {
+func NewWithdrawalRepository(db *gorm.DB, reads *ReadRouter, logger *zap.Logger) WithdrawalRepository {
 	return &WithdrawalRepositoryPostgres{
-		BaseRepository: NewBaseRepository(db, logger),
+		BaseRepository: NewBaseRepository(db, reads, logger),
 	}
 }
 
 // AddWithdrawal — добавление записи о выводе средств
-func (w *WithdrawalRepositoryPostgres) AddWithdrawal(tx *gorm.DB, withdrawal domain.Withdrawal) error {
+func (w *WithdrawalRepositoryPostgres) AddWithdrawal(ctx context.Context, withdrawal domain.Withdrawal) error {
 	w.logger.Info("Adding withdrawal", zap.Int("userID", withdrawal.UserID), zap.String("order", withdrawal.OrderNumber))
-	err := w.getDB(tx).Create(&withdrawal).Error
+	err := w.getDB(ctx).Create(&withdrawal).Error
 	if err != nil {
 		w.logger.Error("Failed to add withdrawal", zap.Error(err))
 		return err
 	}
+	if err := w.bumpUserVersion(ctx, withdrawal.UserID); err != nil {
+		w.logger.Error("Failed to bump user version", zap.Error(err))
+		return err
+	}
+	w.markWritten(ctx, withdrawal.UserID)
 	w.logger.Info("Withdrawal added successfully", zap.Int("userID", withdrawal.UserID), zap.String("order", withdrawal.OrderNumber))
 	return nil
 }
 
-// GetWithdrawalsByUserID — получение списка выводов пользователя
-func (w *WithdrawalRepositoryPostgres) GetWithdrawalsByUserID(tx *gorm.DB, userID int) ([]domain.Withdrawal, error) {
+// GetWithdrawalsByUserID — получение списка выводов пользователя с фильтрами и keyset-пагинацией
+func (w *WithdrawalRepositoryPostgres) GetWithdrawalsByUserID(ctx context.Context, userID int, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error) {
 	w.logger.Info("Getting withdrawals for user", zap.Int("userID", userID))
 	var withdrawals []domain.Withdrawal
-	err := w.getDB(tx).Where("user_id = ?", userID).Order("processed_at desc").Find(&withdrawals).Error
+	query := w.getReadDB(ctx, userID).Where("user_id = ?", userID)
+	if filter.After != nil {
+		// Ключ курсора выводов — числовой идентификатор, строковое сравнение дало бы неверный порядок
+		afterID, err := strconv.Atoi(filter.After.Key)
+		if err != nil {
+			return nil, gofermartErrors.ErrInvalidCursor
+		}
+		comparison := "<"
+		if filter.Ascending {
+			comparison = ">"
+		}
+		query = query.Where("(processed_at, withdrawal_id) "+comparison+" (?, ?)", filter.After.Time, afterID)
+		filter.After = nil
+	}
+	query = applyListOptions(query, filter.ListOptions, "processed_at", "withdrawal_id")
+	err := query.Find(&withdrawals).Error
 	if err != nil {
 		w.logger.Error("Failed to get withdrawals", zap.Error(err))
 		return nil, err
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	gofermartErrors "beliaev-aa/yp-gofermart/internal/gofermart/errors"
+	"cmp"
+	"context"
+	"sort"
+	"strconv"
+)
+
+type WithdrawalRepositoryMemory struct {
+	*MemoryDB
+}
+
+func NewWithdrawalRepositoryMemory(db *MemoryDB) WithdrawalRepository {
+	return &WithdrawalRepositoryMemory{
+		MemoryDB: db,
+	}
+}
+
+// AddWithdrawal — добавление записи о выводе средств
+func (w *WithdrawalRepositoryMemory) AddWithdrawal(ctx context.Context, withdrawal domain.Withdrawal) error {
+	return w.write(ctx, func(state *memoryState) error {
+		withdrawal.WithdrawalID = state.nextWithdrawalID
+		state.nextWithdrawalID++
+		state.withdrawals[withdrawal.WithdrawalID] = withdrawal
+		state.bumpUserVersion(withdrawal.UserID)
+		return nil
+	})
+}
+
+// GetWithdrawalsByUserID — получение списка выводов пользователя с фильтрами и keyset-пагинацией
+func (w *WithdrawalRepositoryMemory) GetWithdrawalsByUserID(ctx context.Context, userID int, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error) {
+	afterID := 0
+	if filter.After != nil {
+		id, err := strconv.Atoi(filter.After.Key)
+		if err != nil {
+			return nil, gofermartErrors.ErrInvalidCursor
+		}
+		afterID = id
+	}
+
+	var withdrawals []domain.Withdrawal
+	err := w.read(ctx, func(state *memoryState) error {
+		for _, withdrawal := range state.withdrawals {
+			if withdrawal.UserID != userID || !inPeriod(filter.ListOptions, withdrawal.ProcessedAt) {
+				continue
+			}
+			if filter.After != nil && !followsCursor(filter.ListOptions, withdrawal.ProcessedAt, cmp.Compare(withdrawal.WithdrawalID, afterID)) {
+				continue
+			}
+			withdrawals = append(withdrawals, withdrawal)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(withdrawals, func(i, j int) bool {
+		return lessInOrder(filter.ListOptions, withdrawals[i].ProcessedAt, withdrawals[j].ProcessedAt, cmp.Compare(withdrawals[i].WithdrawalID, withdrawals[j].WithdrawalID))
+	})
+	return limitRows(withdrawals, filter.Limit), nil
+}
@@ -1,9 +1,41 @@
 package storage
 
-import "beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
+)
 
 type Storage struct {
+	TxManager      repository.TxManager
 	UserRepo       repository.UserRepository
 	OrderRepo      repository.OrderRepository
 	WithdrawalRepo repository.WithdrawalRepository
+	StatementRepo  repository.StatementRepository
+	AdjustmentRepo repository.AdjustmentRepository
+	AuditRepo      repository.AuditRepository
+	OutboxRepo     repository.OutboxRepository
+	WebhookRepo    repository.WebhookRepository
+	NotifyRepo     repository.NotificationRepository
+	RateLimitRepo  repository.RateLimitRepository
+
+	// closer — закрывает подключения к базе данных; у хранилища в памяти отсутствует
+	closer func() error
+	// pinger — проверяет доступность базы данных; у хранилища в памяти отсутствует
+	pinger func(ctx context.Context) error
+}
+
+// Close — закрывает подключения хранилища
+func (s *Storage) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer()
+}
+
+// Ping — проверяет доступность хранилища; хранилище в памяти доступно всегда
+func (s *Storage) Ping(ctx context.Context) error {
+	if s.pinger == nil {
+		return nil
+	}
+	return s.pinger(ctx)
 }
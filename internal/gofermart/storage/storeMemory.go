@@ -0,0 +1,35 @@
+package storage
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"go.uber.org/zap"
+	"strings"
+)
+
+// MemoryDSNScheme — схема DSN, выбирающая хранилище в памяти процесса
+const MemoryDSNScheme = "memory://"
+
+// IsMemoryDSN — проверяет, указывает ли DSN на хранилище в памяти
+func IsMemoryDSN(dsn string) bool {
+	return strings.HasPrefix(dsn, MemoryDSNScheme)
+}
+
+// NewMemoryStorage — создаёт хранилище в памяти процесса для тестов и демонстрационного режима.
+// Данные не сохраняются между перезапусками.
+func NewMemoryStorage(logger *zap.Logger) *Storage {
+	db := repository.NewMemoryDB(logger)
+
+	return &Storage{
+		TxManager:      db,
+		UserRepo:       repository.NewUserRepositoryMemory(db),
+		OrderRepo:      repository.NewOrderRepositoryMemory(db),
+		WithdrawalRepo: repository.NewWithdrawalRepositoryMemory(db),
+		StatementRepo:  repository.NewStatementRepositoryMemory(db),
+		AdjustmentRepo: repository.NewAdjustmentRepositoryMemory(db),
+		AuditRepo:      repository.NewAuditRepositoryMemory(db),
+		OutboxRepo:     repository.NewOutboxRepositoryMemory(db),
+		WebhookRepo:    repository.NewWebhookRepositoryMemory(db),
+		NotifyRepo:     repository.NewNotificationRepositoryMemory(db),
+		RateLimitRepo:  repository.NewRateLimitRepositoryMemory(),
+	}
+}
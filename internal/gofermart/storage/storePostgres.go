@@ -5,23 +5,33 @@ package storage
 import (
 	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
+	"database/sql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	gormLogger "gorm.io/gorm/logger"
 )
 
 // StorePostgres — структура для работы с базой данных PostgreSQL
 type StorePostgres struct {
-	db     *gorm.DB
-	logger *zap.Logger
+	db      *gorm.DB
+	replica *gorm.DB
+	logger  *zap.Logger
 }
 
-// NewStorage — создаёт новое хранилище с подключением к PostgreSQL и инициализирует схему базы данных
-func NewStorage(dsn string, logger *zap.Logger) (*Storage, error) {
-	// Подключение к базе данных через GORM
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+// NewStorage — создаёт новое хранилище с подключением к PostgreSQL и инициализирует схему базы данных.
+// DSN вида memory:// выбирает хранилище в памяти процесса.
+func NewStorage(dsn string, options Options, logger *zap.Logger) (*Storage, error) {
+	if IsMemoryDSN(dsn) {
+		logger.Warn("Using in-memory storage, data will be lost on restart")
+		return NewMemoryStorage(logger), nil
+	}
+
+	db, err := connectPostgres(dsn, options, logger)
 	if err != nil {
-		logger.Error("Failed to open database connection", zap.Error(err))
 		return nil, err
 	}
 
@@ -33,18 +43,109 @@ func NewStorage(dsn string, logger *zap.Logger) (*Storage, error) {
 	// Инициализация схемы базы данных
 	if err := store.initSchema(); err != nil {
 		logger.Error("Failed to initialize database schema", zap.Error(err))
+		_ = store.Close()
 		return nil, err
 	}
 
+	// Подключение к реплике для чтений, если она настроена
+	var replica *gorm.DB
+	if options.ReplicaDSN != "" {
+		replica, err = connectPostgres(options.ReplicaDSN, options, logger)
+		if err != nil {
+			logger.Error("Failed to connect to database replica", zap.Error(err))
+			_ = store.Close()
+			return nil, err
+		}
+		store.replica = replica
+		logger.Info("Read replica configured", zap.Duration("sticky_window", options.ReplicaStickyWindow))
+	}
+
+	reads := repository.NewReadRouter(db, replica, options.ReplicaStickyWindow)
+
 	return &Storage{
-		UserRepo:       repository.NewUserRepository(db, logger),
-		OrderRepo:      repository.NewOrderRepository(db, logger),
-		WithdrawalRepo: repository.NewWithdrawalRepository(db, logger),
+		TxManager:      repository.NewTxManager(db, logger),
+		UserRepo:       repository.NewUserRepository(db, reads, logger),
+		OrderRepo:      repository.NewOrderRepository(db, reads, logger),
+		WithdrawalRepo: repository.NewWithdrawalRepository(db, reads, logger),
+		StatementRepo:  repository.NewStatementRepository(db, reads, logger),
+		AdjustmentRepo: repository.NewAdjustmentRepository(db, reads, logger),
+		AuditRepo:      repository.NewAuditRepository(db, reads, logger),
+		OutboxRepo:     repository.NewOutboxRepository(db, reads, logger),
+		WebhookRepo:    repository.NewWebhookRepository(db, reads, logger),
+		NotifyRepo:     repository.NewNotificationRepository(db, dsn, logger),
+		RateLimitRepo:  repository.NewRateLimitRepository(db, logger),
+		closer:         store.Close,
+		pinger:         store.Ping,
 	}, nil
 }
 
-// Close — закрывает подключение к базе данных
+// connectPostgres — открывает подключение GORM, дожидаясь готовности базы данных
+func connectPostgres(dsn string, options Options, logger *zap.Logger) (*gorm.DB, error) {
+	// Открытие пула соединений с заданными параметрами сессии
+	sqlDB, err := openPostgres(dsn, options)
+	if err != nil {
+		logger.Error("Failed to parse database DSN", zap.Error(err))
+		return nil, err
+	}
+
+	// Ожидание готовности базы данных: при старте через docker-compose она может быть ещё недоступна
+	if err := retryWithBackoff(context.Background(), options.ConnectTimeout, sqlDB.PingContext, logger); err != nil {
+		logger.Error("Failed to open database connection", zap.Error(err))
+		_ = sqlDB.Close()
+		return nil, err
+	}
+
+	// Подключение к базе данных через GORM
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		PrepareStmt: options.PrepareStmt,
+		Logger:      gormLogger.Default.LogMode(options.gormLogLevel()),
+	})
+	if err != nil {
+		logger.Error("Failed to open database connection", zap.Error(err))
+		_ = sqlDB.Close()
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// openPostgres — создаёт пул соединений database/sql поверх pgx с параметрами пула и таймаутами
+func openPostgres(dsn string, options Options) (*sql.DB, error) {
+	connConfig, err := pgx.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range options.runtimeParams() {
+		connConfig.RuntimeParams[name] = value
+	}
+
+	sqlDB := stdlib.OpenDB(*connConfig)
+	sqlDB.SetMaxOpenConns(options.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(options.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(options.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(options.ConnMaxIdleTime)
+
+	return sqlDB, nil
+}
+
+// Ping — проверяет доступность основной базы данных
+func (s *StorePostgres) Ping(ctx context.Context) error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close — закрывает подключения к базе данных и реплике
 func (s *StorePostgres) Close() error {
+	if s.replica != nil {
+		if replicaDB, err := s.replica.DB(); err == nil {
+			if err := replicaDB.Close(); err != nil {
+				s.logger.Error("Failed to close replica connection", zap.Error(err))
+			}
+		}
+	}
 	sqlDB, err := s.db.DB()
 	if err != nil {
 		return err
@@ -52,8 +153,33 @@ func (s *StorePostgres) Close() error {
 	return sqlDB.Close()
 }
 
+// auditAppendOnlySQL — запрещает изменение и удаление записей журнала аудита на уровне базы данных
+var auditAppendOnlySQL = []string{
+	`CREATE OR REPLACE FUNCTION audit_events_append_only() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_events is append-only';
+	END;
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events`,
+	`CREATE TRIGGER audit_events_append_only BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+	FOR EACH STATEMENT EXECUTE PROCEDURE audit_events_append_only()`,
+}
+
 // initSchema — инициализация схемы базы данных с помощью миграций
 func (s *StorePostgres) initSchema() error {
-	// Автоматическая миграция схемы для пользователей, заказов и выводов
-	return s.db.AutoMigrate(&domain.User{}, &domain.Order{}, &domain.Withdrawal{})
+	// Автоматическая миграция схемы для пользователей, заказов, выводов, выписок, корректировок баланса, журнала аудита, outbox, webhook
+	// и корзин ограничения частоты запросов
+	err := s.db.AutoMigrate(&domain.User{}, &domain.Order{}, &domain.Withdrawal{}, &domain.Statement{}, &domain.BalanceAdjustment{}, &domain.AuditEvent{}, &domain.OutboxMessage{}, &domain.Webhook{}, &domain.WebhookDelivery{}, &domain.RateLimitBucket{})
+	if err != nil {
+		return err
+	}
+
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		for _, statement := range auditAppendOnlySQL {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
@@ -0,0 +1,55 @@
+//go:build !test
+
+package storage
+
+import (
+	"go.uber.org/zap"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestPostgresStorage_Conformance — запускает общий набор проверок против PostgreSQL.
+// Требует переменную окружения TEST_DATABASE_URI; все данные в таблицах будут удалены.
+func TestPostgresStorage_Conformance(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URI")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URI is not set")
+	}
+
+	logger := zap.NewNop()
+	options := Options{MaxOpenConns: 5, MaxIdleConns: 2, StatementTimeout: 5 * time.Second, LogLevel: "silent"}
+
+	runConformanceSuite(t, func(t *testing.T) *Storage {
+		s, err := NewStorage(dsn, options, logger)
+		if err != nil {
+			t.Fatalf("failed to connect to database: %v", err)
+		}
+		t.Cleanup(func() { _ = s.Close() })
+
+		db, err := connectPostgres(dsn, options, logger)
+		if err != nil {
+			t.Fatalf("failed to connect to database: %v", err)
+		}
+		defer func() {
+			if sqlDB, err := db.DB(); err == nil {
+				_ = sqlDB.Close()
+			}
+		}()
+		if err := db.Exec("TRUNCATE users, orders, withdrawals, statements, balance_adjustments, outbox, webhooks, webhook_deliveries, rate_limit_buckets RESTART IDENTITY").Error; err != nil {
+			t.Fatalf("failed to truncate tables: %v", err)
+		}
+		// Журнал аудита защищён триггером от очистки, поэтому на время очистки триггер отключается
+		for _, statement := range []string{
+			"ALTER TABLE audit_events DISABLE TRIGGER audit_events_append_only",
+			"TRUNCATE audit_events RESTART IDENTITY",
+			"ALTER TABLE audit_events ENABLE TRIGGER audit_events_append_only",
+		} {
+			if err := db.Exec(statement).Error; err != nil {
+				t.Fatalf("failed to truncate audit events: %v", err)
+			}
+		}
+
+		return s
+	})
+}
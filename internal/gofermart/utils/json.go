@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrTrailingJSON - после JSON-значения в теле запроса есть другие данные
+var ErrTrailingJSON = errors.New("request body must contain a single JSON value")
+
+// DecodeJSON - строго декодирует тело запроса в v: неизвестные поля и данные после JSON-значения считаются ошибкой
+func DecodeJSON(body io.Reader, v any) error {
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return ErrTrailingJSON
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package utils
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestDecodeJSON(t *testing.T) {
+	type request struct {
+		Login    string `json:"login"`
+		Password string `json:"password"`
+	}
+
+	testCases := []struct {
+		Name        string
+		Body        string
+		Limit       int64
+		Expected    request
+		ExpectErr   bool
+		ExpectedErr error
+	}{
+		{
+			Name:     "Valid_Object",
+			Body:     `{"login": "user", "password": "secret"}`,
+			Expected: request{Login: "user", Password: "secret"},
+		},
+		{
+			Name:     "Trailing_Whitespace_Is_Allowed",
+			Body:     "{\"login\": \"user\"}\n",
+			Expected: request{Login: "user"},
+		},
+		{
+			Name:      "Unknown_Field",
+			Body:      `{"login": "user", "password": "secret", "admin": true}`,
+			ExpectErr: true,
+		},
+		{
+			Name:        "Trailing_Object",
+			Body:        `{"login": "user"}{"login": "admin"}`,
+			ExpectErr:   true,
+			ExpectedErr: ErrTrailingJSON,
+		},
+		{
+			Name:        "Trailing_Garbage",
+			Body:        `{"login": "user"} garbage`,
+			ExpectErr:   true,
+			ExpectedErr: ErrTrailingJSON,
+		},
+		{
+			Name:      "Empty_Body",
+			Body:      "",
+			ExpectErr: true,
+		},
+		{
+			Name:      "Body_Over_Limit",
+			Body:      `{"login": "user", "password": "secret"}`,
+			Limit:     10,
+			ExpectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.Name, func(t *testing.T) {
+			limit := int64(1 << 10)
+			if tc.Limit > 0 {
+				limit = tc.Limit
+			}
+			body := http.MaxBytesReader(nil, io.NopCloser(strings.NewReader(tc.Body)), limit)
+
+			var got request
+			err := DecodeJSON(body, &got)
+
+			if (err != nil) != tc.ExpectErr {
+				t.Fatalf("expected error: %v, got: %v", tc.ExpectErr, err)
+			}
+			if tc.ExpectedErr != nil && !errors.Is(err, tc.ExpectedErr) {
+				t.Errorf("expected error: %v, got: %v", tc.ExpectedErr, err)
+			}
+			if tc.Limit > 0 {
+				var tooLarge *http.MaxBytesError
+				if !errors.As(err, &tooLarge) {
+					t.Errorf("expected *http.MaxBytesError, got: %v", err)
+				}
+			}
+			if err == nil && got != tc.Expected {
+				t.Errorf("expected %+v, got %+v", tc.Expected, got)
+			}
+		})
+	}
+}
@@ -1,27 +1,61 @@
 package utils
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	"errors"
 	"github.com/go-chi/jwtauth/v5"
 	"go.uber.org/zap"
 	"net/http"
 )
 
-// UsernameExtractor - интерфейс для извлечения имени пользователя из контекста
-type UsernameExtractor interface {
-	ExtractUsernameFromContext(r *http.Request, logger *zap.Logger) (string, error)
+// Claims токена пользователя
+const (
+	RolesClaim    = "roles"
+	UserIDClaim   = "user_id"
+	UsernameClaim = "username"
+)
+
+// ErrUnauthorized - в контексте запроса нет токена с данными пользователя
+var ErrUnauthorized = errors.New("unauthorized")
+
+// PrincipalExtractor - интерфейс для извлечения пользователя запроса (идентификатор, логин, роли) из контекста
+type PrincipalExtractor interface {
+	ExtractPrincipalFromContext(r *http.Request, logger *zap.Logger) (domain.Principal, error)
 }
 
-// RealUsernameExtractor - реальная реализация интерфейса для продакшн-кода
-type RealUsernameExtractor struct{}
+// RealPrincipalExtractor - реальная реализация интерфейса для продакшн-кода
+type RealPrincipalExtractor struct{}
 
-// ExtractUsernameFromContext - извлекает имя пользователя из контекста JWT токена
-func (e *RealUsernameExtractor) ExtractUsernameFromContext(r *http.Request, logger *zap.Logger) (string, error) {
+// ExtractPrincipalFromContext - извлекает пользователя из claims JWT токена без обращения к хранилищу
+func (e *RealPrincipalExtractor) ExtractPrincipalFromContext(r *http.Request, logger *zap.Logger) (domain.Principal, error) {
 	_, claims, _ := jwtauth.FromContext(r.Context())
-	login, ok := claims["username"].(string)
+	principal, ok := PrincipalFromClaims(claims)
 	if !ok {
 		logger.Warn("Unauthorized access attempt")
-		return "", errors.New("unauthorized")
+		return domain.Principal{}, ErrUnauthorized
+	}
+	return principal, nil
+}
+
+// PrincipalFromClaims - восстанавливает пользователя из claims токена. Токены, выпущенные до появления
+// идентификатора пользователя в claims, считаются недействительными.
+func PrincipalFromClaims(claims map[string]interface{}) (domain.Principal, bool) {
+	login, ok := claims[UsernameClaim].(string)
+	if !ok || login == "" {
+		return domain.Principal{}, false
+	}
+	// После декодирования JSON числовые claims имеют тип float64
+	userID, ok := claims[UserIDClaim].(float64)
+	if !ok || userID <= 0 {
+		return domain.Principal{}, false
+	}
+
+	principal := domain.Principal{UserID: int(userID), Login: login}
+	values, _ := claims[RolesClaim].([]interface{})
+	for _, value := range values {
+		if role, ok := value.(string); ok && domain.Role(role).Valid() {
+			principal.Roles = append(principal.Roles, domain.Role(role))
+		}
 	}
-	return login, nil
+	return principal, true
 }
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
 	"errors"
 	"github.com/go-chi/jwtauth/v5"
+	"github.com/google/go-cmp/cmp"
 	"go.uber.org/zap"
 	"net/http"
 	"net/http/httptest"
@@ -10,39 +12,57 @@ import (
 )
 
 type testCase struct {
-	Name             string
-	ContextClaims    map[string]interface{}
-	ExpectedUsername string
-	ExpectedError    error
+	Name              string
+	ContextClaims     map[string]interface{}
+	ExpectedPrincipal domain.Principal
+	ExpectedError     error
 }
 
-func TestRealUsernameExtractor_ExtractUsernameFromContext(t *testing.T) {
+func TestRealPrincipalExtractor_ExtractPrincipalFromContext(t *testing.T) {
 	testCases := []testCase{
 		{
-			Name: "Valid_Token_With_Username",
+			Name: "Valid_Token_With_Roles",
 			ContextClaims: map[string]interface{}{
+				"user_id":  7,
 				"username": "test_user",
+				"roles":    []string{"user", "partner"},
 			},
-			ExpectedUsername: "test_user",
-			ExpectedError:    nil,
+			ExpectedPrincipal: domain.Principal{UserID: 7, Login: "test_user", Roles: domain.Roles{domain.RoleUser, domain.RolePartner}},
+			ExpectedError:     nil,
+		},
+		{
+			Name: "Unknown_Roles_Are_Ignored",
+			ContextClaims: map[string]interface{}{
+				"user_id":  7,
+				"username": "test_user",
+				"roles":    []string{"root", "support"},
+			},
+			ExpectedPrincipal: domain.Principal{UserID: 7, Login: "test_user", Roles: domain.Roles{domain.RoleSupport}},
+			ExpectedError:     nil,
+		},
+		{
+			Name: "Token_Without_User_ID",
+			ContextClaims: map[string]interface{}{
+				"username": "test_user",
+			},
+			ExpectedError: errors.New("unauthorized"),
 		},
 		{
 			Name: "Token_Without_Username",
 			ContextClaims: map[string]interface{}{
-				"email": "test@example.com",
+				"user_id": 7,
+				"email":   "test@example.com",
 			},
-			ExpectedUsername: "",
-			ExpectedError:    errors.New("unauthorized"),
+			ExpectedError: errors.New("unauthorized"),
 		},
 		{
-			Name:             "Empty_Token_Claims",
-			ContextClaims:    map[string]interface{}{},
-			ExpectedUsername: "",
-			ExpectedError:    errors.New("unauthorized"),
+			Name:          "Empty_Token_Claims",
+			ContextClaims: map[string]interface{}{},
+			ExpectedError: errors.New("unauthorized"),
 		},
 	}
 
-	extractor := &RealUsernameExtractor{}
+	extractor := &RealPrincipalExtractor{}
 	logger := zap.NewNop()
 
 	for _, tc := range testCases {
@@ -62,10 +82,10 @@ func TestRealUsernameExtractor_ExtractUsernameFromContext(t *testing.T) {
 			ctx := jwtauth.NewContext(req.Context(), token, nil)
 			req = req.WithContext(ctx)
 
-			username, err := extractor.ExtractUsernameFromContext(req, logger)
+			principal, err := extractor.ExtractPrincipalFromContext(req, logger)
 
-			if username != tc.ExpectedUsername {
-				t.Errorf("expected username %v, got %v", tc.ExpectedUsername, username)
+			if diff := cmp.Diff(tc.ExpectedPrincipal, principal); diff != "" {
+				t.Errorf("principal mismatch (-want +got):\n%s", diff)
 			}
 
 			if err != nil && tc.ExpectedError == nil {
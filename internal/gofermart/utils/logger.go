@@ -10,10 +10,12 @@ import (
 )
 
 // NewLogger - создает новый экземпляр Logger с конфигурацией для продакшн-окружения.
-// Logger будет использовать формат времени ISO8601 для логов.
-func NewLogger() *zap.Logger {
+// Logger будет использовать формат времени ISO8601 для логов; уровень журнала задаётся level
+// и может меняться во время работы.
+func NewLogger(level zap.AtomicLevel) *zap.Logger {
 	// Создание конфигурации для сервиса логирования в режиме продакшн
 	config := zap.NewProductionConfig()
+	config.Level = level
 	// Задание формата времени в ISO8601 для читаемости
 	config.EncoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
@@ -0,0 +1,98 @@
+package validation
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/utils"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// LuhnValidator — номер должен состоять из цифр, быть ненулевым и проходить проверку алгоритмом Луна
+type LuhnValidator struct{}
+
+// Validate — проверяет номер алгоритмом Луна
+func (LuhnValidator) Validate(_, number string) error {
+	if number == "" {
+		return &OrderNumberError{Rule: RuleLuhn, Reason: "order number is empty"}
+	}
+	if strings.Trim(number, "0123456789") != "" {
+		return &OrderNumberError{Rule: RuleLuhn, Reason: "order number must contain only digits"}
+	}
+	if strings.Trim(number, "0") == "" {
+		return &OrderNumberError{Rule: RuleLuhn, Reason: "order number must not be zero"}
+	}
+	if !utils.IsValidMoon(number) {
+		return &OrderNumberError{Rule: RuleLuhn, Reason: "order number checksum is invalid"}
+	}
+	return nil
+}
+
+// LengthValidator — ограничивает длину номера; Max = 0 снимает верхнюю границу
+type LengthValidator struct {
+	Min int
+	Max int
+}
+
+// Validate — проверяет длину номера
+func (v LengthValidator) Validate(_, number string) error {
+	length := utf8.RuneCountInString(number)
+	if length < v.Min {
+		return &OrderNumberError{Rule: RuleLength, Reason: fmt.Sprintf("order number must be at least %d characters long", v.Min)}
+	}
+	if v.Max > 0 && length > v.Max {
+		return &OrderNumberError{Rule: RuleLength, Reason: fmt.Sprintf("order number must be at most %d characters long", v.Max)}
+	}
+	return nil
+}
+
+// PrefixValidator — номер должен начинаться с одного из префиксов, выделенных партнёрам. Партнёр из Partners
+// загружает только номера со своими префиксами; номера остальных пользователей могут начинаться с общих префиксов
+// Prefixes или с префикса любого партнёра.
+type PrefixValidator struct {
+	Prefixes []string
+	Partners map[string][]string
+}
+
+// Validate — проверяет префикс номера по префиксам, допустимым для пользователя login
+func (v PrefixValidator) Validate(login, number string) error {
+	allowed, ok := v.Partners[login]
+	if !ok {
+		allowed = v.sharedPrefixes()
+	}
+	for _, prefix := range allowed {
+		if strings.HasPrefix(number, prefix) {
+			return nil
+		}
+	}
+	return &OrderNumberError{Rule: RulePrefix, Reason: "order number must start with one of: " + strings.Join(allowed, ", ")}
+}
+
+// sharedPrefixes — общие префиксы и префиксы всех партнёров в порядке логинов, чтобы текст отказа не менялся
+func (v PrefixValidator) sharedPrefixes() []string {
+	logins := make([]string, 0, len(v.Partners))
+	for login := range v.Partners {
+		logins = append(logins, login)
+	}
+	sort.Strings(logins)
+
+	prefixes := append([]string(nil), v.Prefixes...)
+	for _, login := range logins {
+		prefixes = append(prefixes, v.Partners[login]...)
+	}
+	return prefixes
+}
+
+// RegexValidator — номер должен соответствовать регулярному выражению (New привязывает его к началу и концу строки)
+type RegexValidator struct {
+	Pattern *regexp.Regexp
+}
+
+// Validate — проверяет номер регулярным выражением
+func (v RegexValidator) Validate(_, number string) error {
+	if !v.Pattern.MatchString(number) {
+		return &OrderNumberError{Rule: RuleRegex, Reason: "order number does not match pattern " + v.Pattern.String()}
+	}
+	return nil
+}
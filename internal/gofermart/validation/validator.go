@@ -0,0 +1,151 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Названия правил проверки номера заказа, используемые в конфигурации
+const (
+	RuleLength = "length"
+	RuleLuhn   = "luhn"
+	RulePrefix = "prefix"
+	RuleRegex  = "regex"
+)
+
+// Ограничения длины номера для цепочки по умолчанию
+const (
+	DefaultMinLength = 1
+	DefaultMaxLength = 32
+)
+
+// DefaultRules — правила, применяемые, если цепочка не задана конфигурацией
+var DefaultRules = []string{RuleLength, RuleLuhn}
+
+var (
+	// ErrInvalidOrderNumber — общая ошибка, к которой приводятся все отказы валидаторов (errors.Is)
+	ErrInvalidOrderNumber = errors.New("invalid order number")
+	// ErrUnknownRule — в конфигурации указано неизвестное правило проверки
+	ErrUnknownRule = errors.New("unknown order number validation rule")
+)
+
+// OrderNumberValidator — проверяет номер заказа, загружаемый пользователем login; при отказе возвращает *OrderNumberError
+type OrderNumberValidator interface {
+	Validate(login, number string) error
+}
+
+// OrderNumberError — причина, по которой номер заказа отклонён
+type OrderNumberError struct {
+	Rule   string
+	Reason string
+}
+
+// Error — возвращает текстовое описание причины отказа
+func (e *OrderNumberError) Error() string {
+	return e.Reason
+}
+
+// Unwrap — позволяет сравнивать ошибку с ErrInvalidOrderNumber
+func (e *OrderNumberError) Unwrap() error {
+	return ErrInvalidOrderNumber
+}
+
+// Options — параметры цепочки валидаторов, задаваемые конфигурацией
+type Options struct {
+	// Rules — правила в порядке применения; пустой список означает DefaultRules, а при незаданных границах длины —
+	// и длину от DefaultMinLength до DefaultMaxLength
+	Rules     []string
+	MinLength int
+	MaxLength int // 0 — без ограничения
+	Prefixes  []string
+	// PartnerPrefixes — префиксы, выделенные партнёрам, по логину партнёра
+	PartnerPrefixes map[string][]string
+	Pattern         string
+}
+
+// Chain — применяет валидаторы по порядку и возвращает первую ошибку
+type Chain []OrderNumberValidator
+
+// Validate — проверяет номер всеми валидаторами цепочки
+func (c Chain) Validate(login, number string) error {
+	for _, validator := range c {
+		if err := validator.Validate(login, number); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// New — собирает цепочку валидаторов по параметрам конфигурации
+func New(options Options) (OrderNumberValidator, error) {
+	rules := options.Rules
+	if len(rules) == 0 {
+		rules = DefaultRules
+		if options.MinLength == 0 && options.MaxLength == 0 {
+			options.MinLength, options.MaxLength = DefaultMinLength, DefaultMaxLength
+		}
+	}
+
+	chain := make(Chain, 0, len(rules))
+	for _, rule := range rules {
+		switch strings.ToLower(strings.TrimSpace(rule)) {
+		case RuleLuhn:
+			chain = append(chain, LuhnValidator{})
+		case RuleLength:
+			if options.MinLength < 0 || options.MaxLength < 0 || (options.MaxLength > 0 && options.MinLength > options.MaxLength) {
+				return nil, fmt.Errorf("invalid length bounds %d..%d", options.MinLength, options.MaxLength)
+			}
+			chain = append(chain, LengthValidator{Min: options.MinLength, Max: options.MaxLength})
+		case RulePrefix:
+			if len(options.Prefixes) == 0 && len(options.PartnerPrefixes) == 0 {
+				return nil, errors.New("prefix rule requires at least one allowed prefix")
+			}
+			chain = append(chain, PrefixValidator{Prefixes: options.Prefixes, Partners: options.PartnerPrefixes})
+		case RuleRegex:
+			if options.Pattern == "" {
+				return nil, errors.New("regex rule requires a pattern")
+			}
+			pattern, err := regexp.Compile("^(?:" + options.Pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern: %w", err)
+			}
+			chain = append(chain, RegexValidator{Pattern: pattern})
+		default:
+			return nil, fmt.Errorf("%w: %q", ErrUnknownRule, rule)
+		}
+	}
+
+	return chain, nil
+}
+
+// ParsePartnerPrefixes — разбирает префиксы партнёров в формате "login:799|800,login2:1234"
+func ParsePartnerPrefixes(value string) (map[string][]string, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	partners := make(map[string][]string)
+	for _, item := range strings.Split(value, ",") {
+		login, list, ok := strings.Cut(item, ":")
+		login = strings.TrimSpace(login)
+		if !ok || login == "" {
+			return nil, fmt.Errorf("invalid partner prefixes %q, expected login:prefix|prefix", strings.TrimSpace(item))
+		}
+		if _, duplicate := partners[login]; duplicate {
+			return nil, fmt.Errorf("prefixes of partner %q are set twice", login)
+		}
+		var prefixes []string
+		for _, prefix := range strings.Split(list, "|") {
+			if prefix = strings.TrimSpace(prefix); prefix != "" {
+				prefixes = append(prefixes, prefix)
+			}
+		}
+		if len(prefixes) == 0 {
+			return nil, fmt.Errorf("partner %q has no prefixes", login)
+		}
+		partners[login] = prefixes
+	}
+	return partners, nil
+}
@@ -0,0 +1,213 @@
+package validation
+
+import (
+	"errors"
+	"github.com/google/go-cmp/cmp"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		name        string
+		options     Options
+		login       string
+		number      string
+		expectedErr string
+		rule        string
+	}{
+		{
+			name:    "Default_Luhn_Valid",
+			options: Options{},
+			number:  "79927398713",
+		},
+		{
+			name:        "Default_Luhn_Rejects_Zero",
+			options:     Options{},
+			number:      "0",
+			expectedErr: "order number must not be zero",
+			rule:        RuleLuhn,
+		},
+		{
+			name:        "Default_Length_Rejects_Empty",
+			options:     Options{},
+			number:      "",
+			expectedErr: "order number must be at least 1 characters long",
+			rule:        RuleLength,
+		},
+		{
+			name:        "Default_Length_Rejects_Too_Long",
+			options:     Options{},
+			number:      "799273987137992739871379927398711",
+			expectedErr: "order number must be at most 32 characters long",
+			rule:        RuleLength,
+		},
+		{
+			name:        "Luhn_Rejects_Empty",
+			options:     Options{Rules: []string{RuleLuhn}},
+			number:      "",
+			expectedErr: "order number is empty",
+			rule:        RuleLuhn,
+		},
+		{
+			name:        "Luhn_Rejects_Non_Digits",
+			options:     Options{Rules: []string{RuleLuhn}},
+			number:      "7992739871a",
+			expectedErr: "order number must contain only digits",
+			rule:        RuleLuhn,
+		},
+		{
+			name:        "Luhn_Rejects_Checksum",
+			options:     Options{Rules: []string{RuleLuhn}},
+			number:      "79927398710",
+			expectedErr: "order number checksum is invalid",
+			rule:        RuleLuhn,
+		},
+		{
+			name:        "Length_Too_Short",
+			options:     Options{Rules: []string{RuleLength, RuleLuhn}, MinLength: 12, MaxLength: 16},
+			number:      "79927398713",
+			expectedErr: "order number must be at least 12 characters long",
+			rule:        RuleLength,
+		},
+		{
+			name:        "Length_Too_Long",
+			options:     Options{Rules: []string{RuleLength}, MinLength: 1, MaxLength: 5},
+			number:      "79927398713",
+			expectedErr: "order number must be at most 5 characters long",
+			rule:        RuleLength,
+		},
+		{
+			name:    "Prefix_Allowed",
+			options: Options{Rules: []string{"LUHN", " prefix "}, Prefixes: []string{"12", "799"}},
+			number:  "79927398713",
+		},
+		{
+			name:        "Prefix_Rejected",
+			options:     Options{Rules: []string{RuleLuhn, RulePrefix}, Prefixes: []string{"12", "34"}},
+			number:      "79927398713",
+			expectedErr: "order number must start with one of: 12, 34",
+			rule:        RulePrefix,
+		},
+		{
+			name:    "Partner_Own_Prefix",
+			options: Options{Rules: []string{RulePrefix}, PartnerPrefixes: map[string][]string{"acme": {"799"}, "shop": {"12"}}},
+			login:   "acme",
+			number:  "79927398713",
+		},
+		{
+			name:        "Partner_Other_Partner_Prefix_Rejected",
+			options:     Options{Rules: []string{RulePrefix}, Prefixes: []string{"799"}, PartnerPrefixes: map[string][]string{"acme": {"799"}, "shop": {"12", "34"}}},
+			login:       "shop",
+			number:      "79927398713",
+			expectedErr: "order number must start with one of: 12, 34",
+			rule:        RulePrefix,
+		},
+		{
+			name:    "User_Any_Partner_Prefix",
+			options: Options{Rules: []string{RulePrefix}, PartnerPrefixes: map[string][]string{"acme": {"12"}, "shop": {"799"}}},
+			login:   "user",
+			number:  "79927398713",
+		},
+		{
+			name:        "User_Unknown_Prefix_Rejected",
+			options:     Options{Rules: []string{RulePrefix}, Prefixes: []string{"55"}, PartnerPrefixes: map[string][]string{"shop": {"34"}, "acme": {"12"}}},
+			login:       "user",
+			number:      "79927398713",
+			expectedErr: "order number must start with one of: 55, 12, 34",
+			rule:        RulePrefix,
+		},
+		{
+			name:    "Regex_Whole_Match",
+			options: Options{Rules: []string{RuleRegex}, Pattern: `7\d{10}`},
+			number:  "79927398713",
+		},
+		{
+			name:        "Regex_Partial_Match_Rejected",
+			options:     Options{Rules: []string{RuleRegex}, Pattern: `7\d{3}`},
+			number:      "79927398713",
+			expectedErr: `order number does not match pattern ^(?:7\d{3})$`,
+			rule:        RuleRegex,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			validator, err := New(tc.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			err = validator.Validate(tc.login, tc.number)
+			if tc.expectedErr == "" {
+				if err != nil {
+					t.Errorf("expected valid number, got %v", err)
+				}
+				return
+			}
+
+			var numberErr *OrderNumberError
+			if !errors.As(err, &numberErr) {
+				t.Fatalf("expected *OrderNumberError, got %v", err)
+			}
+			if numberErr.Error() != tc.expectedErr || numberErr.Rule != tc.rule {
+				t.Errorf("expected %s: %q, got %s: %q", tc.rule, tc.expectedErr, numberErr.Rule, numberErr.Error())
+			}
+			if !errors.Is(err, ErrInvalidOrderNumber) {
+				t.Errorf("expected error to wrap ErrInvalidOrderNumber")
+			}
+		})
+	}
+}
+
+func TestNew_InvalidOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options Options
+	}{
+		{name: "Unknown_Rule", options: Options{Rules: []string{"checksum"}}},
+		{name: "Inverted_Length_Bounds", options: Options{Rules: []string{RuleLength}, MinLength: 10, MaxLength: 5}},
+		{name: "Prefix_Without_Prefixes", options: Options{Rules: []string{RulePrefix}}},
+		{name: "Regex_Without_Pattern", options: Options{Rules: []string{RuleRegex}}},
+		{name: "Regex_Invalid_Pattern", options: Options{Rules: []string{RuleRegex}, Pattern: "("}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := New(tc.options); err == nil {
+				t.Error("expected error, got none")
+			}
+		})
+	}
+}
+
+func TestParsePartnerPrefixes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		value    string
+		expected map[string][]string
+		wantErr  bool
+	}{
+		{name: "Empty", value: " "},
+		{
+			name:     "Several_Partners",
+			value:    "acme:799|800, shop : 1234 ",
+			expected: map[string][]string{"acme": {"799", "800"}, "shop": {"1234"}},
+		},
+		{name: "Missing_Login", value: ":799", wantErr: true},
+		{name: "Missing_Separator", value: "acme", wantErr: true},
+		{name: "Missing_Prefixes", value: "acme:|", wantErr: true},
+		{name: "Duplicate_Partner", value: "acme:799,acme:800", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			partners, err := ParsePartnerPrefixes(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error: %v, got %v", tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.expected, partners); diff != "" {
+				t.Errorf("unexpected partner prefixes (-expected +got):\n%s", diff)
+			}
+		})
+	}
+}
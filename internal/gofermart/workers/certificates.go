@@ -0,0 +1,33 @@
+package workers
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// CertificateReloader - перечитывает сертификат TLS, если его файлы изменились
+type CertificateReloader interface {
+	Reload() (bool, error)
+}
+
+// StartCertificateReloader - запуск фонового процесса, раз в interval проверяющего файлы сертификата TLS на диске.
+// Ошибка записывается в журнал, серверы продолжают работать с прежним сертификатом.
+func StartCertificateReloader(ctx context.Context, reloader CertificateReloader, interval time.Duration, logger *zap.Logger, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if _, err := reloader.Reload(); err != nil {
+				logger.Error("Failed to reload TLS certificate, keeping the current one", zap.Error(err))
+			}
+		case <-ctx.Done():
+			logger.Info("Shutting down TLS certificate reload goroutine")
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+// certificateReloaderFunc - CertificateReloader на основе функции
+type certificateReloaderFunc func() (bool, error)
+
+func (f certificateReloaderFunc) Reload() (bool, error) { return f() }
+
+func TestStartCertificateReloader(t *testing.T) {
+	testCases := []struct {
+		name      string
+		reloadErr error
+	}{
+		{name: "Checks_Certificate_Periodically"},
+		{name: "Reload_Error_Does_Not_Stop_Worker", reloadErr: errors.New("key does not match certificate")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reloads := make(chan struct{}, 2)
+			reloader := certificateReloaderFunc(func() (bool, error) {
+				select {
+				case reloads <- struct{}{}:
+				default:
+				}
+				return tc.reloadErr == nil, tc.reloadErr
+			})
+
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			ctx, cancel := context.WithCancel(context.Background())
+			go StartCertificateReloader(ctx, reloader, 10*time.Millisecond, zap.NewNop(), wg)
+
+			for i := 0; i < 2; i++ {
+				select {
+				case <-reloads:
+				case <-time.After(time.Second):
+					t.Fatalf("certificate was not checked, check %d", i+1)
+				}
+			}
+
+			cancel()
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package workers
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+)
+
+// ConfigReloader - перечитывает и применяет конфигурацию
+type ConfigReloader interface {
+	Reload() error
+}
+
+// StartConfigReloader - запуск фонового процесса, перечитывающего конфигурацию при каждом сигнале (SIGHUP).
+// Ошибка перезагрузки записывается в журнал, приложение продолжает работать с прежней конфигурацией.
+func StartConfigReloader(ctx context.Context, signals <-chan os.Signal, reloader ConfigReloader, logger *zap.Logger, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		select {
+		case sig := <-signals:
+			logger.Info("Reloading configuration", zap.Stringer("signal", sig))
+			if err := reloader.Reload(); err != nil {
+				logger.Error("Failed to reload configuration, keeping the current one", zap.Error(err))
+			}
+		case <-ctx.Done():
+			logger.Info("Shutting down config reload goroutine")
+			return
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// reloaderFunc - ConfigReloader на основе функции
+type reloaderFunc func() error
+
+func (f reloaderFunc) Reload() error { return f() }
+
+func TestStartConfigReloader(t *testing.T) {
+	testCases := []struct {
+		name      string
+		reloadErr error
+	}{
+		{name: "Reloads_On_Each_Signal"},
+		{name: "Reload_Error_Does_Not_Stop_Worker", reloadErr: errors.New("invalid config")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			reloads := make(chan struct{}, 2)
+			reloader := reloaderFunc(func() error {
+				reloads <- struct{}{}
+				return tc.reloadErr
+			})
+
+			signals := make(chan os.Signal)
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			ctx, cancel := context.WithCancel(context.Background())
+			go StartConfigReloader(ctx, signals, reloader, zap.NewNop(), wg)
+
+			for i := 0; i < 2; i++ {
+				signals <- syscall.SIGHUP
+				select {
+				case <-reloads:
+				case <-time.After(time.Second):
+					t.Fatalf("configuration was not reloaded after signal %d", i+1)
+				}
+			}
+
+			cancel()
+			wg.Wait()
+		})
+	}
+}
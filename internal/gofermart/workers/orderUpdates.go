@@ -0,0 +1,31 @@
+package workers
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/storage/repository"
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// StartOrderUpdateListener - запуск фонового процесса, передающего изменения заказов из хранилища брокеру потоков событий.
+// При потере соединения подписка возобновляется через retryInterval; изменения, пришедшие за это время, теряются,
+// и клиенты получают их при следующем запросе списка заказов.
+func StartOrderUpdateListener(ctx context.Context, listener repository.NotificationRepository, broker *services.OrderUpdateBroker, retryInterval time.Duration, logger *zap.Logger, wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for {
+		err := listener.ListenOrderUpdates(ctx, broker.Publish)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Order update listener failed, reconnecting", zap.Duration("retry_in", retryInterval), zap.Error(err))
+		}
+
+		select {
+		case <-time.After(retryInterval):
+		case <-ctx.Done():
+			logger.Info("Shutting down order update listener goroutine")
+			return
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package workers
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStartOrderUpdateListener(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	broker := services.NewOrderUpdateBroker(services.OrderUpdateBrokerOptions{}, zap.NewNop())
+	subscription, err := broker.Subscribe(1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	update := domain.OrderUpdate{Order: "12345678903", UserID: 1, Status: domain.OrderStatusProcessed}
+	listener := mocks.NewMockNotificationRepository(ctrl)
+	gomock.InOrder(
+		// Потеря соединения не останавливает процесс: подписка возобновляется
+		listener.EXPECT().ListenOrderUpdates(gomock.Any(), gomock.Any()).Return(errors.New("connection lost")),
+		listener.EXPECT().ListenOrderUpdates(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, handler func(update domain.OrderUpdate)) error {
+			handler(update)
+			<-ctx.Done()
+			return nil
+		}),
+	)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	go StartOrderUpdateListener(ctx, listener, broker, time.Millisecond, zap.NewNop(), wg)
+
+	select {
+	case received := <-subscription.Updates():
+		if received != update {
+			t.Errorf("expected %+v, got %+v", update, received)
+		}
+	case <-time.After(time.Second):
+		t.Error("order update was not published to the broker")
+	}
+
+	cancel()
+	wg.Wait()
+}
@@ -8,10 +8,11 @@ import (
 	"time"
 )
 
-// StartOrderStatusUpdater - запуск фонового процесса для обновления статусов заказов
-func StartOrderStatusUpdater(ctx context.Context, orderService services.OrderServiceInterface, logger *zap.Logger, wg *sync.WaitGroup) {
+// StartOrderStatusUpdater - запуск фонового процесса для обновления статусов заказов каждые interval;
+// проход, не завершившийся за timeout, прерывается
+func StartOrderStatusUpdater(ctx context.Context, orderService services.OrderServiceInterface, interval, timeout time.Duration, logger *zap.Logger, wg *sync.WaitGroup) {
 	defer wg.Done()
-	ticker := time.NewTicker(1 * time.Second)
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	var mu sync.Mutex
@@ -25,7 +26,7 @@ func StartOrderStatusUpdater(ctx context.Context, orderService services.OrderSer
 				isUpdating = true
 				mu.Unlock()
 
-				orderCtx, cancel := context.WithTimeout(ctx, 4*time.Second)
+				orderCtx, cancel := context.WithTimeout(ctx, timeout)
 
 				go func() {
 					defer func() {
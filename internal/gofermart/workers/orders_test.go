@@ -47,7 +47,7 @@ func TestStartOrderStatusUpdater(t *testing.T) {
 
 			ctx, cancel := context.WithCancel(context.Background())
 
-			go StartOrderStatusUpdater(ctx, MockOrderServiceInterface, zap.NewNop(), wg)
+			go StartOrderStatusUpdater(ctx, MockOrderServiceInterface, time.Second, 4*time.Second, zap.NewNop(), wg)
 
 			if tc.expectedWait {
 				time.Sleep(2000 * time.Millisecond)
@@ -0,0 +1,34 @@
+package workers
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// StartOutboxRelay - запуск фонового процесса доставки событий outbox. Пока очередной проход доставляет события,
+// следующий запускается сразу, чтобы быстро разобрать накопившуюся очередь; иначе — раз в interval.
+func StartOutboxRelay(ctx context.Context, relay services.OutboxRelayer, interval time.Duration, logger *zap.Logger, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := relay.RelayMessages(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to relay outbox messages", zap.Error(err))
+		}
+		if delivered > 0 && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			logger.Info("Shutting down outbox relay goroutine")
+			return
+		}
+	}
+}
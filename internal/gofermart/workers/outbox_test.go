@@ -0,0 +1,69 @@
+package workers
+
+import (
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStartOutboxRelay(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup func(m *mocks.MockOutboxRelayer, done chan struct{})
+	}{
+		{
+			name: "Drains_Queue_Without_Waiting",
+			setup: func(m *mocks.MockOutboxRelayer, done chan struct{}) {
+				gomock.InOrder(
+					m.EXPECT().RelayMessages(gomock.Any()).Return(100, nil),
+					m.EXPECT().RelayMessages(gomock.Any()).Return(3, nil),
+					m.EXPECT().RelayMessages(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+						close(done)
+						return 0, nil
+					}),
+				)
+			},
+		},
+		{
+			name: "Relay_Error_Does_Not_Stop_Worker",
+			setup: func(m *mocks.MockOutboxRelayer, done chan struct{}) {
+				m.EXPECT().RelayMessages(gomock.Any()).DoAndReturn(func(ctx context.Context) (int, error) {
+					close(done)
+					return 0, errors.New("db error")
+				})
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			done := make(chan struct{})
+			relay := mocks.NewMockOutboxRelayer(ctrl)
+			tc.setup(relay, done)
+
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			ctx, cancel := context.WithCancel(context.Background())
+
+			// Период опроса больше времени теста: все ожидаемые проходы должны выполниться без ожидания тикера
+			go StartOutboxRelay(ctx, relay, time.Hour, zap.NewNop(), wg)
+
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("relay was not called")
+			}
+
+			cancel()
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package workers
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// RateLimitPruner - удаляет корзины ограничения частоты запросов, которые больше не используются
+type RateLimitPruner interface {
+	Prune(ctx context.Context) (int64, error)
+}
+
+// StartRateLimitPruner - запуск фонового процесса, раз в interval удаляющего неиспользуемые корзины ограничения
+// частоты запросов, чтобы их число не росло с каждым новым IP-адресом клиента
+func StartRateLimitPruner(ctx context.Context, pruner RateLimitPruner, interval time.Duration, logger *zap.Logger, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			// Ошибку записывает в журнал сам RateLimiter
+			if pruned, err := pruner.Prune(ctx); err == nil && pruned > 0 {
+				logger.Debug("Pruned idle rate limit buckets", zap.Int64("count", pruned))
+			}
+		case <-ctx.Done():
+			logger.Info("Shutting down rate limit pruning goroutine")
+			return
+		}
+	}
+}
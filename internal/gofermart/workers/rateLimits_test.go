@@ -0,0 +1,57 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+// rateLimitPrunerFunc - RateLimitPruner на основе функции
+type rateLimitPrunerFunc func(ctx context.Context) (int64, error)
+
+func (f rateLimitPrunerFunc) Prune(ctx context.Context) (int64, error) { return f(ctx) }
+
+func TestStartRateLimitPruner(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pruneErr error
+	}{
+		{name: "Prunes_Buckets_Periodically"},
+		{name: "Prune_Error_Does_Not_Stop_Worker", pruneErr: errors.New("connection refused")},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			prunes := make(chan struct{}, 2)
+			pruner := rateLimitPrunerFunc(func(context.Context) (int64, error) {
+				select {
+				case prunes <- struct{}{}:
+				default:
+				}
+				if tc.pruneErr != nil {
+					return 0, tc.pruneErr
+				}
+				return 3, nil
+			})
+
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			ctx, cancel := context.WithCancel(context.Background())
+			go StartRateLimitPruner(ctx, pruner, 10*time.Millisecond, zap.NewNop(), wg)
+
+			for i := 0; i < 2; i++ {
+				select {
+				case <-prunes:
+				case <-time.After(time.Second):
+					t.Fatalf("buckets were not pruned, pass %d", i+1)
+				}
+			}
+
+			cancel()
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package workers
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"beliaev-aa/yp-gofermart/internal/gofermart/statement"
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+const (
+	// statementsCheckInterval — как часто проверяется, сформированы ли выписки за прошедший месяц
+	statementsCheckInterval = time.Hour
+	// statementsRunTimeout — предельное время одного прохода формирования выписок
+	statementsRunTimeout = 10 * time.Minute
+)
+
+// StartMonthlyStatementGenerator - запуск фонового процесса формирования ежемесячных выписок за прошедший месяц.
+// Проверка выполняется сразу при запуске и затем раз в час, поэтому месяц, пропущенный во время простоя, догоняется после старта.
+func StartMonthlyStatementGenerator(ctx context.Context, generator services.MonthlyStatementGenerator, logger *zap.Logger, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(statementsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		runCtx, cancel := context.WithTimeout(ctx, statementsRunTimeout)
+		previousMonth := statement.MonthStart(time.Now()).AddDate(0, -1, 0)
+		if err := generator.GenerateMonthlyStatements(runCtx, previousMonth); err != nil {
+			logger.Error("Failed to generate monthly statements", zap.Error(err))
+		}
+		cancel()
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			logger.Info("Shutting down monthly statement generator goroutine")
+			return
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package workers
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/statement"
+	"beliaev-aa/yp-gofermart/tests/mocks"
+	"context"
+	"errors"
+	"github.com/golang/mock/gomock"
+	"go.uber.org/zap"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStartMonthlyStatementGenerator(t *testing.T) {
+	testCases := []struct {
+		name      string
+		returnErr error
+	}{
+		{
+			name: "Generates_Previous_Month_On_Start",
+		},
+		{
+			name:      "Generation_Error_Does_Not_Stop_Worker",
+			returnErr: errors.New("db error"),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			previousMonth := statement.MonthStart(time.Now()).AddDate(0, -1, 0)
+			called := make(chan struct{})
+
+			generator := mocks.NewMockMonthlyStatementGenerator(ctrl)
+			generator.EXPECT().GenerateMonthlyStatements(gomock.Any(), previousMonth).DoAndReturn(func(ctx context.Context, period time.Time) error {
+				close(called)
+				return tc.returnErr
+			}).Times(1)
+
+			wg := &sync.WaitGroup{}
+			wg.Add(1)
+			ctx, cancel := context.WithCancel(context.Background())
+
+			go StartMonthlyStatementGenerator(ctx, generator, zap.NewNop(), wg)
+
+			select {
+			case <-called:
+			case <-time.After(time.Second):
+				t.Fatal("generator was not called on start")
+			}
+
+			cancel()
+			wg.Wait()
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package workers
+
+import (
+	"beliaev-aa/yp-gofermart/internal/gofermart/services"
+	"context"
+	"go.uber.org/zap"
+	"sync"
+	"time"
+)
+
+// StartWebhookDispatcher - запуск фонового процесса доставки событий на webhook пользователей. Пока очередной проход доставляет события,
+// следующий запускается сразу, чтобы быстро разобрать накопившуюся очередь; иначе — раз в interval.
+func StartWebhookDispatcher(ctx context.Context, dispatcher services.WebhookDispatcher, interval time.Duration, logger *zap.Logger, wg *sync.WaitGroup) {
+	defer wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		delivered, err := dispatcher.DeliverWebhooks(ctx)
+		if err != nil && ctx.Err() == nil {
+			logger.Error("Failed to deliver webhooks", zap.Error(err))
+		}
+		if delivered > 0 && ctx.Err() == nil {
+			continue
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			logger.Info("Shutting down webhook dispatcher goroutine")
+			return
+		}
+	}
+}
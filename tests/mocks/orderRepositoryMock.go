@@ -6,8 +6,8 @@ package mocks
 
 import (
 	domain "beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	context "context"
 	gomock "github.com/golang/mock/gomock"
-	gorm "gorm.io/gorm"
 	reflect "reflect"
 )
 
@@ -35,145 +35,132 @@ func (m *MockOrderRepository) EXPECT() *MockOrderRepositoryMockRecorder {
 }
 
 // AddOrder mocks base method.
-func (m *MockOrderRepository) AddOrder(tx *gorm.DB, order domain.Order) error {
+func (m *MockOrderRepository) AddOrder(ctx context.Context, order domain.Order) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddOrder", tx, order)
+	ret := m.ctrl.Call(m, "AddOrder", ctx, order)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddOrder indicates an expected call of AddOrder.
-func (mr *MockOrderRepositoryMockRecorder) AddOrder(tx, order interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) AddOrder(ctx, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrderRepository)(nil).AddOrder), tx, order)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrderRepository)(nil).AddOrder), ctx, order)
 }
 
-// BeginTransaction mocks base method.
-func (m *MockOrderRepository) BeginTransaction() (*gorm.DB, error) {
+// AddOrders mocks base method.
+func (m *MockOrderRepository) AddOrders(ctx context.Context, orders []domain.Order) ([]string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BeginTransaction")
-	ret0, _ := ret[0].(*gorm.DB)
+	ret := m.ctrl.Call(m, "AddOrders", ctx, orders)
+	ret0, _ := ret[0].([]string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BeginTransaction indicates an expected call of BeginTransaction.
-func (mr *MockOrderRepositoryMockRecorder) BeginTransaction() *gomock.Call {
+// AddOrders indicates an expected call of AddOrders.
+func (mr *MockOrderRepositoryMockRecorder) AddOrders(ctx, orders interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTransaction", reflect.TypeOf((*MockOrderRepository)(nil).BeginTransaction))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrders", reflect.TypeOf((*MockOrderRepository)(nil).AddOrders), ctx, orders)
 }
 
-// Commit mocks base method.
-func (m *MockOrderRepository) Commit(tx *gorm.DB) error {
+// GetOrderByNumber mocks base method.
+func (m *MockOrderRepository) GetOrderByNumber(ctx context.Context, number string) (*domain.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Commit", tx)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetOrderByNumber", ctx, number)
+	ret0, _ := ret[0].(*domain.Order)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Commit indicates an expected call of Commit.
-func (mr *MockOrderRepositoryMockRecorder) Commit(tx interface{}) *gomock.Call {
+// GetOrderByNumber indicates an expected call of GetOrderByNumber.
+func (mr *MockOrderRepositoryMockRecorder) GetOrderByNumber(ctx, number interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockOrderRepository)(nil).Commit), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByNumber", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByNumber), ctx, number)
 }
 
-// GetOrderByNumber mocks base method.
-func (m *MockOrderRepository) GetOrderByNumber(tx *gorm.DB, number string) (*domain.Order, error) {
+// GetOrdersByNumbers mocks base method.
+func (m *MockOrderRepository) GetOrdersByNumbers(ctx context.Context, numbers []string) ([]domain.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrderByNumber", tx, number)
-	ret0, _ := ret[0].(*domain.Order)
+	ret := m.ctrl.Call(m, "GetOrdersByNumbers", ctx, numbers)
+	ret0, _ := ret[0].([]domain.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOrderByNumber indicates an expected call of GetOrderByNumber.
-func (mr *MockOrderRepositoryMockRecorder) GetOrderByNumber(tx, number interface{}) *gomock.Call {
+// GetOrdersByNumbers indicates an expected call of GetOrdersByNumbers.
+func (mr *MockOrderRepositoryMockRecorder) GetOrdersByNumbers(ctx, numbers interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrderByNumber", reflect.TypeOf((*MockOrderRepository)(nil).GetOrderByNumber), tx, number)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByNumbers", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersByNumbers), ctx, numbers)
 }
 
 // GetOrdersByUserID mocks base method.
-func (m *MockOrderRepository) GetOrdersByUserID(tx *gorm.DB, userID int) ([]domain.Order, error) {
+func (m *MockOrderRepository) GetOrdersByUserID(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrdersByUserID", tx, userID)
+	ret := m.ctrl.Call(m, "GetOrdersByUserID", ctx, userID, filter)
 	ret0, _ := ret[0].([]domain.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrdersByUserID indicates an expected call of GetOrdersByUserID.
-func (mr *MockOrderRepositoryMockRecorder) GetOrdersByUserID(tx, userID interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) GetOrdersByUserID(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByUserID", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersByUserID), tx, userID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersByUserID", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersByUserID), ctx, userID, filter)
 }
 
 // GetOrdersForProcessing mocks base method.
-func (m *MockOrderRepository) GetOrdersForProcessing(tx *gorm.DB) ([]domain.Order, error) {
+func (m *MockOrderRepository) GetOrdersForProcessing(ctx context.Context) ([]domain.Order, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrdersForProcessing", tx)
+	ret := m.ctrl.Call(m, "GetOrdersForProcessing", ctx)
 	ret0, _ := ret[0].([]domain.Order)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOrdersForProcessing indicates an expected call of GetOrdersForProcessing.
-func (mr *MockOrderRepositoryMockRecorder) GetOrdersForProcessing(tx interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) GetOrdersForProcessing(ctx interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForProcessing", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersForProcessing), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersForProcessing", reflect.TypeOf((*MockOrderRepository)(nil).GetOrdersForProcessing), ctx)
 }
 
 // LockOrderForProcessing mocks base method.
-func (m *MockOrderRepository) LockOrderForProcessing(tx *gorm.DB, orderNumber string) error {
+func (m *MockOrderRepository) LockOrderForProcessing(ctx context.Context, orderNumber string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LockOrderForProcessing", tx, orderNumber)
+	ret := m.ctrl.Call(m, "LockOrderForProcessing", ctx, orderNumber)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // LockOrderForProcessing indicates an expected call of LockOrderForProcessing.
-func (mr *MockOrderRepositoryMockRecorder) LockOrderForProcessing(tx, orderNumber interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOrderForProcessing", reflect.TypeOf((*MockOrderRepository)(nil).LockOrderForProcessing), tx, orderNumber)
-}
-
-// Rollback mocks base method.
-func (m *MockOrderRepository) Rollback(tx *gorm.DB) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rollback", tx)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Rollback indicates an expected call of Rollback.
-func (mr *MockOrderRepositoryMockRecorder) Rollback(tx interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) LockOrderForProcessing(ctx, orderNumber interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockOrderRepository)(nil).Rollback), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOrderForProcessing", reflect.TypeOf((*MockOrderRepository)(nil).LockOrderForProcessing), ctx, orderNumber)
 }
 
 // UnlockOrder mocks base method.
-func (m *MockOrderRepository) UnlockOrder(tx *gorm.DB, orderNumber string) error {
+func (m *MockOrderRepository) UnlockOrder(ctx context.Context, orderNumber string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UnlockOrder", tx, orderNumber)
+	ret := m.ctrl.Call(m, "UnlockOrder", ctx, orderNumber)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UnlockOrder indicates an expected call of UnlockOrder.
-func (mr *MockOrderRepositoryMockRecorder) UnlockOrder(tx, orderNumber interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) UnlockOrder(ctx, orderNumber interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockOrder", reflect.TypeOf((*MockOrderRepository)(nil).UnlockOrder), tx, orderNumber)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UnlockOrder", reflect.TypeOf((*MockOrderRepository)(nil).UnlockOrder), ctx, orderNumber)
 }
 
 // UpdateOrder mocks base method.
-func (m *MockOrderRepository) UpdateOrder(tx *gorm.DB, order domain.Order) error {
+func (m *MockOrderRepository) UpdateOrder(ctx context.Context, order domain.Order) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOrder", tx, order)
+	ret := m.ctrl.Call(m, "UpdateOrder", ctx, order)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateOrder indicates an expected call of UpdateOrder.
-func (mr *MockOrderRepositoryMockRecorder) UpdateOrder(tx, order interface{}) *gomock.Call {
+func (mr *MockOrderRepositoryMockRecorder) UpdateOrder(ctx, order interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrder), tx, order)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrder", reflect.TypeOf((*MockOrderRepository)(nil).UpdateOrder), ctx, order)
 }
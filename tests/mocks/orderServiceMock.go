@@ -11,7 +11,6 @@ import (
 
 	gomock "github.com/golang/mock/gomock"
 	decimal "github.com/shopspring/decimal"
-	gorm "gorm.io/gorm"
 )
 
 // MockOrderServiceInterface is a mock of OrderServiceInterface interface.
@@ -38,32 +37,62 @@ func (m *MockOrderServiceInterface) EXPECT() *MockOrderServiceInterfaceMockRecor
 }
 
 // AddOrder mocks base method.
-func (m *MockOrderServiceInterface) AddOrder(login, number string) error {
+func (m *MockOrderServiceInterface) AddOrder(ctx context.Context, userID int, number string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddOrder", login, number)
+	ret := m.ctrl.Call(m, "AddOrder", ctx, userID, number)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddOrder indicates an expected call of AddOrder.
-func (mr *MockOrderServiceInterfaceMockRecorder) AddOrder(login, number interface{}) *gomock.Call {
+func (mr *MockOrderServiceInterfaceMockRecorder) AddOrder(ctx, userID, number interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrderServiceInterface)(nil).AddOrder), login, number)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrder", reflect.TypeOf((*MockOrderServiceInterface)(nil).AddOrder), ctx, userID, number)
 }
 
-// GetOrders mocks base method.
-func (m *MockOrderServiceInterface) GetOrders(login string) ([]domain.Order, error) {
+// AddOrders mocks base method.
+func (m *MockOrderServiceInterface) AddOrders(ctx context.Context, userID int, numbers []string) ([]domain.OrderUploadResult, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOrders", login)
-	ret0, _ := ret[0].([]domain.Order)
+	ret := m.ctrl.Call(m, "AddOrders", ctx, userID, numbers)
+	ret0, _ := ret[0].([]domain.OrderUploadResult)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
+// AddOrders indicates an expected call of AddOrders.
+func (mr *MockOrderServiceInterfaceMockRecorder) AddOrders(ctx, userID, numbers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddOrders", reflect.TypeOf((*MockOrderServiceInterface)(nil).AddOrders), ctx, userID, numbers)
+}
+
+// GetOrders mocks base method.
+func (m *MockOrderServiceInterface) GetOrders(ctx context.Context, userID int, filter domain.OrderFilter) ([]domain.Order, *domain.Cursor, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrders", ctx, userID, filter)
+	ret0, _ := ret[0].([]domain.Order)
+	ret1, _ := ret[1].(*domain.Cursor)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
 // GetOrders indicates an expected call of GetOrders.
-func (mr *MockOrderServiceInterfaceMockRecorder) GetOrders(login interface{}) *gomock.Call {
+func (mr *MockOrderServiceInterfaceMockRecorder) GetOrders(ctx, userID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockOrderServiceInterface)(nil).GetOrders), ctx, userID, filter)
+}
+
+// RequeueOrder mocks base method.
+func (m *MockOrderServiceInterface) RequeueOrder(ctx context.Context, number string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueOrder", ctx, number)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequeueOrder indicates an expected call of RequeueOrder.
+func (mr *MockOrderServiceInterfaceMockRecorder) RequeueOrder(ctx, number interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockOrderServiceInterface)(nil).GetOrders), login)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueOrder", reflect.TypeOf((*MockOrderServiceInterface)(nil).RequeueOrder), ctx, number)
 }
 
 // UpdateOrderStatuses mocks base method.
@@ -79,15 +108,15 @@ func (mr *MockOrderServiceInterfaceMockRecorder) UpdateOrderStatuses(ctx interfa
 }
 
 // UpdateUserBalance mocks base method.
-func (m *MockOrderServiceInterface) UpdateUserBalance(tx *gorm.DB, userID int, amount decimal.Decimal) error {
+func (m *MockOrderServiceInterface) UpdateUserBalance(ctx context.Context, userID int, amount decimal.Decimal) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserBalance", tx, userID, amount)
+	ret := m.ctrl.Call(m, "UpdateUserBalance", ctx, userID, amount)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUserBalance indicates an expected call of UpdateUserBalance.
-func (mr *MockOrderServiceInterfaceMockRecorder) UpdateUserBalance(tx, userID, amount interface{}) *gomock.Call {
+func (mr *MockOrderServiceInterfaceMockRecorder) UpdateUserBalance(ctx, userID, amount interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBalance", reflect.TypeOf((*MockOrderServiceInterface)(nil).UpdateUserBalance), tx, userID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBalance", reflect.TypeOf((*MockOrderServiceInterface)(nil).UpdateUserBalance), ctx, userID, amount)
 }
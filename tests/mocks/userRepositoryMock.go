@@ -6,11 +6,11 @@ package mocks
 
 import (
 	domain "beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
 	decimal "github.com/shopspring/decimal"
-	gorm "gorm.io/gorm"
 )
 
 // MockUserRepository is a mock of UserRepository interface.
@@ -36,103 +36,148 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 	return m.recorder
 }
 
-// BeginTransaction mocks base method.
-func (m *MockUserRepository) BeginTransaction() (*gorm.DB, error) {
+// GetUserBalance mocks base method.
+func (m *MockUserRepository) GetUserBalance(ctx context.Context, userID int) (*domain.UserBalance, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BeginTransaction")
-	ret0, _ := ret[0].(*gorm.DB)
+	ret := m.ctrl.Call(m, "GetUserBalance", ctx, userID)
+	ret0, _ := ret[0].(*domain.UserBalance)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// BeginTransaction indicates an expected call of BeginTransaction.
-func (mr *MockUserRepositoryMockRecorder) BeginTransaction() *gomock.Call {
+// GetUserBalance indicates an expected call of GetUserBalance.
+func (mr *MockUserRepositoryMockRecorder) GetUserBalance(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTransaction", reflect.TypeOf((*MockUserRepository)(nil).BeginTransaction))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalance", reflect.TypeOf((*MockUserRepository)(nil).GetUserBalance), ctx, userID)
 }
 
-// Commit mocks base method.
-func (m *MockUserRepository) Commit(tx *gorm.DB) error {
+// GetUserByID mocks base method.
+func (m *MockUserRepository) GetUserByID(ctx context.Context, userID int) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Commit", tx)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetUserByID", ctx, userID)
+	ret0, _ := ret[0].(*domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Commit indicates an expected call of Commit.
-func (mr *MockUserRepositoryMockRecorder) Commit(tx interface{}) *gomock.Call {
+// GetUserByID indicates an expected call of GetUserByID.
+func (mr *MockUserRepositoryMockRecorder) GetUserByID(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockUserRepository)(nil).Commit), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserRepository)(nil).GetUserByID), ctx, userID)
 }
 
-// GetUserBalance mocks base method.
-func (m *MockUserRepository) GetUserBalance(tx *gorm.DB, login string) (*domain.UserBalance, error) {
+// GetUserByLogin mocks base method.
+func (m *MockUserRepository) GetUserByLogin(ctx context.Context, login string) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserBalance", tx, login)
-	ret0, _ := ret[0].(*domain.UserBalance)
+	ret := m.ctrl.Call(m, "GetUserByLogin", ctx, login)
+	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserBalance indicates an expected call of GetUserBalance.
-func (mr *MockUserRepositoryMockRecorder) GetUserBalance(tx, login interface{}) *gomock.Call {
+// GetUserByLogin indicates an expected call of GetUserByLogin.
+func (mr *MockUserRepositoryMockRecorder) GetUserByLogin(ctx, login interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserBalance", reflect.TypeOf((*MockUserRepository)(nil).GetUserBalance), tx, login)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockUserRepository)(nil).GetUserByLogin), ctx, login)
 }
 
-// GetUserByLogin mocks base method.
-func (m *MockUserRepository) GetUserByLogin(tx *gorm.DB, login string) (*domain.User, error) {
+// GetUserForUpdate mocks base method.
+func (m *MockUserRepository) GetUserForUpdate(ctx context.Context, userID int) (*domain.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserByLogin", tx, login)
+	ret := m.ctrl.Call(m, "GetUserForUpdate", ctx, userID)
 	ret0, _ := ret[0].(*domain.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetUserByLogin indicates an expected call of GetUserByLogin.
-func (mr *MockUserRepositoryMockRecorder) GetUserByLogin(tx, login interface{}) *gomock.Call {
+// GetUserForUpdate indicates an expected call of GetUserForUpdate.
+func (mr *MockUserRepositoryMockRecorder) GetUserForUpdate(ctx, userID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLogin", reflect.TypeOf((*MockUserRepository)(nil).GetUserByLogin), tx, login)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserForUpdate", reflect.TypeOf((*MockUserRepository)(nil).GetUserForUpdate), ctx, userID)
 }
 
-// Rollback mocks base method.
-func (m *MockUserRepository) Rollback(tx *gorm.DB) error {
+// GetUserVersion mocks base method.
+func (m *MockUserRepository) GetUserVersion(ctx context.Context, userID int) (int64, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rollback", tx)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetUserVersion", ctx, userID)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserVersion indicates an expected call of GetUserVersion.
+func (mr *MockUserRepositoryMockRecorder) GetUserVersion(ctx, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserVersion", reflect.TypeOf((*MockUserRepository)(nil).GetUserVersion), ctx, userID)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserRepository) ListUsers(ctx context.Context, afterID int, limit int) ([]domain.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", ctx, afterID, limit)
+	ret0, _ := ret[0].([]domain.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Rollback indicates an expected call of Rollback.
-func (mr *MockUserRepositoryMockRecorder) Rollback(tx interface{}) *gomock.Call {
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserRepositoryMockRecorder) ListUsers(ctx, afterID, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockUserRepository)(nil).Rollback), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserRepository)(nil).ListUsers), ctx, afterID, limit)
 }
 
 // SaveUser mocks base method.
-func (m *MockUserRepository) SaveUser(tx *gorm.DB, user domain.User) error {
+func (m *MockUserRepository) SaveUser(ctx context.Context, user domain.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SaveUser", tx, user)
+	ret := m.ctrl.Call(m, "SaveUser", ctx, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // SaveUser indicates an expected call of SaveUser.
-func (mr *MockUserRepositoryMockRecorder) SaveUser(tx, user interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) SaveUser(ctx, user interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockUserRepository)(nil).SaveUser), ctx, user)
+}
+
+// SetUserBlocked mocks base method.
+func (m *MockUserRepository) SetUserBlocked(ctx context.Context, userID int, blocked bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserBlocked", ctx, userID, blocked)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserBlocked indicates an expected call of SetUserBlocked.
+func (mr *MockUserRepositoryMockRecorder) SetUserBlocked(ctx, userID, blocked interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserBlocked", reflect.TypeOf((*MockUserRepository)(nil).SetUserBlocked), ctx, userID, blocked)
+}
+
+// SetUserRoles mocks base method.
+func (m *MockUserRepository) SetUserRoles(ctx context.Context, userID int, roles domain.Roles) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserRoles", ctx, userID, roles)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserRoles indicates an expected call of SetUserRoles.
+func (mr *MockUserRepositoryMockRecorder) SetUserRoles(ctx, userID, roles interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveUser", reflect.TypeOf((*MockUserRepository)(nil).SaveUser), tx, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserRoles", reflect.TypeOf((*MockUserRepository)(nil).SetUserRoles), ctx, userID, roles)
 }
 
 // UpdateUserBalance mocks base method.
-func (m *MockUserRepository) UpdateUserBalance(tx *gorm.DB, userID int, amount decimal.Decimal) error {
+func (m *MockUserRepository) UpdateUserBalance(ctx context.Context, userID int, amount decimal.Decimal) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateUserBalance", tx, userID, amount)
+	ret := m.ctrl.Call(m, "UpdateUserBalance", ctx, userID, amount)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateUserBalance indicates an expected call of UpdateUserBalance.
-func (mr *MockUserRepositoryMockRecorder) UpdateUserBalance(tx, userID, amount interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) UpdateUserBalance(ctx, userID, amount interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBalance", reflect.TypeOf((*MockUserRepository)(nil).UpdateUserBalance), tx, userID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserBalance", reflect.TypeOf((*MockUserRepository)(nil).UpdateUserBalance), ctx, userID, amount)
 }
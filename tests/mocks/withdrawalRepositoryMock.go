@@ -6,8 +6,8 @@ package mocks
 
 import (
 	domain "beliaev-aa/yp-gofermart/internal/gofermart/domain"
+	context "context"
 	gomock "github.com/golang/mock/gomock"
-	gorm "gorm.io/gorm"
 	reflect "reflect"
 )
 
@@ -35,73 +35,30 @@ func (m *MockWithdrawalRepository) EXPECT() *MockWithdrawalRepositoryMockRecorde
 }
 
 // AddWithdrawal mocks base method.
-func (m *MockWithdrawalRepository) AddWithdrawal(tx *gorm.DB, withdrawal domain.Withdrawal) error {
+func (m *MockWithdrawalRepository) AddWithdrawal(ctx context.Context, withdrawal domain.Withdrawal) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "AddWithdrawal", tx, withdrawal)
+	ret := m.ctrl.Call(m, "AddWithdrawal", ctx, withdrawal)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // AddWithdrawal indicates an expected call of AddWithdrawal.
-func (mr *MockWithdrawalRepositoryMockRecorder) AddWithdrawal(tx, withdrawal interface{}) *gomock.Call {
+func (mr *MockWithdrawalRepositoryMockRecorder) AddWithdrawal(ctx, withdrawal interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithdrawal", reflect.TypeOf((*MockWithdrawalRepository)(nil).AddWithdrawal), tx, withdrawal)
-}
-
-// BeginTransaction mocks base method.
-func (m *MockWithdrawalRepository) BeginTransaction() (*gorm.DB, error) {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BeginTransaction")
-	ret0, _ := ret[0].(*gorm.DB)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
-}
-
-// BeginTransaction indicates an expected call of BeginTransaction.
-func (mr *MockWithdrawalRepositoryMockRecorder) BeginTransaction() *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTransaction", reflect.TypeOf((*MockWithdrawalRepository)(nil).BeginTransaction))
-}
-
-// Commit mocks base method.
-func (m *MockWithdrawalRepository) Commit(tx *gorm.DB) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Commit", tx)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Commit indicates an expected call of Commit.
-func (mr *MockWithdrawalRepositoryMockRecorder) Commit(tx interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Commit", reflect.TypeOf((*MockWithdrawalRepository)(nil).Commit), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddWithdrawal", reflect.TypeOf((*MockWithdrawalRepository)(nil).AddWithdrawal), ctx, withdrawal)
 }
 
 // GetWithdrawalsByUserID mocks base method.
-func (m *MockWithdrawalRepository) GetWithdrawalsByUserID(tx *gorm.DB, userID int) ([]domain.Withdrawal, error) {
+func (m *MockWithdrawalRepository) GetWithdrawalsByUserID(ctx context.Context, userID int, filter domain.WithdrawalFilter) ([]domain.Withdrawal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetWithdrawalsByUserID", tx, userID)
+	ret := m.ctrl.Call(m, "GetWithdrawalsByUserID", ctx, userID, filter)
 	ret0, _ := ret[0].([]domain.Withdrawal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetWithdrawalsByUserID indicates an expected call of GetWithdrawalsByUserID.
-func (mr *MockWithdrawalRepositoryMockRecorder) GetWithdrawalsByUserID(tx, userID interface{}) *gomock.Call {
-	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalsByUserID", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetWithdrawalsByUserID), tx, userID)
-}
-
-// Rollback mocks base method.
-func (m *MockWithdrawalRepository) Rollback(tx *gorm.DB) error {
-	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Rollback", tx)
-	ret0, _ := ret[0].(error)
-	return ret0
-}
-
-// Rollback indicates an expected call of Rollback.
-func (mr *MockWithdrawalRepositoryMockRecorder) Rollback(tx interface{}) *gomock.Call {
+func (mr *MockWithdrawalRepositoryMockRecorder) GetWithdrawalsByUserID(ctx, userID, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rollback", reflect.TypeOf((*MockWithdrawalRepository)(nil).Rollback), tx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWithdrawalsByUserID", reflect.TypeOf((*MockWithdrawalRepository)(nil).GetWithdrawalsByUserID), ctx, userID, filter)
 }